@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshery-traefik-mesh/templates"
 	"github.com/layer5io/meshkit/utils"
 	"github.com/layer5io/meshkit/utils/manifests"
 	walker "github.com/layer5io/meshkit/utils/walker"
@@ -49,7 +50,7 @@ func NewConfig(version string) manifests.Config {
 }
 
 func init() {
-	wd, _ := os.Getwd()
+	root, _ := templates.Root()
 	f, _ := os.Open("./build/meshmodel_metadata.json")
 	defer func() {
 		err := f.Close()
@@ -60,8 +61,8 @@ func init() {
 	byt, _ := io.ReadAll(f)
 
 	_ = json.Unmarshal(byt, &meshmodelmetadata)
-	WorkloadPath = filepath.Join(wd, "templates", "oam", "workloads")
-	MeshModelPath = filepath.Join(wd, "templates", "meshmodel", "components")
+	WorkloadPath = filepath.Join(root, "oam", "workloads")
+	MeshModelPath = filepath.Join(root, "meshmodel", "components")
 	AllVersions, _ = utils.GetLatestReleaseTagsSorted("traefik", "mesh")
 	if len(AllVersions) == 0 {
 		return
@@ -0,0 +1,38 @@
+package config
+
+import "time"
+
+const (
+	// BackoffMaxElapsedTimeEnv is the environment variable used to override the time after
+	// which every retry path gives up, e.g. 5m. A path is overridden by suffixing the
+	// variable with its name, e.g. BACKOFF_MAX_ELAPSED_TIME_REGISTRATION.
+	BackoffMaxElapsedTimeEnv = "BACKOFF_MAX_ELAPSED_TIME"
+
+	// RegistrationBackoffPath retries the registration of the components with Meshery server
+	RegistrationBackoffPath = "REGISTRATION"
+
+	// KubeBackoffPath retries the transient errors of the kubernetes API
+	KubeBackoffPath = "KUBE"
+
+	// ReadinessBackoffPath retries the readiness checks of the installed resources
+	ReadinessBackoffPath = "READINESS"
+
+	// ReleasesBackoffPath retries the requests to the GitHub releases API. The versions are
+	// resolved on startup, so without an override of the path or of every path it defaults to a minute.
+	ReleasesBackoffPath = "RELEASES"
+
+	defaultBackoffMaxElapsedTime         = 10 * time.Minute
+	defaultReleasesBackoffMaxElapsedTime = time.Minute
+)
+
+// BackoffCap returns the time after which the retry path gives up, the override of the
+// path takes precedence over the default cap
+func BackoffCap(path string) time.Duration {
+	return durationFromEnv(BackoffMaxElapsedTimeEnv+"_"+path, durationFromEnv(BackoffMaxElapsedTimeEnv, defaultBackoffMaxElapsedTime))
+}
+
+// ReleasesBackoffCap returns the time after which the requests to the GitHub releases API give up, the
+// override of the path takes precedence over the one of every path
+func ReleasesBackoffCap() time.Duration {
+	return durationFromEnv(BackoffMaxElapsedTimeEnv+"_"+ReleasesBackoffPath, durationFromEnv(BackoffMaxElapsedTimeEnv, defaultReleasesBackoffMaxElapsedTime))
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffCap(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		path     string
+		want     time.Duration
+		releases time.Duration
+	}{
+		{name: "defaults", path: KubeBackoffPath, want: 10 * time.Minute, releases: time.Minute},
+		{
+			name:     "default cap",
+			env:      map[string]string{BackoffMaxElapsedTimeEnv: "5m"},
+			path:     KubeBackoffPath,
+			want:     5 * time.Minute,
+			releases: 5 * time.Minute,
+		},
+		{
+			name:     "path override",
+			env:      map[string]string{BackoffMaxElapsedTimeEnv: "5m", BackoffMaxElapsedTimeEnv + "_" + ReadinessBackoffPath: "30s"},
+			path:     ReadinessBackoffPath,
+			want:     30 * time.Second,
+			releases: 5 * time.Minute,
+		},
+		{
+			name:     "override of another path",
+			env:      map[string]string{BackoffMaxElapsedTimeEnv: "5m", BackoffMaxElapsedTimeEnv + "_" + ReleasesBackoffPath: "20s"},
+			path:     RegistrationBackoffPath,
+			want:     5 * time.Minute,
+			releases: 20 * time.Second,
+		},
+		{
+			name:     "non-positive caps",
+			env:      map[string]string{BackoffMaxElapsedTimeEnv: "-1m", BackoffMaxElapsedTimeEnv + "_" + KubeBackoffPath: "0s"},
+			path:     KubeBackoffPath,
+			want:     10 * time.Minute,
+			releases: time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, path := range []string{"", "_" + RegistrationBackoffPath, "_" + KubeBackoffPath, "_" + ReadinessBackoffPath, "_" + ReleasesBackoffPath} {
+				t.Setenv(BackoffMaxElapsedTimeEnv+path, tt.env[BackoffMaxElapsedTimeEnv+path])
+			}
+			if got := BackoffCap(tt.path); got != tt.want {
+				t.Errorf("expected the cap %s of %s, got %s", tt.want, tt.path, got)
+			}
+			if got := ReleasesBackoffCap(); got != tt.releases {
+				t.Errorf("expected the releases cap %s, got %s", tt.releases, got)
+			}
+		})
+	}
+}
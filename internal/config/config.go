@@ -1,6 +1,7 @@
 package config
 
 import (
+	"os"
 	"path"
 	"strings"
 
@@ -98,6 +99,11 @@ func New(provider string) (h config.Handler, err error) {
 		return nil, err
 	}
 
+	// Fail early on a misconfigured default profile rather than on the first install
+	if err := ValidateProfile(h.GetKey(DefaultProfileKey)); err != nil {
+		return nil, err
+	}
+
 	return h, nil
 }
 
@@ -125,3 +131,12 @@ func NewKubeconfigBuilder(provider string) (config.Handler, error) {
 func RootPath() string {
 	return configRootPath
 }
+
+// BinPath returns the bin directory of the config root path, creating it if needed
+func BinPath() (string, error) {
+	dir := path.Join(configRootPath, "bin")
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return "", ErrBinDirSetup(dir, err)
+	}
+	return dir, nil
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/layer5io/meshkit/errors"
+)
+
+func TestBinPathCreatedWhenNeeded(t *testing.T) {
+	root := configRootPath
+	t.Cleanup(func() { configRootPath = root })
+
+	// the root path is a file, as on a read only or foreign home directory
+	configRootPath = path.Join(t.TempDir(), ".meshery")
+	if err := os.WriteFile(configRootPath, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := BinPath(); errors.GetCode(err) != ErrBinDirSetupCode {
+		t.Fatalf("expected the error code %s, got %v", ErrBinDirSetupCode, err)
+	}
+
+	// the operations using the directory create it once the root path is usable
+	if err := os.Remove(configRootPath); err != nil {
+		t.Fatal(err)
+	}
+	dir, err := BinPath()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		t.Fatalf("expected the bin directory %s to be created: %v", dir, err)
+	}
+}
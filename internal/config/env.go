@@ -0,0 +1,492 @@
+package config
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/layer5io/meshery-traefik-mesh/internal/store"
+)
+
+const (
+	// ChartDownloadTimeoutEnv is the environment variable used to override
+	// the timeout for downloading helm charts and indexes
+	ChartDownloadTimeoutEnv = "CHART_DOWNLOAD_TIMEOUT"
+
+	// ChartMaxSizeEnv is the environment variable used to override the
+	// maximum size (in bytes) of a downloaded helm chart or index
+	ChartMaxSizeEnv = "CHART_MAX_SIZE"
+
+	// ListenAddressEnv is the environment variable used to bind the gRPC
+	// server to a specific interface, e.g. the pod IP or localhost
+	ListenAddressEnv = "LISTEN_ADDR"
+
+	// ResultStoreEndpointEnv is the environment variable holding the URL of the
+	// S3-compatible object storage used for large operation results
+	ResultStoreEndpointEnv = "RESULT_STORE_ENDPOINT"
+
+	// ResultStoreBucketEnv is the environment variable holding the bucket name
+	ResultStoreBucketEnv = "RESULT_STORE_BUCKET"
+
+	// ResultStoreRegionEnv is the environment variable holding the bucket region
+	ResultStoreRegionEnv = "RESULT_STORE_REGION"
+
+	// ResultStoreAccessKeyEnv is the environment variable holding the access key id
+	ResultStoreAccessKeyEnv = "RESULT_STORE_ACCESS_KEY"
+
+	// ResultStoreSecretKeyEnv is the environment variable holding the secret access key
+	ResultStoreSecretKeyEnv = "RESULT_STORE_SECRET_KEY"
+
+	// ResultStoreThresholdEnv is the environment variable used to override the size
+	// (in bytes) above which operation results are uploaded instead of streamed inline
+	ResultStoreThresholdEnv = "RESULT_STORE_THRESHOLD"
+
+	// ResultMaxSizeEnv is the environment variable used to override the maximum size
+	// (in bytes) of an operation result streamed inline, the larger results fail
+	// unless they are uploaded to the object storage
+	ResultMaxSizeEnv = "RESULT_MAX_SIZE"
+
+	// MaxConcurrentOperationsEnv is the environment variable used to override the
+	// maximum number of operations processed concurrently
+	MaxConcurrentOperationsEnv = "MAX_CONCURRENT_OPERATIONS"
+
+	// NamespaceScanConcurrencyEnv is the environment variable used to override the
+	// number of namespaces of a cluster scanned concurrently by an operation
+	NamespaceScanConcurrencyEnv = "NAMESPACE_SCAN_CONCURRENCY"
+
+	// OperationQueueModeEnv is the environment variable deciding whether operations
+	// beyond the concurrency limit are queued ("queue") or rejected ("reject")
+	OperationQueueModeEnv = "OPERATION_QUEUE_MODE"
+
+	// KubeRetryMaxAttemptsEnv is the environment variable used to override the number
+	// of attempts made for a kubernetes API call failing with a transient error
+	KubeRetryMaxAttemptsEnv = "KUBE_RETRY_MAX_ATTEMPTS"
+
+	// KubeRetryInitialIntervalEnv is the environment variable used to override the
+	// delay before the first retry of a kubernetes API call
+	KubeRetryInitialIntervalEnv = "KUBE_RETRY_INITIAL_INTERVAL"
+
+	// RequiredNamespaceLabelsEnv is the environment variable holding the labels the
+	// mesh namespace must carry, as a comma separated list of key=value pairs. A key
+	// without a value only needs to be present.
+	RequiredNamespaceLabelsEnv = "REQUIRED_NAMESPACE_LABELS"
+
+	// DrainTimeoutEnv is the environment variable used to override how long the adapter
+	// waits for the in-flight operations on shutdown before exiting anyway
+	DrainTimeoutEnv = "SHUTDOWN_DRAIN_TIMEOUT"
+
+	// RegistrationOrderEnv is the environment variable holding the components to register
+	// first with Meshery, as a comma separated list of component names, e.g. "traefikmesh,trafficsplit".
+	// The components which are not listed are registered afterwards in the filesystem order.
+	RegistrationOrderEnv = "REGISTRATION_ORDER"
+
+	// LogMaxLineLengthEnv is the environment variable used to override the maximum
+	// length (in bytes) of a log line streamed by the adapter, longer lines are truncated
+	LogMaxLineLengthEnv = "LOG_MAX_LINE_LENGTH"
+
+	// LogFormatEnv is the environment variable selecting the format of the adapter logs,
+	// syslog (the default) or json
+	LogFormatEnv = "LOG_FORMAT"
+
+	// KubeconfigSetupModeEnv is the environment variable deciding whether a failure to
+	// set up KUBECONFIG on startup is fatal ("strict") or starts the adapter with the
+	// cluster operations unavailable ("lenient", the default)
+	KubeconfigSetupModeEnv = "KUBECONFIG_SETUP_MODE"
+
+	// BinDirSetupModeEnv is the environment variable deciding whether a failure to
+	// create the bin directory on startup is fatal ("strict", the default) or defers
+	// its creation to the operations which need it ("lenient")
+	BinDirSetupModeEnv = "BIN_DIR_SETUP_MODE"
+
+	// ComponentMetadataEnv is the environment variable holding the metadata added to
+	// the registered components, as a comma separated list of key=value pairs, e.g.
+	// "team=payments,environment=staging"
+	ComponentMetadataEnv = "COMPONENT_METADATA"
+
+	// RegistrationBatchSizeEnv is the environment variable used to register several
+	// components per request with Meshery server. The components are registered one
+	// by one when it is unset or when the server rejects the batches.
+	RegistrationBatchSizeEnv = "REGISTRATION_BATCH_SIZE"
+
+	// RegistrationSkipEventsEnv is the environment variable used to stop streaming an
+	// event when the dynamic component registration is skipped, set it to "false"
+	RegistrationSkipEventsEnv = "REGISTRATION_SKIP_EVENTS"
+
+	// RegistrationSchemaModeEnv is the environment variable deciding whether a component
+	// whose schema is not a valid JSON Schema is skipped with a warning ("skip") or fails
+	// the registration ("fail")
+	RegistrationSchemaModeEnv = "REGISTRATION_SCHEMA_MODE"
+
+	// ForceDynamicRegistrationEnv is the environment variable used to generate the components
+	// dynamically even when they are available statically, and to register all of them with
+	// Meshery server on every cycle instead of the ones which changed, set it to "true"
+	ForceDynamicRegistrationEnv = "FORCE_DYNAMIC_REG"
+
+	// KubeContextEnv is the environment variable used to select the context of the kubeconfigs
+	// the operations run against, the current context is used when it is unset
+	KubeContextEnv = "KUBE_CONTEXT"
+
+	// RetentionMaxFilesEnv is the environment variable used to override the number of
+	// files kept in each directory of the config root path the adapter writes to
+	RetentionMaxFilesEnv = "RETENTION_MAX_FILES"
+
+	// RetentionMaxAgeEnv is the environment variable used to override the age after
+	// which the files written by the adapter are removed, e.g. 72h
+	RetentionMaxAgeEnv = "RETENTION_MAX_AGE"
+
+	// DefaultNamespaceEnv is the environment variable used to override the namespace
+	// the operations target when the request doesn't specify one
+	DefaultNamespaceEnv = "DEFAULT_NAMESPACE"
+
+	// DynamicRegistrationIntervalEnv is the environment variable used to override the
+	// interval at which the dynamic components are registered again, e.g. 1h
+	DynamicRegistrationIntervalEnv = "DYNAMIC_REG_INTERVAL"
+
+	// TLSMinVersionEnv is the environment variable used to raise the minimum TLS
+	// version of the outbound HTTP clients, 1.2 (the default) or 1.3
+	TLSMinVersionEnv = "TLS_MIN_VERSION"
+
+	// HealthPortEnv is the environment variable holding the port of the HTTP health
+	// endpoint used by the liveness and readiness probes, along with the operation
+	// history on /operations and the prometheus metrics on /metrics. It is not served
+	// when unset, the health of the adapter is always served by the gRPC health service.
+	HealthPortEnv = "HEALTH_PORT"
+
+	// HelmIndexCacheTTLEnv is the environment variable used to override how long a
+	// downloaded helm index is used before it is downloaded again, e.g. 1m
+	HelmIndexCacheTTLEnv = "HELM_INDEX_CACHE_TTL"
+
+	// GitHubTokenEnv is the environment variable holding the token authenticating the
+	// requests to the GitHub API, they are anonymous and heavily rate limited without it
+	GitHubTokenEnv = "GITHUB_TOKEN"
+
+	// CRDBaseURLEnv is the environment variable used to override the base URL the CRDs of
+	// the dynamically generated components are downloaded from, e.g. an internal mirror
+	CRDBaseURLEnv = "CRD_BASE_URL"
+
+	// HelmRepositoryURLEnv is the environment variable used to override the helm repository
+	// hosting the traefik mesh chart, e.g. an internal mirror in air-gapped environments
+	HelmRepositoryURLEnv = "HELM_REPOSITORY_URL"
+
+	// OperationHistorySizeEnv is the environment variable used to override the number
+	// of operations kept in the history of the adapter
+	OperationHistorySizeEnv = "OPERATION_HISTORY_SIZE"
+
+	// EventReplayBufferSizeEnv is the environment variable used to override the number of
+	// events replayed to a client reconnecting to the event stream
+	EventReplayBufferSizeEnv = "EVENT_REPLAY_BUFFER_SIZE"
+
+	// ResourceLabelsEnv is the environment variable holding the labels added to the
+	// resources created by the adapter, as a comma separated list of key=value pairs,
+	// e.g. "team=payments,environment=staging"
+	ResourceLabelsEnv = "RESOURCE_LABELS"
+
+	// ResourceAnnotationsEnv is the environment variable holding the annotations added
+	// to the resources created by the adapter, as a comma separated list of key=value pairs
+	ResourceAnnotationsEnv = "RESOURCE_ANNOTATIONS"
+
+	// OperationTimeoutsEnv is the environment variable holding the time the operations may take, waiting
+	// for the resources they apply to become ready included, as a comma separated list of operation=duration
+	// pairs, e.g. "traefik_mesh=20m". The other operations time out after 30 minutes.
+	OperationTimeoutsEnv = "OPERATION_TIMEOUTS"
+
+	// VersionsTimeoutEnv is the environment variable used to override how long the adapter
+	// looks up the traefik mesh versions it offers before giving up, e.g. 30s
+	VersionsTimeoutEnv = "VERSIONS_TIMEOUT"
+
+	defaultChartDownloadTimeout = 2 * time.Minute
+	defaultChartMaxSize         = 20 << 20 // 20 MiB
+	defaultResultStoreThreshold = 64 << 10 // 64 KiB
+	defaultResultMaxSize        = 2 << 20  // 2 MiB, half the default gRPC message size limit
+	defaultMaxConcurrentOps     = 10
+	defaultNamespaceScanWorkers = 4
+	defaultKubeRetryAttempts    = 5
+	defaultKubeRetryInterval    = 500 * time.Millisecond
+	defaultDrainTimeout         = 30 * time.Second
+	defaultLogMaxLineLength     = 4 << 10 // 4 KiB
+	defaultRetentionMaxFiles    = 100
+	defaultRetentionMaxAge      = 7 * 24 * time.Hour
+	defaultNamespace            = "default"
+	defaultDynamicRegInterval   = 24 * time.Hour
+	defaultHelmIndexCacheTTL    = 5 * time.Minute
+	defaultHelmRepositoryURL    = "https://helm.traefik.io/mesh"
+	defaultOperationHistorySize = 50
+	defaultEventReplayBuffer    = 200
+	defaultVersionsTimeout      = 15 * time.Second
+	defaultOperationTimeout     = 30 * time.Minute
+)
+
+// ChartDownloadTimeout returns the timeout applied to chart downloads
+func ChartDownloadTimeout() time.Duration {
+	return durationFromEnv(ChartDownloadTimeoutEnv, defaultChartDownloadTimeout)
+}
+
+// VersionsTimeout returns how long the lookup of the offered traefik mesh versions may take
+func VersionsTimeout() time.Duration {
+	return durationFromEnv(VersionsTimeoutEnv, defaultVersionsTimeout)
+}
+
+// ChartMaxSize returns the maximum number of bytes accepted for a chart download
+func ChartMaxSize() int64 {
+	return int64FromEnv(ChartMaxSizeEnv, defaultChartMaxSize)
+}
+
+// ListenHost returns the validated host the gRPC server binds to. An empty
+// host binds the server to all the interfaces.
+func ListenHost() (string, error) {
+	host := os.Getenv(ListenAddressEnv)
+	if host == "" || host == "localhost" {
+		return host, nil
+	}
+	if net.ParseIP(host) == nil {
+		return "", ErrInvalidListenAddress(host)
+	}
+	return host, nil
+}
+
+// HealthPort returns the port of the HTTP health endpoint, empty when it is unset or invalid
+func HealthPort() string {
+	raw := os.Getenv(HealthPortEnv)
+	if port, err := strconv.Atoi(raw); err != nil || port < 1 || port > 65535 {
+		return ""
+	}
+	return raw
+}
+
+// ResultStore returns the object storage configuration, results are always
+// streamed inline when no endpoint is configured
+func ResultStore() (store.Config, bool) {
+	cfg := store.Config{
+		Endpoint:  os.Getenv(ResultStoreEndpointEnv),
+		Bucket:    os.Getenv(ResultStoreBucketEnv),
+		Region:    os.Getenv(ResultStoreRegionEnv),
+		AccessKey: os.Getenv(ResultStoreAccessKeyEnv),
+		SecretKey: os.Getenv(ResultStoreSecretKeyEnv),
+		Timeout:   ChartDownloadTimeout(),
+		Transport: HTTPTransport(),
+	}
+	return cfg, cfg.Endpoint != ""
+}
+
+// ResultStoreThreshold returns the size above which results are uploaded to the object storage
+func ResultStoreThreshold() int {
+	return int(int64FromEnv(ResultStoreThresholdEnv, defaultResultStoreThreshold))
+}
+
+// NamespaceScanConcurrency returns the number of namespaces of a cluster scanned concurrently
+func NamespaceScanConcurrency() int {
+	return int(int64FromEnv(NamespaceScanConcurrencyEnv, defaultNamespaceScanWorkers))
+}
+
+// ResultMaxSize returns the maximum size of an operation result streamed inline
+func ResultMaxSize() int {
+	return int(int64FromEnv(ResultMaxSizeEnv, defaultResultMaxSize))
+}
+
+// MaxConcurrentOperations returns the maximum number of operations processed concurrently
+func MaxConcurrentOperations() int {
+	return int(int64FromEnv(MaxConcurrentOperationsEnv, defaultMaxConcurrentOps))
+}
+
+// RejectExcessOperations reports whether the operations beyond the concurrency
+// limit are rejected instead of queued
+func RejectExcessOperations() bool {
+	return os.Getenv(OperationQueueModeEnv) == "reject"
+}
+
+// KubeRetryMaxAttempts returns the number of attempts made for a kubernetes API call
+func KubeRetryMaxAttempts() int {
+	return int(int64FromEnv(KubeRetryMaxAttemptsEnv, defaultKubeRetryAttempts))
+}
+
+// KubeRetryInitialInterval returns the delay before the first retry of a kubernetes API call
+func KubeRetryInitialInterval() time.Duration {
+	return durationFromEnv(KubeRetryInitialIntervalEnv, defaultKubeRetryInterval)
+}
+
+// RequiredNamespaceLabels returns the labels the mesh namespace must carry
+func RequiredNamespaceLabels() (map[string]string, error) {
+	return keyValuesFromEnv(RequiredNamespaceLabelsEnv)
+}
+
+// ComponentMetadata returns the metadata added to the registered components
+func ComponentMetadata() (map[string]string, error) {
+	return keyValuesFromEnv(ComponentMetadataEnv)
+}
+
+// ResourceLabels returns the labels added to the resources created by the adapter
+func ResourceLabels() (map[string]string, error) {
+	return keyValuesFromEnv(ResourceLabelsEnv)
+}
+
+// ResourceAnnotations returns the annotations added to the resources created by the adapter
+func ResourceAnnotations() (map[string]string, error) {
+	return keyValuesFromEnv(ResourceAnnotationsEnv)
+}
+
+// OperationTimeout returns the time the operation may take, 30 minutes unless OPERATION_TIMEOUTS sets it
+func OperationTimeout(operation string) time.Duration {
+	timeouts, _ := keyValuesFromEnv(OperationTimeoutsEnv)
+	if d, err := time.ParseDuration(timeouts[operation]); err == nil && d > 0 {
+		return d
+	}
+	return defaultOperationTimeout
+}
+
+// DrainTimeout returns how long the in-flight operations are waited for on shutdown
+func DrainTimeout() time.Duration {
+	return durationFromEnv(DrainTimeoutEnv, defaultDrainTimeout)
+}
+
+// LogFormat returns the format of the adapter logs
+func LogFormat() string {
+	if format := os.Getenv(LogFormatEnv); format != "" {
+		return format
+	}
+	return "syslog"
+}
+
+// LogMaxLineLength returns the maximum length of a streamed log line
+func LogMaxLineLength() int {
+	return int(int64FromEnv(LogMaxLineLengthEnv, defaultLogMaxLineLength))
+}
+
+// RetentionMaxFiles returns the number of files kept in each directory written by the adapter
+func RetentionMaxFiles() int {
+	return int(int64FromEnv(RetentionMaxFilesEnv, defaultRetentionMaxFiles))
+}
+
+// RetentionMaxAge returns the age after which the files written by the adapter are removed
+func RetentionMaxAge() time.Duration {
+	return durationFromEnv(RetentionMaxAgeEnv, defaultRetentionMaxAge)
+}
+
+// DynamicRegistrationInterval returns the interval at which the dynamic components are registered again
+func DynamicRegistrationInterval() time.Duration {
+	return durationFromEnv(DynamicRegistrationIntervalEnv, defaultDynamicRegInterval)
+}
+
+// HelmIndexCacheTTL returns how long a downloaded helm index is used before it is downloaded again
+func HelmIndexCacheTTL() time.Duration {
+	return durationFromEnv(HelmIndexCacheTTLEnv, defaultHelmIndexCacheTTL)
+}
+
+// CRDBaseURL returns the base URL the CRDs are downloaded from, def when it isn't overridden
+func CRDBaseURL(def string) string {
+	raw := os.Getenv(CRDBaseURLEnv)
+	if raw == "" {
+		return def
+	}
+	return strings.TrimSuffix(raw, "/") + "/"
+}
+
+// HelmRepositoryURL returns the helm repository hosting the traefik mesh chart
+func HelmRepositoryURL() string {
+	if raw := os.Getenv(HelmRepositoryURLEnv); raw != "" {
+		return strings.TrimSuffix(raw, "/")
+	}
+	return defaultHelmRepositoryURL
+}
+
+// OperationHistorySize returns the number of operations kept in the history of the adapter
+func OperationHistorySize() int {
+	return int(int64FromEnv(OperationHistorySizeEnv, defaultOperationHistorySize))
+}
+
+// EventReplayBufferSize returns the number of events replayed to a client reconnecting to the event stream
+func EventReplayBufferSize() int {
+	return int(int64FromEnv(EventReplayBufferSizeEnv, defaultEventReplayBuffer))
+}
+
+// DefaultNamespace returns the namespace targeted by the operations which don't specify one
+func DefaultNamespace() string {
+	if ns := os.Getenv(DefaultNamespaceEnv); ns != "" {
+		return ns
+	}
+	return defaultNamespace
+}
+
+// StrictKubeconfigSetup reports whether the adapter exits when KUBECONFIG can't be set up
+func StrictKubeconfigSetup() bool {
+	return os.Getenv(KubeconfigSetupModeEnv) == "strict"
+}
+
+// StrictBinDirSetup reports whether the adapter exits when the bin directory can't be created
+func StrictBinDirSetup() bool {
+	return os.Getenv(BinDirSetupModeEnv) != "lenient"
+}
+
+// RegistrationBatchSize returns the number of components registered per request
+func RegistrationBatchSize() int {
+	return int(int64FromEnv(RegistrationBatchSizeEnv, 1))
+}
+
+// RegistrationSkipEvents reports whether an event is streamed when the dynamic registration is skipped
+func RegistrationSkipEvents() bool {
+	return os.Getenv(RegistrationSkipEventsEnv) != "false"
+}
+
+// FailOnInvalidSchema reports whether a component with an invalid schema fails the registration
+func FailOnInvalidSchema() bool {
+	return os.Getenv(RegistrationSchemaModeEnv) == "fail"
+}
+
+// ForceDynamicRegistration reports whether every dynamic registration cycle generates and registers all the components
+func ForceDynamicRegistration() bool {
+	return os.Getenv(ForceDynamicRegistrationEnv) == "true"
+}
+
+// KubeContext returns the context of the kubeconfigs the operations run against, empty for the current context
+func KubeContext() string {
+	return os.Getenv(KubeContextEnv)
+}
+
+// RegistrationOrder returns the names of the components to register first, in order
+func RegistrationOrder() []string {
+	var order []string
+	for _, name := range strings.Split(os.Getenv(RegistrationOrderEnv), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			order = append(order, name)
+		}
+	}
+	return order
+}
+
+// keyValuesFromEnv parses the environment variable as a comma separated list of key=value pairs
+func keyValuesFromEnv(key string) (map[string]string, error) {
+	kv := map[string]string{}
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return kv, nil
+	}
+	for _, pair := range strings.Split(raw, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(pair), "=")
+		if k == "" {
+			return nil, ErrInvalidEnv(key, raw)
+		}
+		kv[k] = v
+	}
+	return kv, nil
+}
+
+// durationFromEnv parses the environment variable as a positive time.Duration
+// and returns def if it is unset or invalid
+func durationFromEnv(key string, def time.Duration) time.Duration {
+	d, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || d <= 0 {
+		return def
+	}
+	return d
+}
+
+// int64FromEnv parses the environment variable as a positive integer
+// and returns def if it is unset or invalid
+func int64FromEnv(key string, def int64) int64 {
+	i, err := strconv.ParseInt(os.Getenv(key), 10, 64)
+	if err != nil || i <= 0 {
+		return def
+	}
+	return i
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/layer5io/meshkit/errors"
+)
+
+func TestListenHost(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		invalid bool
+	}{
+		{value: "", want: ""},
+		{value: "localhost", want: "localhost"},
+		{value: "10.0.3.7", want: "10.0.3.7"},
+		{value: "::1", want: "::1"},
+		{value: "adapter.local", invalid: true},
+		{value: "10.0.3", invalid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv(ListenAddressEnv, tt.value)
+			got, err := ListenHost()
+			if tt.invalid {
+				if err == nil {
+					t.Fatalf("expected %q to be rejected, got %q", tt.value, got)
+				}
+				if code := errors.GetCode(err); code != ErrInvalidListenAddressCode {
+					t.Fatalf("expected the error code %s, got %s", ErrInvalidListenAddressCode, code)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("expected %q, got %q (%v)", tt.want, got, err)
+			}
+		})
+	}
+}
@@ -15,6 +15,9 @@
 package config
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/layer5io/meshkit/errors"
 )
 
@@ -30,6 +33,50 @@ const (
 	// ErrGetLatestReleaseNamesCode represents the error which occurs during the process of extracting
 	// release names
 	ErrGetLatestReleaseNamesCode = "1031"
+
+	// ErrInvalidListenAddressCode represents the error which occurs when the configured
+	// listen address is not a valid IP address
+	ErrInvalidListenAddressCode = "1048"
+
+	// ErrInvalidProfileCode represents the error which occurs when an unknown
+	// install profile is requested or configured
+	ErrInvalidProfileCode = "1058"
+
+	// ErrInvalidEnvCode represents the error which occurs when an environment
+	// variable holds a malformed value
+	ErrInvalidEnvCode = "1062"
+
+	// ErrResolveVersionsCode represents the error which occurs when the traefik
+	// mesh versions could not be resolved with the configured strategy
+	ErrResolveVersionsCode = "1065"
+
+	// ErrKubeconfigSetupCode represents the error which occurs when the KUBECONFIG
+	// environment variable could not be set up on startup
+	ErrKubeconfigSetupCode = "1070"
+
+	// ErrBinDirSetupCode represents the error which occurs when the bin directory
+	// of the config root path could not be created
+	ErrBinDirSetupCode = "1076"
+
+	// ErrInvalidTLSMinVersionCode represents the error which occurs when the minimum
+	// TLS version of the outbound HTTP clients is unknown or insecure
+	ErrInvalidTLSMinVersionCode = "1078"
+
+	// ErrInvalidConfigCode represents the error which occurs when the configuration
+	// of the adapter fails the startup validation
+	ErrInvalidConfigCode = "1084"
+
+	// ErrInvalidKubeconfigCode represents the error which occurs when the kubeconfig
+	// found on startup can't be read or parsed
+	ErrInvalidKubeconfigCode = "1092"
+
+	// ErrInClusterConfigCode represents the error which occurs when the adapter runs
+	// in a cluster but the kubeconfig can't be built from its service account
+	ErrInClusterConfigCode = "1093"
+
+	// ErrKubeContextNotFoundCode represents the error which occurs when the context
+	// requested for an operation is not defined by the kubeconfig
+	ErrKubeContextNotFoundCode = "1105"
 )
 
 var (
@@ -46,3 +93,58 @@ func ErrGetLatestReleases(err error) error {
 func ErrGetLatestReleaseNames(err error) error {
 	return errors.New(ErrGetLatestReleaseNamesCode, errors.Alert, []string{"Failed to extract release names"}, []string{err.Error()}, []string{}, []string{})
 }
+
+// ErrInvalidListenAddress is the error when the configured listen address is invalid
+func ErrInvalidListenAddress(host string) error {
+	return errors.New(ErrInvalidListenAddressCode, errors.Alert, []string{"Invalid listen address"}, []string{fmt.Sprintf("%s is not a valid IP address", host)}, []string{"LISTEN_ADDR is set to a value which is neither an IP address nor localhost"}, []string{"Set LISTEN_ADDR to the IP address of the interface the adapter should bind to or unset it to bind to all interfaces"})
+}
+
+// ErrInvalidProfile is the error when an unknown install profile is requested or configured
+func ErrInvalidProfile(profile string, valid []string) error {
+	return errors.New(ErrInvalidProfileCode, errors.Alert, []string{"Invalid install profile"}, []string{fmt.Sprintf("profile %q is not one of %s", profile, strings.Join(valid, ", "))}, []string{"The install request or the default_profile configuration refers to an unknown profile"}, []string{fmt.Sprintf("Use one of the profiles: %s", strings.Join(valid, ", "))})
+}
+
+// ErrInvalidEnv is the error when an environment variable holds a malformed value
+func ErrInvalidEnv(key, value string) error {
+	return errors.New(ErrInvalidEnvCode, errors.Alert, []string{"Invalid environment variable"}, []string{fmt.Sprintf("%s has the malformed value %q", key, value)}, []string{"The environment variable doesn't follow the expected format"}, []string{fmt.Sprintf("Fix or unset %s", key)})
+}
+
+// ErrResolveVersions is the error when the traefik mesh versions could not be resolved with the configured strategy
+func ErrResolveVersions(err error) error {
+	return errors.New(ErrResolveVersionsCode, errors.Alert, []string{"Unable to resolve Traefik Mesh versions"}, []string{err.Error()}, []string{"The source of the configured version strategy is unreachable or malformed"}, []string{fmt.Sprintf("Check the %s configuration or select another strategy", VersionStrategyEnv)})
+}
+
+// ErrKubeconfigSetup is the error when the KUBECONFIG environment variable could not be set up on startup
+func ErrKubeconfigSetup(err error) error {
+	return errors.New(ErrKubeconfigSetupCode, errors.Alert, []string{"Unable to set up KUBECONFIG"}, []string{err.Error()}, []string{"The environment of the adapter process can't be modified"}, []string{fmt.Sprintf("Restart the adapter, the operations which need a cluster are unavailable until then unless %s is set to strict", KubeconfigSetupModeEnv)})
+}
+
+// ErrBinDirSetup is the error when the bin directory of the config root path could not be created
+func ErrBinDirSetup(dir string, err error) error {
+	return errors.New(ErrBinDirSetupCode, errors.Alert, []string{"Unable to create the bin directory"}, []string{fmt.Sprintf("%s: %s", dir, err.Error())}, []string{"The config root path is read only or not writable by the adapter's user"}, []string{fmt.Sprintf("Make %s writable by the adapter, the operations which need it fail until then when %s is set to lenient", dir, BinDirSetupModeEnv)})
+}
+
+// ErrInvalidTLSMinVersion is the error when the minimum TLS version is unknown or below the allowed floor
+func ErrInvalidTLSMinVersion(version string) error {
+	return errors.New(ErrInvalidTLSMinVersionCode, errors.Alert, []string{"Invalid minimum TLS version"}, []string{fmt.Sprintf("%s is set to %q, the supported versions are %s", TLSMinVersionEnv, version, strings.Join(tlsVersionNames(), ", "))}, []string{"The version is unknown or older than TLS 1.2 which is no longer considered secure"}, []string{fmt.Sprintf("Set %s to one of %s or unset it", TLSMinVersionEnv, strings.Join(tlsVersionNames(), ", "))})
+}
+
+// ErrInvalidConfig is the error when the configuration of the adapter fails the startup validation, it lists every problem found
+func ErrInvalidConfig(problems []string) error {
+	return errors.New(ErrInvalidConfigCode, errors.Alert, []string{"Invalid adapter configuration"}, problems, []string{"Environment variables of the adapter hold malformed or out of range values"}, []string{fmt.Sprintf("Fix the listed variables, set %s to lenient to start with the cluster operations available meanwhile, the malformed values falling back to their defaults", ConfigValidationModeEnv)})
+}
+
+// ErrInvalidKubeconfig is the error when the kubeconfig found on startup can't be read or parsed
+func ErrInvalidKubeconfig(path string, err error) error {
+	return errors.New(ErrInvalidKubeconfigCode, errors.Alert, []string{"Invalid kubeconfig"}, []string{fmt.Sprintf("%s: %s", path, err.Error())}, []string{"The kubeconfig left by a previous run is empty, truncated or not a kubeconfig"}, []string{fmt.Sprintf("Fix or remove %s, the kubeconfigs are written again when Meshery sends them. The operations which need a cluster are unavailable until the adapter restarts unless %s is set to strict", path, KubeconfigSetupModeEnv)})
+}
+
+// ErrInClusterConfig is the error when the kubeconfig can't be built from the service account of the adapter's pod
+func ErrInClusterConfig(err error) error {
+	return errors.New(ErrInClusterConfigCode, errors.Alert, []string{"Unable to use the in-cluster service account"}, []string{err.Error()}, []string{"The service account token isn't mounted in the adapter's pod or the kubernetes service environment variables are missing"}, []string{"Set automountServiceAccountToken to true on the adapter's pod, or send the kubeconfigs from Meshery"})
+}
+
+// ErrKubeContextNotFound is the error when the requested context is not defined by the kubeconfig
+func ErrKubeContextNotFound(name string, available []string) error {
+	return errors.New(ErrKubeContextNotFoundCode, errors.Alert, []string{"Kubeconfig context not found"}, []string{fmt.Sprintf("context %q is not defined by the kubeconfig, the available contexts are: %s", name, strings.Join(available, ", "))}, []string{"The kubeContext parameter of the operation, or " + KubeContextEnv + ", names a context the kubeconfig doesn't define"}, []string{"Use one of the available contexts, or leave it empty to use the current context"})
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	configprovider "github.com/layer5io/meshkit/config/provider"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// InClusterContext is the context of the kubeconfig built from the service account of the adapter
+const InClusterContext = "in-cluster"
+
+// ServiceAccountDir is the directory the service account of the adapter's pod is mounted at
+var ServiceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// KubeconfigPath returns the path of the kubeconfig file the adapter writes the clusters to
+func KubeconfigPath() string {
+	return path.Join(
+		KubeConfig[configprovider.FilePath],
+		fmt.Sprintf("%s.%s", KubeConfig[configprovider.FileName], KubeConfig[configprovider.FileType]),
+	)
+}
+
+// ValidateKubeconfig loads and parses the kubeconfig at the path. It reports false without
+// an error when the file doesn't exist, no cluster is configured until Meshery sends one.
+func ValidateKubeconfig(p string) (bool, error) {
+	data, err := os.ReadFile(p)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, ErrInvalidKubeconfig(p, err)
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		return false, ErrInvalidKubeconfig(p, fmt.Errorf("the file is empty"))
+	}
+	kubeconfig, err := clientcmd.Load(data)
+	if err != nil {
+		return false, ErrInvalidKubeconfig(p, err)
+	}
+	if len(kubeconfig.Clusters) == 0 {
+		return false, ErrInvalidKubeconfig(p, fmt.Errorf("the file defines no cluster"))
+	}
+	return true, nil
+}
+
+// SelectKubeContext returns the kubeconfig with the named context as its current context, the
+// kubeconfig is returned as is when name is empty
+func SelectKubeContext(kubeconfig, name string) (string, error) {
+	if name == "" {
+		return kubeconfig, nil
+	}
+	cfg, err := clientcmd.Load([]byte(kubeconfig))
+	if err != nil {
+		return "", fmt.Errorf("parsing the kubeconfig to select the context %s: %w", name, err)
+	}
+	if _, ok := cfg.Contexts[name]; !ok {
+		available := make([]string, 0, len(cfg.Contexts))
+		for ctx := range cfg.Contexts {
+			available = append(available, ctx)
+		}
+		sort.Strings(available)
+		return "", ErrKubeContextNotFound(name, available)
+	}
+	cfg.CurrentContext = name
+	byt, err := clientcmd.Write(*cfg)
+	if err != nil {
+		return "", err
+	}
+	return string(byt), nil
+}
+
+// InClusterKubeconfig builds a kubeconfig from the service account mounted in the adapter's pod,
+// it reports false when the adapter doesn't run in a cluster. The token is read from its file
+// on every request so that the rotated tokens are picked up.
+func InClusterKubeconfig() (string, bool, error) {
+	if _, err := os.Stat(ServiceAccountDir); os.IsNotExist(err) {
+		return "", false, nil
+	}
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return "", false, ErrInClusterConfig(fmt.Errorf("KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT must be set"))
+	}
+	tokenFile := path.Join(ServiceAccountDir, "token")
+	if _, err := os.Stat(tokenFile); err != nil {
+		return "", false, ErrInClusterConfig(err)
+	}
+
+	kubeconfig := clientcmdapi.NewConfig()
+	kubeconfig.Clusters[InClusterContext] = &clientcmdapi.Cluster{
+		Server:               "https://" + net.JoinHostPort(host, port),
+		CertificateAuthority: path.Join(ServiceAccountDir, "ca.crt"),
+	}
+	kubeconfig.AuthInfos[InClusterContext] = &clientcmdapi.AuthInfo{TokenFile: tokenFile}
+	kubeconfig.Contexts[InClusterContext] = &clientcmdapi.Context{
+		Cluster:  InClusterContext,
+		AuthInfo: InClusterContext,
+	}
+	kubeconfig.CurrentContext = InClusterContext
+	if ns, err := os.ReadFile(path.Join(ServiceAccountDir, "namespace")); err == nil {
+		kubeconfig.Contexts[InClusterContext].Namespace = strings.TrimSpace(string(ns))
+	}
+	byt, err := clientcmd.Write(*kubeconfig)
+	if err != nil {
+		return "", false, ErrInClusterConfig(err)
+	}
+	return string(byt), true, nil
+}
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/layer5io/meshkit/errors"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const validKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: fake-cluster
+  cluster:
+    server: https://127.0.0.1:6443
+contexts:
+- name: fake-cluster
+  context:
+    cluster: fake-cluster
+    user: fake-user
+current-context: fake-cluster
+users:
+- name: fake-user
+  user:
+    token: fake-token
+`
+
+func TestValidateKubeconfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		content *string
+		ok      bool
+		code    string
+	}{
+		// a missing kubeconfig isn't an error, meshery sends it later
+		{name: "missing", content: nil},
+		{name: "empty", content: strptr(" \n\t\n"), code: ErrInvalidKubeconfigCode},
+		{name: "invalid yaml", content: strptr("clusters: [\n  - name: {"), code: ErrInvalidKubeconfigCode},
+		{name: "no cluster", content: strptr("apiVersion: v1\nkind: Config\n"), code: ErrInvalidKubeconfigCode},
+		{name: "valid", content: strptr(validKubeconfig), ok: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := path.Join(t.TempDir(), "kubeconfig.yaml")
+			if tt.content != nil {
+				if err := os.WriteFile(p, []byte(*tt.content), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			ok, err := ValidateKubeconfig(p)
+			if ok != tt.ok {
+				t.Errorf("expected the kubeconfig to be valid: %v, got %v", tt.ok, ok)
+			}
+			if tt.code == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected an error with code %s, got none", tt.code)
+			}
+			if code := errors.GetCode(err); code != tt.code {
+				t.Fatalf("expected the error code %s, got %s: %v", tt.code, code, err)
+			}
+		})
+	}
+}
+
+func strptr(s string) *string {
+	return &s
+}
+
+func TestInClusterKubeconfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		noDir     bool
+		files     []string
+		host      string
+		inCluster bool
+		code      string
+	}{
+		{name: "outside of a pod", noDir: true, host: "10.96.0.1"},
+		{name: "service account mounted", files: []string{"token", "ca.crt", "namespace"}, host: "10.96.0.1", inCluster: true},
+		{name: "no service host", files: []string{"token", "ca.crt"}, code: ErrInClusterConfigCode},
+		{name: "no token", files: []string{"ca.crt"}, host: "10.96.0.1", code: ErrInClusterConfigCode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := path.Join(t.TempDir(), "serviceaccount")
+			if !tt.noDir {
+				if err := os.Mkdir(dir, 0700); err != nil {
+					t.Fatal(err)
+				}
+				for _, f := range tt.files {
+					if err := os.WriteFile(path.Join(dir, f), []byte("traefik-mesh\n"), 0600); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			defer func(prev string) { ServiceAccountDir = prev }(ServiceAccountDir)
+			ServiceAccountDir = dir
+			t.Setenv("KUBERNETES_SERVICE_HOST", tt.host)
+			t.Setenv("KUBERNETES_SERVICE_PORT", "443")
+
+			kubeconfig, inCluster, err := InClusterKubeconfig()
+			if tt.code != "" {
+				if err == nil {
+					t.Fatalf("expected an error with code %s, got none", tt.code)
+				}
+				if code := errors.GetCode(err); code != tt.code {
+					t.Fatalf("expected the error code %s, got %s: %v", tt.code, code, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if inCluster != tt.inCluster {
+				t.Fatalf("expected in cluster: %v, got %v", tt.inCluster, inCluster)
+			}
+			if !tt.inCluster {
+				return
+			}
+			cfg, err := clientcmd.Load([]byte(kubeconfig))
+			if err != nil {
+				t.Fatalf("parsing the kubeconfig: %v", err)
+			}
+			if cfg.CurrentContext != InClusterContext {
+				t.Errorf("expected the current context %s, got %s", InClusterContext, cfg.CurrentContext)
+			}
+			if server := cfg.Clusters[InClusterContext].Server; server != "https://10.96.0.1:443" {
+				t.Errorf("expected the server https://10.96.0.1:443, got %s", server)
+			}
+			if ca := cfg.Clusters[InClusterContext].CertificateAuthority; ca != path.Join(dir, "ca.crt") {
+				t.Errorf("expected the CA %s, got %s", path.Join(dir, "ca.crt"), ca)
+			}
+			if token := cfg.AuthInfos[InClusterContext].TokenFile; token != path.Join(dir, "token") {
+				t.Errorf("expected the token file %s, got %s", path.Join(dir, "token"), token)
+			}
+			if ns := cfg.Contexts[InClusterContext].Namespace; ns != "traefik-mesh" {
+				t.Errorf("expected the namespace traefik-mesh, got %q", ns)
+			}
+		})
+	}
+}
@@ -12,10 +12,151 @@ var (
 	TraefikOperation          = strings.ToLower(smp.ServiceMesh_TRAEFIK_MESH.Enum().String())
 	TraefikBookStoreOperation = "traefik_bookstore_app"
 	ServiceName               = "service_name"
+
+	// ProxyMetricsDiffOperation compares the proxy metrics before and after a change
+	ProxyMetricsDiffOperation = "traefik_proxy_metrics_diff"
+
+	// TrafficSplitValidationOperation validates the SMI API version of TrafficSplits
+	TrafficSplitValidationOperation = "traefik_traffic_split_validation"
+
+	// AdapterStatusOperation reports the uptime and the registration stats of the adapter
+	AdapterStatusOperation = "traefik_adapter_status"
+
+	// AdmissionPreflightOperation dry runs the mesh resources through the admission chain
+	AdmissionPreflightOperation = "traefik_admission_preflight"
+
+	// ReloadComponentsOperation reloads the meshmodel components from the filesystem
+	ReloadComponentsOperation = "traefik_reload_components"
+
+	// SplitCoverageOperation reports the per namespace TrafficSplit coverage
+	SplitCoverageOperation = "traefik_split_coverage"
+
+	// ControllerConnectivityOperation checks that every proxy can reach the controller
+	ControllerConnectivityOperation = "traefik_controller_connectivity"
+
+	// DeprecatedUsageOperation reports the resources relying on deprecated SMI API versions
+	DeprecatedUsageOperation = "traefik_deprecated_usage"
+
+	// ExportEventsOperation exports the events recorded for an operation
+	ExportEventsOperation = "traefik_export_events"
+
+	// NamespaceLabelsOperation checks that the mesh namespace carries the required labels
+	NamespaceLabelsOperation = "traefik_namespace_labels"
+
+	// CanaryAnalysisOperation compares a canary backend with the stable one
+	CanaryAnalysisOperation = "traefik_canary_analysis"
+
+	// RestartReportOperation reports the restarts of the controller and proxy pods
+	RestartReportOperation = "traefik_restart_report"
+
+	// IPFamilyOperation checks the IP families of the shadow services on dual-stack clusters
+	IPFamilyOperation = "traefik_ip_family"
+
+	// ReadinessOperation aggregates several checks into a production readiness score
+	ReadinessOperation = "traefik_readiness"
+
+	// CapabilitiesOperation lists the operations and components advertised by the adapter
+	CapabilitiesOperation = "traefik_capabilities"
+
+	// MiddlewareOrderOperation validates the order of the middlewares chained on a service
+	MiddlewareOrderOperation = "traefik_middleware_order"
+
+	// DrainTestOperation measures the requests dropped while a proxy restarts
+	DrainTestOperation = "traefik_drain_test"
+
+	// SplitPortsOperation verifies that the TrafficSplit backends expose the ports of the root service
+	SplitPortsOperation = "traefik_split_ports"
+
+	// SMICountsOperation counts the SMI resources and middlewares and reports their growth over the stored snapshots
+	SMICountsOperation = "traefik_smi_counts"
+
+	// PortNamesOperation reports the service ports which are unnamed or not prefixed with their protocol
+	PortNamesOperation = "traefik_port_names"
+
+	// StaleConfigOperation reports the proxies serving a configuration older than the controller's
+	StaleConfigOperation = "traefik_stale_config"
+
+	// CNICompatibilityOperation detects the CNI of the cluster and reports its known issues with traefik mesh
+	CNICompatibilityOperation = "traefik_cni_compatibility"
+
+	// OrphansOperation reports and optionally deletes the SMI resources left behind by an uninstalled traefik mesh
+	OrphansOperation = "traefik_orphans"
+
+	// RouteTraceOperation explains which backends traefik mesh routes a request to
+	RouteTraceOperation = "traefik_route_trace"
+
+	// ConfigValidationOperation reports every problem of the adapter configuration
+	ConfigValidationOperation = "traefik_config_validation"
+
+	// SelectorLinkageOperation checks the selectors linking the shadow services to the proxies and the meshed services
+	SelectorLinkageOperation = "traefik_selector_linkage"
+
+	// PruneFilesOperation removes the exports, snapshots and cached charts beyond the configured retention
+	PruneFilesOperation = "traefik_prune_files"
+
+	// TraefikMeshUninstallOperation removes the traefik mesh release and the namespace the adapter created for it
+	TraefikMeshUninstallOperation = "traefik_mesh_uninstall"
+
+	// TrafficSplitOperation creates an SMI TrafficSplit between the backends of a service
+	TrafficSplitOperation = "traefik_traffic_split"
+
+	// BookInfoOperation deploys the bookinfo application embedded in the adapter and waits for it to be ready
+	BookInfoOperation = "traefik_bookinfo_app"
+
+	// BookInfoTeardownOperation removes the bookinfo application deployed by BookInfoOperation
+	BookInfoTeardownOperation = "traefik_bookinfo_app_teardown"
+
+	// HTTPBinOperation deploys the httpbin application embedded in the adapter and returns its in-cluster URL
+	HTTPBinOperation = "traefik_httpbin_app"
+
+	// MeshHealthOperation checks the controller, the proxies and the SMI CRDs of the installed mesh
+	MeshHealthOperation = "traefik_mesh_health"
+
+	// OperationHistoryOperation lists the latest operations requested to the adapter along with their outcome
+	OperationHistoryOperation = "traefik_operation_history"
+
+	// TrafficTargetOperation creates an SMI TrafficTarget allowing source identities to reach a service account
+	TrafficTargetOperation = "traefik_traffic_target"
+
+	// HTTPRouteGroupOperation creates an SMI HTTPRouteGroup defining the HTTP routes the TrafficTargets allow
+	HTTPRouteGroupOperation = "traefik_http_route_group"
+
+	// ScaleOperation sets the controller replicas and the proxy resources of an installed traefik mesh
+	ScaleOperation = "traefik_scale"
+
+	// CancelOperation cancels a queued or running operation, the partially applied resources of an install are removed
+	CancelOperation = "traefik_cancel_operation"
+
+	// CompatibilityPreflightOperation checks the kubernetes version, the nodes and the meshes installed against a traefik mesh version
+	CompatibilityPreflightOperation = "traefik_compatibility_preflight"
+
+	// ProxyLogsOperation fetches the latest log lines of the proxy pods of a namespace
+	ProxyLogsOperation = "traefik_proxy_logs"
+
+	// InstallableVersionsOperation lists the traefik mesh versions which can be installed along with their chart versions
+	InstallableVersionsOperation = "traefik_installable_versions"
+
+	// UpgradeOperation upgrades the installed traefik mesh release to a version, rolling it back when the upgrade fails
+	UpgradeOperation = "traefik_mesh_upgrade"
+
+	// RollbackOperation rolls the installed traefik mesh release back to a helm revision, the previous one by default
+	RollbackOperation = "traefik_mesh_rollback"
+
+	// SMIManifestOperation applies the SMI resources of a user supplied manifest with a server side apply
+	SMIManifestOperation = "traefik_smi_manifest"
 )
 
+// versionedOperations are the operations offered for the resolved traefik mesh versions
+var versionedOperations = map[string]bool{
+	TraefikMeshOperation:            true,
+	AdmissionPreflightOperation:     true,
+	CompatibilityPreflightOperation: true,
+	UpgradeOperation:                true,
+}
+
 func getOperations(dev adapter.Operations) adapter.Operations {
-	versions, _ := getLatestReleaseNames(3)
+	// the versions are resolved lazily, see RefreshOperationVersions
+	versions := []adapter.Version{}
 
 	dev[TraefikMeshOperation] = &adapter.Operation{
 		Type:        int32(meshes.OpCategory_INSTALL),
@@ -27,5 +168,67 @@ func getOperations(dev adapter.Operations) adapter.Operations {
 		AdditionalProperties: map[string]string{},
 	}
 
+	registerOperation(dev, ProxyMetricsDiffOperation, meshes.OpCategory_VALIDATE, "Compare Traefik Mesh proxy metrics before and after a change", adapter.NoneVersion)
+	registerOperation(dev, TrafficSplitValidationOperation, meshes.OpCategory_VALIDATE, "Validate TrafficSplit SMI API versions", adapter.NoneVersion)
+	registerOperation(dev, AdapterStatusOperation, meshes.OpCategory_VALIDATE, "Adapter uptime and registration status", adapter.NoneVersion)
+	registerOperation(dev, AdmissionPreflightOperation, meshes.OpCategory_VALIDATE, "Check the Traefik Mesh resources against admission webhooks", versions)
+	registerOperation(dev, ReloadComponentsOperation, meshes.OpCategory_CONFIGURE, "Reload meshmodel components", adapter.NoneVersion)
+	registerOperation(dev, SplitCoverageOperation, meshes.OpCategory_VALIDATE, "TrafficSplit coverage per namespace", adapter.NoneVersion)
+	registerOperation(dev, ControllerConnectivityOperation, meshes.OpCategory_VALIDATE, "Check proxy to controller connectivity", adapter.NoneVersion)
+	registerOperation(dev, DeprecatedUsageOperation, meshes.OpCategory_VALIDATE, "Report deprecated SMI API version usage", adapter.NoneVersion)
+	registerOperation(dev, ExportEventsOperation, meshes.OpCategory_CUSTOM, "Export the events of an operation", adapter.NoneVersion)
+	registerOperation(dev, NamespaceLabelsOperation, meshes.OpCategory_VALIDATE, "Check the mesh namespace for required labels", adapter.NoneVersion)
+	registerOperation(dev, CanaryAnalysisOperation, meshes.OpCategory_VALIDATE, "Canary analysis report", adapter.NoneVersion)
+	registerOperation(dev, RestartReportOperation, meshes.OpCategory_VALIDATE, "Mesh pod restarts report", adapter.NoneVersion)
+	registerOperation(dev, IPFamilyOperation, meshes.OpCategory_VALIDATE, "Shadow services IP family check", adapter.NoneVersion)
+	registerOperation(dev, ReadinessOperation, meshes.OpCategory_VALIDATE, "Mesh production readiness", adapter.NoneVersion)
+	registerOperation(dev, CapabilitiesOperation, meshes.OpCategory_CUSTOM, "Adapter capabilities", adapter.NoneVersion)
+	registerOperation(dev, MiddlewareOrderOperation, meshes.OpCategory_VALIDATE, "Service middleware order check", adapter.NoneVersion)
+	registerOperation(dev, DrainTestOperation, meshes.OpCategory_VALIDATE, "Proxy restart connection draining test", adapter.NoneVersion)
+	registerOperation(dev, SplitPortsOperation, meshes.OpCategory_VALIDATE, "TrafficSplit backend ports check", adapter.NoneVersion)
+	registerOperation(dev, SMICountsOperation, meshes.OpCategory_CUSTOM, "SMI resource counts", adapter.NoneVersion)
+	registerOperation(dev, PortNamesOperation, meshes.OpCategory_VALIDATE, "Service port names check", adapter.NoneVersion)
+	registerOperation(dev, StaleConfigOperation, meshes.OpCategory_VALIDATE, "Stale proxy configuration check", adapter.NoneVersion)
+	registerOperation(dev, CNICompatibilityOperation, meshes.OpCategory_VALIDATE, "CNI compatibility preflight", adapter.NoneVersion)
+	registerOperation(dev, OrphansOperation, meshes.OpCategory_CUSTOM, "Orphaned SMI resources cleanup", adapter.NoneVersion)
+	registerOperation(dev, RouteTraceOperation, meshes.OpCategory_CUSTOM, "Request routing trace", adapter.NoneVersion)
+	registerOperation(dev, ConfigValidationOperation, meshes.OpCategory_VALIDATE, "Adapter configuration validation", adapter.NoneVersion)
+	registerOperation(dev, SelectorLinkageOperation, meshes.OpCategory_VALIDATE, "Shadow service selector linkage check", adapter.NoneVersion)
+	registerOperation(dev, PruneFilesOperation, meshes.OpCategory_CUSTOM, "Stored files cleanup", adapter.NoneVersion)
+	registerOperation(dev, TraefikMeshUninstallOperation, meshes.OpCategory_INSTALL, "Uninstall Traefik Mesh", adapter.NoneVersion)
+	registerOperation(dev, TrafficSplitOperation, meshes.OpCategory_CONFIGURE, "SMI TrafficSplit", adapter.NoneVersion)
+	registerSampleApp(dev, BookInfoOperation, "Book Info Application (embedded)", "bookinfo")
+	registerSampleApp(dev, BookInfoTeardownOperation, "Book Info Application (embedded) teardown", "bookinfo")
+	registerSampleApp(dev, HTTPBinOperation, "HTTPBin Application (embedded)", "httpbin")
+	registerOperation(dev, MeshHealthOperation, meshes.OpCategory_VALIDATE, "Traefik Mesh health validation", adapter.NoneVersion)
+	registerOperation(dev, OperationHistoryOperation, meshes.OpCategory_VALIDATE, "Latest operations and their outcome", adapter.NoneVersion)
+	registerOperation(dev, TrafficTargetOperation, meshes.OpCategory_CONFIGURE, "SMI TrafficTarget (access control)", adapter.NoneVersion)
+	registerOperation(dev, HTTPRouteGroupOperation, meshes.OpCategory_CONFIGURE, "SMI HTTPRouteGroup (traffic specs)", adapter.NoneVersion)
+	registerOperation(dev, ScaleOperation, meshes.OpCategory_CONFIGURE, "Scale Traefik Mesh controller and proxies", adapter.NoneVersion)
+	registerOperation(dev, CancelOperation, meshes.OpCategory_CONFIGURE, "Cancel an operation in flight", adapter.NoneVersion)
+	registerOperation(dev, CompatibilityPreflightOperation, meshes.OpCategory_VALIDATE, "Check the cluster compatibility with Traefik Mesh", versions)
+	registerOperation(dev, ProxyLogsOperation, meshes.OpCategory_VALIDATE, "Traefik Mesh proxy logs", adapter.NoneVersion)
+	registerOperation(dev, InstallableVersionsOperation, meshes.OpCategory_VALIDATE, "Installable Traefik Mesh versions", adapter.NoneVersion)
+	registerOperation(dev, UpgradeOperation, meshes.OpCategory_INSTALL, "Upgrade Traefik Mesh", versions)
+	registerOperation(dev, RollbackOperation, meshes.OpCategory_INSTALL, "Roll back Traefik Mesh", adapter.NoneVersion)
+	registerOperation(dev, SMIManifestOperation, meshes.OpCategory_CONFIGURE, "Apply SMI manifest", adapter.NoneVersion)
+
 	return dev
 }
+
+// registerOperation adds to ops an operation which applies no template
+func registerOperation(ops adapter.Operations, name string, category meshes.OpCategory, description string, versions []adapter.Version) {
+	ops[name] = &adapter.Operation{
+		Type:                 int32(category),
+		Description:          description,
+		Versions:             versions,
+		Templates:            adapter.NoneTemplate,
+		AdditionalProperties: map[string]string{},
+	}
+}
+
+// registerSampleApp adds to ops an operation deploying the sample application embedded in the adapter
+func registerSampleApp(ops adapter.Operations, name, description, serviceName string) {
+	registerOperation(ops, name, meshes.OpCategory_SAMPLE_APPLICATION, description, adapter.NoneVersion)
+	ops[name].AdditionalProperties[ServiceName] = serviceName
+}
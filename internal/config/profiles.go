@@ -0,0 +1,93 @@
+package config
+
+import (
+	"sort"
+
+	"github.com/layer5io/meshkit/config"
+)
+
+const (
+	// DefaultProfileKey is the config provider key holding the install profile applied
+	// when the install request doesn't specify one. With the viper provider it can also
+	// be set through the DEFAULT_PROFILE environment variable.
+	DefaultProfileKey = "default_profile"
+
+	// DevProfile favours debuggability, it enables verbose logs and deploys the
+	// tracing and metrics addons along with the mesh
+	DevProfile = "dev"
+
+	// ProductionProfile favours stability, it keeps the logs quiet and reserves
+	// resources for the controller and the proxies
+	ProductionProfile = "production"
+)
+
+// Profiles maps the install profiles to the helm values they override
+var Profiles = map[string]map[string]interface{}{
+	DevProfile: {
+		"controller": map[string]interface{}{
+			"logLevel": "debug",
+		},
+		"mesh": map[string]interface{}{
+			"logLevel": "debug",
+		},
+		"tracing": map[string]interface{}{
+			"deploy": true,
+		},
+		"metrics": map[string]interface{}{
+			"deploy": true,
+		},
+	},
+	ProductionProfile: {
+		"controller": map[string]interface{}{
+			"logLevel": "error",
+			"resources": map[string]interface{}{
+				"request": map[string]interface{}{"mem": "100Mi", "cpu": "200m"},
+				"limit":   map[string]interface{}{"mem": "200Mi", "cpu": "500m"},
+			},
+		},
+		"mesh": map[string]interface{}{
+			"logLevel": "error",
+			"resources": map[string]interface{}{
+				"request": map[string]interface{}{"mem": "100Mi", "cpu": "200m"},
+				"limit":   map[string]interface{}{"mem": "200Mi", "cpu": "500m"},
+			},
+		},
+		"tracing": map[string]interface{}{
+			"deploy": false,
+		},
+		"metrics": map[string]interface{}{
+			"deploy": false,
+		},
+	},
+}
+
+// ValidateProfile returns an error if the profile is not one of the known ones,
+// an empty profile is valid and leaves the chart defaults untouched
+func ValidateProfile(profile string) error {
+	if _, ok := Profiles[profile]; !ok && profile != "" {
+		return ErrInvalidProfile(profile, profileNames())
+	}
+	return nil
+}
+
+// ResolveProfile returns the profile requested by the operation, falling back
+// to the default profile of the config provider
+func ResolveProfile(h config.Handler, requested string) (string, error) {
+	profile := requested
+	if profile == "" {
+		profile = h.GetKey(DefaultProfileKey)
+	}
+	if err := ValidateProfile(profile); err != nil {
+		return "", err
+	}
+	return profile, nil
+}
+
+func profileNames() []string {
+	names := make([]string, 0, len(Profiles))
+	for name := range Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/layer5io/meshkit/config/provider"
+	"github.com/layer5io/meshkit/errors"
+)
+
+func TestResolveProfile(t *testing.T) {
+	tests := []struct {
+		name           string
+		defaultProfile string
+		requested      string
+		want           string
+		invalid        bool
+	}{
+		{name: "default applied when omitted", defaultProfile: ProductionProfile, want: ProductionProfile},
+		{name: "requested overrides the default", defaultProfile: ProductionProfile, requested: DevProfile, want: DevProfile},
+		{name: "no default", requested: "", want: ""},
+		{name: "invalid default", defaultProfile: "staging", invalid: true},
+		{name: "invalid request", defaultProfile: DevProfile, requested: "prod", invalid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := provider.NewInMem(provider.Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.defaultProfile != "" {
+				h.SetKey(DefaultProfileKey, tt.defaultProfile)
+			}
+
+			got, err := ResolveProfile(h, tt.requested)
+			if tt.invalid {
+				if code := errors.GetCode(err); code != ErrInvalidProfileCode {
+					t.Fatalf("expected the error code %s, got %s: %v", ErrInvalidProfileCode, code, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("expected the profile %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -1,13 +1,18 @@
 package config
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"regexp"
 	"sort"
+	"strconv"
+	"time"
 
+	backoff "github.com/cenkalti/backoff/v4"
 	"github.com/layer5io/meshery-adapter-library/adapter"
 )
 
@@ -30,8 +35,8 @@ type Asset struct {
 // getLatestReleaseNames returns the names of the latest releases
 // limited by the "limit" parameter. It filters out all the rc
 // releases and sorts the result lexographically (descending)
-func getLatestReleaseNames(limit int) ([]adapter.Version, error) {
-	releases, err := GetLatestReleases(10)
+func getLatestReleaseNames(ctx context.Context, limit int) ([]adapter.Version, error) {
+	releases, err := GetLatestReleases(ctx, 10)
 	if err != nil {
 		return []adapter.Version{}, ErrGetLatestReleaseNames(err)
 	}
@@ -62,34 +67,90 @@ func getLatestReleaseNames(limit int) ([]adapter.Version, error) {
 	return result[:limit], nil
 }
 
-// GetLatestReleases fetches the latest releases from the traefik mesh repository
-func GetLatestReleases(releases uint) ([]*Release, error) {
+// GetLatestReleases fetches the latest releases from the traefik mesh repository. The request is
+// retried with an exponential backoff, waiting for the reset of the GitHub rate limit when it is exhausted.
+// The retries and the waits stop when ctx is done.
+func GetLatestReleases(ctx context.Context, releases uint) ([]*Release, error) {
 	releaseAPIURL := "https://api.github.com/repos/traefik/mesh/releases?per_page=" + fmt.Sprint(releases)
+
+	var releaseList []*Release
+	attempts := 0
+	b := backoff.NewExponentialBackOff()
+	b.MaxElapsedTime = ReleasesBackoffCap()
+	deadline := time.Now().Add(b.MaxElapsedTime)
+	err := backoff.Retry(func() (err error) {
+		attempts++
+		releaseList, err = fetchReleases(ctx, releaseAPIURL, deadline)
+		return err
+	}, backoff.WithContext(b, ctx))
+	if err != nil {
+		return []*Release{}, ErrGetLatestReleases(fmt.Errorf("%s failed after %d attempts: %w", releaseAPIURL, attempts, err))
+	}
+	return releaseList, nil
+}
+
+// fetchReleases requests the releases once. The errors which retrying doesn't fix are permanent, a rate
+// limit is waited for when it is reset before the deadline.
+func fetchReleases(ctx context.Context, releaseAPIURL string, deadline time.Time) ([]*Release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, releaseAPIURL, nil)
+	if err != nil {
+		return nil, backoff.Permanent(err)
+	}
+	if token := os.Getenv(GitHubTokenEnv); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
 	// We need a variable url here hence using nosec
 	// #nosec
-	resp, err := http.Get(releaseAPIURL)
+	resp, err := HTTPClient(0).Do(req)
 	if err != nil {
-		return []*Release{}, ErrGetLatestReleases(err)
+		return nil, err
 	}
-
-	if resp.StatusCode != http.StatusOK {
-		return []*Release{}, ErrGetLatestReleases(fmt.Errorf("unexpected status code: %d", resp.StatusCode))
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case rateLimited(resp):
+		reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("rate limited, unexpected status code: %d", resp.StatusCode)
+		}
+		resetAt := time.Unix(reset, 0)
+		if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+			deadline = d
+		}
+		if resetAt.After(deadline) {
+			return nil, backoff.Permanent(fmt.Errorf("rate limited until %s", resetAt.Format(time.RFC3339)))
+		}
+		timer := time.NewTimer(time.Until(resetAt))
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return nil, backoff.Permanent(ctx.Err())
+		}
+		return nil, fmt.Errorf("rate limited until %s", resetAt.Format(time.RFC3339))
+	case resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests:
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	default:
+		return nil, backoff.Permanent(fmt.Errorf("unexpected status code: %d", resp.StatusCode))
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return []*Release{}, ErrGetLatestReleases(err)
+		return nil, err
 	}
 
 	var releaseList []*Release
-
 	if err = json.Unmarshal(body, &releaseList); err != nil {
-		return []*Release{}, ErrGetLatestReleases(err)
+		return nil, backoff.Permanent(err)
 	}
-
-	if err = resp.Body.Close(); err != nil {
-		return []*Release{}, ErrGetLatestReleases(err)
-	}
-
 	return releaseList, nil
 }
+
+// rateLimited reports whether the response rejects the request because the GitHub rate limit is exhausted
+func rateLimited(resp *http.Response) bool {
+	return (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests) &&
+		resp.Header.Get("X-RateLimit-Remaining") == "0"
+}
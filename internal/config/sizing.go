@@ -0,0 +1,75 @@
+package config
+
+import "fmt"
+
+const (
+	// SmallSizing suits the clusters with a handful of nodes, e.g. dev or edge clusters
+	SmallSizing = "small"
+
+	// MediumSizing suits most clusters, its requests match the production profile
+	MediumSizing = "medium"
+
+	// LargeSizing reserves more resources for the controller which watches every
+	// service and for the proxies which handle the traffic of more pods per node
+	LargeSizing = "large"
+
+	// smallClusterNodes and largeClusterNodes are the node counts delimiting the sizings
+	smallClusterNodes = 3
+	largeClusterNodes = 20
+)
+
+// Sizings maps the sizings to the helm values they override
+var Sizings = map[string]map[string]interface{}{
+	SmallSizing:  sizingValues("50Mi", "50m", "100Mi", "200m"),
+	MediumSizing: sizingValues("100Mi", "200m", "200Mi", "500m"),
+	LargeSizing:  sizingValues("200Mi", "500m", "500Mi", "1000m"),
+}
+
+// SizingForNodes returns the sizing suited to a cluster of the given number of nodes
+func SizingForNodes(nodes int) string {
+	switch {
+	case nodes <= smallClusterNodes:
+		return SmallSizing
+	case nodes <= largeClusterNodes:
+		return MediumSizing
+	}
+	return LargeSizing
+}
+
+// DescribeSizing explains why the sizing was selected
+func DescribeSizing(sizing string, nodes int) string {
+	return fmt.Sprintf("%s sizing for %d nodes", sizing, nodes)
+}
+
+// MergeValues returns the helm values of base overridden by the ones of override, the nested maps are merged
+func MergeValues(base, override map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		bm, bok := merged[k].(map[string]interface{})
+		om, ook := v.(map[string]interface{})
+		if bok && ook {
+			merged[k] = MergeValues(bm, om)
+			continue
+		}
+		merged[k] = v
+	}
+	return merged
+}
+
+func sizingValues(reqMem, reqCPU, limMem, limCPU string) map[string]interface{} {
+	resources := func() map[string]interface{} {
+		return map[string]interface{}{
+			"resources": map[string]interface{}{
+				"request": map[string]interface{}{"mem": reqMem, "cpu": reqCPU},
+				"limit":   map[string]interface{}{"mem": limMem, "cpu": limCPU},
+			},
+		}
+	}
+	return map[string]interface{}{
+		"controller": resources(),
+		"mesh":       resources(),
+	}
+}
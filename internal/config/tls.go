@@ -0,0 +1,66 @@
+package config
+
+import (
+	"crypto/tls"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// tlsVersions are the minimum TLS versions allowed for the outbound HTTP clients,
+// the older versions are rejected
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+var (
+	httpTransport     *http.Transport
+	httpTransportOnce sync.Once
+)
+
+// TLSMinVersion returns the minimum TLS version of the outbound HTTP clients
+func TLSMinVersion() (uint16, error) {
+	raw := os.Getenv(TLSMinVersionEnv)
+	if raw == "" {
+		return tls.VersionTLS12, nil
+	}
+	v, ok := tlsVersions[raw]
+	if !ok {
+		return 0, ErrInvalidTLSMinVersion(raw)
+	}
+	return v, nil
+}
+
+// HTTPTransport returns the transport shared by the outbound HTTP clients of the
+// adapter (helm repositories, GitHub, Meshery server, result store). It enforces
+// the configured minimum TLS version.
+func HTTPTransport() *http.Transport {
+	httpTransportOnce.Do(func() {
+		// the version is validated when the config is created, an invalid
+		// version never lowers the minimum below the default
+		minVersion, err := TLSMinVersion()
+		if err != nil {
+			minVersion = tls.VersionTLS12
+		}
+		httpTransport = http.DefaultTransport.(*http.Transport).Clone()
+		httpTransport.TLSClientConfig = &tls.Config{MinVersion: minVersion}
+	})
+	return httpTransport
+}
+
+// HTTPClient returns a client using the shared transport, timeout bounds every request
+func HTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: HTTPTransport()}
+}
+
+func tlsVersionNames() []string {
+	names := make([]string, 0, len(tlsVersions))
+	for name := range tlsVersions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
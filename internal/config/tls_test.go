@@ -0,0 +1,94 @@
+package config
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/layer5io/meshkit/errors"
+)
+
+// resetHTTPTransport builds the shared transport again on its next use
+func resetHTTPTransport(t *testing.T) {
+	t.Helper()
+	httpTransportOnce = sync.Once{}
+	t.Cleanup(func() { httpTransportOnce = sync.Once{} })
+}
+
+func TestTLSMinVersion(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want uint16
+		code string
+	}{
+		{raw: "", want: tls.VersionTLS12},
+		{raw: "1.2", want: tls.VersionTLS12},
+		{raw: "1.3", want: tls.VersionTLS13},
+		{raw: "1.1", code: ErrInvalidTLSMinVersionCode},
+		{raw: "1.0", code: ErrInvalidTLSMinVersionCode},
+		{raw: "TLS1.3", code: ErrInvalidTLSMinVersionCode},
+	}
+	for _, tt := range tests {
+		t.Run("version "+tt.raw, func(t *testing.T) {
+			t.Setenv(TLSMinVersionEnv, tt.raw)
+			got, err := TLSMinVersion()
+			if tt.code != "" {
+				if err == nil {
+					t.Fatalf("expected an error with code %s, got the version %x", tt.code, got)
+				}
+				if code := errors.GetCode(err); code != tt.code {
+					t.Fatalf("expected the error code %s, got %s: %v", tt.code, code, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("expected the version %x, got %x: %v", tt.want, got, err)
+			}
+		})
+	}
+}
+
+func TestHTTPTransportMinVersion(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	srv.StartTLS()
+	defer srv.Close()
+
+	tests := []struct {
+		raw  string
+		want uint16
+		// handshake reports whether a server limited to TLS 1.2 is reachable
+		handshake bool
+	}{
+		{raw: "", want: tls.VersionTLS12, handshake: true},
+		{raw: "1.3", want: tls.VersionTLS13},
+		// the configuration is rejected on startup, the transport keeps the floor
+		{raw: "1.0", want: tls.VersionTLS12, handshake: true},
+	}
+	for _, tt := range tests {
+		t.Run("version "+tt.raw, func(t *testing.T) {
+			t.Setenv(TLSMinVersionEnv, tt.raw)
+			resetHTTPTransport(t)
+
+			transport := HTTPTransport()
+			if got := transport.TLSClientConfig.MinVersion; got != tt.want {
+				t.Fatalf("expected the minimum version %x, got %x", tt.want, got)
+			}
+
+			// trust the test server, the minimum version is kept
+			client := srv.Client()
+			trusted := transport.Clone()
+			trusted.TLSClientConfig.RootCAs = client.Transport.(*http.Transport).TLSClientConfig.RootCAs
+			client.Transport = trusted
+			resp, err := client.Get(srv.URL)
+			if err == nil {
+				_ = resp.Body.Close()
+			}
+			if (err == nil) != tt.handshake {
+				t.Fatalf("expected the handshake to succeed: %v, got %v", tt.handshake, err)
+			}
+		})
+	}
+}
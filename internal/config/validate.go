@@ -0,0 +1,196 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// ConfigValidationModeEnv is the environment variable deciding what the adapter does with an
+	// invalid configuration: it starts degraded, with the cluster operations unavailable ("degraded",
+	// the default), refuses to start ("strict"), or logs the problems and starts, the malformed values
+	// falling back to their defaults ("lenient")
+	ConfigValidationModeEnv = "CONFIG_VALIDATION_MODE"
+
+	// MesheryServerEnv is the environment variable holding the address of Meshery server
+	MesheryServerEnv = "MESHERY_SERVER"
+
+	// ServiceAddressEnv is the environment variable holding the address Meshery server reaches the adapter at
+	ServiceAddressEnv = "SERVICE_ADDR"
+)
+
+// durationEnvs must hold positive durations when they are set
+var durationEnvs = []string{
+	ChartDownloadTimeoutEnv,
+	KubeRetryInitialIntervalEnv,
+	DrainTimeoutEnv,
+	RetentionMaxAgeEnv,
+	DynamicRegistrationIntervalEnv,
+	HelmIndexCacheTTLEnv,
+	VersionsTimeoutEnv,
+	BackoffMaxElapsedTimeEnv,
+	BackoffMaxElapsedTimeEnv + "_" + RegistrationBackoffPath,
+	BackoffMaxElapsedTimeEnv + "_" + KubeBackoffPath,
+	BackoffMaxElapsedTimeEnv + "_" + ReadinessBackoffPath,
+	BackoffMaxElapsedTimeEnv + "_" + ReleasesBackoffPath,
+}
+
+// positiveIntEnvs must hold positive integers when they are set
+var positiveIntEnvs = []string{
+	ChartMaxSizeEnv,
+	ResultStoreThresholdEnv,
+	ResultMaxSizeEnv,
+	MaxConcurrentOperationsEnv,
+	NamespaceScanConcurrencyEnv,
+	KubeRetryMaxAttemptsEnv,
+	LogMaxLineLengthEnv,
+	RegistrationBatchSizeEnv,
+	RetentionMaxFilesEnv,
+	OperationHistorySizeEnv,
+	EventReplayBufferSizeEnv,
+}
+
+// enumEnvs must hold one of the listed values when they are set
+var enumEnvs = map[string][]string{
+	OperationQueueModeEnv:       {"queue", "reject"},
+	KubeconfigSetupModeEnv:      {"strict", "lenient"},
+	BinDirSetupModeEnv:          {"strict", "lenient"},
+	ConfigValidationModeEnv:     {"strict", "degraded", "lenient"},
+	RegistrationSkipEventsEnv:   {"true", "false"},
+	RegistrationSchemaModeEnv:   {"skip", "fail"},
+	ForceDynamicRegistrationEnv: {"true", "false"},
+	LogFormatEnv:                {"syslog", "json"},
+}
+
+// StrictConfigValidation reports whether the adapter exits when its configuration is invalid
+func StrictConfigValidation() bool {
+	return os.Getenv(ConfigValidationModeEnv) == "strict"
+}
+
+// DegradedOnInvalidConfig reports whether the adapter starts degraded when its configuration is invalid
+func DegradedOnInvalidConfig() bool {
+	mode := os.Getenv(ConfigValidationModeEnv)
+	return mode != "strict" && mode != "lenient"
+}
+
+// Validate checks the whole configuration of the adapter and reports every problem at once
+func Validate() error {
+	problems := ConfigProblems()
+	if len(problems) == 0 {
+		return nil
+	}
+	return ErrInvalidConfig(problems)
+}
+
+// ConfigProblems returns the problems of the configuration of the adapter, sorted by variable
+func ConfigProblems() []string {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	for _, key := range durationEnvs {
+		if raw := os.Getenv(key); raw != "" {
+			if d, err := time.ParseDuration(raw); err != nil || d <= 0 {
+				add("%s: %q is not a positive duration, e.g. 30s", key, raw)
+			}
+		}
+	}
+	for _, key := range positiveIntEnvs {
+		if raw := os.Getenv(key); raw != "" {
+			if i, err := strconv.ParseInt(raw, 10, 64); err != nil || i <= 0 {
+				add("%s: %q is not a positive integer", key, raw)
+			}
+		}
+	}
+	for key, values := range enumEnvs {
+		if raw := os.Getenv(key); raw != "" && !contains(values, raw) {
+			add("%s: %q is not one of %s", key, raw, strings.Join(values, ", "))
+		}
+	}
+	for _, key := range []string{RequiredNamespaceLabelsEnv, ComponentMetadataEnv, ResourceLabelsEnv, ResourceAnnotationsEnv} {
+		if _, err := keyValuesFromEnv(key); err != nil {
+			add("%s: %q is not a comma separated list of key=value pairs", key, os.Getenv(key))
+		}
+	}
+	if timeouts, err := keyValuesFromEnv(OperationTimeoutsEnv); err != nil {
+		add("%s: %q is not a comma separated list of operation=duration pairs", OperationTimeoutsEnv, os.Getenv(OperationTimeoutsEnv))
+	} else {
+		for operation, raw := range timeouts {
+			if d, err := time.ParseDuration(raw); err != nil || d <= 0 {
+				add("%s: the timeout %q of %s is not a positive duration, e.g. 20m", OperationTimeoutsEnv, raw, operation)
+			}
+		}
+	}
+
+	if raw := os.Getenv(DefaultNamespaceEnv); raw != "" && len(validation.IsDNS1123Label(raw)) != 0 {
+		add("%s: %q is not a valid namespace name", DefaultNamespaceEnv, raw)
+	}
+	if _, err := ListenHost(); err != nil {
+		add("%s: %q is not an IP address", ListenAddressEnv, os.Getenv(ListenAddressEnv))
+	}
+	if raw := os.Getenv(HealthPortEnv); raw != "" && HealthPort() == "" {
+		add("%s: %q is not a port number", HealthPortEnv, raw)
+	}
+	if _, err := TLSMinVersion(); err != nil {
+		add("%s: %q is not one of %s", TLSMinVersionEnv, os.Getenv(TLSMinVersionEnv), strings.Join(tlsVersionNames(), ", "))
+	}
+	if _, err := NewVersionResolver(); err != nil {
+		add("%s: %q is not a known strategy", VersionStrategyEnv, os.Getenv(VersionStrategyEnv))
+	}
+
+	if raw := os.Getenv(MesheryServerEnv); raw != "" {
+		addr := raw
+		if !strings.HasPrefix(addr, "http") {
+			addr = "http://" + addr
+		}
+		if u, err := url.Parse(addr); err != nil || u.Host == "" {
+			add("%s: %q is not a valid address, e.g. http://meshery:9081", MesheryServerEnv, raw)
+		}
+	}
+	if raw := os.Getenv(ServiceAddressEnv); raw != "" {
+		if u, err := url.Parse("//" + raw); err != nil || u.Host == "" || u.Path != "" {
+			add("%s: %q is not a valid host, e.g. meshery-traefik-mesh", ServiceAddressEnv, raw)
+		}
+	}
+	if raw := os.Getenv(VersionIndexURLEnv); raw != "" && !isHTTPURL(raw) {
+		add("%s: %q is not an http(s) URL", VersionIndexURLEnv, raw)
+	}
+	for _, key := range []string{CRDBaseURLEnv, HelmRepositoryURLEnv} {
+		if raw := os.Getenv(key); raw != "" && !isHTTPURL(raw) {
+			add("%s: %q is not an http(s) URL", key, raw)
+		}
+	}
+	if raw := os.Getenv(ResultStoreEndpointEnv); raw != "" {
+		if !isHTTPURL(raw) {
+			add("%s: %q is not an http(s) URL", ResultStoreEndpointEnv, raw)
+		}
+		if os.Getenv(ResultStoreBucketEnv) == "" {
+			add("%s: required when %s is set", ResultStoreBucketEnv, ResultStoreEndpointEnv)
+		}
+	}
+
+	sort.Strings(problems)
+	return problems
+}
+
+func isHTTPURL(raw string) bool {
+	u, err := url.ParseRequestURI(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/layer5io/meshkit/errors"
+)
+
+func TestConfigProblemsReportedTogether(t *testing.T) {
+	env := map[string]string{
+		ChartDownloadTimeoutEnv:    "soon",
+		MaxConcurrentOperationsEnv: "0",
+		OperationQueueModeEnv:      "drop",
+		OperationTimeoutsEnv:       "install=20m,uninstall=-1m",
+		MesheryServerEnv:           "http://",
+		ServiceAddressEnv:          "meshery-traefik-mesh/adapter",
+		ResultStoreEndpointEnv:     "ftp://results.example.com",
+		TLSMinVersionEnv:           "1.0",
+	}
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+	t.Setenv(ResultStoreBucketEnv, "")
+
+	want := []string{
+		`CHART_DOWNLOAD_TIMEOUT: "soon" is not a positive duration, e.g. 30s`,
+		`MAX_CONCURRENT_OPERATIONS: "0" is not a positive integer`,
+		`MESHERY_SERVER: "http://" is not a valid address, e.g. http://meshery:9081`,
+		`OPERATION_QUEUE_MODE: "drop" is not one of queue, reject`,
+		OperationTimeoutsEnv + `: the timeout "-1m" of uninstall is not a positive duration, e.g. 20m`,
+		`RESULT_STORE_BUCKET: required when RESULT_STORE_ENDPOINT is set`,
+		`RESULT_STORE_ENDPOINT: "ftp://results.example.com" is not an http(s) URL`,
+		`SERVICE_ADDR: "meshery-traefik-mesh/adapter" is not a valid host, e.g. meshery-traefik-mesh`,
+		`TLS_MIN_VERSION: "1.0" is not one of 1.2, 1.3`,
+	}
+	if got := ConfigProblems(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected the problems\n%q\ngot\n%q", want, got)
+	}
+
+	err := Validate()
+	if code := errors.GetCode(err); code != ErrInvalidConfigCode {
+		t.Fatalf("expected the error code %s, got %s: %v", ErrInvalidConfigCode, code, err)
+	}
+	for _, problem := range want {
+		if !strings.Contains(err.Error(), problem) {
+			t.Fatalf("the problem %q is missing from the error: %v", problem, err)
+		}
+	}
+
+	for key := range env {
+		t.Setenv(key, "")
+	}
+	if err := Validate(); err != nil {
+		t.Fatalf("unexpected error with the defaults: %v", err)
+	}
+}
+
+func TestConfigValidationMode(t *testing.T) {
+	tests := []struct {
+		mode     string
+		strict   bool
+		degraded bool
+	}{
+		{mode: "", degraded: true},
+		{mode: "degraded", degraded: true},
+		{mode: "strict", strict: true},
+		{mode: "lenient"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			t.Setenv(ConfigValidationModeEnv, tt.mode)
+			if StrictConfigValidation() != tt.strict || DegradedOnInvalidConfig() != tt.degraded {
+				t.Fatalf("expected strict %v and degraded %v, got %v and %v", tt.strict, tt.degraded, StrictConfigValidation(), DegradedOnInvalidConfig())
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshery-adapter-library/config"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// VersionStrategyEnv is the environment variable selecting how the versions
+	// of traefik mesh offered by the adapter are resolved
+	VersionStrategyEnv = "VERSION_STRATEGY"
+
+	// VersionPinEnv holds the comma separated versions offered with the pinned strategy
+	VersionPinEnv = "VERSION_PIN"
+
+	// VersionIndexURLEnv overrides the helm index read with the helm index strategy,
+	// e.g. to point to a local mirror of the traefik mesh helm repository. It defaults
+	// to the index of the helm repository set with HELM_REPOSITORY_URL.
+	VersionIndexURLEnv = "VERSION_INDEX_URL"
+
+	// VersionFileEnv holds the path of the file read with the file strategy,
+	// it lists one version per line
+	VersionFileEnv = "VERSION_FILE"
+
+	// GitHubStrategy offers the latest stable GitHub releases of traefik mesh
+	GitHubStrategy = "github"
+
+	// PinnedStrategy offers the versions listed in VERSION_PIN
+	PinnedStrategy = "pinned"
+
+	// HelmIndexStrategy offers the app versions of the charts in a helm index
+	HelmIndexStrategy = "helm-index"
+
+	// FileStrategy offers the versions listed in the file at VERSION_FILE
+	FileStrategy = "file"
+
+	traefikMeshChartName = "traefik-mesh"
+
+	// offeredVersionsCount is the number of versions offered by the versioned operations
+	offeredVersionsCount = 3
+)
+
+// stableVersionRegex matches the stable versions, filtering out the release candidates
+var stableVersionRegex = regexp.MustCompile(`\d+(\.\d+){2,}$`)
+
+// VersionResolver resolves the traefik mesh versions offered by the adapter
+type VersionResolver interface {
+	// Versions returns at most limit versions, the latest first
+	Versions(ctx context.Context, limit int) ([]adapter.Version, error)
+}
+
+// offeredVersions keeps the versions once resolved, the lookup may be slow or fail when air-gapped
+var offeredVersions = struct {
+	sync.Mutex
+	versions []adapter.Version
+}{}
+
+// LatestVersions returns the versions offered by the adapter, the latest first. They are resolved
+// on first use with the strategy of VERSION_STRATEGY, within VERSIONS_TIMEOUT, and kept afterwards.
+func LatestVersions(ctx context.Context) ([]adapter.Version, error) {
+	offeredVersions.Lock()
+	defer offeredVersions.Unlock()
+	if len(offeredVersions.versions) != 0 {
+		return append([]adapter.Version{}, offeredVersions.versions...), nil
+	}
+
+	resolver, err := NewVersionResolver()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, VersionsTimeout())
+	defer cancel()
+	versions, err := resolver.Versions(ctx, offeredVersionsCount)
+	if err != nil {
+		return nil, err
+	}
+	offeredVersions.versions = versions
+	return append([]adapter.Version{}, versions...), nil
+}
+
+// RefreshOperationVersions resolves the offered versions and sets them on the versioned operations of
+// the config handler, so that Meshery lists them. The operations are registered without versions,
+// the lookup doesn't hold up the start of the adapter.
+func RefreshOperationVersions(ctx context.Context, h config.Handler) error {
+	versions, err := LatestVersions(ctx)
+	if err != nil {
+		return err
+	}
+	operations := make(adapter.Operations, len(Operations))
+	for name, op := range Operations {
+		copied := *op
+		if versionedOperations[name] {
+			copied.Versions = versions
+		}
+		operations[name] = &copied
+	}
+	return h.SetObject(adapter.OperationsKey, operations)
+}
+
+// NewVersionResolver returns the resolver for the strategy selected with VERSION_STRATEGY
+func NewVersionResolver() (VersionResolver, error) {
+	switch strategy := os.Getenv(VersionStrategyEnv); strategy {
+	case "", GitHubStrategy:
+		return gitHubResolver{}, nil
+	case PinnedStrategy:
+		return pinnedResolver{versions: splitVersions(os.Getenv(VersionPinEnv), ",")}, nil
+	case HelmIndexStrategy:
+		url := os.Getenv(VersionIndexURLEnv)
+		if url == "" {
+			url = HelmRepositoryURL() + "/index.yaml"
+		}
+		return helmIndexResolver{url: url}, nil
+	case FileStrategy:
+		return fileResolver{path: os.Getenv(VersionFileEnv)}, nil
+	default:
+		return nil, ErrInvalidEnv(VersionStrategyEnv, strategy)
+	}
+}
+
+// gitHubResolver offers the latest stable GitHub releases
+type gitHubResolver struct{}
+
+func (gitHubResolver) Versions(ctx context.Context, limit int) ([]adapter.Version, error) {
+	return getLatestReleaseNames(ctx, limit)
+}
+
+// pinnedResolver offers a fixed set of versions
+type pinnedResolver struct {
+	versions []string
+}
+
+func (pr pinnedResolver) Versions(_ context.Context, limit int) ([]adapter.Version, error) {
+	if len(pr.versions) == 0 {
+		return []adapter.Version{}, ErrResolveVersions(fmt.Errorf("%s is empty", VersionPinEnv))
+	}
+	return latestVersions(pr.versions, limit), nil
+}
+
+// helmIndexResolver offers the app versions of the traefik mesh charts in a helm index
+type helmIndexResolver struct {
+	url string
+}
+
+func (hr helmIndexResolver) Versions(ctx context.Context, limit int) ([]adapter.Version, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, hr.url, nil)
+	if err != nil {
+		return []adapter.Version{}, ErrResolveVersions(err)
+	}
+	// The url is configured by the operator hence,
+	// #nosec
+	resp, err := HTTPClient(0).Do(req)
+	if err != nil {
+		return []adapter.Version{}, ErrResolveVersions(err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return []adapter.Version{}, ErrResolveVersions(fmt.Errorf("unexpected status code: %d", resp.StatusCode))
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, ChartMaxSize()))
+	if err != nil {
+		return []adapter.Version{}, ErrResolveVersions(err)
+	}
+
+	var index struct {
+		Entries map[string][]struct {
+			AppVersion string `yaml:"appVersion"`
+		} `yaml:"entries"`
+	}
+	if err := yaml.Unmarshal(body, &index); err != nil {
+		return []adapter.Version{}, ErrResolveVersions(err)
+	}
+
+	var versions []string
+	for _, entry := range index.Entries[traefikMeshChartName] {
+		versions = append(versions, entry.AppVersion)
+	}
+	return latestVersions(versions, limit), nil
+}
+
+// fileResolver offers the versions listed in a file
+type fileResolver struct {
+	path string
+}
+
+func (fr fileResolver) Versions(_ context.Context, limit int) ([]adapter.Version, error) {
+	byt, err := os.ReadFile(fr.path)
+	if err != nil {
+		return []adapter.Version{}, ErrResolveVersions(err)
+	}
+	return latestVersions(splitVersions(string(byt), "\n"), limit), nil
+}
+
+// latestVersions returns at most limit stable versions, the latest first
+func latestVersions(versions []string, limit int) []adapter.Version {
+	seen := map[string]bool{}
+	result := []adapter.Version{}
+	for _, v := range versions {
+		if !strings.HasPrefix(v, "v") {
+			v = "v" + v
+		}
+		if stableVersionRegex.MatchString(v) && !seen[v] {
+			seen[v] = true
+			result = append(result, adapter.Version(v))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return versionLess(string(result[j]), string(result[i]))
+	})
+	if limit < len(result) {
+		result = result[:limit]
+	}
+	return result
+}
+
+// versionLess compares two versions numerically, component by component
+func versionLess(a, b string) bool {
+	pa := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	pb := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		var na, nb int
+		_, _ = fmt.Sscan(pa[i], &na)
+		_, _ = fmt.Sscan(pb[i], &nb)
+		if na != nb {
+			return na < nb
+		}
+	}
+	return len(pa) < len(pb)
+}
+
+func splitVersions(s, sep string) []string {
+	var versions []string
+	for _, v := range strings.Split(s, sep) {
+		if v = strings.TrimSpace(v); v != "" {
+			versions = append(versions, v)
+		}
+	}
+	return versions
+}
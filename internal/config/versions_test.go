@@ -0,0 +1,114 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshkit/config/provider"
+	"github.com/layer5io/meshkit/errors"
+)
+
+// resetOfferedVersions drops the versions resolved by the previous tests
+func resetOfferedVersions(t *testing.T) {
+	t.Helper()
+	offeredVersions.Lock()
+	offeredVersions.versions = nil
+	offeredVersions.Unlock()
+	t.Cleanup(func() {
+		offeredVersions.Lock()
+		offeredVersions.versions = nil
+		offeredVersions.Unlock()
+	})
+}
+
+func TestPinnedStrategyOffersFixedVersions(t *testing.T) {
+	resetOfferedVersions(t)
+	t.Setenv(VersionStrategyEnv, PinnedStrategy)
+	t.Setenv(VersionPinEnv, "v1.4.5, 1.4.8,v1.5.0-rc1,v1.3.2,v1.4.10")
+
+	h, err := provider.NewInMem(provider.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RefreshOperationVersions(context.Background(), h); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	operations := adapter.Operations{}
+	if err := h.GetObject(adapter.OperationsKey, &operations); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []adapter.Version{"v1.4.10", "v1.4.8", "v1.4.5"}
+	for name, op := range operations {
+		if !versionedOperations[name] {
+			if len(op.Versions) != len(Operations[name].Versions) {
+				t.Errorf("the versions of %s changed to %v", name, op.Versions)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(op.Versions, want) {
+			t.Errorf("expected %s to offer %v, got %v", name, want, op.Versions)
+		}
+	}
+
+	// the versions are kept once resolved
+	t.Setenv(VersionPinEnv, "v2.0.0")
+	if versions, _ := LatestVersions(context.Background()); !reflect.DeepEqual(versions, want) {
+		t.Errorf("expected the resolved versions %v to be kept, got %v", want, versions)
+	}
+}
+
+func TestNewVersionResolver(t *testing.T) {
+	versionFile := filepath.Join(t.TempDir(), "versions")
+	if err := os.WriteFile(versionFile, []byte("v1.4.1\n\nv1.4.3\nv1.4.2\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		env      map[string]string
+		want     []adapter.Version
+		code     string
+		resolver VersionResolver
+	}{
+		{name: "github by default", resolver: gitHubResolver{}},
+		{name: "pinned", env: map[string]string{VersionStrategyEnv: PinnedStrategy, VersionPinEnv: "v1.4.8"}, want: []adapter.Version{"v1.4.8"}},
+		{name: "pinned without versions", env: map[string]string{VersionStrategyEnv: PinnedStrategy}, code: ErrResolveVersionsCode},
+		{name: "file", env: map[string]string{VersionStrategyEnv: FileStrategy, VersionFileEnv: versionFile}, want: []adapter.Version{"v1.4.3", "v1.4.2", "v1.4.1"}},
+		{name: "unknown strategy", env: map[string]string{VersionStrategyEnv: "artifactory"}, code: ErrInvalidEnvCode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, k := range []string{VersionStrategyEnv, VersionPinEnv, VersionFileEnv} {
+				t.Setenv(k, tt.env[k])
+			}
+			resolver, err := NewVersionResolver()
+			if err == nil && tt.resolver != nil {
+				if reflect.TypeOf(resolver) != reflect.TypeOf(tt.resolver) {
+					t.Fatalf("expected a %T, got %T", tt.resolver, resolver)
+				}
+				return
+			}
+			var versions []adapter.Version
+			if err == nil {
+				versions, err = resolver.Versions(context.Background(), 3)
+			}
+			if tt.code != "" {
+				if code := errors.GetCode(err); code != tt.code {
+					t.Fatalf("expected the error code %s, got %s: %v", tt.code, code, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(versions, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, versions)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+// Package logging builds the logger of the adapter, a meshkit logger whose
+// records can carry fields, e.g. the operation they belong to
+package logging
+
+import (
+	"os"
+	"time"
+
+	"github.com/layer5io/meshkit/errors"
+	"github.com/layer5io/meshkit/logger"
+	"github.com/sirupsen/logrus"
+)
+
+// The formats of the records
+const (
+	FormatSyslog = "syslog"
+	FormatJSON   = "json"
+)
+
+// Fields are the fields added to the records of a logger
+type Fields map[string]interface{}
+
+// Handler is a meshkit logger whose records can carry fields
+type Handler interface {
+	logger.Handler
+	WithFields(fields Fields) Handler
+}
+
+// fieldsLogger writes the records the way the meshkit logger does, the controller
+// and database loggers are the ones of meshkit
+type fieldsLogger struct {
+	logger.Handler
+	entry *logrus.Entry
+}
+
+// New creates the logger of the app, writing its records in the format: syslog
+// (the default) or json
+func New(appname, format string, debug bool) (Handler, error) {
+	var meshkitFormat logger.Format = logger.SyslogLogFormat
+	var formatter logrus.Formatter = &logrus.TextFormatter{
+		TimestampFormat: time.RFC3339,
+		FullTimestamp:   true,
+	}
+	if format == FormatJSON {
+		meshkitFormat = logger.JsonLogFormat
+		formatter = &logrus.JSONFormatter{
+			TimestampFormat: time.RFC3339,
+		}
+	}
+	base, err := logger.New(appname, logger.Options{
+		Format:     meshkitFormat,
+		DebugLevel: debug,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log := logrus.New()
+	log.SetFormatter(formatter)
+	log.SetOutput(os.Stdout)
+	log.SetLevel(logrus.InfoLevel)
+	if debug {
+		log.SetLevel(logrus.DebugLevel)
+	}
+	return &fieldsLogger{
+		Handler: base,
+		entry:   log.WithFields(logrus.Fields{"app": appname}),
+	}, nil
+}
+
+// WithFields returns a logger adding the fields to the records
+func (l *fieldsLogger) WithFields(fields Fields) Handler {
+	return &fieldsLogger{
+		Handler: l.Handler,
+		entry:   l.entry.WithFields(logrus.Fields(fields)),
+	}
+}
+
+func (l *fieldsLogger) Info(description ...interface{}) {
+	l.entry.Log(logrus.InfoLevel, description...)
+}
+
+func (l *fieldsLogger) Debug(description ...interface{}) {
+	l.entry.Log(logrus.DebugLevel, description...)
+}
+
+func (l *fieldsLogger) Warn(err error) {
+	if err == nil {
+		return
+	}
+	l.entry.WithFields(errorFields(err)).Log(logrus.WarnLevel, err.Error())
+}
+
+func (l *fieldsLogger) Error(err error) {
+	if err == nil {
+		return
+	}
+	l.entry.WithFields(errorFields(err)).Log(logrus.ErrorLevel, err.Error())
+}
+
+// errorFields are the fields of the meshkit error records
+func errorFields(err error) logrus.Fields {
+	return logrus.Fields{
+		"code":                  errors.GetCode(err),
+		"severity":              errors.GetSeverity(err),
+		"short-description":     errors.GetSDescription(err),
+		"probable-cause":        errors.GetCause(err),
+		"suggested-remediation": errors.GetRemedy(err),
+	}
+}
+
+// WithFields returns a logger adding the fields to the records of log, log itself when
+// it can't carry fields
+func WithFields(log logger.Handler, fields Fields) logger.Handler {
+	if h, ok := log.(Handler); ok {
+		return h.WithFields(fields)
+	}
+	return log
+}
@@ -0,0 +1,68 @@
+// Package metrics holds the prometheus metrics of the adapter, they are
+// exposed on /metrics along with the health endpoint
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "meshery_traefik_adapter"
+
+var (
+	registry = prometheus.NewRegistry()
+
+	operations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "operations_total",
+		Help:      "Operations completed by the adapter, by operation, result and adapter version.",
+	}, []string{"operation", "result", "version"})
+
+	operationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "operation_duration_seconds",
+		Help:      "Time taken by the operations from the request to the outcome, queueing included.",
+		// from 100ms to about 7 minutes, installs wait for the proxies to be ready
+		Buckets: prometheus.ExponentialBuckets(0.1, 2, 13),
+	}, []string{"operation", "version"})
+
+	registrations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "registrations_total",
+		Help:      "Component registrations with Meshery server, by kind (static or dynamic) and result.",
+	}, []string{"kind", "result"})
+)
+
+func init() {
+	registry.MustRegister(
+		operations,
+		operationDuration,
+		registrations,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// ObserveOperation records the outcome and the duration of an operation
+func ObserveOperation(operation, result, version string, d time.Duration) {
+	operations.WithLabelValues(operation, result, version).Inc()
+	operationDuration.WithLabelValues(operation, version).Observe(d.Seconds())
+}
+
+// ObserveRegistration records a component registration of the given kind, failed when err is set
+func ObserveRegistration(kind string, err error) {
+	result := "succeeded"
+	if err != nil {
+		result = "failed"
+	}
+	registrations.WithLabelValues(kind, result).Inc()
+}
+
+// Handler serves the metrics in the prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
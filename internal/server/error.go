@@ -0,0 +1,25 @@
+package server
+
+import (
+	"github.com/layer5io/meshkit/errors"
+)
+
+const (
+	// ErrHealthServerCode represents the error which occurs when the HTTP health
+	// endpoint could not be served
+	ErrHealthServerCode = "1091"
+
+	// ErrEventStreamLaggingCode represents the error which occurs when an event stream
+	// client doesn't keep up with the events and is disconnected
+	ErrEventStreamLaggingCode = "1106"
+)
+
+// ErrHealthServer is the error when the HTTP health endpoint could not be served
+func ErrHealthServer(err error) error {
+	return errors.New(ErrHealthServerCode, errors.Alert, []string{"Unable to serve the health endpoint"}, []string{err.Error()}, []string{"The health port is already in use or can't be bound by the adapter's user"}, []string{"Set HEALTH_PORT to a free port, the health is still served by the gRPC health service meanwhile"})
+}
+
+// ErrEventStreamLagging is the error when an event stream client doesn't keep up with the events
+func ErrEventStreamLagging() error {
+	return errors.New(ErrEventStreamLaggingCode, errors.Alert, []string{"Event stream disconnected"}, []string{"The client didn't receive the events as fast as they were emitted"}, []string{"The network between Meshery server and the adapter is slow or congested"}, []string{"Reconnect to the event stream, the buffered events are replayed first", "Raise EVENT_REPLAY_BUFFER_SIZE to tolerate longer bursts of events"})
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"sync"
+
+	adaptergrpc "github.com/layer5io/meshery-adapter-library/api/grpc"
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	"github.com/layer5io/meshkit/utils/events"
+)
+
+// EventBuffer retains the latest events published on the event streamer and replays them
+// to the clients subscribing to the event stream, a client reconnecting after a transient
+// disconnect catches up on the events emitted meanwhile before receiving the live events
+type EventBuffer struct {
+	mx sync.Mutex
+	// ring holds the latest events, next is the slot the next event is written to
+	ring    []*meshes.EventsResponse
+	next    int
+	full    bool
+	clients map[chan *meshes.EventsResponse]struct{}
+}
+
+// NewEventBuffer creates a buffer retaining the given number of events
+func NewEventBuffer(size int) *EventBuffer {
+	if size < 1 {
+		size = 1
+	}
+	return &EventBuffer{
+		ring:    make([]*meshes.EventsResponse, size),
+		clients: make(map[chan *meshes.EventsResponse]struct{}),
+	}
+}
+
+// Follow subscribes the buffer to the events published on the streamer
+func (eb *EventBuffer) Follow(e *events.EventStreamer) {
+	ch := make(chan interface{}, 10)
+	e.Subscribe(ch)
+	go func() {
+		for i := range ch {
+			if event, ok := i.(*meshes.EventsResponse); ok {
+				eb.add(event)
+			}
+		}
+	}()
+}
+
+// add retains the event and forwards it to the clients. A client too slow to keep up is
+// disconnected rather than stalling the others, it catches up from the buffer when it reconnects.
+func (eb *EventBuffer) add(event *meshes.EventsResponse) {
+	eb.mx.Lock()
+	defer eb.mx.Unlock()
+
+	eb.ring[eb.next] = event
+	eb.next = (eb.next + 1) % len(eb.ring)
+	if eb.next == 0 {
+		eb.full = true
+	}
+	for ch := range eb.clients {
+		select {
+		case ch <- event:
+		default:
+			delete(eb.clients, ch)
+			close(ch)
+		}
+	}
+}
+
+// subscribe returns the retained events, oldest first, along with the channel receiving the
+// events added from then on. The channel is closed when the client is too slow to keep up.
+func (eb *EventBuffer) subscribe() ([]*meshes.EventsResponse, chan *meshes.EventsResponse, func()) {
+	eb.mx.Lock()
+	defer eb.mx.Unlock()
+
+	var replay []*meshes.EventsResponse
+	if eb.full {
+		replay = append(replay, eb.ring[eb.next:]...)
+	}
+	replay = append(replay, eb.ring[:eb.next]...)
+
+	ch := make(chan *meshes.EventsResponse, len(eb.ring))
+	eb.clients[ch] = struct{}{}
+	unsubscribe := func() {
+		eb.mx.Lock()
+		defer eb.mx.Unlock()
+		if _, ok := eb.clients[ch]; ok {
+			delete(eb.clients, ch)
+			close(ch)
+		}
+	}
+	return replay, ch, unsubscribe
+}
+
+// bufferedService serves the MeshService, the event stream replays the buffered events first
+type bufferedService struct {
+	*adaptergrpc.Service
+	events *EventBuffer
+}
+
+// StreamEvents sends the retained events to the client followed by the live ones
+func (s *bufferedService) StreamEvents(_ *meshes.EventsRequest, srv meshes.MeshService_StreamEventsServer) error {
+	replay, ch, unsubscribe := s.events.subscribe()
+	defer unsubscribe()
+
+	for _, event := range replay {
+		if err := srv.Send(event); err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case event, ok := <-ch:
+			if !ok {
+				return ErrEventStreamLagging()
+			}
+			if err := srv.Send(event); err != nil {
+				return err
+			}
+		case <-srv.Context().Done():
+			return nil
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// HealthFunc reports whether the adapter is healthy along with the details of its subsystems
+type HealthFunc func() (bool, interface{})
+
+// healthService serves the gRPC health checking protocol on the service port
+type healthService struct {
+	healthpb.UnimplementedHealthServer
+	check HealthFunc
+}
+
+// Check reports the health of the adapter, the service name is ignored
+func (hs *healthService) Check(_ context.Context, _ *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	st := healthpb.HealthCheckResponse_SERVING
+	if healthy, _ := hs.check(); !healthy {
+		st = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	return &healthpb.HealthCheckResponse{Status: st}, nil
+}
+
+// Watch is not supported, the probes poll Check
+func (hs *healthService) Watch(_ *healthpb.HealthCheckRequest, _ healthpb.Health_WatchServer) error {
+	return status.Error(codes.Unimplemented, "watching the health is not supported")
+}
+
+// RegisterHealth serves the gRPC health checking protocol along with the MeshService,
+// it must be called before the server is started
+func (s *Server) RegisterHealth(check HealthFunc) {
+	healthpb.RegisterHealthServer(s.server, &healthService{check: check})
+}
+
+// HealthHandler responds 200 when the adapter is healthy and 503 otherwise,
+// the body describes the health of every subsystem
+func HealthHandler(check HealthFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		healthy, details := check()
+		w.Header().Set("Content-Type", "application/json")
+		if healthy {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(details)
+	})
+}
+
+// HealthServer serves the health of the adapter over HTTP on /healthz
+type HealthServer struct {
+	server *http.Server
+	mux    *http.ServeMux
+}
+
+// NewHealthServer creates the HTTP health server which binds to the given host and port
+func NewHealthServer(host, port string, check HealthFunc) *HealthServer {
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", HealthHandler(check))
+	return &HealthServer{
+		server: &http.Server{
+			Addr:              net.JoinHostPort(host, port),
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		},
+		mux: mux,
+	}
+}
+
+// Handle serves the handler along with the health, e.g. to expose diagnostics to the operators.
+// It must be called before the server is started.
+func (hs *HealthServer) Handle(pattern string, handler http.Handler) {
+	hs.mux.Handle(pattern, handler)
+}
+
+// JSONHandler responds with the JSON encoding of the value returned by fn
+func JSONHandler(fn func() interface{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(fn())
+	})
+}
+
+// JSONResultHandler responds with the JSON encoding of the value returned by fn, or with 500 and
+// the error when fn fails
+func JSONResultHandler(fn func() (interface{}, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		v, err := fn()
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			v = map[string]string{"error": err.Error()}
+		}
+		_ = json.NewEncoder(w).Encode(v)
+	})
+}
+
+// Address returns the address the health server binds to
+func (hs *HealthServer) Address() string {
+	return hs.server.Addr
+}
+
+// Start serves the health requests until the server is stopped
+func (hs *HealthServer) Start() error {
+	if err := hs.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return ErrHealthServer(err)
+	}
+	return nil
+}
+
+// Stop closes the health server within the timeout
+func (hs *HealthServer) Stop(timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	_ = hs.server.Shutdown(ctx)
+}
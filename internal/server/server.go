@@ -0,0 +1,87 @@
+// Package server runs the gRPC server which exposes the adapter's
+// MeshService on a configurable address
+package server
+
+import (
+	"net"
+	"time"
+
+	middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	grpc_recovery "github.com/grpc-ecosystem/go-grpc-middleware/recovery"
+	adaptergrpc "github.com/layer5io/meshery-adapter-library/api/grpc"
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// Server serves the MeshService of the adapter
+type Server struct {
+	service *adaptergrpc.Service
+	server  *grpc.Server
+	address string
+}
+
+// New creates a server for the service which binds to the given host
+// on the service's port. An empty host binds to all the interfaces. The
+// event stream replays the events retained by the buffer when it is set.
+func New(service *adaptergrpc.Service, host string, buffer *EventBuffer) *Server {
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(middleware.ChainUnaryServer(
+			grpc_recovery.UnaryServerInterceptor(
+				grpc_recovery.WithRecoveryHandler(panicHandler),
+			),
+		)),
+	)
+	// Reflection is enabled to simplify accessing the gRPC service using gRPCurl
+	reflection.Register(server)
+	if buffer != nil {
+		meshes.RegisterMeshServiceServer(server, &bufferedService{Service: service, events: buffer})
+	} else {
+		meshes.RegisterMeshServiceServer(server, service)
+	}
+
+	return &Server{
+		service: service,
+		server:  server,
+		address: net.JoinHostPort(host, service.Port),
+	}
+}
+
+// Address returns the address the server binds to
+func (s *Server) Address() string {
+	return s.address
+}
+
+// Start binds to the address and serves requests until the server is stopped
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.address)
+	if err != nil {
+		return adaptergrpc.ErrGrpcListener(err)
+	}
+
+	if err = s.server.Serve(listener); err != nil {
+		return adaptergrpc.ErrGrpcServer(err)
+	}
+	return nil
+}
+
+// Stop stops the server gracefully, connections which are still open once
+// the timeout elapsed, like the event streams, are closed forcefully
+func (s *Server) Stop(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		s.server.Stop()
+	}
+}
+
+// panicHandler recovers from panics raised while handling a request
+func panicHandler(r interface{}) error {
+	return adaptergrpc.ErrPanic(r)
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	adaptergrpc "github.com/layer5io/meshery-adapter-library/api/grpc"
+)
+
+// freePort returns a port nothing listens on
+func freePort(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	return port
+}
+
+func TestServerBindsToConfiguredHost(t *testing.T) {
+	tests := []struct {
+		name string
+		host string
+		dial string
+	}{
+		{name: "loopback address", host: "127.0.0.1", dial: "127.0.0.1"},
+		{name: "localhost for sidecars", host: "localhost", dial: "localhost"},
+		{name: "all the interfaces", host: "", dial: "127.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port := freePort(t)
+			srv := New(&adaptergrpc.Service{Port: port}, tt.host, nil)
+			if want := net.JoinHostPort(tt.host, port); srv.Address() != want {
+				t.Fatalf("expected the address %s, got %s", want, srv.Address())
+			}
+
+			errc := make(chan error, 1)
+			go func() {
+				errc <- srv.Start()
+			}()
+			defer srv.Stop(time.Second)
+
+			deadline := time.Now().Add(5 * time.Second)
+			for {
+				conn, err := net.DialTimeout("tcp", net.JoinHostPort(tt.dial, port), time.Second)
+				if err == nil {
+					_ = conn.Close()
+					return
+				}
+				select {
+				case err := <-errc:
+					t.Fatalf("the server didn't start: %v", err)
+				default:
+				}
+				if time.Now().After(deadline) {
+					t.Fatalf("the server doesn't accept connections on %s: %v", srv.Address(), err)
+				}
+				time.Sleep(20 * time.Millisecond)
+			}
+		})
+	}
+}
+
+func TestServerRejectsAddressInUse(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+
+	srv := New(&adaptergrpc.Service{Port: port}, "127.0.0.1", nil)
+	if err := srv.Start(); err == nil {
+		t.Fatalf("expected the server to fail binding %s, which is in use", srv.Address())
+	}
+}
@@ -0,0 +1,26 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/layer5io/meshkit/errors"
+)
+
+const (
+	// ErrInvalidConfigCode represents the error which occurs when the object
+	// storage configuration is incomplete or invalid
+	ErrInvalidConfigCode = "1051"
+
+	// ErrUploadCode represents the error which occurs when an object could not be uploaded
+	ErrUploadCode = "1052"
+)
+
+// ErrInvalidConfig is the error when the object storage configuration is invalid
+func ErrInvalidConfig(err error) error {
+	return errors.New(ErrInvalidConfigCode, errors.Alert, []string{"Invalid object storage configuration"}, []string{err.Error()}, []string{"The result store environment variables are incomplete or malformed"}, []string{"Set RESULT_STORE_ENDPOINT to a valid URL and RESULT_STORE_BUCKET to an existing bucket"})
+}
+
+// ErrUpload is the error when an object could not be uploaded to the object storage
+func ErrUpload(url string, err error) error {
+	return errors.New(ErrUploadCode, errors.Alert, []string{"Error uploading operation result"}, []string{fmt.Sprintf("failed to upload %s: %v", url, err)}, []string{"The object storage is unreachable or the credentials are not allowed to write to the bucket"}, []string{"Check the connectivity to the object storage and the configured credentials"})
+}
@@ -0,0 +1,136 @@
+// Package store uploads operation results to an S3-compatible object storage
+package store
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// service is the name of the signed service in the AWS signature
+	service = "s3"
+
+	// algorithm is the AWS signature algorithm used to sign the requests
+	algorithm = "AWS4-HMAC-SHA256"
+)
+
+// Config holds the connection details of the object storage
+type Config struct {
+	// Endpoint is the base URL of the object storage, e.g. https://s3.us-east-1.amazonaws.com
+	Endpoint  string
+	Bucket    string
+	Region    string
+	AccessKey string
+	SecretKey string
+	// Timeout bounds the duration of a single upload
+	Timeout time.Duration
+	// Transport sends the uploads, http.DefaultTransport is used when it is nil
+	Transport http.RoundTripper
+}
+
+// ObjectStore uploads objects to a bucket using path style requests
+// signed with AWS Signature Version 4
+type ObjectStore struct {
+	cfg    Config
+	client *http.Client
+	now    func() time.Time
+}
+
+// New returns an ObjectStore for the given configuration
+func New(cfg Config) (*ObjectStore, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, ErrInvalidConfig(fmt.Errorf("endpoint and bucket are required"))
+	}
+	if _, err := url.ParseRequestURI(cfg.Endpoint); err != nil {
+		return nil, ErrInvalidConfig(err)
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	return &ObjectStore{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout, Transport: cfg.Transport},
+		now:    time.Now,
+	}, nil
+}
+
+// Put uploads the object under the given key and returns its URL
+func (s *ObjectStore) Put(ctx context.Context, key, contentType string, body []byte) (string, error) {
+	objURL := fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(s.cfg.Endpoint, "/"), s.cfg.Bucket, key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, objURL, bytes.NewReader(body))
+	if err != nil {
+		return "", ErrUpload(objURL, err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	s.sign(req, body)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", ErrUpload(objURL, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", ErrUpload(objURL, fmt.Errorf("unexpected status code: %d", resp.StatusCode))
+	}
+	return objURL, nil
+}
+
+// sign adds the AWS Signature Version 4 headers to the request. Requests are
+// left unsigned when no credentials are configured, e.g. for public buckets.
+func (s *ObjectStore) sign(req *http.Request, body []byte) {
+	payloadHash := hashHex(body)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.cfg.AccessKey == "" || s.cfg.SecretKey == "" {
+		return
+	}
+
+	t := s.now().UTC()
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	signedHeaders := "content-type;host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := fmt.Sprintf("content-type:%s\nhost:%s\nx-amz-content-sha256:%s\nx-amz-date:%s\n",
+		req.Header.Get("Content-Type"), req.URL.Host, payloadHash, amzDate)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/%s/aws4_request", date, s.cfg.Region, service)
+	stringToSign := strings.Join([]string{algorithm, amzDate, scope, hashHex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretKey), date)
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		algorithm, s.cfg.AccessKey, scope, signedHeaders, signature))
+}
+
+func hashHex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(data))
+	return h.Sum(nil)
+}
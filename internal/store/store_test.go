@@ -0,0 +1,97 @@
+package store
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/layer5io/meshkit/errors"
+)
+
+// fakeObjectStore keeps the uploaded objects in memory, keyed by their path
+type fakeObjectStore struct {
+	mu      sync.Mutex
+	objects map[string]string
+	auth    map[string]string
+	status  int
+}
+
+func (f *fakeObjectStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if f.status != 0 {
+		w.WriteHeader(f.status)
+		return
+	}
+	byt, _ := io.ReadAll(r.Body)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.objects[r.URL.Path] = string(byt)
+	f.auth[r.URL.Path] = r.Header.Get("Authorization")
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestPut(t *testing.T) {
+	tests := []struct {
+		name      string
+		accessKey string
+		status    int
+		signed    bool
+		code      string
+	}{
+		{name: "signed upload", accessKey: "AKIDEXAMPLE", signed: true},
+		{name: "anonymous upload"},
+		{name: "rejected upload", accessKey: "AKIDEXAMPLE", status: http.StatusForbidden, code: ErrUploadCode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeObjectStore{objects: map[string]string{}, auth: map[string]string{}, status: tt.status}
+			srv := httptest.NewServer(fake)
+			defer srv.Close()
+
+			s, err := New(Config{Endpoint: srv.URL + "/", Bucket: "results", AccessKey: tt.accessKey, SecretKey: "secret", Timeout: time.Second})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			url, err := s.Put(context.Background(), "traefik-mesh/op-1.json", "application/json", []byte(`{"ok":true}`))
+			if tt.code != "" {
+				if code := errors.GetCode(err); code != tt.code {
+					t.Fatalf("expected the error code %s, got %s: %v", tt.code, code, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if url != srv.URL+"/results/traefik-mesh/op-1.json" {
+				t.Errorf("unexpected reference %s", url)
+			}
+			if got := fake.objects["/results/traefik-mesh/op-1.json"]; got != `{"ok":true}` {
+				t.Errorf("unexpected object %q, stored objects: %v", got, fake.objects)
+			}
+			auth := fake.auth["/results/traefik-mesh/op-1.json"]
+			if signed := strings.HasPrefix(auth, algorithm+" Credential="+tt.accessKey+"/"); signed != tt.signed {
+				t.Errorf("expected signed to be %v, got the authorization %q", tt.signed, auth)
+			}
+		})
+	}
+}
+
+func TestNewRejectsIncompleteConfig(t *testing.T) {
+	for _, cfg := range []Config{
+		{Bucket: "results"},
+		{Endpoint: "http://minio:9000"},
+		{Endpoint: "minio", Bucket: "results"},
+	} {
+		if _, err := New(cfg); errors.GetCode(err) != ErrInvalidConfigCode {
+			t.Errorf("expected %+v to be rejected with %s, got %v", cfg, ErrInvalidConfigCode, err)
+		}
+	}
+}
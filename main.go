@@ -15,10 +15,12 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"path"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/google/uuid"
@@ -30,8 +32,13 @@ import (
 	// "github.com/layer5io/meshkit/tracing"
 	"github.com/layer5io/meshery-adapter-library/adapter"
 	"github.com/layer5io/meshery-adapter-library/api/grpc"
+	adapterconfig "github.com/layer5io/meshery-adapter-library/config"
+	"github.com/layer5io/meshery-adapter-library/meshes"
 	"github.com/layer5io/meshery-traefik-mesh/build"
 	"github.com/layer5io/meshery-traefik-mesh/internal/config"
+	"github.com/layer5io/meshery-traefik-mesh/internal/logging"
+	"github.com/layer5io/meshery-traefik-mesh/internal/metrics"
+	"github.com/layer5io/meshery-traefik-mesh/internal/server"
 	configprovider "github.com/layer5io/meshkit/config/provider"
 )
 
@@ -42,35 +49,49 @@ var (
 	instanceID  = uuid.NewString()
 )
 
+const (
+	// serverStopTimeout bounds the time given to the open connections to close on shutdown
+	serverStopTimeout = 5 * time.Second
+
+	// eventFlushDelay is the time given to the event streams to forward the last events,
+	// they are published asynchronously
+	eventFlushDelay = 2 * time.Second
+)
+
 func init() {
 	// Create the config path if it doesn't exists as the entire adapter
 	// expects that directory to exists, which may or may not be true
-	if err := os.MkdirAll(path.Join(config.RootPath(), "bin"), 0750); err != nil {
+	if fatal, err := setupBinDir(config.BinPath); err != nil {
 		fmt.Println(err)
-		os.Exit(1)
+		if fatal {
+			os.Exit(1)
+		}
+		// the directory is created again by the operations which need it
 	}
 }
 
 // main is the entrypoint of the adapter
 func main() {
 	// Initialize Logger instance
-	log, err := logger.New(serviceName, logger.Options{
-		Format:     logger.SyslogLogFormat,
-		DebugLevel: isDebug(),
-	})
+	log, err := logging.New(serviceName, config.LogFormat(), isDebug())
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 
-	err = os.Setenv("KUBECONFIG", path.Join(
-		config.KubeConfig[configprovider.FilePath],
-		fmt.Sprintf("%s.%s", config.KubeConfig[configprovider.FileName], config.KubeConfig[configprovider.FileType])),
-	)
+	// Report every configuration problem at once rather than on the first operation using the value
+	configErr := config.Validate()
+	if configErr != nil {
+		if config.StrictConfigValidation() {
+			log.Error(configErr)
+			os.Exit(1)
+		}
+		log.Warn(configErr)
+	}
 
-	if err != nil {
-		// Fail silently
-		log.Warn(err)
+	kubeconfig := setupKubeconfig(log, os.Setenv)
+	if kubeconfig.fatal {
+		os.Exit(1)
 	}
 
 	// Initialize application specific configs and dependencies
@@ -102,25 +123,170 @@ func main() {
 	// }
 	e := events.NewEventStreamer()
 	// Initialize Handler intance
-	handler := traefik.New(cfg, log, kubeconfigHandler, e)
-	handler = adapter.AddLogger(log, handler)
+	mesh := traefik.New(cfg, log, kubeconfigHandler, e)
+	handler := adapter.AddLogger(log, mesh)
+
+	if kubeconfig.inCluster {
+		mesh.SetDefaultKubeconfigs([]string{kubeconfig.inClusterKubeconfig})
+	}
 
 	service.Handler = handler
 	service.EventStreamer = e
 	service.StartedAt = time.Now()
+	mesh.StartedAt = service.StartedAt
+	mesh.Version = version
+	if configErr != nil && config.DegradedOnInvalidConfig() {
+		// Start degraded unless the malformed values may fall back to their defaults
+		mesh.SetDegraded(configErr)
+	}
+	if kubeconfig.err != nil {
+		// Start degraded, only the operations which don't need a cluster are served
+		mesh.SetDegraded(kubeconfig.err)
+	}
 	service.Version = version
 	service.GitSHA = gitsha
 
-	go registerCapabilities(service.Port, log)        //Registering static capabilities
-	go registerDynamicCapabilities(service.Port, log) //Registering latest capabilities periodically
+	componentMetadata, err := config.ComponentMetadata()
+	if err != nil {
+		// only reachable with a lenient config validation, the components are registered without the metadata
+		log.Warn(err)
+	}
+	oam.SetComponentMetadata(componentMetadata)
+	oam.SetRegistrationOrder(config.RegistrationOrder())
+	oam.SetRegistrationBatchSize(config.RegistrationBatchSize())
+	oam.SetHTTPTransport(config.HTTPTransport())
+	oam.SetRegistrationBackoffCap(config.BackoffCap(config.RegistrationBackoffPath))
+	oam.SetFailOnInvalidSchema(config.FailOnInvalidSchema())
+	oam.SetLogger(log)
+	go registerCapabilities(service.Port, log)              //Registering static capabilities
+	go registerDynamicCapabilities(service.Port, log, mesh) //Registering latest capabilities periodically
+	go pruneStoredFilesPeriodically(log)
+	go refreshOperationVersions(cfg, log)
+	go reloadComponentsOnSignal(log) //Reloading meshmodel components on SIGHUP
 
-	// Server Initialization
-	log.Info("Adaptor Listening at port: ", service.Port)
-	err = grpc.Start(service, nil)
+	log.Info("Using the helm repository ", config.HelmRepositoryURL())
+	log.Info("Generating the dynamic components in ", build.MeshModelPath)
+
+	listenHost, err := config.ListenHost()
 	if err != nil {
 		log.Error(err)
 		os.Exit(1)
 	}
+
+	// Server Initialization
+	eventBuffer := server.NewEventBuffer(config.EventReplayBufferSize())
+	eventBuffer.Follow(e)
+	srv := server.New(service, listenHost, eventBuffer)
+	srv.RegisterHealth(adapterHealth(mesh))
+	log.Info("Adaptor Listening at address: ", srv.Address())
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.Start()
+	}()
+
+	var healthSrv *server.HealthServer
+	if port := config.HealthPort(); port != "" {
+		healthSrv = server.NewHealthServer(listenHost, port, adapterHealth(mesh))
+		healthSrv.Handle("/operations", server.JSONHandler(func() interface{} {
+			return mesh.OperationHistory()
+		}))
+		// the operations served by this adapter, whatever was registered with Meshery server
+		healthSrv.Handle("/capabilities", server.JSONResultHandler(func() (interface{}, error) {
+			return mesh.Capabilities()
+		}))
+		healthSrv.Handle("/metrics", metrics.Handler())
+		log.Info("Health endpoint listening at address: ", healthSrv.Address())
+		go func() {
+			// the probes fail without the endpoint, the adapter keeps serving the operations
+			if err := healthSrv.Start(); err != nil {
+				log.Error(err)
+			}
+		}()
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case err = <-errc:
+		if err != nil {
+			log.Error(err)
+			os.Exit(1)
+		}
+		return
+	case sig := <-sigs:
+		log.Info("Received ", sig, ", draining in-flight operations")
+	}
+
+	// Operations are drained before the server stops so that their events still reach Meshery
+	mesh.Drain(config.DrainTimeout())
+	time.Sleep(eventFlushDelay)
+	srv.Stop(serverStopTimeout)
+	if healthSrv != nil {
+		healthSrv.Stop(serverStopTimeout)
+	}
+	log.Info("Adapter stopped")
+}
+
+// kubeconfigSetup is the outcome of setting up KUBECONFIG on startup
+type kubeconfigSetup struct {
+	// inClusterKubeconfig is used by the requests which carry no kubeconfig when inCluster is set
+	inClusterKubeconfig string
+	inCluster           bool
+	// err makes the operations which need a cluster unavailable
+	err error
+	// fatal is set when err must stop the adapter, with KUBECONFIG_SETUP_MODE set to strict
+	fatal bool
+}
+
+// setupKubeconfig points KUBECONFIG to the kubeconfig written by the adapter using setenv,
+// falling back to the in-cluster service account when no kubeconfig was written yet
+func setupKubeconfig(log logger.Handler, setenv func(key, value string) error) kubeconfigSetup {
+	var res kubeconfigSetup
+	kubeconfigPath := config.KubeconfigPath()
+	if err := setenv("KUBECONFIG", kubeconfigPath); err != nil {
+		res.err = config.ErrKubeconfigSetup(err)
+	} else {
+		var configured bool
+		configured, res.err = config.ValidateKubeconfig(kubeconfigPath)
+		if res.err == nil && !configured {
+			var err error
+			res.inClusterKubeconfig, res.inCluster, err = config.InClusterKubeconfig()
+			switch {
+			case err != nil:
+				log.Warn(err)
+			case res.inCluster:
+				log.Info("Using the in-cluster service account for the requests which carry no kubeconfig")
+			default:
+				log.Info("No cluster configured: ", kubeconfigPath, " doesn't exist yet, it is written when Meshery sends the kubeconfigs")
+			}
+		}
+	}
+	if res.err != nil {
+		res.fatal = config.StrictKubeconfigSetup()
+		if res.fatal {
+			log.Error(res.err)
+		} else {
+			log.Warn(res.err)
+		}
+	}
+	return res
+}
+
+// setupBinDir creates the bin directory using binPath, its failure is fatal unless
+// the creation is deferred to the operations which need the directory
+func setupBinDir(binPath func() (string, error)) (fatal bool, err error) {
+	if _, err := binPath(); err != nil {
+		return config.StrictBinDirSetup(), err
+	}
+	return false, nil
+}
+
+// adapterHealth reports the health of the adapter to the health endpoints
+func adapterHealth(mesh *traefik.Mesh) server.HealthFunc {
+	return func() (bool, interface{}) {
+		h := mesh.Health()
+		return h.Healthy, h
+	}
 }
 
 func isDebug() bool {
@@ -128,7 +294,7 @@ func isDebug() bool {
 }
 
 func mesheryServerAddress() string {
-	meshReg := os.Getenv("MESHERY_SERVER")
+	meshReg := os.Getenv(config.MesheryServerEnv)
 
 	if meshReg != "" {
 		if strings.HasPrefix(meshReg, "http") {
@@ -142,7 +308,7 @@ func mesheryServerAddress() string {
 }
 
 func serviceAddress() string {
-	svcAddr := os.Getenv("SERVICE_ADDR")
+	svcAddr := os.Getenv(config.ServiceAddressEnv)
 
 	if svcAddr != "" {
 		return svcAddr
@@ -155,29 +321,85 @@ func registerCapabilities(port string, log logger.Handler) {
 	// Register meshmodel components
 	if err := oam.RegisterMeshModelComponents(instanceID, mesheryServerAddress(), serviceAddress(), port); err != nil {
 		log.Error(err)
+		oam.RecordRegistrationFailure(oam.StaticRegistration, err, time.Now())
+		return
 	}
+	oam.RecordRegistration(oam.StaticRegistration, time.Now())
 }
-func registerDynamicCapabilities(port string, log logger.Handler) {
-	registerWorkloads(port, log)
+func registerDynamicCapabilities(port string, log logger.Handler, mesh *traefik.Mesh) {
+	reRegisterAfter := config.DynamicRegistrationInterval()
+	log.Info("Registering the dynamic components again every ", reRegisterAfter)
+	registerWorkloads(port, log, mesh)
+	oam.RecordRegistrationCycle()
 	//Start the ticker
-	const reRegisterAfter = 24
-	ticker := time.NewTicker(reRegisterAfter * time.Hour)
+	ticker := time.NewTicker(reRegisterAfter)
 	for {
 		<-ticker.C
-		registerWorkloads(port, log)
+		registerWorkloads(port, log, mesh)
+		oam.RecordRegistrationCycle()
+	}
+}
+
+// pruneStoredFilesPeriodically removes the files written by the adapter beyond the configured retention
+func pruneStoredFilesPeriodically(log logger.Handler) {
+	const pruneEvery = time.Hour
+	ticker := time.NewTicker(pruneEvery)
+	for now := time.Now(); ; now = <-ticker.C {
+		if _, err := traefik.PruneStoredFiles(now); err != nil {
+			log.Warn(err)
+		}
+	}
+}
+
+// refreshOperationVersions resolves the traefik mesh versions offered by the versioned operations, the
+// lookup is retried until it succeeds, e.g. once GitHub is reachable again
+func refreshOperationVersions(cfg adapterconfig.Handler, log logger.Handler) {
+	const retryEvery = 10 * time.Minute
+	for {
+		err := config.RefreshOperationVersions(context.Background(), cfg)
+		if err == nil {
+			return
+		}
+		log.Warn(err)
+		time.Sleep(retryEvery)
+	}
+}
+
+// reloadComponentsOnSignal reloads the meshmodel components whenever the adapter receives SIGHUP
+func reloadComponentsOnSignal(log logger.Handler) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGHUP)
+	for range sigs {
+		res, err := oam.Reload()
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+		log.Info(fmt.Sprintf("Reloaded %d meshmodel components for versions %v", res.Components, res.Versions))
 	}
 }
 
-func registerWorkloads(port string, log logger.Handler) {
+func registerWorkloads(port string, log logger.Handler, mesh *traefik.Mesh) {
 	version := build.DefaultVersion
-	url := build.DefaultURL
+	url := config.CRDBaseURL(build.DefaultURL)
 	gm := build.DefaultGenerationMethod
 	// Prechecking to skip comp gen
-	if os.Getenv("FORCE_DYNAMIC_REG") != "true" && oam.AvailableVersions[version] {
+	force := config.ForceDynamicRegistration()
+	if !force && oam.AvailableVersions[version] {
 		log.Info("Components available statically for version ", version, ". Skipping dynamic component registeration")
+		if config.RegistrationSkipEvents() {
+			// Let the UI know the registration was skipped on purpose
+			mesh.StreamInfo(&meshes.EventsResponse{
+				OperationId:   uuid.NewString(),
+				Summary:       "Dynamic component registration skipped",
+				Details:       fmt.Sprintf("The components of version %s are available statically, set %s=true to generate them dynamically", version, config.ForceDynamicRegistrationEnv),
+				Component:     config.ServerConfig["type"],
+				ComponentName: config.ServerConfig["name"],
+			})
+		}
 		return
 	}
-	log.Info("Registering latest workload components for version ", version)
+	log.Info("Registering latest workload components for version ", version, " from ", url)
 	// Register workloads
 	for _, crd := range build.CRDNames {
 		crdurl := url + crd
@@ -191,6 +413,7 @@ func registerWorkloads(port string, log logger.Handler) {
 			Config:          build.NewConfig(version),
 		}); err != nil {
 			log.Info(err.Error())
+			oam.RecordRegistrationFailure(oam.DynamicRegistration, err, time.Now())
 			return
 		}
 	}
@@ -200,8 +423,22 @@ func registerWorkloads(port string, log logger.Handler) {
 
 	//Now we will register in case
 	log.Info("Registering workloads with Meshery Server for version ", version)
-	if err := oam.RegisterMeshModelComponents(instanceID, mesheryServerAddress(), serviceAddress(), port); err != nil {
+	var registered int
+	var err error
+	if force {
+		err = oam.RegisterMeshModelComponents(instanceID, mesheryServerAddress(), serviceAddress(), port)
+	} else {
+		// only the components which changed since they were last registered are posted again
+		registered, err = oam.RegisterChangedMeshModelComponents(instanceID, mesheryServerAddress(), serviceAddress(), port)
+	}
+	if err != nil {
 		log.Info(err.Error())
+		oam.RecordRegistrationFailure(oam.DynamicRegistration, err, time.Now())
+		return
+	}
+	oam.RecordRegistration(oam.DynamicRegistration, time.Now())
+	if !force && registered == 0 {
+		log.Info("No workload component changed since the last registration, nothing registered.")
 		return
 	}
 	log.Info("Latest workload components successfully registered.")
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	"github.com/layer5io/meshery-traefik-mesh/build"
+	"github.com/layer5io/meshery-traefik-mesh/internal/config"
+	"github.com/layer5io/meshery-traefik-mesh/traefik"
+	"github.com/layer5io/meshery-traefik-mesh/traefik/oam"
+	configprovider "github.com/layer5io/meshkit/config/provider"
+	"github.com/layer5io/meshkit/errors"
+	"github.com/layer5io/meshkit/logger"
+	"github.com/layer5io/meshkit/utils/events"
+)
+
+func TestSetupKubeconfigFailure(t *testing.T) {
+	failingSetenv := func(key, value string) error {
+		return fmt.Errorf("setenv %s: invalid argument", key)
+	}
+	tests := []struct {
+		mode  string
+		fatal bool
+		level string
+	}{
+		{mode: "strict", fatal: true, level: `"level":"error"`},
+		{mode: "lenient", level: `"level":"warning"`},
+		{mode: "", level: `"level":"warning"`},
+	}
+	for _, tt := range tests {
+		t.Run("mode "+tt.mode, func(t *testing.T) {
+			t.Setenv(config.KubeconfigSetupModeEnv, tt.mode)
+			var buf bytes.Buffer
+			log, err := logger.New("test", logger.Options{Format: logger.JsonLogFormat, Output: &buf})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res := setupKubeconfig(log, failingSetenv)
+			if code := errors.GetCode(res.err); code != config.ErrKubeconfigSetupCode {
+				t.Fatalf("expected the error code %s, got %s: %v", config.ErrKubeconfigSetupCode, code, res.err)
+			}
+			if res.fatal != tt.fatal {
+				t.Fatalf("expected fatal to be %v", tt.fatal)
+			}
+			if res.inCluster {
+				t.Fatal("the in-cluster fallback was used although KUBECONFIG couldn't be set")
+			}
+			if !strings.Contains(buf.String(), tt.level) || !strings.Contains(buf.String(), config.ErrKubeconfigSetupCode) {
+				t.Fatalf("expected the failure to be logged with %s, got %s", tt.level, buf.String())
+			}
+		})
+	}
+}
+
+func TestSetupKubeconfigInClusterFallback(t *testing.T) {
+	tests := []struct {
+		name       string
+		kubeconfig bool
+		token      bool
+		inCluster  bool
+		warning    string
+	}{
+		{name: "service account mounted", token: true, inCluster: true},
+		{name: "kubeconfig written by meshery", kubeconfig: true, token: true},
+		{name: "token missing", warning: config.ErrInClusterConfigCode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func(prev string) { config.KubeConfig[configprovider.FilePath] = prev }(config.KubeConfig[configprovider.FilePath])
+			config.KubeConfig[configprovider.FilePath] = t.TempDir()
+			if tt.kubeconfig {
+				kubeconfig := "apiVersion: v1\nkind: Config\nclusters:\n- name: fake-cluster\n  cluster:\n    server: https://127.0.0.1:6443\n"
+				if err := os.WriteFile(config.KubeconfigPath(), []byte(kubeconfig), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			defer func(prev string) { config.ServiceAccountDir = prev }(config.ServiceAccountDir)
+			config.ServiceAccountDir = t.TempDir()
+			for _, f := range []string{"ca.crt", "namespace"} {
+				if err := os.WriteFile(path.Join(config.ServiceAccountDir, f), []byte("traefik-mesh"), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.token {
+				if err := os.WriteFile(path.Join(config.ServiceAccountDir, "token"), []byte("fake-token"), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			t.Setenv("KUBERNETES_SERVICE_HOST", "10.96.0.1")
+			t.Setenv("KUBERNETES_SERVICE_PORT", "443")
+			var buf bytes.Buffer
+			log, err := logger.New("test", logger.Options{Format: logger.JsonLogFormat, Output: &buf})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res := setupKubeconfig(log, func(key, value string) error { return nil })
+			if res.err != nil || res.fatal {
+				t.Fatalf("unexpected failure, fatal %v: %v", res.fatal, res.err)
+			}
+			if res.inCluster != tt.inCluster {
+				t.Fatalf("expected in cluster to be %v", tt.inCluster)
+			}
+			if tt.inCluster && !strings.Contains(res.inClusterKubeconfig, "server: https://10.96.0.1:443") {
+				t.Fatalf("expected the in-cluster kubeconfig to target the service host, got %s", res.inClusterKubeconfig)
+			}
+			if !tt.inCluster && res.inClusterKubeconfig != "" {
+				t.Fatalf("expected no in-cluster kubeconfig, got %s", res.inClusterKubeconfig)
+			}
+			if tt.warning != "" && (!strings.Contains(buf.String(), `"level":"warning"`) || !strings.Contains(buf.String(), tt.warning)) {
+				t.Fatalf("expected a warning with %s, got %s", tt.warning, buf.String())
+			}
+		})
+	}
+}
+
+func TestSetupBinDirFailure(t *testing.T) {
+	failingBinPath := func() (string, error) {
+		return "", config.ErrBinDirSetup("/home/meshery/.meshery/bin", fmt.Errorf("read-only file system"))
+	}
+	tests := []struct {
+		mode  string
+		fatal bool
+	}{
+		{mode: "strict", fatal: true},
+		{mode: "", fatal: true},
+		{mode: "lenient"},
+	}
+	for _, tt := range tests {
+		t.Run("mode "+tt.mode, func(t *testing.T) {
+			t.Setenv(config.BinDirSetupModeEnv, tt.mode)
+			fatal, err := setupBinDir(failingBinPath)
+			if code := errors.GetCode(err); code != config.ErrBinDirSetupCode {
+				t.Fatalf("expected the error code %s, got %s: %v", config.ErrBinDirSetupCode, code, err)
+			}
+			if fatal != tt.fatal {
+				t.Fatalf("expected fatal to be %v", tt.fatal)
+			}
+		})
+	}
+
+	fatal, err := setupBinDir(func() (string, error) { return t.TempDir(), nil })
+	if fatal || err != nil {
+		t.Fatalf("expected the bin directory to be set up, got fatal %v: %v", fatal, err)
+	}
+}
+
+func TestRegisterWorkloadsSkipEvent(t *testing.T) {
+	available := oam.AvailableVersions[build.DefaultVersion]
+	oam.AvailableVersions[build.DefaultVersion] = true
+	t.Cleanup(func() { oam.AvailableVersions[build.DefaultVersion] = available })
+	t.Setenv(config.ForceDynamicRegistrationEnv, "")
+
+	tests := []struct {
+		skipEvents string
+		streamed   bool
+	}{
+		{skipEvents: "", streamed: true},
+		{skipEvents: "true", streamed: true},
+		{skipEvents: "false"},
+	}
+	for _, tt := range tests {
+		t.Run("skip events "+tt.skipEvents, func(t *testing.T) {
+			t.Setenv(config.RegistrationSkipEventsEnv, tt.skipEvents)
+			var buf bytes.Buffer
+			log, err := logger.New("test", logger.Options{Format: logger.JsonLogFormat, Output: &buf})
+			if err != nil {
+				t.Fatal(err)
+			}
+			e := events.NewEventStreamer()
+			ch := make(chan interface{}, 1)
+			e.Subscribe(ch)
+
+			registerWorkloads("10006", log, traefik.New(nil, log, nil, e))
+			// the workflows check the log line
+			if !strings.Contains(buf.String(), "Skipping dynamic component registeration") {
+				t.Fatalf("the skip was not logged: %s", buf.String())
+			}
+			select {
+			case ev := <-ch:
+				if !tt.streamed {
+					t.Fatalf("unexpected event %+v", ev)
+				}
+				res, ok := ev.(*meshes.EventsResponse)
+				if !ok || res.Summary != "Dynamic component registration skipped" || !strings.Contains(res.Details, config.ForceDynamicRegistrationEnv) {
+					t.Fatalf("expected the skip event, got %+v", ev)
+				}
+			case <-time.After(100 * time.Millisecond):
+				if tt.streamed {
+					t.Fatal("the skip event was not streamed")
+				}
+			}
+		})
+	}
+}
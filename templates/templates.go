@@ -0,0 +1,51 @@
+// Package templates embeds the meshmodel component definitions shipped with the adapter
+package templates
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// meshmodel holds the static meshmodel component definitions, by version
+//
+//go:embed meshmodel/components
+var meshmodel embed.FS
+
+// MeshmodelComponents returns the static meshmodel component definitions, the files are
+// named <version>/<component>_meshmodel.json
+func MeshmodelComponents() fs.FS {
+	comps, err := fs.Sub(meshmodel, "meshmodel/components")
+	if err != nil {
+		// the directory is embedded at build time
+		panic(err)
+	}
+	return comps
+}
+
+// Root returns the templates directory the adapter writes to at runtime, e.g. the components generated
+// dynamically. It is the templates directory next to the executable when it exists, the one of the working
+// directory otherwise. When neither exists, the directory next to the executable is returned.
+func Root() (string, error) {
+	var candidates []string
+	if exe, err := os.Executable(); err == nil {
+		if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+			exe = resolved
+		}
+		candidates = append(candidates, filepath.Join(filepath.Dir(exe), "templates"))
+	}
+	if wd, err := os.Getwd(); err == nil {
+		candidates = append(candidates, filepath.Join(wd, "templates"))
+	}
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("neither the executable path nor the working directory can be resolved")
+	}
+	for _, dir := range candidates {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir, nil
+		}
+	}
+	return candidates[0], nil
+}
@@ -0,0 +1,66 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRoot(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		t.Fatal(err)
+	}
+	exeTemplates := filepath.Join(filepath.Dir(exe), "templates")
+	if _, err := os.Stat(exeTemplates); err == nil {
+		t.Skipf("%s already exists next to the test binary", exeTemplates)
+	}
+	wd, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	wdTemplates := filepath.Join(wd, "templates")
+	prev, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(wd); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(prev) }()
+
+	tests := []struct {
+		name string
+		exe  bool
+		wd   bool
+		want string
+	}{
+		{name: "next to the executable and in the working directory", exe: true, wd: true, want: exeTemplates},
+		{name: "in the working directory only", wd: true, want: wdTemplates},
+		{name: "neither", want: exeTemplates},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for dir, present := range map[string]bool{exeTemplates: tt.exe, wdTemplates: tt.wd} {
+				if !present {
+					continue
+				}
+				if err := os.Mkdir(dir, 0700); err != nil {
+					t.Fatal(err)
+				}
+				defer os.RemoveAll(dir)
+			}
+
+			got, err := Root()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("expected the templates directory %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package traefik
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	internalconfig "github.com/layer5io/meshery-traefik-mesh/internal/config"
+	"github.com/layer5io/meshery-traefik-mesh/traefik/oam"
+)
+
+// SubsystemHealth is the health of a part of the adapter
+type SubsystemHealth struct {
+	Healthy bool   `json:"healthy"`
+	Detail  string `json:"detail"`
+}
+
+// AdapterHealth is the health of the adapter reported to the liveness and readiness probes,
+// it is healthy when all of its subsystems are
+type AdapterHealth struct {
+	Healthy    bool                       `json:"healthy"`
+	Subsystems map[string]SubsystemHealth `json:"subsystems"`
+}
+
+// Health checks the configuration, the kubeconfig and the component registration of the
+// adapter. It doesn't reach the clusters, so it is cheap enough to be probed frequently.
+func (mesh *Mesh) Health() AdapterHealth {
+	h := AdapterHealth{
+		Healthy: true,
+		Subsystems: map[string]SubsystemHealth{
+			"config":       configHealth(),
+			"kubeconfig":   kubeconfigHealth(),
+			"registration": registrationHealth(oam.GetRegistrationStats()),
+		},
+	}
+	for _, s := range h.Subsystems {
+		h.Healthy = h.Healthy && s.Healthy
+	}
+	return h
+}
+
+// configHealth reports the problems of the adapter configuration
+func configHealth() SubsystemHealth {
+	problems := internalconfig.ConfigProblems()
+	if len(problems) != 0 {
+		return SubsystemHealth{Detail: strings.Join(problems, "; ")}
+	}
+	return SubsystemHealth{Healthy: true, Detail: "the configuration is valid"}
+}
+
+// kubeconfigHealth reports whether the kubeconfig written for the clusters can be parsed, the
+// adapter is healthy until Meshery sends a kubeconfig
+func kubeconfigHealth() SubsystemHealth {
+	path := os.Getenv("KUBECONFIG")
+	if path == "" {
+		return SubsystemHealth{Detail: "KUBECONFIG is not set up"}
+	}
+	configured, err := internalconfig.ValidateKubeconfig(path)
+	switch {
+	case err != nil:
+		return SubsystemHealth{Detail: err.Error()}
+	case !configured:
+		return SubsystemHealth{Healthy: true, Detail: "no cluster is configured yet"}
+	}
+	return SubsystemHealth{Healthy: true, Detail: "the kubeconfig can be parsed"}
+}
+
+// registrationHealth reports whether the latest component registration succeeded
+func registrationHealth(rs oam.RegistrationStats) SubsystemHealth {
+	if rs.Failing() {
+		return SubsystemHealth{Detail: fmt.Sprintf("the registration failed at %s: %s", rs.LastFailure.Format(time.RFC3339), rs.LastError)}
+	}
+	var last []string
+	if rs.LastStatic != nil {
+		last = append(last, "static at "+rs.LastStatic.Format(time.RFC3339))
+	}
+	if rs.LastDynamic != nil {
+		last = append(last, "dynamic at "+rs.LastDynamic.Format(time.RFC3339))
+	}
+	if len(last) == 0 {
+		return SubsystemHealth{Healthy: true, Detail: "no registration has completed yet"}
+	}
+	return SubsystemHealth{Healthy: true, Detail: "registered components: " + strings.Join(last, ", ")}
+}
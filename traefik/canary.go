@@ -0,0 +1,156 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+)
+
+const (
+	canaryPromote      = "promote"
+	canaryRollback     = "rollback"
+	canaryInconclusive = "inconclusive"
+
+	defaultCanaryMinRequests        = 100
+	defaultCanaryMaxErrorRateDelta  = 0.01
+	defaultCanaryMaxLatencyIncrease = 1.2
+)
+
+// canaryAnalysisParams are the parameters of the canary analysis operation
+type canaryAnalysisParams struct {
+	// Canary and Stable are the names of the backend services being compared
+	Canary string `yaml:"canary" required:"true"`
+	Stable string `yaml:"stable" required:"true"`
+	// Window is the duration the backends are observed for. The traffic served since
+	// the proxies started is analysed when it is empty.
+	Window string `yaml:"window"`
+	// MinRequests is the number of canary requests below which the analysis is inconclusive
+	MinRequests float64 `yaml:"minRequests"`
+	// MaxErrorRateDelta is the tolerated increase of the error rate of the canary
+	MaxErrorRateDelta float64 `yaml:"maxErrorRateDelta"`
+	// MaxLatencyIncrease is the tolerated ratio between the canary and stable latencies
+	MaxLatencyIncrease float64 `yaml:"maxLatencyIncrease"`
+}
+
+// backendStats are the numbers supporting the canary recommendation
+type backendStats struct {
+	Service     string             `json:"service"`
+	Requests    float64            `json:"requests"`
+	SuccessRate float64            `json:"success_rate"`
+	AvgLatency  float64            `json:"avg_latency_seconds"`
+	Codes       map[string]float64 `json:"codes"`
+}
+
+// canaryReport is the outcome of the canary analysis of a cluster
+type canaryReport struct {
+	Recommendation string       `json:"recommendation"`
+	Reasons        []string     `json:"reasons"`
+	Window         string       `json:"window"`
+	Canary         backendStats `json:"canary"`
+	Stable         backendStats `json:"stable"`
+}
+
+// validate checks the parameters and fills in the default thresholds
+func (p *canaryAnalysisParams) validate() error {
+	if p.Canary == "" || p.Stable == "" {
+		return ErrInvalidOperationParams(fmt.Errorf("canary and stable backends are required"))
+	}
+	if p.MinRequests <= 0 {
+		p.MinRequests = defaultCanaryMinRequests
+	}
+	if p.MaxErrorRateDelta <= 0 {
+		p.MaxErrorRateDelta = defaultCanaryMaxErrorRateDelta
+	}
+	if p.MaxLatencyIncrease <= 0 {
+		p.MaxLatencyIncrease = defaultCanaryMaxLatencyIncrease
+	}
+	return nil
+}
+
+// analyseCanary compares the canary and stable backends of every cluster
+func (mesh *Mesh) analyseCanary(ctx context.Context, params canaryAnalysisParams, kubeconfigs []string, waiting func(time.Duration)) (map[string]interface{}, error) {
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+	var window time.Duration
+	if params.Window != "" {
+		var err error
+		window, err = proxyMetricsDiffParams{Window: params.Window}.window()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		after, err := snapshotProxyMetrics(ctx, kClient, "")
+		if err != nil {
+			return nil, err
+		}
+		before := &metricsSnapshot{Services: map[string]*serviceMetrics{}}
+		if window > 0 {
+			before = after
+			waiting(window)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(window):
+			}
+			after, err = snapshotProxyMetrics(ctx, kClient, "")
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		report := recommendCanary(
+			backendStatsOf(params.Canary, after.aggregate(params.Canary).sub(before.aggregate(params.Canary))),
+			backendStatsOf(params.Stable, after.aggregate(params.Stable).sub(before.aggregate(params.Stable))),
+			params,
+		)
+		report.Window = "since proxies started"
+		if window > 0 {
+			report.Window = window.String()
+		}
+		return report, nil
+	})
+}
+
+func backendStatsOf(service string, sm serviceMetrics) backendStats {
+	return backendStats{
+		Service:     service,
+		Requests:    sm.Requests,
+		SuccessRate: 1 - sm.errorRate(),
+		AvgLatency:  sm.avgLatency(),
+		Codes:       sm.Codes,
+	}
+}
+
+// recommendCanary decides whether the canary can be promoted from its numbers
+func recommendCanary(canary, stable backendStats, params canaryAnalysisParams) *canaryReport {
+	report := &canaryReport{
+		Recommendation: canaryPromote,
+		Reasons:        []string{},
+		Canary:         canary,
+		Stable:         stable,
+	}
+
+	if canary.Requests < params.MinRequests {
+		report.Recommendation = canaryInconclusive
+		report.Reasons = append(report.Reasons, fmt.Sprintf("canary served %.0f requests, at least %.0f are needed", canary.Requests, params.MinRequests))
+		return report
+	}
+
+	if delta := stable.SuccessRate - canary.SuccessRate; delta > params.MaxErrorRateDelta {
+		report.Recommendation = canaryRollback
+		report.Reasons = append(report.Reasons, fmt.Sprintf("canary error rate is %.2f%% higher than stable, %.2f%% is tolerated", delta*100, params.MaxErrorRateDelta*100))
+	}
+	if stable.AvgLatency > 0 && canary.AvgLatency > stable.AvgLatency*params.MaxLatencyIncrease {
+		report.Recommendation = canaryRollback
+		report.Reasons = append(report.Reasons, fmt.Sprintf("canary average latency %.3fs exceeds %.1fx the stable latency %.3fs", canary.AvgLatency, params.MaxLatencyIncrease, stable.AvgLatency))
+	}
+	if report.Recommendation == canaryPromote {
+		report.Reasons = append(report.Reasons, "canary error rate and latency are within the tolerated thresholds")
+	}
+	return report
+}
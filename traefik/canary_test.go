@@ -0,0 +1,82 @@
+package traefik
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRecommendCanary(t *testing.T) {
+	stable := backendStats{Service: "reviews-v1", Requests: 5000, SuccessRate: 0.995, AvgLatency: 0.100}
+	tests := []struct {
+		name    string
+		canary  backendStats
+		params  canaryAnalysisParams
+		want    string
+		reasons []string
+	}{
+		{
+			name:    "healthy canary",
+			canary:  backendStats{Requests: 500, SuccessRate: 0.99, AvgLatency: 0.110},
+			want:    canaryPromote,
+			reasons: []string{"within the tolerated thresholds"},
+		},
+		{
+			name:    "too little traffic",
+			canary:  backendStats{Requests: 40, SuccessRate: 0.5, AvgLatency: 1},
+			want:    canaryInconclusive,
+			reasons: []string{"canary served 40 requests, at least 100 are needed"},
+		},
+		{
+			name:    "failing canary",
+			canary:  backendStats{Requests: 500, SuccessRate: 0.95, AvgLatency: 0.100},
+			want:    canaryRollback,
+			reasons: []string{"error rate is 4.50% higher than stable"},
+		},
+		{
+			name:    "slow and failing canary",
+			canary:  backendStats{Requests: 500, SuccessRate: 0.9, AvgLatency: 0.250},
+			want:    canaryRollback,
+			reasons: []string{"error rate is 9.50% higher", "latency 0.250s exceeds 1.2x"},
+		},
+		{
+			name:    "latency within a custom threshold",
+			canary:  backendStats{Requests: 500, SuccessRate: 0.995, AvgLatency: 0.180},
+			params:  canaryAnalysisParams{MaxLatencyIncrease: 2},
+			want:    canaryPromote,
+			reasons: []string{"within the tolerated thresholds"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := tt.params
+			params.Canary, params.Stable = "reviews-v2", "reviews-v1"
+			if err := params.validate(); err != nil {
+				t.Fatal(err)
+			}
+			tt.canary.Service = "reviews-v2"
+
+			report := recommendCanary(tt.canary, stable, params)
+			if report.Recommendation != tt.want {
+				t.Fatalf("expected to %s, got %s because %v", tt.want, report.Recommendation, report.Reasons)
+			}
+			if len(report.Reasons) != len(tt.reasons) {
+				t.Fatalf("expected %d reasons, got %v", len(tt.reasons), report.Reasons)
+			}
+			for i, r := range tt.reasons {
+				if !strings.Contains(report.Reasons[i], r) {
+					t.Errorf("expected the reason %q, got %q", r, report.Reasons[i])
+				}
+			}
+			if report.Canary.Service != "reviews-v2" || report.Stable.Service != "reviews-v1" {
+				t.Errorf("the supporting numbers are missing from %+v", report)
+			}
+		})
+	}
+}
+
+func TestCanaryAnalysisParamsRequireBackends(t *testing.T) {
+	params := canaryAnalysisParams{Canary: "reviews-v2"}
+	if err := params.validate(); err == nil {
+		t.Fatal("expected the missing stable backend to be rejected")
+	}
+}
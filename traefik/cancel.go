@@ -0,0 +1,161 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+
+	internalconfig "github.com/layer5io/meshery-traefik-mesh/internal/config"
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	"helm.sh/helm/v3/pkg/action"
+)
+
+// cancelParams are the parameters of the cancel operation
+type cancelParams struct {
+	// OperationID is the id of the queued or running operation to cancel
+	OperationID string `yaml:"operationId" required:"true"`
+}
+
+// cancelResult is the result of the cancel operation
+type cancelResult struct {
+	OperationID string `json:"operation_id"`
+	Cancelled   bool   `json:"cancelled"`
+}
+
+// CancelOperation cancels the queued or running operation, it reports false when the operation is not in flight.
+// A running operation stops at its next cancellation point and emits a cancelled event.
+func (mesh *Mesh) CancelOperation(id string) bool {
+	return mesh.inflight.cancel(id)
+}
+
+// cancelOperation cancels the operation given by the parameters of the cancel operation
+func (mesh *Mesh) cancelOperation(params cancelParams) (cancelResult, error) {
+	if params.OperationID == "" {
+		return cancelResult{}, ErrInvalidOperationParams(fmt.Errorf("operationId is required"))
+	}
+	if !mesh.CancelOperation(params.OperationID) {
+		return cancelResult{}, ErrInvalidOperationParams(fmt.Errorf("operation %s is not in flight", params.OperationID))
+	}
+	return cancelResult{OperationID: params.OperationID, Cancelled: true}, nil
+}
+
+// priorInstall is what the namespace held before an install, a cancelled install restores it
+type priorInstall struct {
+	// release is the helm release installed before, empty when there was none
+	release          string
+	releaseNamespace string
+	// revision is the latest revision of the release before the install
+	revision int
+	// version is the version installed before with the kubectl backend, empty when there was none
+	version string
+	// createdNamespace reports whether the install created the namespace
+	createdNamespace bool
+}
+
+// recordPriorInstall returns the traefik mesh install the namespace holds, before the install applies anything
+func (mesh *Mesh) recordPriorInstall(ctx context.Context, kClient *mesherykube.Client, namespace string) (priorInstall, error) {
+	var prior priorInstall
+	name, releaseNamespace, err := findTraefikMeshRelease(ctx, kClient, namespace)
+	if err != nil {
+		return prior, err
+	}
+	if name != "" {
+		cfg, err := mesh.helmActionConfig(kClient, releaseNamespace)
+		if err != nil {
+			return prior, err
+		}
+		rel, err := action.NewGet(cfg).Run(name)
+		if err != nil {
+			return prior, err
+		}
+		prior.release, prior.releaseNamespace, prior.revision = name, releaseNamespace, rel.Version
+		return prior, nil
+	}
+	backend, version, err := installedBackend(ctx, kClient, namespace)
+	if err != nil {
+		return prior, err
+	}
+	if backend == installBackendKubectl {
+		prior.version = version
+	}
+	return prior, nil
+}
+
+// rollbackInstall restores, on a best effort basis, what the namespace held before a cancelled install. The
+// release installed before is rolled back to its previous revision and the manifests applied before by the
+// kubectl backend are applied again, otherwise what the install applied is removed along with the namespace
+// when the install created it. The failures are logged, the install already failed.
+func (mesh *Mesh) rollbackInstall(kClient *mesherykube.Client, backend, chartPath, namespace string, values map[string]interface{}, prior priorInstall) {
+	// the context of the operation is cancelled already
+	ctx := context.Background()
+	var err error
+	switch {
+	case prior.release != "":
+		err = mesh.restoreRelease(ctx, kClient, prior)
+	case prior.version != "":
+		err = restoreRendered(ctx, kClient, namespace, values, prior)
+	default:
+		err = mesh.removeInstall(ctx, kClient, backend, chartPath, namespace, values)
+	}
+	if err != nil {
+		mesh.Log.Warn(ErrApplyHelmChart(fmt.Errorf("rolling back the cancelled install in namespace %s: %w", namespace, err)))
+		return
+	}
+	if prior.createdNamespace {
+		if _, err := deleteCreatedNamespace(ctx, kClient, namespace); err != nil {
+			mesh.Log.Warn(ErrUninstall(err))
+		}
+	}
+	mesh.Log.Info(fmt.Sprintf("Rolled back the cancelled install of Traefik Mesh in namespace %s", namespace))
+}
+
+// restoreRelease rolls the release back to the revision it had before the install, unless the install
+// didn't get to create a revision
+func (mesh *Mesh) restoreRelease(ctx context.Context, kClient *mesherykube.Client, prior priorInstall) error {
+	cfg, err := mesh.helmActionConfig(kClient, prior.releaseNamespace)
+	if err != nil {
+		return err
+	}
+	current, err := action.NewGet(cfg).Run(prior.release)
+	if err != nil {
+		return err
+	}
+	if current.Version <= prior.revision {
+		return nil
+	}
+	_, target, err := rollbackRelease(cfg, prior.release, prior.revision)
+	if err != nil {
+		return err
+	}
+	return recordInstallBackend(ctx, kClient, prior.releaseNamespace, installBackendHelm, normalizeVersion(target.Chart.Metadata.AppVersion))
+}
+
+// restoreRendered applies again the manifests of the version installed before with the kubectl backend
+func restoreRendered(ctx context.Context, kClient *mesherykube.Client, namespace string, values map[string]interface{}, prior priorInstall) error {
+	chartPath, err := fetchChart(ctx, traefikMeshRepository(), traefikMeshChart, prior.version)
+	if err != nil {
+		return err
+	}
+	if err := applyRenderedChart(kClient, chartPath, false, namespace, values); err != nil {
+		return err
+	}
+	return recordInstallBackend(ctx, kClient, namespace, installBackendKubectl, prior.version)
+}
+
+// removeInstall removes what the install applied when traefik mesh wasn't installed before
+func (mesh *Mesh) removeInstall(ctx context.Context, kClient *mesherykube.Client, backend, chartPath, namespace string, values map[string]interface{}) error {
+	var err error
+	if backend == installBackendKubectl {
+		err = applyRenderedChart(kClient, chartPath, true, namespace, values)
+	} else {
+		err = kClient.ApplyHelmChart(mesherykube.ApplyHelmChartConfig{
+			LocalPath: chartPath,
+			Namespace: namespace,
+			Action:    mesherykube.UNINSTALL,
+			Logger:    mesh.helmLogger(internalconfig.LogMaxLineLength()),
+		})
+	}
+	if err != nil {
+		return err
+	}
+	return recordInstallBackend(ctx, kClient, namespace, "", "")
+}
@@ -0,0 +1,68 @@
+package traefik
+
+import (
+	"sort"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	internalconfig "github.com/layer5io/meshery-traefik-mesh/internal/config"
+	"github.com/layer5io/meshery-traefik-mesh/traefik/oam"
+)
+
+// OperationCapability describes an operation advertised by the adapter
+type OperationCapability struct {
+	Name                 string            `json:"name"`
+	Category             string            `json:"category"`
+	Description          string            `json:"description"`
+	Versions             []string          `json:"versions,omitempty"`
+	AdditionalProperties map[string]string `json:"additional_properties,omitempty"`
+	// Parameters are the parameters read from the custom body of the request
+	Parameters []OperationParameter `json:"parameters,omitempty"`
+}
+
+// Capabilities are the operations and components advertised by the adapter
+type Capabilities struct {
+	Adapter    string                `json:"adapter"`
+	Version    string                `json:"version,omitempty"`
+	Operations []OperationCapability `json:"operations"`
+	Components []oam.Component       `json:"components"`
+}
+
+// Capabilities returns the operations registered in the config, along with their parameters,
+// and the meshmodel components registered with Meshery server
+func (mesh *Mesh) Capabilities() (*Capabilities, error) {
+	operations := make(adapter.Operations)
+	if err := mesh.Config.GetObject(adapter.OperationsKey, &operations); err != nil {
+		return nil, ErrCapabilities(err)
+	}
+	comps, err := oam.Components()
+	if err != nil {
+		return nil, ErrCapabilities(err)
+	}
+
+	caps := &Capabilities{
+		Adapter:    internalconfig.ServerConfig["name"],
+		Version:    mesh.Version,
+		Operations: make([]OperationCapability, 0, len(operations)),
+		Components: comps,
+	}
+	for name, op := range operations {
+		oc := OperationCapability{
+			Name:                 name,
+			Category:             meshes.OpCategory_name[op.Type],
+			Description:          op.Description,
+			AdditionalProperties: op.AdditionalProperties,
+		}
+		if params, ok := operationParamTypes[name]; ok {
+			oc.Parameters = describeParams(params)
+		}
+		for _, v := range op.Versions {
+			oc.Versions = append(oc.Versions, string(v))
+		}
+		caps.Operations = append(caps.Operations, oc)
+	}
+	sort.Slice(caps.Operations, func(i, j int) bool {
+		return caps.Operations[i].Name < caps.Operations[j].Name
+	})
+	return caps, nil
+}
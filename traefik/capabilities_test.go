@@ -0,0 +1,58 @@
+package traefik
+
+import (
+	"testing"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	internalconfig "github.com/layer5io/meshery-traefik-mesh/internal/config"
+	"github.com/layer5io/meshkit/config/provider"
+)
+
+func TestCapabilitiesMatchRegisteredOperations(t *testing.T) {
+	h, err := provider.NewInMem(provider.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the operations registered on startup, with the versions resolved later on
+	registered := make(adapter.Operations, len(internalconfig.Operations))
+	for name, op := range internalconfig.Operations {
+		copied := *op
+		registered[name] = &copied
+	}
+	registered[internalconfig.TraefikMeshOperation].Versions = []adapter.Version{"v1.4.8", "v1.4.5"}
+	if err := h.SetObject(adapter.OperationsKey, registered); err != nil {
+		t.Fatal(err)
+	}
+	mesh := &Mesh{Adapter: adapter.Adapter{Config: h}, Version: "v0.6.0"}
+
+	caps, err := mesh.Capabilities()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if caps.Adapter != internalconfig.ServerConfig["name"] || caps.Version != "v0.6.0" {
+		t.Errorf("unexpected adapter %s %s", caps.Adapter, caps.Version)
+	}
+	if len(caps.Operations) != len(registered) {
+		t.Fatalf("expected the %d registered operations, got %d", len(registered), len(caps.Operations))
+	}
+	for i, oc := range caps.Operations {
+		if i > 0 && caps.Operations[i-1].Name >= oc.Name {
+			t.Errorf("the operations are not sorted by name: %s before %s", caps.Operations[i-1].Name, oc.Name)
+		}
+		op, ok := registered[oc.Name]
+		if !ok {
+			t.Errorf("the capability %s is not a registered operation", oc.Name)
+			continue
+		}
+		if oc.Category != meshes.OpCategory_name[op.Type] || oc.Description != op.Description || len(oc.Versions) != len(op.Versions) {
+			t.Errorf("the capability %+v doesn't match the registered operation %+v", oc, op)
+		}
+		if _, ok := operationParamTypes[oc.Name]; ok != (len(oc.Parameters) > 0) {
+			t.Errorf("expected the parameters of %s to be described: %v", oc.Name, oc.Parameters)
+		}
+	}
+	if len(caps.Components) == 0 {
+		t.Error("expected the embedded components to be advertised")
+	}
+}
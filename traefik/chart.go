@@ -0,0 +1,229 @@
+package traefik
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/layer5io/meshery-traefik-mesh/internal/config"
+	"gopkg.in/yaml.v2"
+)
+
+// traefikMeshChart is the name of the traefik mesh chart in the repository
+const traefikMeshChart = "traefik-mesh"
+
+// traefikMeshRepository returns the helm repository hosting the traefik mesh chart
+func traefikMeshRepository() string {
+	return config.HelmRepositoryURL()
+}
+
+// helmIndex is the subset of a helm repository's index.yaml used by the adapter
+type helmIndex struct {
+	Entries map[string][]helmIndexEntry `yaml:"entries"`
+
+	// byAppVersion maps the normalized app versions to the entries, per chart
+	byAppVersion map[string]map[string]helmIndexEntry
+}
+
+// cachedHelmIndex is a helm index along with the time it was fetched at
+type cachedHelmIndex struct {
+	index     *helmIndex
+	fetchedAt time.Time
+}
+
+// helmIndexCache keeps the fetched helm indexes by repository, so that the installs and
+// registrations within the cache TTL don't download the index again
+var helmIndexCache = struct {
+	sync.Mutex
+	repos map[string]cachedHelmIndex
+}{repos: map[string]cachedHelmIndex{}}
+
+// helmIndexEntry describes a single chart release within the helm index
+type helmIndexEntry struct {
+	Name       string   `yaml:"name"`
+	Version    string   `yaml:"version"`
+	AppVersion string   `yaml:"appVersion"`
+	URLs       []string `yaml:"urls"`
+}
+
+// entryForAppVersion returns the chart entry whose app version matches the given one
+func (hi *helmIndex) entryForAppVersion(chart, appVersion string) (helmIndexEntry, bool) {
+	entry, ok := hi.byAppVersion[chart][normalizeVersion(appVersion)]
+	return entry, ok
+}
+
+// indexAppVersions builds the app version map of the index, the first entry of an
+// app version wins as the index lists the latest chart versions first
+func (hi *helmIndex) indexAppVersions() {
+	hi.byAppVersion = make(map[string]map[string]helmIndexEntry, len(hi.Entries))
+	for chart, entries := range hi.Entries {
+		versions := make(map[string]helmIndexEntry, len(entries))
+		for _, entry := range entries {
+			v := normalizeVersion(entry.AppVersion)
+			if _, ok := versions[v]; !ok {
+				versions[v] = entry
+			}
+		}
+		hi.byAppVersion[chart] = versions
+	}
+}
+
+// fetchHelmIndex returns the index.yaml of the given repository, it is downloaded once
+// per cache TTL. The stale index is returned when the repository is unreachable.
+func fetchHelmIndex(ctx context.Context, repo string) (*helmIndex, error) {
+	helmIndexCache.Lock()
+	defer helmIndexCache.Unlock()
+
+	cached, ok := helmIndexCache.repos[repo]
+	if ok && time.Since(cached.fetchedAt) < config.HelmIndexCacheTTL() {
+		return cached.index, nil
+	}
+
+	hi, err := downloadHelmIndex(ctx, repo)
+	if err != nil {
+		if ok {
+			return cached.index, nil
+		}
+		return nil, err
+	}
+	helmIndexCache.repos[repo] = cachedHelmIndex{index: hi, fetchedAt: time.Now()}
+	return hi, nil
+}
+
+// downloadHelmIndex downloads and decodes the index.yaml of the given repository
+func downloadHelmIndex(ctx context.Context, repo string) (*helmIndex, error) {
+	byt, err := downloadWithLimit(ctx, fmt.Sprintf("%s/index.yaml", strings.TrimSuffix(repo, "/")), config.ChartDownloadTimeout(), config.ChartMaxSize())
+	if err != nil {
+		return nil, err
+	}
+
+	var hi helmIndex
+	if err := yaml.Unmarshal(byt, &hi); err != nil {
+		return nil, ErrDecodeYaml(err)
+	}
+	hi.indexAppVersions()
+	return &hi, nil
+}
+
+// fetchChart downloads the chart matching the app version into the adapter's
+// chart cache and returns its location on the filesystem. Charts which are
+// already present in the cache are not downloaded again.
+func fetchChart(ctx context.Context, repo, chart, appVersion string) (string, error) {
+	index, err := fetchHelmIndex(ctx, repo)
+	if err != nil {
+		return "", err
+	}
+
+	entry, ok := index.entryForAppVersion(chart, appVersion)
+	if !ok || len(entry.URLs) == 0 {
+		return "", ErrEntryWithAppVersionNotExists(chart, appVersion)
+	}
+
+	chartURL := entry.URLs[0]
+	if !strings.HasPrefix(chartURL, "http://") && !strings.HasPrefix(chartURL, "https://") {
+		chartURL = fmt.Sprintf("%s/%s", strings.TrimSuffix(repo, "/"), chartURL)
+	}
+
+	dir := path.Join(config.RootPath(), "charts")
+	dest := path.Join(dir, chartCacheKey(repo)+"-"+path.Base(chartURL))
+	if _, err := os.Stat(dest); err == nil {
+		return dest, nil
+	}
+
+	byt, err := downloadWithLimit(ctx, chartURL, config.ChartDownloadTimeout(), config.ChartMaxSize())
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return "", ErrDownloadChart(chartURL, err)
+	}
+	if err := writeFileAtomic(dir, dest, byt); err != nil {
+		return "", ErrDownloadChart(chartURL, err)
+	}
+
+	return dest, nil
+}
+
+// chartCacheKey returns the prefix of the cached charts of the repository, so that the repositories
+// serving a chart under the same file name don't share it. The cache stays a flat directory, which
+// the retention of the stored files prunes.
+func chartCacheKey(repo string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSuffix(repo, "/")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// writeFileAtomic writes the file through a temporary file of dir renamed to dest, so that an
+// interrupted write never leaves a truncated file at dest
+func writeFileAtomic(dir, dest string, byt []byte) error {
+	tmp, err := os.CreateTemp(dir, ".download-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// nothing is left to remove once renamed
+		_ = os.Remove(tmp.Name())
+	}()
+	if _, err := tmp.Write(byt); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dest)
+}
+
+// downloadWithLimit fetches the given url, aborting when ctx is done, the request takes
+// longer than timeout or the response body grows beyond maxSize bytes
+func downloadWithLimit(ctx context.Context, url string, timeout time.Duration, maxSize int64) ([]byte, error) {
+	client := config.HTTPClient(timeout)
+
+	// The url is either the configured helm repository or derived from its index hence,
+	// #nosec
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, ErrDownloadChart(url, err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, ErrDownloadChart(url, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, ErrDownloadChart(url, fmt.Errorf("unexpected status code: %d", resp.StatusCode))
+	}
+
+	if resp.ContentLength > maxSize {
+		return nil, ErrChartTooLarge(url, maxSize)
+	}
+
+	byt, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, ErrDownloadChart(url, err)
+	}
+	if int64(len(byt)) > maxSize {
+		return nil, ErrChartTooLarge(url, maxSize)
+	}
+
+	return byt, nil
+}
+
+// normalizeVersion adds the "v" prefix to the version if it isn't present already
+func normalizeVersion(version string) string {
+	if strings.HasPrefix(version, "v") {
+		return version
+	}
+	return "v" + version
+}
@@ -0,0 +1,158 @@
+package traefik
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/layer5io/meshkit/errors"
+)
+
+func TestDownloadWithLimit(t *testing.T) {
+	chart := strings.Repeat("c", 512)
+	tests := []struct {
+		name      string
+		handler   http.HandlerFunc
+		timeout   time.Duration
+		maxSize   int64
+		cancelled bool
+		want      string
+		code      string
+	}{
+		{
+			name: "within the limits",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(chart))
+			},
+			timeout: time.Second,
+			maxSize: 1024,
+			want:    chart,
+		},
+		{
+			name: "content length over the limit",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(chart))
+			},
+			timeout: time.Second,
+			maxSize: 256,
+			code:    ErrChartTooLargeCode,
+		},
+		{
+			name: "streamed body over the limit",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				// flushing before the end leaves the content length unknown
+				for i := 0; i < 4; i++ {
+					_, _ = w.Write([]byte(chart))
+					w.(http.Flusher).Flush()
+				}
+			},
+			timeout: time.Second,
+			maxSize: 1024,
+			code:    ErrChartTooLargeCode,
+		},
+		{
+			name: "slower than the timeout",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-time.After(time.Second):
+				case <-r.Context().Done():
+				}
+			},
+			timeout: 50 * time.Millisecond,
+			maxSize: 1024,
+			code:    ErrDownloadChartCode,
+		},
+		{
+			// the operation was cancelled while the chart was downloaded
+			name: "cancelled",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-time.After(time.Second):
+				case <-r.Context().Done():
+				}
+			},
+			timeout:   time.Minute,
+			maxSize:   1024,
+			cancelled: true,
+			code:      ErrDownloadChartCode,
+		},
+		{
+			name: "unexpected status",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.NotFound(w, r)
+			},
+			timeout: time.Second,
+			maxSize: 1024,
+			code:    ErrDownloadChartCode,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(tt.handler)
+			defer srv.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelled {
+				time.AfterFunc(50*time.Millisecond, cancel)
+			}
+			byt, err := downloadWithLimit(ctx, srv.URL+"/traefik-mesh-4.1.1.tgz", tt.timeout, tt.maxSize)
+			if tt.code != "" {
+				if err == nil {
+					t.Fatalf("expected an error with code %s, got none", tt.code)
+				}
+				if code := errors.GetCode(err); code != tt.code {
+					t.Fatalf("expected the error code %s, got %s: %v", tt.code, code, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(byt) != tt.want {
+				t.Fatalf("expected %d bytes, got %d", len(tt.want), len(byt))
+			}
+		})
+	}
+}
+
+func TestChartCacheKey(t *testing.T) {
+	mirror := chartCacheKey("https://charts.example.com/mesh")
+	if mirror == chartCacheKey("https://helm.traefik.io/mesh") {
+		t.Fatalf("the charts of two repositories share the cache key %s", mirror)
+	}
+	if mirror != chartCacheKey("https://charts.example.com/mesh/") {
+		t.Fatalf("the trailing slash of the repository changed its cache key")
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	dest := path.Join(dir, "chart.tgz")
+	if err := os.WriteFile(dest, []byte("truncat"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeFileAtomic(dir, dest, []byte("complete chart")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	byt, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(byt) != "complete chart" {
+		t.Fatalf("expected the complete chart, got %q", byt)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected the temporary file to be gone, found %d files", len(entries))
+	}
+}
@@ -0,0 +1,108 @@
+package traefik
+
+import (
+	"context"
+	"sync"
+
+	internalconfig "github.com/layer5io/meshery-traefik-mesh/internal/config"
+	"github.com/layer5io/meshkit/models"
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// collectFromClusters runs fn concurrently against a kubernetes client for each of
+// the kubeconfigs and returns the results keyed by the cluster's current context
+func collectFromClusters(kubeconfigs []string, fn func(*mesherykube.Client) (interface{}, error)) (map[string]interface{}, error) {
+	var wg sync.WaitGroup
+	var errs []error
+	var mx sync.Mutex
+	results := make(map[string]interface{})
+	for _, k8sconfig := range kubeconfigs {
+		wg.Add(1)
+		go func(k8sconfig string) {
+			defer wg.Done()
+			kClient, err := mesherykube.New([]byte(k8sconfig))
+			if err != nil {
+				mx.Lock()
+				errs = append(errs, err)
+				mx.Unlock()
+				return
+			}
+
+			res, err := fn(kClient)
+			mx.Lock()
+			defer mx.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+			results[clusterName(k8sconfig, kClient)] = res
+		}(k8sconfig)
+	}
+	wg.Wait()
+	if len(errs) != 0 {
+		return results, mergeErrors(errs)
+	}
+	return results, nil
+}
+
+// clusterName returns the current context of the kubeconfig which is used to
+// identify the cluster in operation results, falling back to the API server host
+func clusterName(kubeconfig string, kClient *mesherykube.Client) string {
+	kconfig := models.Kubeconfig{}
+	if err := yaml.Unmarshal([]byte(kubeconfig), &kconfig); err != nil || kconfig.CurrentContext == "" {
+		return kClient.RestConfig.Host
+	}
+	return kconfig.CurrentContext
+}
+
+// forEachNamespace runs fn for each of the namespaces, as many at once as the configured
+// namespace scan concurrency allows, and returns the results keyed by namespace
+func forEachNamespace(namespaces []string, fn func(string) (interface{}, error)) (map[string]interface{}, error) {
+	var wg sync.WaitGroup
+	var errs []error
+	var mx sync.Mutex
+	results := make(map[string]interface{})
+	workers := make(chan struct{}, internalconfig.NamespaceScanConcurrency())
+	for _, ns := range namespaces {
+		wg.Add(1)
+		workers <- struct{}{}
+		go func(ns string) {
+			defer wg.Done()
+			defer func() { <-workers }()
+
+			res, err := fn(ns)
+			mx.Lock()
+			defer mx.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+			results[ns] = res
+		}(ns)
+	}
+	wg.Wait()
+	if len(errs) != 0 {
+		return results, mergeErrors(errs)
+	}
+	return results, nil
+}
+
+// listNamespaces returns the names of the namespaces of the cluster
+func listNamespaces(ctx context.Context, kClient *mesherykube.Client) ([]string, error) {
+	var nss *corev1.NamespaceList
+	err := retryOnTransient(ctx, func() (err error) {
+		nss, err = kClient.KubeClient.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(nss.Items))
+	for _, ns := range nss.Items {
+		names = append(names, ns.Name)
+	}
+	return names, nil
+}
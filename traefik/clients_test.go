@@ -0,0 +1,157 @@
+package traefik
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	internalconfig "github.com/layer5io/meshery-traefik-mesh/internal/config"
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+var (
+	// trafficSplitGVR is the TrafficSplit version listed first by the adapter
+	trafficSplitGVR = schema.GroupVersionResource{Group: smiSplitGroup, Version: "v1alpha4", Resource: "trafficsplits"}
+
+	trafficTargetGVR  = schema.GroupVersionResource{Group: smiAccessGroup, Version: "v1alpha2", Resource: "traffictargets"}
+	httpRouteGroupGVR = schema.GroupVersionResource{Group: smiSpecsGroup, Version: "v1alpha3", Resource: "httproutegroups"}
+)
+
+// fakeAPIServer answers the requests with the object registered for their path, or not
+// found, and records the body of the patches, prefixed with their path, when patches is set
+func fakeAPIServer(t *testing.T, responses map[string]interface{}, patches *[]string) *httptest.Server {
+	t.Helper()
+	var mx sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch && patches != nil {
+			byt, _ := io.ReadAll(r.Body)
+			mx.Lock()
+			*patches = append(*patches, r.URL.Path+" "+string(byt))
+			mx.Unlock()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		res, ok := responses[r.URL.Path]
+		if !ok {
+			// answered like the API server, a plain text body would be taken for an unexpected error
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(metav1.Status{
+				TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"},
+				Status:   metav1.StatusFailure,
+				Reason:   metav1.StatusReasonNotFound,
+				Code:     http.StatusNotFound,
+			})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// fakeKubeconfig returns a kubeconfig of the fake-cluster context pointing to the server
+func fakeKubeconfig(server string) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: fake
+  cluster:
+    server: %s
+users:
+- name: fake
+  user:
+    token: fake
+contexts:
+- name: fake-cluster
+  context:
+    cluster: fake
+    user: fake
+current-context: fake-cluster
+`, server)
+}
+
+// fakeClient returns a client whose typed requests are answered by a fake API server
+// and whose dynamic client holds the given objects
+func fakeClient(t *testing.T, responses map[string]interface{}, objs ...runtime.Object) *mesherykube.Client {
+	t.Helper()
+	srv := fakeAPIServer(t, responses, nil)
+
+	kube, err := kubernetes.NewForConfig(&rest.Config{Host: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		crdResource:       "CustomResourceDefinitionList",
+		trafficSplitGVR:   "TrafficSplitList",
+		trafficTargetGVR:  "TrafficTargetList",
+		httpRouteGroupGVR: "HTTPRouteGroupList",
+	}, objs...)
+	return &mesherykube.Client{KubeClient: kube, DynamicKubeClient: dyn}
+}
+
+func TestForEachNamespace(t *testing.T) {
+	t.Setenv(internalconfig.NamespaceScanConcurrencyEnv, "4")
+	namespaces := make([]string, 0, 200)
+	for i := 0; i < 200; i++ {
+		namespaces = append(namespaces, fmt.Sprintf("team-%03d", i))
+	}
+
+	tests := []struct {
+		name   string
+		failed map[string]bool
+	}{
+		{name: "every namespace scanned"},
+		{name: "failed namespaces", failed: map[string]bool{"team-007": true, "team-150": true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var inflight, peak atomic.Int32
+			res, err := forEachNamespace(namespaces, func(ns string) (interface{}, error) {
+				n := inflight.Add(1)
+				defer inflight.Add(-1)
+				for {
+					if p := peak.Load(); n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				if tt.failed[ns] {
+					return nil, fmt.Errorf("listing the services of %s: forbidden", ns)
+				}
+				return "scanned " + ns, nil
+			})
+
+			if len(tt.failed) == 0 && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for ns := range tt.failed {
+				if err == nil || !strings.Contains(err.Error(), ns) {
+					t.Fatalf("expected the failure of %s to be reported, got %v", ns, err)
+				}
+			}
+			if len(res) != len(namespaces)-len(tt.failed) {
+				t.Fatalf("expected %d results, got %d", len(namespaces)-len(tt.failed), len(res))
+			}
+			for _, ns := range namespaces {
+				if r, ok := res[ns]; !tt.failed[ns] && (!ok || r != "scanned "+ns) {
+					t.Fatalf("unexpected result of %s: %v", ns, r)
+				}
+			}
+			if p := peak.Load(); p < 2 || p > 4 {
+				t.Fatalf("expected up to 4 namespaces scanned at once, got %d", p)
+			}
+		})
+	}
+}
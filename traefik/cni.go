@@ -0,0 +1,156 @@
+package traefik
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	cniCompatible = "compatible"
+	cniWarning    = "warning"
+	cniUnknown    = "unknown"
+
+	// policyNote applies to the CNIs enforcing network policies: the meshed traffic reaches
+	// the destination pods from the proxies rather than from the client pods
+	policyNote = "Network policies see the traefik mesh proxies as the source of the meshed traffic, allow the proxy pods to reach the meshed services"
+)
+
+// cniProfile describes a CNI, how to detect it and how it behaves with traefik mesh
+type cniProfile struct {
+	name string
+	// daemonSets are the name prefixes of the DaemonSets deploying the CNI
+	daemonSets []string
+	verdict    string
+	notes      []string
+}
+
+// cniProfiles lists the known CNIs, the first matching profile wins hence canal,
+// which runs calico along flannel, comes before both
+var cniProfiles = []cniProfile{
+	{
+		name:       "canal",
+		daemonSets: []string{"canal"},
+		verdict:    cniCompatible,
+		notes:      []string{policyNote},
+	},
+	{
+		name:       "calico",
+		daemonSets: []string{"calico-node"},
+		verdict:    cniCompatible,
+		notes:      []string{policyNote},
+	},
+	{
+		name:       "cilium",
+		daemonSets: []string{"cilium"},
+		verdict:    cniWarning,
+		notes: []string{
+			policyNote,
+			"With the kube-proxy replacement, socket level load balancing resolves the service IPs before the traffic leaves the pod, make sure the shadow services are not bypassed when the mesh DNS names are not used",
+		},
+	},
+	{
+		name:       "flannel",
+		daemonSets: []string{"kube-flannel", "flannel"},
+		verdict:    cniCompatible,
+	},
+	{
+		name:       "aws-vpc-cni",
+		daemonSets: []string{"aws-node"},
+		verdict:    cniWarning,
+		notes: []string{
+			"Every proxy pod consumes an IP of the node's ENIs, account for it in the maximum number of pods per node",
+		},
+	},
+	{
+		name:       "azure-cni",
+		daemonSets: []string{"azure-cni", "azure-cns"},
+		verdict:    cniCompatible,
+	},
+	{
+		name:       "antrea",
+		daemonSets: []string{"antrea-agent"},
+		verdict:    cniCompatible,
+		notes:      []string{policyNote},
+	},
+	{
+		name:       "kube-router",
+		daemonSets: []string{"kube-router"},
+		verdict:    cniWarning,
+		notes: []string{
+			policyNote,
+			"When kube-router replaces kube-proxy with IPVS, check that the shadow services are reachable from every node",
+		},
+	},
+	{
+		name:       "weave-net",
+		daemonSets: []string{"weave-net"},
+		verdict:    cniWarning,
+		notes: []string{
+			policyNote,
+			"Weave Net is no longer maintained, consider migrating to a maintained CNI",
+		},
+	},
+	{
+		name:       "ovn-kubernetes",
+		daemonSets: []string{"ovnkube-node"},
+		verdict:    cniCompatible,
+		notes:      []string{policyNote},
+	},
+}
+
+// cniReport is the CNI detected in a cluster and its compatibility with traefik mesh
+type cniReport struct {
+	CNI       string   `json:"cni"`
+	DaemonSet string   `json:"daemonset,omitempty"`
+	Verdict   string   `json:"verdict"`
+	Notes     []string `json:"notes,omitempty"`
+}
+
+// checkCNICompatibility detects the CNI of every cluster from its DaemonSets and
+// reports the known issues of the CNI with the shadow services of traefik mesh
+func (mesh *Mesh) checkCNICompatibility(ctx context.Context, kubeconfigs []string) (map[string]interface{}, error) {
+	return collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		var dss *appsv1.DaemonSetList
+		err := retryOnTransient(ctx, func() (err error) {
+			dss, err = kClient.KubeClient.AppsV1().DaemonSets("").List(ctx, metav1.ListOptions{})
+			return err
+		})
+		if err != nil {
+			return nil, ErrCNICompatibility(err)
+		}
+		names := make([]string, 0, len(dss.Items))
+		for _, ds := range dss.Items {
+			names = append(names, ds.Name)
+		}
+		return detectCNI(names), nil
+	})
+}
+
+// detectCNI matches the DaemonSet names against the known CNIs
+func detectCNI(daemonSets []string) *cniReport {
+	sort.Strings(daemonSets)
+	for _, profile := range cniProfiles {
+		for _, prefix := range profile.daemonSets {
+			for _, ds := range daemonSets {
+				if strings.HasPrefix(ds, prefix) {
+					return &cniReport{
+						CNI:       profile.name,
+						DaemonSet: ds,
+						Verdict:   profile.verdict,
+						Notes:     profile.notes,
+					}
+				}
+			}
+		}
+	}
+	return &cniReport{
+		CNI:     cniUnknown,
+		Verdict: cniUnknown,
+		Notes:   []string{"The CNI could not be detected, it may be managed by the cloud provider outside of the cluster"},
+	}
+}
@@ -0,0 +1,34 @@
+package traefik
+
+import "testing"
+
+func TestDetectCNI(t *testing.T) {
+	tests := []struct {
+		name       string
+		daemonSets []string
+		cni        string
+		daemonSet  string
+		verdict    string
+	}{
+		{name: "flannel", daemonSets: []string{"kube-proxy", "kube-flannel-ds"}, cni: "flannel", daemonSet: "kube-flannel-ds", verdict: cniCompatible},
+		{name: "calico", daemonSets: []string{"kube-proxy", "calico-node"}, cni: "calico", daemonSet: "calico-node", verdict: cniCompatible},
+		// canal runs calico-node along flannel and is detected first
+		{name: "canal", daemonSets: []string{"calico-node", "canal", "kube-flannel"}, cni: "canal", daemonSet: "canal", verdict: cniCompatible},
+		{name: "cilium", daemonSets: []string{"cilium", "cilium-envoy"}, cni: "cilium", daemonSet: "cilium", verdict: cniWarning},
+		{name: "aws vpc cni", daemonSets: []string{"aws-node", "kube-proxy", "ebs-csi-node"}, cni: "aws-vpc-cni", daemonSet: "aws-node", verdict: cniWarning},
+		{name: "unmaintained weave net", daemonSets: []string{"weave-net"}, cni: "weave-net", daemonSet: "weave-net", verdict: cniWarning},
+		{name: "managed outside of the cluster", daemonSets: []string{"kube-proxy", "node-exporter"}, cni: cniUnknown, verdict: cniUnknown},
+		{name: "no daemonset", cni: cniUnknown, verdict: cniUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := detectCNI(tt.daemonSets)
+			if report.CNI != tt.cni || report.DaemonSet != tt.daemonSet || report.Verdict != tt.verdict {
+				t.Fatalf("expected %s from %q with the verdict %s, got %+v", tt.cni, tt.daemonSet, tt.verdict, report)
+			}
+			if tt.verdict != cniCompatible && len(report.Notes) == 0 {
+				t.Fatalf("expected the remediation notes of the %s verdict", tt.verdict)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package traefik
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	findingOK      = "ok"
+	findingWarning = "warning"
+	findingError   = "error"
+
+	// defaultKubeVersionConstraint is the kubernetes version required by traefik mesh
+	// when the chart doesn't state it
+	defaultKubeVersionConstraint = ">= 1.11.0-0"
+)
+
+// conflictingMeshGroups maps the API groups installed by other service meshes to the mesh,
+// running two meshes side by side makes their proxies intercept the same traffic
+var conflictingMeshGroups = map[string]string{
+	"networking.istio.io":       "Istio",
+	"linkerd.io":                "Linkerd",
+	"policy.linkerd.io":         "Linkerd",
+	"consul.hashicorp.com":      "Consul",
+	"kuma.io":                   "Kuma",
+	"config.openservicemesh.io": "Open Service Mesh",
+}
+
+// compatibilityFinding is the outcome of a single check of the compatibility preflight
+type compatibilityFinding struct {
+	Check    string `json:"check"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// compatibilityReport is the compatibility of a cluster with the traefik mesh version, the cluster is
+// compatible unless a finding is an error, the warnings don't prevent the install
+type compatibilityReport struct {
+	KubernetesVersion string                 `json:"kubernetes_version"`
+	RequiredVersion   string                 `json:"required_kubernetes_version"`
+	Nodes             int                    `json:"nodes"`
+	SchedulableNodes  int                    `json:"schedulable_nodes"`
+	ConflictingMeshes []string               `json:"conflicting_meshes,omitempty"`
+	Compatible        bool                   `json:"compatible"`
+	Findings          []compatibilityFinding `json:"findings"`
+}
+
+// errors returns the messages of the error findings
+func (cr compatibilityReport) errors() []string {
+	var msgs []string
+	for _, f := range cr.Findings {
+		if f.Severity == findingError {
+			msgs = append(msgs, f.Message)
+		}
+	}
+	return msgs
+}
+
+// kubeVersionConstraint returns the kubernetes versions supported by the chart
+func kubeVersionConstraint(chartPath string) (string, error) {
+	ch, err := loader.Load(chartPath)
+	if err != nil {
+		return "", err
+	}
+	if ch.Metadata != nil && ch.Metadata.KubeVersion != "" {
+		return ch.Metadata.KubeVersion, nil
+	}
+	return defaultKubeVersionConstraint, nil
+}
+
+// compatibilityPreflight checks the compatibility of every cluster with the version of traefik mesh
+func (mesh *Mesh) compatibilityPreflight(ctx context.Context, version string, kubeconfigs []string) (map[string]interface{}, error) {
+	chartPath, err := fetchChart(ctx, traefikMeshRepository(), traefikMeshChart, version)
+	if err != nil {
+		return nil, err
+	}
+	constraint, err := kubeVersionConstraint(chartPath)
+	if err != nil {
+		return nil, ErrApplyHelmChart(err)
+	}
+	return collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		return checkCompatibility(ctx, kClient, constraint)
+	})
+}
+
+// rejectIncompatibleClusters runs the compatibility preflight before an install, the reports are
+// streamed along e, if set. It fails when a cluster doesn't meet the requirements of traefik mesh.
+func (mesh *Mesh) rejectIncompatibleClusters(ctx context.Context, chartPath string, e *meshes.EventsResponse, kubeconfigs []string) error {
+	constraint, err := kubeVersionConstraint(chartPath)
+	if err != nil {
+		return ErrApplyHelmChart(err)
+	}
+	res, err := collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		return checkCompatibility(ctx, kClient, constraint)
+	})
+	if err != nil {
+		return err
+	}
+	if e != nil {
+		if byt, err := json.Marshal(res); err == nil {
+			mesh.streamUpdate(e, "Compatibility preflight completed", string(byt))
+		}
+	}
+	var problems []string
+	for cluster, r := range res {
+		for _, msg := range r.(compatibilityReport).errors() {
+			problems = append(problems, fmt.Sprintf("%s: %s", cluster, msg))
+		}
+	}
+	if len(problems) != 0 {
+		sort.Strings(problems)
+		return ErrIncompatibleCluster(problems)
+	}
+	return nil
+}
+
+// checkCompatibility checks the kubernetes version of the cluster against the constraint, the nodes
+// the proxies can run on and the service meshes already installed
+func checkCompatibility(ctx context.Context, kClient *mesherykube.Client, constraint string) (compatibilityReport, error) {
+	report := compatibilityReport{RequiredVersion: constraint}
+
+	info, err := kClient.KubeClient.Discovery().ServerVersion()
+	if err != nil {
+		return report, ErrCompatibilityPreflight(err)
+	}
+	report.KubernetesVersion = info.GitVersion
+	if chartutil.IsCompatibleRange(constraint, info.GitVersion) {
+		report.add("kubernetes_version", findingOK, fmt.Sprintf("kubernetes %s meets the requirement %s", info.GitVersion, constraint))
+	} else {
+		report.add("kubernetes_version", findingError, fmt.Sprintf("kubernetes %s doesn't meet the requirement %s of traefik mesh", info.GitVersion, constraint))
+	}
+
+	var nodes *corev1.NodeList
+	err = retryOnTransient(ctx, func() (err error) {
+		nodes, err = kClient.KubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return report, ErrCompatibilityPreflight(err)
+	}
+	report.Nodes = len(nodes.Items)
+	for _, node := range nodes.Items {
+		if schedulable(node) {
+			report.SchedulableNodes++
+		}
+	}
+	switch {
+	case report.SchedulableNodes == 0:
+		report.add("nodes", findingWarning, "no node is ready and schedulable, the proxy daemonset won't run any proxy")
+	case report.SchedulableNodes < report.Nodes:
+		report.add("nodes", findingWarning, fmt.Sprintf("%d of the %d nodes are not ready or not schedulable, the proxy daemonset won't run a proxy there and their pods are not meshed", report.Nodes-report.SchedulableNodes, report.Nodes))
+	default:
+		report.add("nodes", findingOK, fmt.Sprintf("the proxy daemonset can run on the %d nodes", report.Nodes))
+	}
+
+	groups, err := kClient.KubeClient.Discovery().ServerGroups()
+	if err != nil {
+		return report, ErrCompatibilityPreflight(err)
+	}
+	found := map[string]bool{}
+	for _, g := range groups.Groups {
+		if name, ok := conflictingMeshGroups[g.Name]; ok && !found[name] {
+			found[name] = true
+			report.ConflictingMeshes = append(report.ConflictingMeshes, name)
+		}
+	}
+	sort.Strings(report.ConflictingMeshes)
+	if len(report.ConflictingMeshes) != 0 {
+		report.add("conflicting_meshes", findingWarning, fmt.Sprintf("%s already installed, make sure the meshes don't proxy the same workloads", strings.Join(report.ConflictingMeshes, ", ")))
+	} else {
+		report.add("conflicting_meshes", findingOK, "no other service mesh is installed")
+	}
+
+	report.Compatible = len(report.errors()) == 0
+	return report, nil
+}
+
+func (cr *compatibilityReport) add(check, severity, message string) {
+	cr.Findings = append(cr.Findings, compatibilityFinding{Check: check, Severity: severity, Message: message})
+}
+
+// schedulable reports whether the node is ready and accepts new pods
+func schedulable(node corev1.Node) bool {
+	if node.Spec.Unschedulable {
+		return false
+	}
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+package traefik
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+const (
+	// controllerAPIPort is the port of the traefik mesh controller API polled by the proxies
+	controllerAPIPort = 9000
+
+	// controllerProbePath is requested by the connectivity probe, it is served as soon
+	// as the controller API is up
+	controllerProbePath = "/api/status/readiness"
+)
+
+// connectivityProber checks whether a proxy pod can reach the given url
+type connectivityProber interface {
+	probe(ctx context.Context, pod corev1.Pod, url string) error
+}
+
+// execProber probes the connectivity by running wget inside the proxy container
+type execProber struct {
+	kClient *mesherykube.Client
+}
+
+func (ep execProber) probe(ctx context.Context, pod corev1.Pod, url string) error {
+	_, err := execInPod(ctx, ep.kClient, pod, []string{"wget", "-q", "-T", "5", "-O", "/dev/null", url})
+	return err
+}
+
+// execInPod runs the command in the first container of the pod and returns its output. The
+// errors of the exec itself are prefixed with "exec:" while the failures of the command
+// are reported with its error output.
+func execInPod(ctx context.Context, kClient *mesherykube.Client, pod corev1.Pod, cmd []string) (string, error) {
+	req := kClient.KubeClient.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: pod.Spec.Containers[0].Name,
+			Command:   cmd,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	exec, err := remotecommand.NewSPDYExecutor(&kClient.RestConfig, "POST", req.URL())
+	if err != nil {
+		return "", fmt.Errorf("exec: %v", err)
+	}
+	var stdout, stderr bytes.Buffer
+	if err := exec.StreamWithContext(ctx, remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr}); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return stdout.String(), fmt.Errorf("%s", msg)
+		}
+		return stdout.String(), fmt.Errorf("exec: %w", err)
+	}
+	return stdout.String(), nil
+}
+
+// proxyConnectivity is the outcome of probing the controller from a proxy pod
+type proxyConnectivity struct {
+	Pod          string   `json:"pod"`
+	Node         string   `json:"node"`
+	Reachable    bool     `json:"reachable"`
+	Error        string   `json:"error,omitempty"`
+	LikelyCauses []string `json:"likely_causes,omitempty"`
+}
+
+// controllerConnectivity is the connectivity matrix of a cluster
+type controllerConnectivity struct {
+	ControllerURL string              `json:"controller_url"`
+	Unreachable   int                 `json:"unreachable"`
+	Proxies       []proxyConnectivity `json:"proxies"`
+}
+
+// checkControllerConnectivity verifies that every proxy pod of each cluster can reach the controller
+func (mesh *Mesh) checkControllerConnectivity(ctx context.Context, kubeconfigs []string) (map[string]interface{}, error) {
+	return collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		return probeControllerConnectivity(ctx, kClient, "", execProber{kClient: kClient})
+	})
+}
+
+// probeControllerConnectivity builds the connectivity matrix of the mesh installed in the namespace using the
+// prober, the mesh of any namespace is probed when it is empty
+func probeControllerConnectivity(ctx context.Context, kClient *mesherykube.Client, namespace string, prober connectivityProber) (*controllerConnectivity, error) {
+	url, pods, err := connectivityTargets(ctx, kClient, namespace)
+	if err != nil {
+		return nil, ErrControllerConnectivity(err)
+	}
+
+	res := &controllerConnectivity{
+		ControllerURL: url,
+		Proxies:       []proxyConnectivity{},
+	}
+	for _, pod := range pods {
+		pc := proxyConnectivity{
+			Pod:  fmt.Sprintf("%s/%s", pod.Namespace, pod.Name),
+			Node: pod.Spec.NodeName,
+		}
+		if pod.Status.Phase != corev1.PodRunning {
+			pc.Error = fmt.Sprintf("pod is %s", pod.Status.Phase)
+			pc.LikelyCauses = []string{"The proxy pod is not running"}
+		} else if err := prober.probe(ctx, pod, res.ControllerURL); err != nil {
+			pc.Error = err.Error()
+			pc.LikelyCauses = likelyConnectivityCauses(err.Error())
+		} else {
+			pc.Reachable = true
+		}
+		if !pc.Reachable {
+			res.Unreachable++
+		}
+		res.Proxies = append(res.Proxies, pc)
+	}
+	return res, nil
+}
+
+// connectivityTargets returns the URL of the controller probe and the proxy pods of the namespace
+func connectivityTargets(ctx context.Context, kClient *mesherykube.Client, namespace string) (string, []corev1.Pod, error) {
+	svc, err := controllerService(ctx, kClient, namespace)
+	if err != nil {
+		return "", nil, err
+	}
+	pods, err := listProxyPods(ctx, kClient, namespace)
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("http://%s.%s.svc:%d%s", svc.Name, svc.Namespace, controllerAPIPort, controllerProbePath), pods, nil
+}
+
+// likelyConnectivityCauses maps the probe failure to the usual culprits
+func likelyConnectivityCauses(msg string) []string {
+	msg = strings.ToLower(msg)
+	switch {
+	case strings.HasPrefix(msg, "exec:"):
+		return []string{"The adapter is not allowed to exec into the proxy pods (pods/exec RBAC)", "The proxy container doesn't ship wget"}
+	case strings.Contains(msg, "bad address") || strings.Contains(msg, "resolve"):
+		return []string{"The controller service name can't be resolved, check the cluster DNS and the configured cluster domain"}
+	case strings.Contains(msg, "refused"):
+		return []string{"The controller is not listening on its API port, check the controller pod"}
+	case strings.Contains(msg, "timed out") || strings.Contains(msg, "timeout"):
+		return []string{"A NetworkPolicy blocks the traffic from the proxies to the controller", "The controller pod is not ready"}
+	case strings.Contains(msg, "server returned error"):
+		return []string{"The controller API is up but not ready, check the controller logs"}
+	}
+	return []string{"Unknown, check the proxy and controller logs"}
+}
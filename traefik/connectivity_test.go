@@ -0,0 +1,94 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// probeResults answers the connectivity probes with the recorded outcome of each proxy pod
+type probeResults map[string]error
+
+func (pr probeResults) probe(_ context.Context, pod corev1.Pod, url string) error {
+	if url != "http://traefik-mesh-controller.traefik-mesh.svc:9000/api/status/readiness" {
+		return fmt.Errorf("unexpected probe url %s", url)
+	}
+	return pr[pod.Name]
+}
+
+func proxyPod(name, node string, phase corev1.PodPhase) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "traefik-mesh"},
+		Spec:       corev1.PodSpec{NodeName: node, Containers: []corev1.Container{{Name: "traefik-mesh-proxy"}}},
+		Status:     corev1.PodStatus{Phase: phase},
+	}
+}
+
+func TestProbeControllerConnectivity(t *testing.T) {
+	kClient := fakeClient(t, map[string]interface{}{
+		"/api/v1/services": corev1.ServiceList{Items: []corev1.Service{
+			{ObjectMeta: metav1.ObjectMeta{Name: "traefik-mesh-controller", Namespace: "traefik-mesh"}},
+		}},
+		"/api/v1/pods": corev1.PodList{Items: []corev1.Pod{
+			proxyPod("proxy-a", "node-1", corev1.PodRunning),
+			proxyPod("proxy-b", "node-2", corev1.PodRunning),
+			proxyPod("proxy-c", "node-3", corev1.PodRunning),
+			proxyPod("proxy-d", "node-4", corev1.PodRunning),
+			proxyPod("proxy-e", "node-5", corev1.PodPending),
+		}},
+	})
+	prober := probeResults{
+		"proxy-b": fmt.Errorf("wget: bad address 'traefik-mesh-controller.traefik-mesh.svc:9000'"),
+		"proxy-c": fmt.Errorf("wget: download timed out"),
+		"proxy-d": fmt.Errorf("exec: pods \"proxy-d\" is forbidden"),
+	}
+
+	res, err := probeControllerConnectivity(context.Background(), kClient, "", prober)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Unreachable != 4 || len(res.Proxies) != 5 {
+		t.Fatalf("expected 4 of the 5 proxies to be unreachable, got %+v", res)
+	}
+
+	tests := []struct {
+		pod       string
+		node      string
+		reachable bool
+		cause     string
+	}{
+		{pod: "traefik-mesh/proxy-a", node: "node-1", reachable: true},
+		{pod: "traefik-mesh/proxy-b", node: "node-2", cause: "The controller service name can't be resolved, check the cluster DNS and the configured cluster domain"},
+		{pod: "traefik-mesh/proxy-c", node: "node-3", cause: "A NetworkPolicy blocks the traffic from the proxies to the controller"},
+		{pod: "traefik-mesh/proxy-d", node: "node-4", cause: "The adapter is not allowed to exec into the proxy pods (pods/exec RBAC)"},
+		{pod: "traefik-mesh/proxy-e", node: "node-5", cause: "The proxy pod is not running"},
+	}
+	for i, tt := range tests {
+		pc := res.Proxies[i]
+		if pc.Pod != tt.pod || pc.Node != tt.node || pc.Reachable != tt.reachable {
+			t.Errorf("expected %s on %s to be reachable %v, got %+v", tt.pod, tt.node, tt.reachable, pc)
+			continue
+		}
+		if tt.cause == "" {
+			if len(pc.LikelyCauses) != 0 || pc.Error != "" {
+				t.Errorf("%s: expected no failure, got %+v", tt.pod, pc)
+			}
+			continue
+		}
+		if len(pc.LikelyCauses) == 0 || pc.LikelyCauses[0] != tt.cause {
+			t.Errorf("%s: expected the likely cause %q, got %v", tt.pod, tt.cause, pc.LikelyCauses)
+		}
+	}
+}
+
+func TestProbeControllerConnectivityWithoutController(t *testing.T) {
+	kClient := fakeClient(t, map[string]interface{}{
+		"/api/v1/services": corev1.ServiceList{},
+	})
+	if _, err := probeControllerConnectivity(context.Background(), kClient, "", probeResults{}); err == nil {
+		t.Fatal("expected an error when the controller service is missing")
+	}
+}
@@ -0,0 +1,89 @@
+package traefik
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// controllerConfigurationPath is served by the controller API with the dynamic
+// configuration pushed to the proxies
+const controllerConfigurationPath = "/api/configuration/current"
+
+// dynamicConfiguration is the subset of the traefik dynamic configuration built by the controller
+type dynamicConfiguration struct {
+	HTTP struct {
+		Routers     map[string]httpRouter             `json:"routers"`
+		Middlewares map[string]map[string]interface{} `json:"middlewares"`
+		Services    map[string]httpService            `json:"services"`
+	} `json:"http"`
+}
+
+// httpRouter is a router of the dynamic configuration
+type httpRouter struct {
+	Rule        string   `json:"rule"`
+	Service     string   `json:"service"`
+	Middlewares []string `json:"middlewares"`
+	// Priority orders the routers matching a request, the length of the rule when it is 0
+	Priority int `json:"priority,omitempty"`
+}
+
+// httpService is a service of the dynamic configuration, either load balancing the
+// servers of a kubernetes service or splitting the traffic between other services
+type httpService struct {
+	LoadBalancer *struct {
+		Servers []struct {
+			URL string `json:"url"`
+		} `json:"servers"`
+	} `json:"loadBalancer,omitempty"`
+	Weighted *struct {
+		Services []struct {
+			Name   string `json:"name"`
+			Weight *int   `json:"weight,omitempty"`
+		} `json:"services"`
+	} `json:"weighted,omitempty"`
+}
+
+// controllerService returns the service of the traefik mesh controller of the namespace, of any namespace when it is empty
+func controllerService(ctx context.Context, kClient *mesherykube.Client, namespace string) (*corev1.Service, error) {
+	var svcs *corev1.ServiceList
+	err := retryOnTransient(ctx, func() (err error) {
+		svcs, err = kClient.KubeClient.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{LabelSelector: controllerSelector})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(svcs.Items) == 0 {
+		return nil, fmt.Errorf("traefik mesh controller service not found")
+	}
+	return &svcs.Items[0], nil
+}
+
+// fetchControllerConfiguration fetches the dynamic configuration of the controller through the API server proxy
+func fetchControllerConfiguration(ctx context.Context, kClient *mesherykube.Client) (*dynamicConfiguration, error) {
+	svc, err := controllerService(ctx, kClient, "")
+	if err != nil {
+		return nil, err
+	}
+	var raw []byte
+	err = retryOnTransient(ctx, func() (err error) {
+		raw, err = kClient.KubeClient.CoreV1().Services(svc.Namespace).
+			ProxyGet("http", svc.Name, strconv.Itoa(controllerAPIPort), controllerConfigurationPath, nil).
+			DoRaw(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	var conf dynamicConfiguration
+	if err := json.Unmarshal(raw, &conf); err != nil {
+		return nil, err
+	}
+	return &conf, nil
+}
@@ -0,0 +1,77 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// correlationIDHeader is the gRPC metadata carrying the correlation id of an operation
+	// request, the id of the operation is sent back in the response header
+	correlationIDHeader = "x-correlation-id"
+
+	// correlationIDParam is the field of the custom body carrying the correlation id, every operation accepts it
+	correlationIDParam = "correlationId"
+)
+
+var correlationIDRegex = regexp.MustCompile(`^[A-Za-z0-9._:/-]{1,128}$`)
+
+// requestCorrelationID returns the correlation id of the request, read from the gRPC metadata or else from
+// the custom body. A new id is generated when the request carries none, or an invalid one which is reported.
+func requestCorrelationID(ctx context.Context, body string) (string, error) {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(correlationIDHeader); len(values) != 0 {
+			id = values[0]
+		}
+	}
+	if id == "" && strings.TrimSpace(body) != "" {
+		// the body is validated along with the parameters of the operation
+		var doc map[string]interface{}
+		if err := yaml.Unmarshal([]byte(body), &doc); err == nil {
+			id, _ = doc[correlationIDParam].(string)
+		}
+	}
+	if id == "" {
+		return uuid.NewString(), nil
+	}
+	if !correlationIDRegex.MatchString(id) {
+		return uuid.NewString(), ErrInvalidOperationParams(fmt.Errorf("correlation id %q must be 1 to 128 letters, digits or ._:/- characters", id))
+	}
+	return id, nil
+}
+
+// sendCorrelationID sets the correlation id in the header of the gRPC response, so that the client
+// learns the id generated for it
+func sendCorrelationID(ctx context.Context, id string) {
+	// there is no response to set the header of outside of a gRPC call
+	_ = grpc.SetHeader(ctx, metadata.Pairs(correlationIDHeader, id))
+}
+
+// correlate returns the event with the correlation id of its operation appended to the summary,
+// the event itself when the operation is unknown
+func (mesh *Mesh) correlate(e *meshes.EventsResponse) *meshes.EventsResponse {
+	rec, ok := mesh.history.get(e.OperationId)
+	if !ok || rec.CorrelationID == "" {
+		return e
+	}
+	return &meshes.EventsResponse{
+		EventType:            e.EventType,
+		Summary:              fmt.Sprintf("%s [correlation id: %s]", e.Summary, rec.CorrelationID),
+		Details:              e.Details,
+		OperationId:          e.OperationId,
+		ProbableCause:        e.ProbableCause,
+		SuggestedRemediation: e.SuggestedRemediation,
+		ErrorCode:            e.ErrorCode,
+		Component:            e.Component,
+		ComponentName:        e.ComponentName,
+	}
+}
@@ -0,0 +1,181 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// crdResource is the resource of the CustomResourceDefinitions
+var crdResource = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+// deprecatedUsage is a resource last written with a deprecated SMI API version
+type deprecatedUsage struct {
+	Kind             string `json:"kind"`
+	Namespace        string `json:"namespace"`
+	Name             string `json:"name"`
+	APIVersion       string `json:"api_version"`
+	SupportedVersion string `json:"supported_version"`
+	Manager          string `json:"manager,omitempty"`
+	Guidance         string `json:"guidance"`
+}
+
+// deprecatedStorage is a CRD which still stores objects in a deprecated version
+type deprecatedStorage struct {
+	CRD            string   `json:"crd"`
+	StoredVersions []string `json:"stored_versions"`
+	Guidance       string   `json:"guidance"`
+}
+
+// deprecationReport lists the usages of deprecated SMI API versions in a cluster
+type deprecationReport struct {
+	MeshVersion string              `json:"mesh_version,omitempty"`
+	Resources   []deprecatedUsage   `json:"resources"`
+	Storage     []deprecatedStorage `json:"storage,omitempty"`
+}
+
+// reportDeprecatedUsage scans every cluster for SMI resources relying on API versions
+// which are still served but older than the ones the installed mesh watches
+func (mesh *Mesh) reportDeprecatedUsage(ctx context.Context, kubeconfigs []string) (map[string]interface{}, error) {
+	return collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		meshVersion, err := detectMeshVersion(ctx, kClient)
+		if err != nil {
+			mesh.Log.Warn(err)
+		}
+		return scanDeprecatedUsage(ctx, kClient, meshVersion)
+	})
+}
+
+// scanDeprecatedUsage inspects the SMI CRDs of the cluster and the managed fields
+// of their resources to find out which API versions the clients are writing with
+func scanDeprecatedUsage(ctx context.Context, kClient *mesherykube.Client, meshVersion string) (*deprecationReport, error) {
+	supported := supportedSMIVersions(meshVersion)
+	report := &deprecationReport{
+		MeshVersion: meshVersion,
+		Resources:   []deprecatedUsage{},
+	}
+
+	var crds *unstructured.UnstructuredList
+	err := retryOnTransient(ctx, func() (err error) {
+		crds, err = kClient.DynamicKubeClient.Resource(crdResource).List(ctx, metav1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, ErrDeprecatedUsage(err)
+	}
+
+	for _, crd := range crds.Items {
+		group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+		target, ok := supported[group]
+		if !ok {
+			continue
+		}
+		kind, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "kind")
+		plural, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "plural")
+
+		stored, _, _ := unstructured.NestedStringSlice(crd.Object, "status", "storedVersions")
+		var old []string
+		for _, v := range stored {
+			if smiVersionOlder(v, target) {
+				old = append(old, v)
+			}
+		}
+		if len(old) > 0 {
+			report.Storage = append(report.Storage, deprecatedStorage{
+				CRD:            crd.GetName(),
+				StoredVersions: old,
+				Guidance:       fmt.Sprintf("Rewrite the existing %s objects (e.g. with a storage version migration) and remove %s from status.storedVersions before upgrading", kind, strings.Join(old, ", ")),
+			})
+		}
+
+		if !versionServed(crd, target) {
+			continue
+		}
+		var objs *unstructured.UnstructuredList
+		gvr := schema.GroupVersionResource{Group: group, Version: target, Resource: plural}
+		err := retryOnTransient(ctx, func() (err error) {
+			objs, err = kClient.DynamicKubeClient.Resource(gvr).List(ctx, metav1.ListOptions{})
+			return err
+		})
+		if err != nil {
+			return nil, ErrDeprecatedUsage(err)
+		}
+		for _, obj := range objs.Items {
+			report.Resources = append(report.Resources, deprecatedUsages(obj, kind, group, target)...)
+		}
+	}
+
+	sort.Slice(report.Resources, func(i, j int) bool {
+		a, b := report.Resources[i], report.Resources[j]
+		return a.Kind+a.Namespace+a.Name < b.Kind+b.Namespace+b.Name
+	})
+	return report, nil
+}
+
+// deprecatedUsages returns the managers which last wrote the object with a deprecated version
+func deprecatedUsages(obj unstructured.Unstructured, kind, group, target string) []deprecatedUsage {
+	var usages []deprecatedUsage
+	seen := map[string]bool{}
+	for _, mf := range obj.GetManagedFields() {
+		gv, err := schema.ParseGroupVersion(mf.APIVersion)
+		if err != nil || gv.Group != group || !smiVersionOlder(gv.Version, target) {
+			continue
+		}
+		if seen[mf.Manager+mf.APIVersion] {
+			continue
+		}
+		seen[mf.Manager+mf.APIVersion] = true
+		usages = append(usages, deprecatedUsage{
+			Kind:             kind,
+			Namespace:        obj.GetNamespace(),
+			Name:             obj.GetName(),
+			APIVersion:       mf.APIVersion,
+			SupportedVersion: fmt.Sprintf("%s/%s", group, target),
+			Manager:          mf.Manager,
+			Guidance:         migrationGuidance(kind, gv.Version, group, target),
+		})
+	}
+	return usages
+}
+
+// migrationGuidance describes how to move a resource to the supported version
+func migrationGuidance(kind, from, group, target string) string {
+	guidance := fmt.Sprintf("Update the manifests applied by the manager to apiVersion %s/%s", group, target)
+	if kind == trafficSplitKind && from == "v1alpha1" {
+		guidance += ", v1alpha1 weights are quantities and can be converted with the TrafficSplit validation operation"
+	}
+	return guidance
+}
+
+// versionServed reports whether the CRD serves the given version
+func versionServed(crd unstructured.Unstructured, version string) bool {
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	for _, v := range versions {
+		m, ok := v.(map[string]interface{})
+		if ok && m["name"] == version && m["served"] == true {
+			return true
+		}
+	}
+	return false
+}
+
+// smiVersionOlder reports whether the SMI API version a is older than b, e.g. v1alpha1 < v1alpha4
+func smiVersionOlder(a, b string) bool {
+	return alphaNumber(a) < alphaNumber(b)
+}
+
+func alphaNumber(version string) int {
+	i := strings.LastIndex(version, "alpha")
+	if i < 0 {
+		return 0
+	}
+	n, _ := strconv.Atoi(version[i+len("alpha"):])
+	return n
+}
@@ -0,0 +1,105 @@
+package traefik
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func trafficSplitCRD(stored ...string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": "trafficsplits.split.smi-spec.io"},
+		"spec": map[string]interface{}{
+			"group": smiSplitGroup,
+			"names": map[string]interface{}{"kind": trafficSplitKind, "plural": "trafficsplits"},
+			"versions": []interface{}{
+				map[string]interface{}{"name": "v1alpha2", "served": true},
+				map[string]interface{}{"name": "v1alpha4", "served": true},
+			},
+		},
+		"status": map[string]interface{}{"storedVersions": toInterfaces(stored)},
+	}}
+}
+
+func toInterfaces(s []string) []interface{} {
+	res := make([]interface{}, 0, len(s))
+	for _, v := range s {
+		res = append(res, v)
+	}
+	return res
+}
+
+func TestScanDeprecatedUsage(t *testing.T) {
+	split := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "split.smi-spec.io/v1alpha4",
+		"kind":       trafficSplitKind,
+		"metadata":   map[string]interface{}{"name": "reviews-rollout", "namespace": "bookinfo"},
+	}}
+	split.SetManagedFields([]metav1.ManagedFieldsEntry{
+		{Manager: "kubectl-client-side-apply", APIVersion: "split.smi-spec.io/v1alpha2", Operation: metav1.ManagedFieldsOperationUpdate},
+		{Manager: "kubectl-client-side-apply", APIVersion: "split.smi-spec.io/v1alpha2", Operation: metav1.ManagedFieldsOperationUpdate},
+		{Manager: "flagger", APIVersion: "split.smi-spec.io/v1alpha4", Operation: metav1.ManagedFieldsOperationUpdate},
+	})
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		crdResource:     "CustomResourceDefinitionList",
+		trafficSplitGVR: "TrafficSplitList",
+	}, trafficSplitCRD("v1alpha2", "v1alpha4"), split)
+	kClient := &mesherykube.Client{DynamicKubeClient: dyn}
+
+	tests := []struct {
+		meshVersion string
+		resources   []deprecatedUsage
+		storage     []string
+	}{
+		{
+			meshVersion: "v1.4.8",
+			resources: []deprecatedUsage{{
+				Kind:             trafficSplitKind,
+				Namespace:        "bookinfo",
+				Name:             "reviews-rollout",
+				APIVersion:       "split.smi-spec.io/v1alpha2",
+				SupportedVersion: "split.smi-spec.io/v1alpha4",
+				Manager:          "kubectl-client-side-apply",
+				Guidance:         "Update the manifests applied by the manager to apiVersion split.smi-spec.io/v1alpha4",
+			}},
+			storage: []string{"v1alpha2"},
+		},
+		{
+			// the CRD doesn't serve v1alpha3, the resources can't be listed with it
+			meshVersion: "v1.3.2",
+			resources:   []deprecatedUsage{},
+			storage:     []string{"v1alpha2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.meshVersion, func(t *testing.T) {
+			report, err := scanDeprecatedUsage(context.Background(), kClient, tt.meshVersion)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(report.Resources, tt.resources) {
+				t.Errorf("expected the deprecated resources %+v, got %+v", tt.resources, report.Resources)
+			}
+			if len(report.Storage) != 1 || !reflect.DeepEqual(report.Storage[0].StoredVersions, tt.storage) {
+				t.Errorf("expected the deprecated stored versions %v, got %+v", tt.storage, report.Storage)
+			}
+		})
+	}
+}
+
+func TestMigrationGuidanceOfV1alpha1Splits(t *testing.T) {
+	got := migrationGuidance(trafficSplitKind, "v1alpha1", smiSplitGroup, "v1alpha4")
+	want := "Update the manifests applied by the manager to apiVersion split.smi-spec.io/v1alpha4, v1alpha1 weights are quantities and can be converted with the TrafficSplit validation operation"
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
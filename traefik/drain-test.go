@@ -0,0 +1,173 @@
+package traefik
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+const (
+	defaultDrainTestRequests = 100
+	maxDrainTestRequests     = 3000
+	defaultDrainTestInterval = 100 * time.Millisecond
+
+	// drainTestRestartDelay restarts the proxy once 1/drainTestRestartDelay of the requests were sent
+	drainTestRestartDelay = 5
+
+	// drainTestDroppedExitCode is the exit status of the traffic generator when requests were dropped
+	drainTestDroppedExitCode = 3
+)
+
+// drainTestParams are the parameters of the drain test operation
+type drainTestParams struct {
+	// Service is the name of the service the traffic is sent to through the mesh
+	Service string `yaml:"service" required:"true"`
+	// Port is the port of the service, 80 by default
+	Port int `yaml:"port"`
+	// Requests is the number of requests sent during the test
+	Requests int `yaml:"requests"`
+	// Interval is the delay between two requests, e.g. 100ms
+	Interval string `yaml:"interval"`
+}
+
+// drainTestResult is the outcome of the drain test in a cluster
+type drainTestResult struct {
+	Client       string `json:"client"`
+	RestartedPod string `json:"restarted_pod"`
+	Requests     int    `json:"requests"`
+	Dropped      int    `json:"dropped"`
+	DropDuration string `json:"drop_duration"`
+	Drained      bool   `json:"drained"`
+}
+
+// testConnectionDraining sends continuous traffic to the service through the mesh from a
+// proxy pod while restarting another proxy pod and counts the requests dropped meanwhile
+func (mesh *Mesh) testConnectionDraining(ctx context.Context, namespace string, params drainTestParams, kubeconfigs []string) (map[string]interface{}, error) {
+	if params.Service == "" {
+		return nil, ErrInvalidOperationParams(fmt.Errorf("service is required"))
+	}
+	if params.Port <= 0 {
+		params.Port = 80
+	}
+	if params.Requests <= 0 {
+		params.Requests = defaultDrainTestRequests
+	}
+	if params.Requests > maxDrainTestRequests {
+		return nil, ErrInvalidOperationParams(fmt.Errorf("requests must not exceed %d", maxDrainTestRequests))
+	}
+	interval := defaultDrainTestInterval
+	if params.Interval != "" {
+		d, err := time.ParseDuration(params.Interval)
+		if err != nil || d <= 0 {
+			return nil, ErrInvalidOperationParams(fmt.Errorf("invalid interval %q", params.Interval))
+		}
+		interval = d
+	}
+
+	return collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		return runDrainTest(ctx, kClient, namespace, params, interval)
+	})
+}
+
+// runDrainTest runs the drain test in a cluster
+func runDrainTest(ctx context.Context, kClient *mesherykube.Client, namespace string, params drainTestParams, interval time.Duration) (*drainTestResult, error) {
+	// the proxies of the mesh serve the namespace of the service, wherever the mesh is installed
+	pods, err := listProxyPods(ctx, kClient, "")
+	if err != nil {
+		return nil, ErrDrainTest(err)
+	}
+	var running []corev1.Pod
+	for _, pod := range pods {
+		if podReady(pod) {
+			running = append(running, pod)
+		}
+	}
+	// the traffic is sent from a proxy and another one is restarted, the
+	// client would otherwise be killed along with the traffic generator
+	if len(running) < 2 {
+		return nil, ErrDrainTest(fmt.Errorf("at least two ready proxy pods are required, found %d", len(running)))
+	}
+	client, target := running[0], running[1]
+
+	url := fmt.Sprintf("http://%s.%s.maesh:%d/", params.Service, namespace, params.Port)
+	script := fmt.Sprintf("dropped=0; for i in $(seq %d); do if wget -q -T 2 -O /dev/null %s 2>/dev/null; then echo ok; else echo fail; dropped=1; fi; sleep %g; done; [ $dropped -eq 0 ] || exit %d",
+		params.Requests, url, interval.Seconds(), drainTestDroppedExitCode)
+
+	type execResult struct {
+		out string
+		err error
+	}
+	done := make(chan execResult, 1)
+	go func() {
+		out, err := execInPod(ctx, kClient, client, []string{"sh", "-c", script})
+		done <- execResult{out: out, err: err}
+	}()
+
+	// let some requests through before restarting the proxy
+	select {
+	case <-time.After(time.Duration(params.Requests/drainTestRestartDelay) * interval):
+	case <-ctx.Done():
+		return nil, ErrDrainTest(ctx.Err())
+	}
+	err = retryOnTransient(ctx, func() error {
+		return kClient.KubeClient.CoreV1().Pods(target.Namespace).Delete(ctx, target.Name, metav1.DeleteOptions{})
+	})
+	if err != nil {
+		return nil, ErrDrainTest(err)
+	}
+
+	res := <-done
+	if !drainTestCompleted(res.err) {
+		return nil, ErrDrainTest(res.err)
+	}
+	result := parseDrainTestOutput(res.out, interval)
+	result.Client = fmt.Sprintf("%s/%s", client.Namespace, client.Name)
+	result.RestartedPod = fmt.Sprintf("%s/%s", target.Namespace, target.Name)
+	return result, nil
+}
+
+// drainTestCompleted reports whether the traffic generator ran to completion, with the dropped
+// requests in its output when it exited with drainTestDroppedExitCode
+func drainTestCompleted(err error) bool {
+	if err == nil {
+		return true
+	}
+	var exitErr utilexec.ExitError
+	return errors.As(err, &exitErr) && exitErr.ExitStatus() == drainTestDroppedExitCode
+}
+
+// parseDrainTestOutput counts the dropped requests of the traffic generator output, one
+// "ok" or "fail" line per request. The drop duration spans from the first to the last
+// dropped request.
+func parseDrainTestOutput(out string, interval time.Duration) *drainTestResult {
+	res := &drainTestResult{}
+	first, last := -1, -1
+	for _, line := range strings.Split(out, "\n") {
+		switch strings.TrimSpace(line) {
+		case "ok":
+		case "fail":
+			if first < 0 {
+				first = res.Requests
+			}
+			last = res.Requests
+			res.Dropped++
+		default:
+			continue
+		}
+		res.Requests++
+	}
+	var dropDuration time.Duration
+	if first >= 0 {
+		dropDuration = time.Duration(last-first+1) * interval
+	}
+	res.DropDuration = dropDuration.String()
+	res.Drained = res.Requests > 0 && res.Dropped == 0
+	return res
+}
@@ -0,0 +1,107 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/layer5io/meshkit/errors"
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+func TestParseDrainTestOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		out      string
+		requests int
+		dropped  int
+		duration time.Duration
+		drained  bool
+	}{
+		{
+			name:     "no request dropped during the restart",
+			out:      strings.Repeat("ok\n", 20),
+			requests: 20,
+			drained:  true,
+		},
+		{
+			// the requests between the first and the last failure count as the drop window
+			name:     "requests dropped around the restart",
+			out:      strings.Repeat("ok\n", 4) + "fail\nfail\nok\nfail\n" + strings.Repeat("ok\n", 4),
+			requests: 12,
+			dropped:  3,
+			duration: 4 * 100 * time.Millisecond,
+		},
+		{
+			name:     "noise of the shell is ignored",
+			out:      "ok\nwget: can't connect to remote host\n  fail  \n\nok\n",
+			requests: 3,
+			dropped:  1,
+			duration: 100 * time.Millisecond,
+		},
+		{
+			name: "no traffic sent",
+			out:  "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := parseDrainTestOutput(tt.out, 100*time.Millisecond)
+			if res.Requests != tt.requests || res.Dropped != tt.dropped {
+				t.Errorf("expected %d requests and %d dropped, got %d and %d", tt.requests, tt.dropped, res.Requests, res.Dropped)
+			}
+			if res.DropDuration != tt.duration.String() {
+				t.Errorf("expected the drop duration %s, got %s", tt.duration, res.DropDuration)
+			}
+			if res.Drained != tt.drained {
+				t.Errorf("expected drained %v, got %v", tt.drained, res.Drained)
+			}
+		})
+	}
+}
+
+func TestConnectionDrainingParams(t *testing.T) {
+	tests := []struct {
+		name   string
+		params drainTestParams
+	}{
+		{name: "missing service", params: drainTestParams{}},
+		{name: "too many requests", params: drainTestParams{Service: "reviews", Requests: maxDrainTestRequests + 1}},
+		{name: "invalid interval", params: drainTestParams{Service: "reviews", Interval: "-1s"}},
+	}
+	mesh := &Mesh{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := mesh.testConnectionDraining(context.Background(), "default", tt.params, nil)
+			if code := errors.GetCode(err); code != ErrInvalidOperationParamsCode {
+				t.Fatalf("expected the error code %s, got %s: %v", ErrInvalidOperationParamsCode, code, err)
+			}
+		})
+	}
+}
+
+func TestDrainTestCompleted(t *testing.T) {
+	exitStatus := func(code int) error {
+		return utilexec.CodeExitError{Err: fmt.Errorf("command terminated with exit code %d", code), Code: code}
+	}
+	tests := []struct {
+		name      string
+		err       error
+		completed bool
+	}{
+		{name: "no request dropped", completed: true},
+		{name: "requests dropped", err: fmt.Errorf("exec: %w", exitStatus(drainTestDroppedExitCode)), completed: true},
+		{name: "unexpected exit status", err: fmt.Errorf("exec: %w", exitStatus(137))},
+		{name: "error output of the shell", err: fmt.Errorf("sh: seq: not found")},
+		{name: "exec not allowed", err: fmt.Errorf("exec: pods \"proxy-a\" is forbidden")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := drainTestCompleted(tt.err); got != tt.completed {
+				t.Fatalf("expected completed %v, got %v", tt.completed, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package traefik
+
+import (
+	"context"
+
+	internalconfig "github.com/layer5io/meshery-traefik-mesh/internal/config"
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+// renderedInstall is the outcome of an install dry run in a cluster
+type renderedInstall struct {
+	Namespace string       `json:"namespace"`
+	Sizing    *clusterSize `json:"sizing,omitempty"`
+	Manifests string       `json:"manifests"`
+}
+
+// dryRunTraefikMesh renders the manifests the install would apply to every cluster. The clusters
+// are only read, to size them, nothing is applied and the namespace isn't created.
+func (mesh *Mesh) dryRunTraefikMesh(ctx context.Context, version, namespace string, params installParams, kubeconfigs []string) (map[string]interface{}, error) {
+	values, _, err := mesh.installValues(params)
+	if err != nil {
+		return nil, err
+	}
+	chartPath, err := fetchChart(ctx, traefikMeshRepository(), traefikMeshChart, version)
+	if err != nil {
+		return nil, ErrApplyHelmChart(err)
+	}
+	ch, err := loader.Load(chartPath)
+	if err != nil {
+		return nil, ErrApplyHelmChart(err)
+	}
+
+	return collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		res := renderedInstall{Namespace: namespace}
+		clusterValues := values
+		if params.AutoSize {
+			size, err := sizeCluster(ctx, kClient)
+			if err != nil {
+				return nil, err
+			}
+			res.Sizing = &size
+			clusterValues = internalconfig.MergeValues(values, internalconfig.Sizings[size.Sizing])
+		}
+		manifests, err := renderChart(ch, namespace, clusterValues)
+		if err != nil {
+			return nil, err
+		}
+		res.Manifests = manifests
+		return res, nil
+	})
+}
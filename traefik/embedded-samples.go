@@ -0,0 +1,167 @@
+package traefik
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// samples holds the manifests of the sample applications shipped with the adapter
+//
+//go:embed samples/*.yaml
+var samples embed.FS
+
+// sampleApp is a sample application deployed from the manifests embedded in the adapter
+type sampleApp struct {
+	// Name is the name of the application, its manifest is samples/<name>.yaml
+	Name string
+	// Service and Port are the entrypoint of the application, if it has one
+	Service string
+	Port    int
+}
+
+// embeddedSampleApps are the embedded sample applications keyed by name
+var embeddedSampleApps = map[string]sampleApp{
+	"bookinfo": {Name: "bookinfo", Service: "productpage", Port: 9080},
+	"httpbin":  {Name: "httpbin", Service: "httpbin", Port: 8000},
+}
+
+// embeddedSampleResult is the outcome of deploying, or removing, an embedded sample application
+type embeddedSampleResult struct {
+	App         string   `json:"app"`
+	Namespace   string   `json:"namespace"`
+	Deployments []string `json:"deployments"`
+	// URL reaches the entrypoint of the application through the mesh from within the cluster
+	URL     string `json:"url,omitempty"`
+	Deleted bool   `json:"deleted"`
+}
+
+// manifest returns the embedded manifest of the application
+func (app sampleApp) manifest() ([]byte, error) {
+	return samples.ReadFile(path.Join("samples", app.Name+".yaml"))
+}
+
+// deployEmbeddedSample applies the embedded manifest of the application to every cluster, or deletes it,
+// and waits for its deployments to become ready. An event is streamed as each deployment becomes ready.
+func (mesh *Mesh) deployEmbeddedSample(ctx context.Context, app sampleApp, del bool, namespace string, e *meshes.EventsResponse, kubeconfigs []string) (*embeddedSampleResult, error) {
+	manifest, err := app.manifest()
+	if err != nil {
+		return nil, ErrSampleApp(err)
+	}
+	objs, err := decodeManifest(string(manifest))
+	if err != nil {
+		return nil, ErrSampleApp(err)
+	}
+	res := &embeddedSampleResult{App: app.Name, Namespace: namespace, Deployments: []string{}, Deleted: del}
+	for _, obj := range objs {
+		if obj.GetKind() == "Deployment" {
+			res.Deployments = append(res.Deployments, obj.GetName())
+		}
+	}
+
+	if app.Service != "" && !del {
+		res.URL = fmt.Sprintf("http://%s.%s.maesh:%d", app.Service, namespace, app.Port)
+	}
+
+	if del {
+		if err := mesh.applyManifest(manifest, true, namespace, kubeconfigs); err != nil {
+			return nil, ErrSampleApp(err)
+		}
+		return res, nil
+	}
+
+	_, err = collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		return nil, ensureNamespace(ctx, kClient, namespace)
+	})
+	if err != nil {
+		return nil, ErrSampleApp(err)
+	}
+	if err := mesh.applyManifest(manifest, false, namespace, kubeconfigs); err != nil {
+		return nil, ErrSampleApp(err)
+	}
+	_, err = collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		return nil, waitForDeployments(ctx, kClient, namespace, res.Deployments, func(name string) {
+			mesh.streamUpdate(e, fmt.Sprintf("Deployment %s ready", name), fmt.Sprintf("The deployment %s of %s is ready in namespace %s of cluster %s", name, app.Name, namespace, kClient.RestConfig.Host))
+		})
+	})
+	if err != nil {
+		return nil, ErrSampleApp(err)
+	}
+	return res, nil
+}
+
+// waitForDeployments waits for the deployments of the namespace to roll out, onReady is called once
+// for each deployment as it becomes ready. The pods of the pending deployments are diagnosed on each poll.
+// It gives up after the readiness timeout of the operation, with the last diagnosis of the pods.
+func waitForDeployments(ctx context.Context, kClient *mesherykube.Client, namespace string, names []string, onReady func(string)) error {
+	pending := make(map[string]bool, len(names))
+	for _, name := range names {
+		pending[name] = true
+	}
+
+	var diagnoses []podDiagnosis
+	b := readinessBackoff(ctx)
+	err := backoff.Retry(func() error {
+		diagnoses = nil
+		for name := range pending {
+			var d *appsv1.Deployment
+			err := retryOnTransient(ctx, func() (err error) {
+				d, err = kClient.KubeClient.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			if deploymentReady(d) {
+				delete(pending, name)
+				onReady(name)
+				continue
+			}
+			found, err := diagnoseWorkload(ctx, kClient, namespace, scaledWorkload{Kind: "Deployment", Name: name, Namespace: namespace})
+			if err != nil {
+				return err
+			}
+			diagnoses = append(diagnoses, found...)
+		}
+		if len(pending) != 0 {
+			return fmt.Errorf("%d deployments are not ready", len(pending))
+		}
+		return nil
+	}, backoff.WithContext(b, ctx))
+	if err != nil && len(pending) != 0 {
+		notReady := make([]string, 0, len(pending))
+		for name := range pending {
+			notReady = append(notReady, name)
+		}
+		sort.Strings(notReady)
+		var ready []string
+		for _, name := range names {
+			if !pending[name] {
+				ready = append(ready, name)
+			}
+		}
+		return fmt.Errorf("the deployments %v of namespace %s are not ready after %s, %v are: %v%s", notReady, namespace, b.MaxElapsedTime, ready, err,
+			strings.Join(append([]string{""}, describeDiagnoses(diagnoses)...), "\n"))
+	}
+	return err
+}
+
+// deploymentReady reports whether the latest generation of the deployment rolled out and all its replicas are available
+func deploymentReady(d *appsv1.Deployment) bool {
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	return d.Status.ObservedGeneration >= d.Generation &&
+		d.Status.UpdatedReplicas == replicas &&
+		d.Status.AvailableReplicas == replicas
+}
@@ -2,7 +2,10 @@ package traefik
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
+	internalconfig "github.com/layer5io/meshery-traefik-mesh/internal/config"
 	"github.com/layer5io/meshkit/errors"
 )
 
@@ -100,6 +103,206 @@ var (
 	// generated when the latest stable version could not
 	// be fetched during runtime component registeration
 	ErrGetLatestReleaseCode = "1020"
+
+	// ErrDownloadChartCode represents the error which is generated
+	// when a helm chart or index could not be downloaded
+	ErrDownloadChartCode = "1043"
+
+	// ErrChartTooLargeCode represents the error which is generated
+	// when a downloaded helm chart or index exceeds the configured size limit
+	ErrChartTooLargeCode = "1044"
+
+	// ErrInvalidOperationParamsCode represents the error which is generated
+	// when the parameters of an operation request are invalid
+	ErrInvalidOperationParamsCode = "1045"
+
+	// ErrScrapeProxyMetricsCode represents the error which is generated
+	// when the metrics of the proxies could not be scraped
+	ErrScrapeProxyMetricsCode = "1046"
+
+	// ErrEncodeResultCode represents the error which is generated
+	// when the result of an operation could not be encoded
+	ErrEncodeResultCode = "1047"
+
+	// ErrInvalidTrafficSplitCode represents the error which is generated
+	// when a TrafficSplit uses an SMI API version the mesh doesn't support
+	ErrInvalidTrafficSplitCode = "1049"
+
+	// ErrDetectMeshVersionCode represents the error which is generated
+	// when the version of the installed traefik mesh could not be detected
+	ErrDetectMeshVersionCode = "1050"
+
+	// ErrTooManyOperationsCode represents the error which is generated when an
+	// operation is rejected because of the concurrency limit
+	ErrTooManyOperationsCode = "1053"
+
+	// ErrAdmissionPreflightCode represents the error which is generated when
+	// the resources of the mesh could not be dry run through the admission chain
+	ErrAdmissionPreflightCode = "1054"
+
+	// ErrSplitCoverageCode represents the error which is generated when
+	// the TrafficSplit coverage could not be computed
+	ErrSplitCoverageCode = "1056"
+
+	// ErrControllerConnectivityCode represents the error which is generated when
+	// the connectivity between the proxies and the controller could not be checked
+	ErrControllerConnectivityCode = "1057"
+
+	// ErrDeprecatedUsageCode represents the error which is generated when
+	// the cluster could not be scanned for deprecated SMI API versions
+	ErrDeprecatedUsageCode = "1059"
+
+	// ErrUnknownOperationCode represents the error which is generated when
+	// no events were recorded for the requested operation
+	ErrUnknownOperationCode = "1060"
+
+	// ErrExportEventsCode represents the error which is generated when
+	// the events of an operation could not be written to a file
+	ErrExportEventsCode = "1061"
+
+	// ErrNamespaceLabelsCode represents the error which is generated when
+	// the labels of the mesh namespace could not be checked or applied
+	ErrNamespaceLabelsCode = "1063"
+
+	// ErrOperationAbandonedCode represents the error which is generated when
+	// an operation is still running once the shutdown drain timeout elapsed
+	ErrOperationAbandonedCode = "1064"
+
+	// ErrRestartReportCode represents the error which is generated when
+	// the restarts of the mesh pods could not be reported
+	ErrRestartReportCode = "1066"
+
+	// ErrIPFamiliesCode represents the error which is generated when
+	// the IP families of the shadow services could not be checked
+	ErrIPFamiliesCode = "1067"
+
+	// ErrExistingInstallCode represents the error which is generated when
+	// an existing install of traefik mesh prevents the install
+	ErrExistingInstallCode = "1068"
+
+	// ErrCapabilitiesCode represents the error which is generated when
+	// the capabilities of the adapter could not be listed
+	ErrCapabilitiesCode = "1069"
+
+	// ErrMiddlewareOrderCode represents the error which is generated when
+	// the middleware chains of a service could not be read from the controller
+	ErrMiddlewareOrderCode = "1071"
+
+	// ErrDrainTestCode represents the error which is generated when
+	// the connection draining test could not run
+	ErrDrainTestCode = "1072"
+
+	// ErrSplitPortsCode represents the error which is generated when
+	// the TrafficSplit backends don't expose the ports of the root service
+	ErrSplitPortsCode = "1073"
+
+	// ErrClusterSizingCode represents the error which is generated when
+	// the nodes of a cluster could not be counted to size the install
+	ErrClusterSizingCode = "1074"
+
+	// ErrSMICountsCode represents the error which is generated when
+	// the SMI resources could not be counted or the snapshots could not be stored
+	ErrSMICountsCode = "1075"
+
+	// ErrPortNamesCode represents the error which is generated when
+	// the port names of the services could not be checked or fixed
+	ErrPortNamesCode = "1077"
+
+	// ErrStaleConfigCode represents the error which is generated when
+	// the configuration of the proxies could not be compared with the controller's
+	ErrStaleConfigCode = "1079"
+
+	// ErrResultTooLargeCode represents the error which is generated when
+	// an operation result exceeds the size which can be streamed inline
+	ErrResultTooLargeCode = "1080"
+
+	// ErrCNICompatibilityCode represents the error which is generated when
+	// the CNI of the cluster could not be detected
+	ErrCNICompatibilityCode = "1081"
+
+	// ErrOrphansCode represents the error which is generated when
+	// the orphaned SMI resources could not be listed or deleted
+	ErrOrphansCode = "1082"
+
+	// ErrRouteTraceCode represents the error which is generated when
+	// the routing of a request could not be traced
+	ErrRouteTraceCode = "1083"
+
+	// ErrSelectorLinkageCode represents the error which is generated when
+	// the selectors of the shadow services could not be checked or reconciled
+	ErrSelectorLinkageCode = "1085"
+
+	// ErrPruneFilesCode represents the error which is generated when
+	// the files written by the adapter could not be pruned
+	ErrPruneFilesCode = "1086"
+
+	// ErrUninstallCode represents the error which is generated when
+	// the release or the namespace of traefik mesh could not be removed
+	ErrUninstallCode = "1087"
+
+	// ErrVersionNotAvailableCode represents the error which is generated when
+	// the version pinned on install is not a release with a chart
+	ErrVersionNotAvailableCode = "1088"
+
+	// ErrHelmValuesCode represents the error which is generated when
+	// the chart can't be rendered with the helm values of the install
+	ErrHelmValuesCode = "1089"
+
+	// ErrShuttingDownCode represents the error which is generated when
+	// an operation is requested while the adapter is shutting down
+	ErrShuttingDownCode = "1090"
+
+	// ErrCreateNamespaceCode represents the error which is generated when
+	// the namespace of the install could not be created
+	ErrCreateNamespaceCode = "1094"
+
+	// ErrReadinessTimeoutCode represents the error which is generated when
+	// the installed components didn't become ready in time
+	ErrReadinessTimeoutCode = "1095"
+
+	// ErrMissingServiceAccountsCode represents the error which is generated when
+	// the service accounts referenced by a TrafficTarget don't exist
+	ErrMissingServiceAccountsCode = "1096"
+
+	// ErrNamespaceBusyCode represents the error which is generated when
+	// another operation is in progress for the namespace
+	ErrNamespaceBusyCode = "1097"
+
+	// ErrScaleCode represents the error which is generated when
+	// the controller or the proxies can't be scaled
+	ErrScaleCode = "1098"
+
+	// ErrOperationCancelledCode represents the error which is generated when
+	// an operation is cancelled before it completed
+	ErrOperationCancelledCode = "1100"
+
+	// ErrIncompatibleClusterCode represents the error which is generated when
+	// a cluster doesn't meet the requirements of traefik mesh
+	ErrIncompatibleClusterCode = "1101"
+
+	// ErrCompatibilityPreflightCode represents the error which is generated when
+	// the compatibility of a cluster can't be checked
+	ErrCompatibilityPreflightCode = "1102"
+
+	// ErrProxyLogsCode represents the error which is generated when
+	// the logs of the proxies can't be fetched
+	ErrProxyLogsCode = "1104"
+
+	// ErrUpgradeCode represents the error which is generated when
+	// the traefik mesh release can't be upgraded
+	ErrUpgradeCode = "1107"
+
+	// ErrRollbackCode represents the error which is generated when
+	// the traefik mesh release can't be rolled back
+	ErrRollbackCode = "1108"
+
+	// ErrResourceMetadataCode represents the error which is generated when
+	// the labels and annotations can't be added to the installed resources
+	ErrResourceMetadataCode = "1109"
+
+	// ErrNoVersionCode represents the error which is generated when
+	// no version of traefik mesh could be resolved for an operation
+	ErrNoVersionCode = "1110"
 )
 
 // ErrInstallTraefik is the error for install mesh
@@ -119,7 +322,7 @@ func ErrCreatingHelmIndex(err error) error {
 
 // ErrEntryWithAppVersionNotExists is the error when an entry with the given app version is not found
 func ErrEntryWithAppVersionNotExists(entry, appVersion string) error {
-	return errors.New(ErrEntryWithAppVersionNotExistsCode, errors.Alert, []string{"Entry for the app version does not exist"}, []string{fmt.Sprintf("entry %s with app version %s does not exists", entry, appVersion)}, []string{}, []string{})
+	return errors.New(ErrEntryWithAppVersionNotExistsCode, errors.Alert, []string{"Entry for the app version does not exist"}, []string{fmt.Sprintf("entry %s with app version %s does not exists", entry, appVersion)}, []string{"The helm repository has no chart for the version, or the configured repository is a mirror lagging behind"}, []string{"Install one of the versions offered by the adapter, or sync the mirror set with HELM_REPOSITORY_URL"})
 }
 
 // ErrHelmRepositoryNotFound is the error when no valid remote helm repository is found
@@ -134,7 +337,7 @@ func ErrMeshConfig(err error) error {
 
 // ErrApplyHelmChart is the error for applying helm chart
 func ErrApplyHelmChart(err error) error {
-	return errors.New(ErrApplyHelmChartCode, errors.Alert, []string{"Error occurred while applying Helm Chart"}, []string{err.Error()}, []string{}, []string{})
+	return errors.New(ErrApplyHelmChartCode, errors.Alert, []string{"Error occurred while applying Helm Chart"}, []string{err.Error()}, []string{"The adapter is not allowed to create the resources of the chart, or they conflict with resources which aren't managed by helm"}, []string{"Grant the adapter's service account the permissions of the chart, and remove or adopt the conflicting resources"})
 }
 
 // ErrConvertingAppVersionToChartVersion is the error for converting app version to chart version
@@ -181,3 +384,268 @@ func ErrProcessOAM(err error) error {
 func ErrGetLatestRelease(err error) error {
 	return errors.New(ErrGetLatestReleaseCode, errors.Alert, []string{"Could not get latest version"}, []string{err.Error()}, []string{"Latest version could not be found at the specified url"}, []string{})
 }
+
+// ErrDownloadChart is the error when a helm chart or index could not be downloaded
+func ErrDownloadChart(url string, err error) error {
+	return errors.New(ErrDownloadChartCode, errors.Alert, []string{"Error downloading helm chart"}, []string{fmt.Sprintf("failed to download %s: %v", url, err)}, []string{"Helm repository is unreachable or the download exceeded the configured timeout"}, []string{"Check the network connectivity to the helm repository or increase CHART_DOWNLOAD_TIMEOUT"})
+}
+
+// ErrChartTooLarge is the error when a downloaded helm chart or index exceeds the size limit
+func ErrChartTooLarge(url string, limit int64) error {
+	return errors.New(ErrChartTooLargeCode, errors.Alert, []string{"Helm chart exceeds the maximum allowed size"}, []string{fmt.Sprintf("response from %s is larger than %d bytes", url, limit)}, []string{"The helm repository returned a corrupt or unexpectedly large artifact"}, []string{"Verify the helm repository serves valid charts or increase CHART_MAX_SIZE"})
+}
+
+// ErrInvalidOperationParams is the error when the parameters of an operation request are invalid
+func ErrInvalidOperationParams(err error) error {
+	return errors.New(ErrInvalidOperationParamsCode, errors.Alert, []string{"Invalid operation parameters"}, []string{err.Error()}, []string{"The operation parameters supplied in the request body are malformed or out of range"}, []string{"Provide the operation parameters as a valid YAML or JSON document"})
+}
+
+// ErrScrapeProxyMetrics is the error when the metrics of the proxies could not be scraped
+func ErrScrapeProxyMetrics(err error) error {
+	return errors.New(ErrScrapeProxyMetricsCode, errors.Alert, []string{"Error scraping Traefik Mesh proxy metrics"}, []string{err.Error()}, []string{"Proxy pods are not running or do not expose prometheus metrics"}, []string{"Make sure Traefik Mesh is installed with metrics enabled"})
+}
+
+// ErrEncodeResult is the error when the result of an operation could not be encoded
+func ErrEncodeResult(err error) error {
+	return errors.New(ErrEncodeResultCode, errors.Alert, []string{"Error encoding operation result"}, []string{err.Error()}, []string{}, []string{})
+}
+
+// ErrInvalidTrafficSplit is the error when a TrafficSplit can't be validated or converted
+func ErrInvalidTrafficSplit(err error) error {
+	return errors.New(ErrInvalidTrafficSplitCode, errors.Alert, []string{"Invalid TrafficSplit"}, []string{err.Error()}, []string{"The TrafficSplit uses an SMI API version which is not watched by the installed Traefik Mesh and would be silently ignored"}, []string{"Use the SMI API version supported by the installed Traefik Mesh or enable the conversion"})
+}
+
+// ErrDetectMeshVersion is the error when the version of the installed traefik mesh could not be detected
+func ErrDetectMeshVersion(err error) error {
+	return errors.New(ErrDetectMeshVersionCode, errors.Alert, []string{"Could not detect the installed Traefik Mesh version"}, []string{err.Error()}, []string{"Traefik Mesh is not installed or the controller deployment is not labelled as expected"}, []string{"Make sure Traefik Mesh is installed or pass the mesh version explicitly"})
+}
+
+// ErrTooManyOperations is the error when an operation is rejected because of the concurrency limit
+func ErrTooManyOperations(limit int) error {
+	return errors.New(ErrTooManyOperationsCode, errors.Alert, []string{"Too many concurrent operations"}, []string{fmt.Sprintf("the adapter is already processing the maximum of %d operations", limit)}, []string{"Too many operations were requested at the same time"}, []string{"Retry the operation later or increase MAX_CONCURRENT_OPERATIONS"})
+}
+
+// ErrAdmissionPreflight is the error when the resources of the mesh could not be dry run through the admission chain
+func ErrAdmissionPreflight(err error) error {
+	return errors.New(ErrAdmissionPreflightCode, errors.Alert, []string{"Error running the admission preflight"}, []string{err.Error()}, []string{"The Traefik Mesh chart could not be rendered or the API server could not be reached"}, []string{"Check the connectivity to the cluster and that the requested Traefik Mesh version exists"})
+}
+
+// ErrSplitCoverage is the error when the TrafficSplit coverage could not be computed
+func ErrSplitCoverage(err error) error {
+	return errors.New(ErrSplitCoverageCode, errors.Alert, []string{"Error computing TrafficSplit coverage"}, []string{err.Error()}, []string{"The services, deployments or TrafficSplits of the cluster could not be listed"}, []string{"Make sure the adapter is allowed to list services, deployments and TrafficSplits in all namespaces"})
+}
+
+// ErrControllerConnectivity is the error when the connectivity between the proxies and the controller could not be checked
+func ErrControllerConnectivity(err error) error {
+	return errors.New(ErrControllerConnectivityCode, errors.Alert, []string{"Error checking proxy to controller connectivity"}, []string{err.Error()}, []string{"Traefik Mesh is not installed or the proxy pods could not be listed"}, []string{"Make sure Traefik Mesh is installed and the adapter is allowed to list services and pods"})
+}
+
+// ErrDeprecatedUsage is the error when the cluster could not be scanned for deprecated SMI API versions
+func ErrDeprecatedUsage(err error) error {
+	return errors.New(ErrDeprecatedUsageCode, errors.Alert, []string{"Error scanning for deprecated SMI API versions"}, []string{err.Error()}, []string{"The CRDs or the SMI resources of the cluster could not be listed"}, []string{"Make sure the adapter is allowed to list CRDs and SMI resources in all namespaces"})
+}
+
+// ErrUnknownOperation is the error when no events were recorded for the requested operation
+func ErrUnknownOperation(id string) error {
+	return errors.New(ErrUnknownOperationCode, errors.Alert, []string{"Unknown operation"}, []string{fmt.Sprintf("no events were recorded for operation %q", id)}, []string{"The operation id is wrong, the operation ran before the adapter restarted or its events were evicted by newer operations"}, []string{"Check the operation id"})
+}
+
+// ErrExportEvents is the error when the events of an operation could not be written to a file
+func ErrExportEvents(err error) error {
+	return errors.New(ErrExportEventsCode, errors.Alert, []string{"Error exporting operation events"}, []string{err.Error()}, []string{"The adapter's configuration directory is not writable"}, []string{"Make sure the adapter's configuration directory is writable or export the events without a file"})
+}
+
+// ErrNamespaceLabels is the error when the labels of the mesh namespace could not be checked or applied
+func ErrNamespaceLabels(err error) error {
+	return errors.New(ErrNamespaceLabelsCode, errors.Alert, []string{"Error checking the mesh namespace labels"}, []string{err.Error()}, []string{"The mesh namespace doesn't exist or the adapter is not allowed to read or patch it"}, []string{"Make sure the namespace exists and the adapter is allowed to get and patch namespaces"})
+}
+
+// ErrOperationAbandoned is the error when an operation is still running once the shutdown drain timeout elapsed
+func ErrOperationAbandoned(name, id string, running time.Duration) error {
+	return errors.New(ErrOperationAbandonedCode, errors.Alert, []string{"Operation abandoned on shutdown"}, []string{fmt.Sprintf("operation %s (%s) was still running after %s", name, id, running.Round(time.Second))}, []string{"The operation didn't complete within the shutdown drain timeout"}, []string{"Check the state of the resources touched by the operation or increase SHUTDOWN_DRAIN_TIMEOUT"})
+}
+
+// ErrRestartReport is the error when the restarts of the mesh pods could not be reported
+func ErrRestartReport(err error) error {
+	return errors.New(ErrRestartReportCode, errors.Alert, []string{"Error reporting the mesh pod restarts"}, []string{err.Error()}, []string{"The controller or proxy pods could not be listed"}, []string{"Make sure the adapter is allowed to list pods in all namespaces"})
+}
+
+// ErrIPFamilies is the error when the IP families of the shadow services could not be checked
+func ErrIPFamilies(err error) error {
+	return errors.New(ErrIPFamiliesCode, errors.Alert, []string{"Error checking the IP families of the shadow services"}, []string{err.Error()}, []string{"The nodes or the services of the cluster could not be listed"}, []string{"Make sure the adapter is allowed to list nodes and services"})
+}
+
+// ErrExistingInstall is the error when an existing install of traefik mesh could not be detected or prevents the install
+func ErrExistingInstall(err error) error {
+	return errors.New(ErrExistingInstallCode, errors.Alert, []string{"Error handling the existing Traefik Mesh install"}, []string{err.Error()}, []string{"Traefik Mesh is already installed and the existingInstall policy forbids installing over it", "The helm release secrets or the CRDs of the cluster could not be listed"}, []string{"Remove the existing install, use the skip or upgrade policy or install to the namespace of the existing release"})
+}
+
+// ErrCapabilities is the error when the operations or the components advertised by the adapter could not be listed
+func ErrCapabilities(err error) error {
+	return errors.New(ErrCapabilitiesCode, errors.Alert, []string{"Error listing the adapter capabilities"}, []string{err.Error()}, []string{"The operations could not be read from the config or the meshmodel component definitions could not be loaded"}, []string{"Check the component definitions in the templates/meshmodel/components directory"})
+}
+
+// ErrMiddlewareOrder is the error when the middleware chains of a service could not be read from the controller
+func ErrMiddlewareOrder(err error) error {
+	return errors.New(ErrMiddlewareOrderCode, errors.Alert, []string{"Error reading the middleware chains"}, []string{err.Error()}, []string{"Traefik Mesh is not installed or its controller API is not reachable through the API server proxy"}, []string{"Make sure Traefik Mesh is installed and the adapter is allowed to proxy to services"})
+}
+
+// ErrDrainTest is the error when the connection draining test could not run
+func ErrDrainTest(err error) error {
+	return errors.New(ErrDrainTestCode, errors.Alert, []string{"Error testing the connection draining"}, []string{err.Error()}, []string{"Traefik Mesh runs less than two ready proxies or the adapter is not allowed to exec into and delete the proxy pods"}, []string{"Make sure Traefik Mesh is installed on a multi node cluster and the adapter is allowed to exec into and delete pods"})
+}
+
+// ErrSplitPorts is the error when the ports of the TrafficSplit services could not be compared or don't match
+func ErrSplitPorts(err error) error {
+	return errors.New(ErrSplitPortsCode, errors.Alert, []string{"Incompatible TrafficSplit backends"}, []string{err.Error()}, []string{"A backend service doesn't exist or doesn't expose the ports of the root service", "The services of the TrafficSplit could not be fetched"}, []string{"Expose the ports of the root service on every backend service with the same protocol"})
+}
+
+// ErrClusterSizing is the error when the nodes of a cluster could not be counted to select the sizing of the install
+func ErrClusterSizing(err error) error {
+	return errors.New(ErrClusterSizingCode, errors.Alert, []string{"Error sizing the install to the cluster"}, []string{err.Error()}, []string{"The nodes of the cluster could not be listed"}, []string{"Make sure the adapter is allowed to list nodes or install without autoSize"})
+}
+
+// ErrSMICounts is the error when the SMI resources could not be counted or their snapshots could not be stored
+func ErrSMICounts(err error) error {
+	return errors.New(ErrSMICountsCode, errors.Alert, []string{"Error counting the SMI resources"}, []string{err.Error()}, []string{"The CRDs or the SMI resources of the cluster could not be listed", "The snapshot file in the adapter's config directory could not be read or written"}, []string{"Make sure the adapter is allowed to list CRDs and SMI resources and can write to its config directory"})
+}
+
+// ErrPortNames is the error when the port names of the services could not be checked or fixed
+func ErrPortNames(err error) error {
+	return errors.New(ErrPortNamesCode, errors.Alert, []string{"Error checking the service port names"}, []string{err.Error()}, []string{"The services of the cluster could not be listed or updated"}, []string{"Make sure the adapter is allowed to list services and, to fix the port names, to update them"})
+}
+
+// ErrStaleConfig is the error when the configuration of the proxies could not be compared with the controller's
+func ErrStaleConfig(err error) error {
+	return errors.New(ErrStaleConfigCode, errors.Alert, []string{"Error comparing the proxy configurations"}, []string{err.Error()}, []string{"Traefik Mesh is not installed or the API of its controller or proxies is not reachable through the API server proxy"}, []string{"Make sure Traefik Mesh is installed and the adapter is allowed to proxy to services and pods"})
+}
+
+// ErrResultTooLarge is the error when an operation result exceeds the size which can be streamed inline
+func ErrResultTooLarge(size, limit int) error {
+	return errors.New(ErrResultTooLargeCode, errors.Alert, []string{"Result too large"}, []string{fmt.Sprintf("The result of %d bytes exceeds the limit of %d bytes", size, limit)}, []string{"The operation produced a result too large for the event stream", "The result could not be uploaded to the object storage"}, []string{fmt.Sprintf("Configure %s to export the large results to object storage, narrow down the operation or raise %s", internalconfig.ResultStoreEndpointEnv, internalconfig.ResultMaxSizeEnv)})
+}
+
+// ErrCNICompatibility is the error when the CNI of the cluster could not be detected
+func ErrCNICompatibility(err error) error {
+	return errors.New(ErrCNICompatibilityCode, errors.Alert, []string{"Error detecting the CNI"}, []string{err.Error()}, []string{"The DaemonSets of the cluster could not be listed"}, []string{"Make sure the adapter is allowed to list DaemonSets in every namespace"})
+}
+
+// ErrOrphans is the error when the orphaned SMI resources could not be listed or deleted
+func ErrOrphans(err error) error {
+	return errors.New(ErrOrphansCode, errors.Alert, []string{"Error handling the orphaned SMI resources"}, []string{err.Error()}, []string{"The deployments, the CRDs or the SMI resources of the cluster could not be listed or deleted"}, []string{"Make sure the adapter is allowed to list deployments and CRDs and to list and delete the SMI resources"})
+}
+
+// ErrRouteTrace is the error when the routing of a request could not be traced
+func ErrRouteTrace(err error) error {
+	return errors.New(ErrRouteTraceCode, errors.Alert, []string{"Error tracing the routing of the request"}, []string{err.Error()}, []string{"Traefik Mesh is not installed or its controller API is not reachable through the API server proxy"}, []string{"Make sure Traefik Mesh is installed and the adapter is allowed to proxy to services"})
+}
+
+// ErrSelectorLinkage is the error when the selectors of the shadow services could not be checked or reconciled
+func ErrSelectorLinkage(err error) error {
+	return errors.New(ErrSelectorLinkageCode, errors.Alert, []string{"Error checking the shadow service selectors"}, []string{err.Error()}, []string{"The services or the pods of the cluster could not be listed or a shadow service could not be patched"}, []string{"Make sure the adapter is allowed to list services and pods and, to reconcile the selectors, to patch services"})
+}
+
+// ErrPruneFiles is the error when the files written by the adapter could not be pruned
+func ErrPruneFiles(err error) error {
+	return errors.New(ErrPruneFilesCode, errors.Alert, []string{"Error pruning the stored files"}, []string{err.Error()}, []string{"The directories of the config root path could not be read or their files could not be removed"}, []string{"Make sure the adapter's user owns the config root path"})
+}
+
+// ErrUninstall is the error when traefik mesh could not be uninstalled
+func ErrUninstall(err error) error {
+	return errors.New(ErrUninstallCode, errors.Alert, []string{"Error uninstalling Traefik Mesh"}, []string{err.Error()}, []string{"The helm release secrets could not be listed", "The namespace created by the adapter could not be deleted"}, []string{"Make sure the adapter is allowed to list the secrets and to delete the namespaces of the cluster"})
+}
+
+// ErrVersionNotAvailable is the error when the version pinned on install can't be installed
+func ErrVersionNotAvailable(version string, nearest []string) error {
+	return errors.New(ErrVersionNotAvailableCode, errors.Alert, []string{"Traefik Mesh version not available"}, []string{fmt.Sprintf("version %s is not a release with a chart in the helm repository, the nearest available versions are: %s", version, strings.Join(nearest, ", "))}, []string{"The version doesn't exist, is a draft release or has no chart in the helm repository"}, []string{"Pin one of the nearest available versions or leave the version unset to install the latest one"})
+}
+
+// ErrHelmValues is the error when the chart can't be rendered with the helm values of the install
+func ErrHelmValues(keyPath string, err error) error {
+	return errors.New(ErrHelmValuesCode, errors.Alert, []string{"Invalid helm values"}, []string{fmt.Sprintf("the chart can't be rendered with the value at key path %s: %v", keyPath, err)}, []string{"The value has a type the chart templates don't expect"}, []string{"Fix the value at the key path, the defaults of the chart show the expected structure"})
+}
+
+// ErrShuttingDown is the error when an operation is requested while the adapter is shutting down
+func ErrShuttingDown(name string) error {
+	return errors.New(ErrShuttingDownCode, errors.Alert, []string{"Adapter shutting down"}, []string{fmt.Sprintf("operation %s was rejected as the adapter is draining the in-flight operations before stopping", name)}, []string{"The adapter received SIGTERM or SIGINT, e.g. during a rolling deployment"}, []string{"Retry the operation once the new adapter instance is ready"})
+}
+
+// ErrCreateNamespace is the error when the namespace of the install could not be created
+func ErrCreateNamespace(namespace string, err error) error {
+	return errors.New(ErrCreateNamespaceCode, errors.Alert, []string{"Error creating the namespace"}, []string{fmt.Sprintf("namespace %s could not be created: %v", namespace, err)}, []string{"The adapter is not allowed to create namespaces, or the namespace is being deleted"}, []string{"Create the namespace beforehand or allow the adapter to create namespaces, then retry once the namespace isn't terminating"})
+}
+
+// ErrReadinessTimeout is the error when the installed components didn't become ready in time, it lists
+// the components which became ready before the deadline, the ones which didn't and why their pods aren't ready
+func ErrReadinessTimeout(waited time.Duration, ready, pending, diagnoses []string, err error) error {
+	long := []string{fmt.Sprintf("not ready after %s: %v", waited, err), fmt.Sprintf("ready: %s", describeList(ready)), fmt.Sprintf("not ready: %s", describeList(pending))}
+	return errors.New(ErrReadinessTimeoutCode, errors.Alert, []string{"Timed out waiting for Traefik Mesh to become ready"}, append(long, diagnoses...), []string{"The controller can't be scheduled or the proxies are crash looping, e.g. for lack of resources or an image pull failure"}, []string{fmt.Sprintf("Check the events of the controller and proxy pods, or raise the timeout of the operation with its timeout parameter or %s", internalconfig.OperationTimeoutsEnv)})
+}
+
+// ErrMissingServiceAccounts is the error when the service accounts referenced by a TrafficTarget don't exist,
+// or when they could not be checked
+func ErrMissingServiceAccounts(missing []string, err error) error {
+	details := missing
+	if err != nil {
+		details = []string{fmt.Sprintf("the service accounts could not be checked: %v", err)}
+	}
+	return errors.New(ErrMissingServiceAccountsCode, errors.Alert, []string{"Service accounts of the TrafficTarget not found"}, details, []string{"The destination or a source of the TrafficTarget refers to a service account which doesn't exist in the namespace"}, []string{"Create the listed service accounts, or fix the names and namespaces of the identities"})
+}
+
+// ErrNamespaceBusy is the error when another operation is in progress for the namespace and the caller doesn't wait
+func ErrNamespaceBusy(namespace, holder string) error {
+	return errors.New(ErrNamespaceBusyCode, errors.Alert, []string{"Operation in progress for the namespace"}, []string{fmt.Sprintf("operation %s is in progress for namespace %s", holder, namespace)}, []string{"Another install or removal of Traefik Mesh targets the same namespace"}, []string{"Retry once the operation in progress completed, or unset noWait to wait for it"})
+}
+
+// ErrScale is the error when the controller or the proxies can't be scaled
+func ErrScale(err error) error {
+	return errors.New(ErrScaleCode, errors.Alert, []string{"Error while scaling Traefik Mesh"}, []string{err.Error()}, []string{"Traefik Mesh is not installed in the namespace", "The adapter isn't allowed to update the deployments or the daemonsets of the namespace"}, []string{"Install Traefik Mesh in the namespace first", "Grant the adapter the permission to update the deployments and the daemonsets"})
+}
+
+// ErrOperationCancelled is the error when the operation is cancelled before it completed
+func ErrOperationCancelled(name, id string) error {
+	return errors.New(ErrOperationCancelledCode, errors.Alert, []string{"Operation cancelled"}, []string{fmt.Sprintf("operation %s (%s) was cancelled before it completed", name, id)}, []string{"The cancel operation was requested for the operation"}, []string{"Check the state of the resources touched by the operation, the partially applied resources of an install are removed on a best effort basis"})
+}
+
+// ErrIncompatibleCluster is the error when clusters don't meet the requirements of traefik mesh
+func ErrIncompatibleCluster(problems []string) error {
+	return errors.New(ErrIncompatibleClusterCode, errors.Alert, []string{"Cluster incompatible with Traefik Mesh"}, problems, []string{"The Kubernetes version of the cluster is not supported by the requested Traefik Mesh version"}, []string{"Upgrade the cluster, or install a Traefik Mesh version supporting its Kubernetes version"})
+}
+
+// ErrCompatibilityPreflight is the error when the compatibility of a cluster can't be checked
+func ErrCompatibilityPreflight(err error) error {
+	return errors.New(ErrCompatibilityPreflightCode, errors.Alert, []string{"Error running the compatibility preflight"}, []string{err.Error()}, []string{"The API server could not be reached", "The adapter isn't allowed to list the nodes"}, []string{"Check the connectivity to the cluster", "Grant the adapter the permission to list the nodes"})
+}
+
+// ErrProxyLogs is the error when the logs of the proxies can't be fetched
+func ErrProxyLogs(err error) error {
+	return errors.New(ErrProxyLogsCode, errors.Alert, []string{"Error while fetching the proxy logs"}, []string{err.Error()}, []string{"Traefik Mesh is not installed in the namespace", "The adapter isn't allowed to read the logs of the pods"}, []string{"Request the namespace Traefik Mesh is installed in", "Grant the adapter the permission to get pods/log"})
+}
+
+// ErrUpgrade is the error when the traefik mesh release can't be upgraded
+func ErrUpgrade(err error) error {
+	return errors.New(ErrUpgradeCode, errors.Alert, []string{"Error while upgrading Traefik Mesh"}, []string{err.Error()}, []string{"Traefik Mesh is not installed with helm in the namespace", "The upgraded pods didn't become ready before the timeout of the operation"}, []string{"Install Traefik Mesh before upgrading it, the kubectl backend installs are upgraded by installing again", "Check the events of the controller and proxy pods, the release was rolled back to the previous revision"})
+}
+
+// ErrRollback is the error when the traefik mesh release can't be rolled back
+func ErrRollback(err error) error {
+	return errors.New(ErrRollbackCode, errors.Alert, []string{"Error while rolling back Traefik Mesh"}, []string{err.Error()}, []string{"Traefik Mesh is not installed with helm in the namespace", "The requested revision is not in the history of the release, it may have been pruned"}, []string{"Request one of the revisions listed in the error, or omit the revision to roll back to the previous one"})
+}
+
+// ErrResourceMetadata is the error when the labels and annotations can't be added to the installed resources
+func ErrResourceMetadata(err error) error {
+	return errors.New(ErrResourceMetadataCode, errors.Alert, []string{"Error while adding the labels and annotations to the installed resources"}, []string{err.Error()}, []string{"The inventory of the installed resources couldn't be built", "The adapter isn't allowed to patch the installed resources"}, []string{"Grant the adapter the patch permission on the resources of the chart, then install Traefik Mesh again to add the labels and annotations"})
+}
+
+// describeList joins the items of the list, or returns none when it is empty
+func describeList(items []string) string {
+	if len(items) == 0 {
+		return "none"
+	}
+	return strings.Join(items, ", ")
+}
+
+// ErrNoVersion is the error when the operation needs a version of traefik mesh and none was resolved
+func ErrNoVersion(operation string, err error) error {
+	return errors.New(ErrNoVersionCode, errors.Alert, []string{"No Traefik Mesh version available"}, []string{fmt.Sprintf("operation %s needs a version of traefik mesh and none could be resolved", operation), err.Error()}, []string{fmt.Sprintf("The versions are resolved with the %s strategy, which failed, e.g. GitHub is rate limited or unreachable or %s is empty", internalconfig.VersionStrategyEnv, internalconfig.VersionPinEnv)}, []string{fmt.Sprintf("Pin the version in the request, or configure %s so that the versions can be resolved, e.g. set %s for air-gapped clusters", internalconfig.VersionStrategyEnv, internalconfig.VersionPinEnv)})
+}
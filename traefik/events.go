@@ -0,0 +1,160 @@
+package traefik
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	"github.com/layer5io/meshery-traefik-mesh/internal/config"
+)
+
+const (
+	// maxRecordedOperations bounds the number of operations whose events are kept,
+	// the events of the oldest operation are dropped first
+	maxRecordedOperations = 100
+
+	// maxEventsPerOperation bounds the number of events kept for a single operation
+	maxEventsPerOperation = 1000
+)
+
+// recordedEvent is an event streamed for an operation
+type recordedEvent struct {
+	Time                 time.Time `json:"time"`
+	Type                 string    `json:"type"`
+	Summary              string    `json:"summary"`
+	Details              string    `json:"details,omitempty"`
+	ErrorCode            string    `json:"error_code,omitempty"`
+	ProbableCause        string    `json:"probable_cause,omitempty"`
+	SuggestedRemediation string    `json:"suggested_remediation,omitempty"`
+}
+
+// eventLog keeps the events of the latest operations in memory
+type eventLog struct {
+	mx     sync.Mutex
+	order  []string
+	events map[string][]recordedEvent
+}
+
+func newEventLog() *eventLog {
+	return &eventLog{
+		events: make(map[string][]recordedEvent),
+	}
+}
+
+// record adds the event to the log of its operation
+func (el *eventLog) record(e *meshes.EventsResponse, eventType string) {
+	if e.OperationId == "" {
+		return
+	}
+	el.mx.Lock()
+	defer el.mx.Unlock()
+
+	events, ok := el.events[e.OperationId]
+	if !ok {
+		el.order = append(el.order, e.OperationId)
+		if len(el.order) > maxRecordedOperations {
+			delete(el.events, el.order[0])
+			el.order = el.order[1:]
+		}
+	}
+	if len(events) >= maxEventsPerOperation {
+		return
+	}
+	el.events[e.OperationId] = append(events, recordedEvent{
+		Time:                 time.Now(),
+		Type:                 eventType,
+		Summary:              e.Summary,
+		Details:              e.Details,
+		ErrorCode:            e.ErrorCode,
+		ProbableCause:        e.ProbableCause,
+		SuggestedRemediation: e.SuggestedRemediation,
+	})
+}
+
+// get returns a copy of the events recorded for the operation
+func (el *eventLog) get(operationID string) ([]recordedEvent, bool) {
+	el.mx.Lock()
+	defer el.mx.Unlock()
+
+	events, ok := el.events[operationID]
+	if !ok {
+		return nil, false
+	}
+	return append([]recordedEvent{}, events...), true
+}
+
+// last returns the latest event recorded for the operation
+func (el *eventLog) last(operationID string) (recordedEvent, bool) {
+	el.mx.Lock()
+	defer el.mx.Unlock()
+
+	events := el.events[operationID]
+	if len(events) == 0 {
+		return recordedEvent{}, false
+	}
+	return events[len(events)-1], true
+}
+
+// StreamInfo records the informational event and streams it, along with the correlation id of its operation
+func (mesh *Mesh) StreamInfo(e *meshes.EventsResponse) {
+	e = mesh.correlate(e)
+	mesh.events.record(e, "info")
+	mesh.Adapter.StreamInfo(e)
+}
+
+// StreamErr records the error event and streams it, along with the correlation id of its operation
+func (mesh *Mesh) StreamErr(e *meshes.EventsResponse, err error) {
+	e = mesh.correlate(e)
+	mesh.events.record(e, "error")
+	mesh.Adapter.StreamErr(e, err)
+}
+
+// exportEventsParams are the parameters of the event export operation
+type exportEventsParams struct {
+	OperationID string `yaml:"operationId"`
+	// ToFile writes the events to a file on the adapter's filesystem instead of returning them
+	ToFile bool `yaml:"toFile"`
+}
+
+// exportedEvents is the result of the event export operation
+type exportedEvents struct {
+	OperationID string          `json:"operation_id"`
+	Count       int             `json:"count"`
+	File        string          `json:"file,omitempty"`
+	Events      []recordedEvent `json:"events,omitempty"`
+}
+
+// exportEvents returns the events recorded for the operation or writes them to a file
+func (mesh *Mesh) exportEvents(params exportEventsParams) (*exportedEvents, error) {
+	events, ok := mesh.events.get(params.OperationID)
+	if !ok {
+		return nil, ErrUnknownOperation(params.OperationID)
+	}
+
+	res := &exportedEvents{
+		OperationID: params.OperationID,
+		Count:       len(events),
+	}
+	if !params.ToFile {
+		res.Events = events
+		return res, nil
+	}
+
+	byt, err := json.MarshalIndent(events, "", "  ")
+	if err != nil {
+		return nil, ErrEncodeResult(err)
+	}
+	dir := path.Join(config.RootPath(), "exports")
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, ErrExportEvents(err)
+	}
+	// the base name keeps a crafted operation id from escaping the exports directory
+	res.File = path.Join(dir, path.Base(params.OperationID)+".json")
+	if err := os.WriteFile(res.File, byt, 0600); err != nil {
+		return nil, ErrExportEvents(err)
+	}
+	return res, nil
+}
@@ -0,0 +1,72 @@
+package traefik
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	"github.com/layer5io/meshkit/errors"
+)
+
+func TestExportEvents(t *testing.T) {
+	mesh := &Mesh{events: newEventLog()}
+	streamed := []struct {
+		event     *meshes.EventsResponse
+		eventType string
+	}{
+		{&meshes.EventsResponse{OperationId: "op-install", Summary: "Operation queued"}, "info"},
+		{&meshes.EventsResponse{OperationId: "op-other", Summary: "Traefik service mesh deleted successfully"}, "info"},
+		{&meshes.EventsResponse{OperationId: "op-install", Summary: "Waiting for the proxies", Details: "2/3 proxies ready"}, "info"},
+		{&meshes.EventsResponse{OperationId: "op-install", Summary: "Error while installing Traefik service mesh", ErrorCode: ErrInstallTraefikCode}, "error"},
+		{&meshes.EventsResponse{Summary: "Event without operation"}, "info"},
+	}
+	for _, s := range streamed {
+		mesh.events.record(s.event, s.eventType)
+	}
+
+	res, err := mesh.exportEvents(exportEventsParams{OperationID: "op-install"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.OperationID != "op-install" || res.Count != 3 || len(res.Events) != 3 || res.File != "" {
+		t.Fatalf("expected the 3 events of op-install to be returned, got %+v", res)
+	}
+	want := []recordedEvent{
+		{Type: "info", Summary: "Operation queued"},
+		{Type: "info", Summary: "Waiting for the proxies", Details: "2/3 proxies ready"},
+		{Type: "error", Summary: "Error while installing Traefik service mesh", ErrorCode: ErrInstallTraefikCode},
+	}
+	for i, w := range want {
+		got := res.Events[i]
+		if got.Time.IsZero() {
+			t.Errorf("event %d: the time was not recorded", i)
+		}
+		got.Time = w.Time
+		if got != w {
+			t.Errorf("event %d: expected %+v, got %+v", i, w, got)
+		}
+	}
+
+	// the export is a copy, later events don't change it
+	mesh.events.record(&meshes.EventsResponse{OperationId: "op-install", Summary: "Retrying"}, "info")
+	if len(res.Events) != 3 {
+		t.Fatalf("the exported events changed after the export: %d events", len(res.Events))
+	}
+
+	if _, err := mesh.exportEvents(exportEventsParams{OperationID: "op-unknown"}); errors.GetCode(err) != ErrUnknownOperationCode {
+		t.Fatalf("expected the error code %s for an unknown operation, got %v", ErrUnknownOperationCode, err)
+	}
+}
+
+func TestEventLogDropsOldestOperation(t *testing.T) {
+	el := newEventLog()
+	for i := 0; i <= maxRecordedOperations; i++ {
+		el.record(&meshes.EventsResponse{OperationId: fmt.Sprintf("op-%d", i)}, "info")
+	}
+	if _, ok := el.get("op-0"); ok {
+		t.Fatal("the events of the oldest operation were kept")
+	}
+	if _, ok := el.get(fmt.Sprintf("op-%d", maxRecordedOperations)); !ok {
+		t.Fatal("the events of the latest operation were dropped")
+	}
+}
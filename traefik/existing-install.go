@@ -0,0 +1,206 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// existingInstallFail fails the install when traefik mesh is already installed
+	existingInstallFail = "fail"
+	// existingInstallSkip leaves the clusters where traefik mesh is already installed untouched
+	existingInstallSkip = "skip"
+	// existingInstallUpgrade upgrades the existing release in place to the requested version
+	existingInstallUpgrade = "upgrade"
+	// existingInstallReport leaves the clusters where traefik mesh is already installed untouched
+	// and reports the installed version, this is the default so that a mesh is never upgraded
+	// unless it is requested explicitly
+	existingInstallReport = "report"
+
+	// statusAlreadyInstalled is the status of an install which found traefik mesh installed
+	// in every cluster and applied nothing
+	statusAlreadyInstalled = "already installed"
+
+	// helmReleaseSelector selects the secrets in which helm stores the traefik mesh releases
+	helmReleaseSelector = "owner=helm,name=" + traefikMeshChart
+)
+
+// existingInstall is a traefik mesh install found in a cluster
+type existingInstall struct {
+	// ReleaseNamespace is the namespace of the helm release, empty if there is no release
+	ReleaseNamespace string
+	// Version is the version of traefik mesh running, empty if it is unknown
+	Version string
+	// CRDs are the SMI CRDs present in the cluster
+	CRDs []string
+}
+
+// found reports whether traefik mesh or its CRDs are installed
+func (ei existingInstall) found() bool {
+	return ei.ReleaseNamespace != "" || len(ei.CRDs) > 0
+}
+
+func (ei existingInstall) String() string {
+	var parts []string
+	if ei.ReleaseNamespace != "" {
+		release := traefikMeshChart
+		if ei.Version != "" {
+			release += " " + ei.Version
+		}
+		parts = append(parts, fmt.Sprintf("helm release %s in namespace %s", release, ei.ReleaseNamespace))
+	}
+	if len(ei.CRDs) > 0 {
+		parts = append(parts, fmt.Sprintf("%d SMI CRDs", len(ei.CRDs)))
+	}
+	return strings.Join(parts, " and ")
+}
+
+// validateExistingInstallPolicy returns the policy to apply, report when it is empty
+func validateExistingInstallPolicy(policy string) (string, error) {
+	switch policy {
+	case "":
+		return existingInstallReport, nil
+	case existingInstallFail, existingInstallSkip, existingInstallUpgrade, existingInstallReport:
+		return policy, nil
+	}
+	return "", ErrInvalidOperationParams(fmt.Errorf("existingInstall must be one of %s, %s, %s or %s, got %q", existingInstallReport, existingInstallFail, existingInstallSkip, existingInstallUpgrade, policy))
+}
+
+// applyExistingInstallPolicy detects the existing installs of every cluster and applies the
+// policy to them. It returns the kubeconfigs of the clusters to install the version to along
+// with the decision taken for each cluster.
+func applyExistingInstallPolicy(ctx context.Context, policy, version, namespace string, kubeconfigs []string) ([]string, map[string]string, error) {
+	var wg sync.WaitGroup
+	var mx sync.Mutex
+	var errs []error
+	var install []string
+	decisions := map[string]string{}
+	for _, k8sconfig := range kubeconfigs {
+		wg.Add(1)
+		go func(k8sconfig string) {
+			defer wg.Done()
+			kClient, err := mesherykube.New([]byte(k8sconfig))
+			if err != nil {
+				mx.Lock()
+				errs = append(errs, err)
+				mx.Unlock()
+				return
+			}
+			existing, err := detectExistingInstall(ctx, kClient)
+			if err != nil {
+				mx.Lock()
+				errs = append(errs, ErrExistingInstall(err))
+				mx.Unlock()
+				return
+			}
+			proceed, decision, err := existingInstallDecision(policy, version, namespace, existing)
+
+			mx.Lock()
+			defer mx.Unlock()
+			cluster := clusterName(k8sconfig, kClient)
+			decisions[cluster] = decision
+			if err != nil {
+				errs = append(errs, ErrExistingInstall(fmt.Errorf("%s: %v", cluster, err)))
+				return
+			}
+			if proceed {
+				install = append(install, k8sconfig)
+			}
+		}(k8sconfig)
+	}
+	wg.Wait()
+	if len(errs) != 0 {
+		return nil, decisions, mergeErrors(errs)
+	}
+	return install, decisions, nil
+}
+
+// existingInstallDecision applies the policy to the existing install of a cluster,
+// it reports whether the chart should be applied and the decision taken
+func existingInstallDecision(policy, version, namespace string, existing existingInstall) (bool, string, error) {
+	if !existing.found() {
+		return true, "installed", nil
+	}
+	switch policy {
+	case existingInstallFail:
+		return false, "failed", fmt.Errorf("traefik mesh is already installed (%s)", existing)
+	case existingInstallSkip:
+		return false, fmt.Sprintf("skipped, found %s", existing), nil
+	case existingInstallReport:
+		// the CRDs left behind by an uninstall are not a mesh to preserve
+		if existing.ReleaseNamespace != "" {
+			return false, fmt.Sprintf("already installed, found %s, set existingInstall to %s to upgrade it to %s", existing, existingInstallUpgrade, version), nil
+		}
+	}
+	if existing.ReleaseNamespace != "" && existing.ReleaseNamespace != namespace {
+		return false, "failed", fmt.Errorf("the existing release is in namespace %s and can't be upgraded in place in namespace %s", existing.ReleaseNamespace, namespace)
+	}
+	if existing.ReleaseNamespace == "" {
+		return true, fmt.Sprintf("installed over the %s", existing), nil
+	}
+	return true, fmt.Sprintf("upgraded in place the %s to %s", existing, version), nil
+}
+
+// detectExistingInstall looks for the helm release of traefik mesh and for the SMI CRDs
+func detectExistingInstall(ctx context.Context, kClient *mesherykube.Client) (existingInstall, error) {
+	var existing existingInstall
+
+	var secrets *corev1.SecretList
+	err := retryOnTransient(ctx, func() (err error) {
+		secrets, err = kClient.KubeClient.CoreV1().Secrets("").List(ctx, metav1.ListOptions{LabelSelector: helmReleaseSelector})
+		return err
+	})
+	if err != nil {
+		return existing, err
+	}
+	for _, s := range secrets.Items {
+		// uninstalled releases kept with --keep-history are not an install
+		if s.Labels["status"] != "uninstalled" {
+			existing.ReleaseNamespace = s.Namespace
+			break
+		}
+	}
+	if existing.ReleaseNamespace != "" {
+		// the controller may not be scheduled yet, the version is then left unknown
+		existing.Version, _ = detectMeshVersion(ctx, kClient)
+	}
+
+	var crds *unstructured.UnstructuredList
+	err = retryOnTransient(ctx, func() (err error) {
+		crds, err = kClient.DynamicKubeClient.Resource(crdResource).List(ctx, metav1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return existing, err
+	}
+	for _, crd := range crds.Items {
+		group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+		if group == smiSplitGroup || group == smiSpecsGroup || group == smiAccessGroup {
+			existing.CRDs = append(existing.CRDs, crd.GetName())
+		}
+	}
+	sort.Strings(existing.CRDs)
+	return existing, nil
+}
+
+// describeDecisions lists the decision taken for each cluster, sorted by cluster
+func describeDecisions(decisions map[string]string) string {
+	clusters := make([]string, 0, len(decisions))
+	for cluster := range decisions {
+		clusters = append(clusters, cluster)
+	}
+	sort.Strings(clusters)
+	var sb strings.Builder
+	for _, cluster := range clusters {
+		sb.WriteString(fmt.Sprintf("\n%s: %s", cluster, decisions[cluster]))
+	}
+	return sb.String()
+}
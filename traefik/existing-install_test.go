@@ -0,0 +1,80 @@
+package traefik
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// preExistingInstall is a cluster where traefik mesh v1.4.5 was installed with helm in traefik-mesh,
+// an older release of it was uninstalled with --keep-history from another namespace
+func preExistingInstall(t *testing.T) existingInstall {
+	t.Helper()
+	kClient := fakeClient(t, map[string]interface{}{
+		"/api/v1/secrets": corev1.SecretList{Items: []corev1.Secret{
+			{ObjectMeta: metav1.ObjectMeta{Name: "sh.helm.release.v1.traefik-mesh.v1", Namespace: "mesh-old", Labels: map[string]string{"owner": "helm", "status": "uninstalled"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "sh.helm.release.v1.traefik-mesh.v1", Namespace: "traefik-mesh", Labels: map[string]string{"owner": "helm", "status": "deployed"}}},
+		}},
+		"/apis/apps/v1/deployments": appsv1.DeploymentList{Items: []appsv1.Deployment{{
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "traefik-mesh-controller", Image: "traefik/mesh:v1.4.5"}},
+			}}},
+		}}},
+	}, trafficSplitCRD("v1alpha4"))
+
+	existing, err := detectExistingInstall(context.Background(), kClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := existingInstall{ReleaseNamespace: "traefik-mesh", Version: "v1.4.5", CRDs: []string{"trafficsplits.split.smi-spec.io"}}
+	if !reflect.DeepEqual(existing, want) {
+		t.Fatalf("expected to detect %+v, got %+v", want, existing)
+	}
+	return existing
+}
+
+func TestExistingInstallPolicies(t *testing.T) {
+	existing := preExistingInstall(t)
+	leftoverCRDs := existingInstall{CRDs: existing.CRDs}
+
+	tests := []struct {
+		policy    string
+		namespace string
+		existing  existingInstall
+		proceed   bool
+		decision  string
+		failed    bool
+	}{
+		{policy: "", namespace: "traefik-mesh", existing: existing, decision: "already installed, found helm release traefik-mesh v1.4.5 in namespace traefik-mesh and 1 SMI CRDs, set existingInstall to upgrade"},
+		{policy: existingInstallFail, namespace: "traefik-mesh", existing: existing, decision: "failed", failed: true},
+		{policy: existingInstallSkip, namespace: "traefik-mesh", existing: existing, decision: "skipped, found helm release traefik-mesh v1.4.5"},
+		{policy: existingInstallUpgrade, namespace: "traefik-mesh", existing: existing, proceed: true, decision: "upgraded in place the helm release traefik-mesh v1.4.5 in namespace traefik-mesh and 1 SMI CRDs to v1.4.8"},
+		{policy: existingInstallUpgrade, namespace: "mesh", existing: existing, decision: "failed", failed: true},
+		{policy: existingInstallReport, namespace: "traefik-mesh", existing: leftoverCRDs, proceed: true, decision: "installed over the 1 SMI CRDs"},
+		{policy: existingInstallFail, namespace: "traefik-mesh", existing: existingInstall{}, proceed: true, decision: "installed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy+" "+tt.existing.String(), func(t *testing.T) {
+			policy, err := validateExistingInstallPolicy(tt.policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			proceed, decision, err := existingInstallDecision(policy, "v1.4.8", tt.namespace, tt.existing)
+			if (err != nil) != tt.failed {
+				t.Fatalf("expected failed to be %v, got %v", tt.failed, err)
+			}
+			if proceed != tt.proceed || !strings.HasPrefix(decision, tt.decision) {
+				t.Fatalf("expected to proceed %v with the decision %q, got %v with %q", tt.proceed, tt.decision, proceed, decision)
+			}
+		})
+	}
+
+	if _, err := validateExistingInstallPolicy("replace"); err == nil {
+		t.Fatal("expected an unknown policy to be rejected")
+	}
+}
@@ -0,0 +1,127 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// healthCheck is the outcome of a check of the installed mesh along with the way to fix it when it failed
+type healthCheck struct {
+	Name        string `json:"name"`
+	Passed      bool   `json:"passed"`
+	Detail      string `json:"detail"`
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// healthReport is the health of the installed mesh in a cluster
+type healthReport struct {
+	Healthy bool          `json:"healthy"`
+	Checks  []healthCheck `json:"checks"`
+}
+
+// meshHealthCheck checks a part of the mesh installed in the namespace, remediation is reported when it fails
+type meshHealthCheck struct {
+	name        string
+	remediation string
+	run         func(ctx context.Context, kClient *mesherykube.Client, namespace string) (bool, string)
+}
+
+// meshHealthChecks are the checks of the installed mesh, in the order they run
+var meshHealthChecks = []meshHealthCheck{
+	{
+		name:        "controller",
+		remediation: "Check the events and the logs of the controller pod, e.g. kubectl describe pod -l " + controllerSelector,
+		run:         checkControllerPods,
+	},
+	{
+		name:        "proxies",
+		remediation: "Check the nodes the proxy pods are missing from for taints and resource pressure, e.g. kubectl describe daemonset -l " + proxyPodSelector,
+		run:         checkProxyDaemonSets,
+	},
+	{
+		name:        "smi_crds",
+		remediation: "Install the SMI CRDs shipped with the chart of the installed Traefik Mesh version, e.g. by reinstalling it",
+		run: func(ctx context.Context, kClient *mesherykube.Client, _ string) (bool, string) {
+			res := checkCRDPresence(ctx, kClient)
+			return res.Passed, res.Detail
+		},
+	},
+}
+
+// validateMeshHealth runs the health checks of the mesh installed in the namespace against every cluster,
+// an event is streamed as each check completes
+func (mesh *Mesh) validateMeshHealth(ctx context.Context, namespace string, e *meshes.EventsResponse, kubeconfigs []string) (map[string]interface{}, error) {
+	return collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		report := &healthReport{Healthy: true, Checks: []healthCheck{}}
+		for _, c := range meshHealthChecks {
+			passed, detail := c.run(ctx, kClient, namespace)
+			check := healthCheck{Name: c.name, Passed: passed, Detail: detail}
+			if !passed {
+				check.Remediation = c.remediation
+			}
+			report.Healthy = report.Healthy && passed
+			report.Checks = append(report.Checks, check)
+
+			outcome := "passed"
+			if !passed {
+				outcome = "failed"
+			}
+			mesh.streamUpdate(e, fmt.Sprintf("Health check %s %s", c.name, outcome), fmt.Sprintf("%s: %s", kClient.RestConfig.Host, detail))
+		}
+		return report, nil
+	})
+}
+
+// checkControllerPods checks that a controller pod of the namespace is running and ready
+func checkControllerPods(ctx context.Context, kClient *mesherykube.Client, namespace string) (bool, string) {
+	var pods *corev1.PodList
+	err := retryOnTransient(ctx, func() (err error) {
+		pods, err = kClient.KubeClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: controllerSelector})
+		return err
+	})
+	if err != nil {
+		return false, err.Error()
+	}
+	if len(pods.Items) == 0 {
+		return false, fmt.Sprintf("no controller pod found, traefik mesh may not be installed in namespace %s", namespace)
+	}
+	var notReady []string
+	for _, pod := range pods.Items {
+		if podReady(pod) {
+			return true, fmt.Sprintf("controller pod %s/%s is running", pod.Namespace, pod.Name)
+		}
+		notReady = append(notReady, fmt.Sprintf("%s/%s (%s)", pod.Namespace, pod.Name, pod.Status.Phase))
+	}
+	return false, fmt.Sprintf("no controller pod is ready: %s", strings.Join(notReady, ", "))
+}
+
+// checkProxyDaemonSets checks that the proxy DaemonSets of the namespace run a ready proxy on every node they target
+func checkProxyDaemonSets(ctx context.Context, kClient *mesherykube.Client, namespace string) (bool, string) {
+	var dss *appsv1.DaemonSetList
+	err := retryOnTransient(ctx, func() (err error) {
+		dss, err = kClient.KubeClient.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{LabelSelector: proxyPodSelector})
+		return err
+	})
+	if err != nil {
+		return false, err.Error()
+	}
+	if len(dss.Items) == 0 {
+		return false, fmt.Sprintf("no proxy DaemonSet found, traefik mesh may not be installed in namespace %s", namespace)
+	}
+	var details []string
+	passed := true
+	for _, ds := range dss.Items {
+		st := ds.Status
+		ready := st.DesiredNumberScheduled > 0 && st.NumberReady == st.DesiredNumberScheduled && st.UpdatedNumberScheduled == st.DesiredNumberScheduled
+		passed = passed && ready
+		details = append(details, fmt.Sprintf("%s/%s: %d/%d proxies ready", ds.Namespace, ds.Name, st.NumberReady, st.DesiredNumberScheduled))
+	}
+	return passed, strings.Join(details, ", ")
+}
@@ -0,0 +1,61 @@
+package traefik
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// readyControllerPod returns a controller pod of the namespace, running and ready on a node
+func readyControllerPod(namespace string) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "traefik-mesh-controller-7d9c5"},
+		Spec:       corev1.PodSpec{NodeName: "node-1"},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+// readyProxyDaemonSet returns a proxy DaemonSet of the namespace running a ready proxy on every node
+func readyProxyDaemonSet(namespace string) appsv1.DaemonSet {
+	return appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "traefik-mesh-proxy"},
+		Status:     appsv1.DaemonSetStatus{DesiredNumberScheduled: 2, NumberReady: 2, UpdatedNumberScheduled: 2},
+	}
+}
+
+func TestMeshHealthChecksScopedToNamespace(t *testing.T) {
+	kClient := fakeClient(t, map[string]interface{}{
+		// listed across the namespaces
+		"/api/v1/pods":                                     corev1.PodList{Items: []corev1.Pod{readyControllerPod("other-mesh")}},
+		"/apis/apps/v1/daemonsets":                         appsv1.DaemonSetList{Items: []appsv1.DaemonSet{readyProxyDaemonSet("other-mesh")}},
+		"/api/v1/namespaces/other-mesh/pods":               corev1.PodList{Items: []corev1.Pod{readyControllerPod("other-mesh")}},
+		"/apis/apps/v1/namespaces/other-mesh/daemonsets":   appsv1.DaemonSetList{Items: []appsv1.DaemonSet{readyProxyDaemonSet("other-mesh")}},
+		"/api/v1/namespaces/traefik-mesh/pods":             corev1.PodList{},
+		"/apis/apps/v1/namespaces/traefik-mesh/daemonsets": appsv1.DaemonSetList{},
+	})
+
+	tests := []struct {
+		namespace string
+		healthy   bool
+	}{
+		{namespace: "other-mesh", healthy: true},
+		// the mesh of the other namespace doesn't make the one of the operation healthy
+		{namespace: "traefik-mesh", healthy: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.namespace, func(t *testing.T) {
+			if passed, detail := checkControllerPods(context.Background(), kClient, tt.namespace); passed != tt.healthy {
+				t.Errorf("expected the controller check to pass: %v, got %v (%s)", tt.healthy, passed, detail)
+			}
+			if passed, detail := checkProxyDaemonSets(context.Background(), kClient, tt.namespace); passed != tt.healthy {
+				t.Errorf("expected the proxies check to pass: %v, got %v (%s)", tt.healthy, passed, detail)
+			}
+		})
+	}
+}
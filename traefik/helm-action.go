@@ -0,0 +1,60 @@
+package traefik
+
+import (
+	internalconfig "github.com/layer5io/meshery-traefik-mesh/internal/config"
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	"helm.sh/helm/v3/pkg/action"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// restClientGetter gives the helm actions the REST config of a client, the resources
+// of the releases which don't set their namespace are applied to namespace
+type restClientGetter struct {
+	config    *rest.Config
+	namespace string
+}
+
+func (g *restClientGetter) ToRESTConfig() (*rest.Config, error) {
+	return rest.CopyConfig(g.config), nil
+}
+
+func (g *restClientGetter) ToDiscoveryClient() (discovery.CachedDiscoveryInterface, error) {
+	dc, err := discovery.NewDiscoveryClientForConfig(g.config)
+	if err != nil {
+		return nil, err
+	}
+	return memory.NewMemCacheClient(dc), nil
+}
+
+func (g *restClientGetter) ToRESTMapper() (meta.RESTMapper, error) {
+	dc, err := g.ToDiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(dc)
+	return restmapper.NewShortcutExpander(mapper, dc), nil
+}
+
+func (g *restClientGetter) ToRawKubeConfigLoader() clientcmd.ClientConfig {
+	return clientcmd.NewDefaultClientConfig(clientcmdapi.Config{}, &clientcmd.ConfigOverrides{
+		Context: clientcmdapi.Context{Namespace: g.namespace},
+	})
+}
+
+// helmActionConfig returns the configuration of the helm actions on the releases of the
+// namespace, for the actions which meshkit's ApplyHelmChart doesn't offer
+func (mesh *Mesh) helmActionConfig(kClient *mesherykube.Client, namespace string) (*action.Configuration, error) {
+	restConfig := kClient.RestConfig
+	getter := &restClientGetter{config: &restConfig, namespace: namespace}
+	cfg := new(action.Configuration)
+	if err := cfg.Init(getter, namespace, "secret", mesh.helmLogger(internalconfig.LogMaxLineLength())); err != nil {
+		return nil, ErrApplyHelmChart(err)
+	}
+	return cfg, nil
+}
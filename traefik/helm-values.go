@@ -0,0 +1,140 @@
+package traefik
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	internalconfig "github.com/layer5io/meshery-traefik-mesh/internal/config"
+	"gopkg.in/yaml.v2"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+// valuesKeyRegex extracts the key path of the value a helm template failed on
+var valuesKeyRegex = regexp.MustCompile(`<\.Values((?:\.[A-Za-z0-9_-]+)+)>`)
+
+// requestValues returns the helm values of the install request, the structured values are merged over the YAML ones
+func requestValues(params installParams) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	if strings.TrimSpace(params.ValuesYAML) != "" {
+		var raw map[interface{}]interface{}
+		if err := yaml.Unmarshal([]byte(params.ValuesYAML), &raw); err != nil {
+			return nil, ErrInvalidOperationParams(fmt.Errorf("valuesYaml: %v", err))
+		}
+		normalized, err := normalizeValues(raw, "")
+		if err != nil {
+			return nil, err
+		}
+		values = normalized.(map[string]interface{})
+	}
+
+	structured := make(map[interface{}]interface{}, len(params.Values))
+	for k, v := range params.Values {
+		structured[k] = v
+	}
+	normalized, err := normalizeValues(structured, "")
+	if err != nil {
+		return nil, err
+	}
+	return internalconfig.MergeValues(values, normalized.(map[string]interface{})), nil
+}
+
+// normalizeValues converts the maps decoded from YAML to the string keyed maps helm expects
+func normalizeValues(v interface{}, path string) (interface{}, error) {
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, val := range t {
+			key, ok := k.(string)
+			if !ok {
+				return nil, ErrInvalidOperationParams(fmt.Errorf("values: key %v of %q is not a string", k, path))
+			}
+			n, err := normalizeValues(val, joinKeyPath(path, key))
+			if err != nil {
+				return nil, err
+			}
+			m[key] = n
+		}
+		return m, nil
+	case map[string]interface{}:
+		m := make(map[interface{}]interface{}, len(t))
+		for k, val := range t {
+			m[k] = val
+		}
+		return normalizeValues(m, path)
+	case []interface{}:
+		s := make([]interface{}, len(t))
+		for i, val := range t {
+			n, err := normalizeValues(val, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			s[i] = n
+		}
+		return s, nil
+	}
+	return v, nil
+}
+
+// validateHelmValues checks that the values fit the defaults of the chart and that the chart renders with them
+func validateHelmValues(chartPath, namespace string, values map[string]interface{}) error {
+	ch, err := loader.Load(chartPath)
+	if err != nil {
+		return ErrApplyHelmChart(err)
+	}
+	if path := mismatchedValue(ch.Values, values, ""); path != "" {
+		return ErrHelmValues(path, fmt.Errorf("the chart expects a map of values"))
+	}
+	_, err = renderChart(ch, namespace, values)
+	return err
+}
+
+// mismatchedValue returns the key path of the first value set where the chart defaults hold a map, or the reverse
+func mismatchedValue(defaults, values map[string]interface{}, path string) string {
+	for k, v := range values {
+		def, ok := defaults[k]
+		if !ok || def == nil || v == nil {
+			continue
+		}
+		dm, dok := def.(map[string]interface{})
+		vm, vok := v.(map[string]interface{})
+		if dok != vok {
+			return joinKeyPath(path, k)
+		}
+		if dok {
+			if p := mismatchedValue(dm, vm, joinKeyPath(path, k)); p != "" {
+				return p
+			}
+		}
+	}
+	return ""
+}
+
+// renderChart renders the manifests of the chart with the values, without reaching the cluster.
+// The rendering errors point to the key path of the value the templates failed on.
+func renderChart(ch *chart.Chart, namespace string, values map[string]interface{}) (string, error) {
+	act := action.NewInstall(new(action.Configuration))
+	act.ReleaseName = traefikMeshChart
+	act.Namespace = namespace
+	act.DryRun = true
+	act.ClientOnly = true
+	act.IncludeCRDs = true
+	rel, err := act.Run(ch, values)
+	if err != nil {
+		keyPath := "unknown"
+		if m := valuesKeyRegex.FindStringSubmatch(err.Error()); m != nil {
+			keyPath = strings.TrimPrefix(m[1], ".")
+		}
+		return "", ErrHelmValues(keyPath, err)
+	}
+	return strings.TrimSpace(rel.Manifest), nil
+}
+
+func joinKeyPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,147 @@
+package traefik
+
+import (
+	"sync"
+	"time"
+
+	"github.com/layer5io/meshery-traefik-mesh/internal/logging"
+	"github.com/layer5io/meshery-traefik-mesh/internal/metrics"
+	"github.com/layer5io/meshkit/logger"
+)
+
+// The results of a recorded operation
+const (
+	operationRunning   = "running"
+	operationSucceeded = "succeeded"
+	operationFailed    = "failed"
+)
+
+// OperationRecord describes an operation requested to the adapter and its outcome
+type OperationRecord struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	// Version is the version of traefik mesh the operation targets
+	Version string `json:"version,omitempty"`
+	// CorrelationID traces the operation across the logs and the events
+	CorrelationID string     `json:"correlation_id,omitempty"`
+	StartedAt     time.Time  `json:"started_at"`
+	EndedAt       *time.Time `json:"ended_at,omitempty"`
+	// Result is running, succeeded or failed
+	Result    string `json:"result"`
+	Summary   string `json:"summary,omitempty"`
+	ErrorCode string `json:"error_code,omitempty"`
+}
+
+// operationHistory keeps the latest operations, the oldest one is dropped first
+type operationHistory struct {
+	mx      sync.Mutex
+	size    int
+	records []*OperationRecord
+}
+
+func newOperationHistory(size int) *operationHistory {
+	return &operationHistory{size: size}
+}
+
+// start records the operation as running
+func (oh *operationHistory) start(id, name, namespace, version, correlationID string) {
+	oh.mx.Lock()
+	defer oh.mx.Unlock()
+
+	oh.records = append(oh.records, &OperationRecord{
+		ID:        id,
+		Name:      name,
+		Namespace: namespace,
+		Version:   version,
+		StartedAt: time.Now(),
+
+		CorrelationID: correlationID,
+		Result:        operationRunning,
+	})
+	if len(oh.records) > oh.size {
+		oh.records = oh.records[len(oh.records)-oh.size:]
+	}
+}
+
+// finish records the outcome of the operation, the latest operation with the id when it was requested
+// several times. It returns the record of the operation, false when it isn't in the history anymore.
+func (oh *operationHistory) finish(id string, failed bool, summary, errorCode string) (OperationRecord, bool) {
+	oh.mx.Lock()
+	defer oh.mx.Unlock()
+
+	for i := len(oh.records) - 1; i >= 0; i-- {
+		rec := oh.records[i]
+		if rec.ID != id || rec.EndedAt != nil {
+			continue
+		}
+		now := time.Now()
+		rec.EndedAt = &now
+		rec.Result = operationSucceeded
+		if failed {
+			rec.Result = operationFailed
+		}
+		rec.Summary = summary
+		rec.ErrorCode = errorCode
+		return *rec, true
+	}
+	return OperationRecord{}, false
+}
+
+// get returns the record of the operation, the latest operation with the id when it was requested
+// several times
+func (oh *operationHistory) get(id string) (OperationRecord, bool) {
+	oh.mx.Lock()
+	defer oh.mx.Unlock()
+
+	for i := len(oh.records) - 1; i >= 0; i-- {
+		if oh.records[i].ID == id {
+			return *oh.records[i], true
+		}
+	}
+	return OperationRecord{}, false
+}
+
+// list returns a copy of the recorded operations, the latest first
+func (oh *operationHistory) list() []OperationRecord {
+	oh.mx.Lock()
+	defer oh.mx.Unlock()
+
+	records := make([]OperationRecord, 0, len(oh.records))
+	for i := len(oh.records) - 1; i >= 0; i-- {
+		records = append(records, *oh.records[i])
+	}
+	return records
+}
+
+// OperationHistory returns the latest operations requested to the adapter along with their outcome
+func (mesh *Mesh) OperationHistory() []OperationRecord {
+	return mesh.history.list()
+}
+
+// finishOperation records the outcome of the operation from the last event streamed for it
+func (mesh *Mesh) finishOperation(id string) {
+	ev, _ := mesh.events.last(id)
+	rec, ok := mesh.history.finish(id, ev.Type == "error", ev.Summary, ev.ErrorCode)
+	if ok {
+		metrics.ObserveOperation(rec.Name, rec.Result, mesh.Version, rec.EndedAt.Sub(rec.StartedAt))
+		logging.WithFields(mesh.operationLog(id), logging.Fields{
+			"result":      rec.Result,
+			"duration_ms": rec.EndedAt.Sub(rec.StartedAt).Milliseconds(),
+			"error_code":  rec.ErrorCode,
+		}).Info("Operation " + rec.Result)
+	}
+}
+
+// operationLog returns the logger of the operation, its records carry the name of the operation,
+// its namespace, the version of traefik mesh it targets, the id of the request and its correlation id
+func (mesh *Mesh) operationLog(id string) logger.Handler {
+	rec, _ := mesh.history.get(id)
+	return logging.WithFields(mesh.Log, logging.Fields{
+		"operation":      rec.Name,
+		"namespace":      rec.Namespace,
+		"version":        rec.Version,
+		"request_id":     id,
+		"correlation_id": rec.CorrelationID,
+	})
+}
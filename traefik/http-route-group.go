@@ -0,0 +1,126 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// httpMethods are the methods an HTTPRouteGroup match accepts, * matches every method
+var httpMethods = map[string]bool{
+	"*": true, "GET": true, "HEAD": true, "PUT": true, "POST": true, "DELETE": true,
+	"CONNECT": true, "OPTIONS": true, "TRACE": true, "PATCH": true,
+}
+
+// httpRouteGroupParams are the parameters of the HTTPRouteGroup operation
+type httpRouteGroupParams struct {
+	// Name is the name of the HTTPRouteGroup, referenced by the rules of the TrafficTargets
+	Name string `yaml:"name" required:"true"`
+	// Matches are the HTTP routes of the group
+	Matches []httpMatch `yaml:"matches" required:"true"`
+}
+
+// httpMatch is a named HTTP route, the path regex and the methods are optional
+type httpMatch struct {
+	Name      string   `yaml:"name" required:"true"`
+	PathRegex string   `yaml:"pathRegex"`
+	Methods   []string `yaml:"methods"`
+}
+
+// httpRouteGroupResult is the result of the HTTPRouteGroup operation
+type httpRouteGroupResult struct {
+	Name       string   `json:"name"`
+	Namespace  string   `json:"namespace"`
+	APIVersion string   `json:"api_version"`
+	Matches    []string `json:"matches"`
+	Deleted    bool     `json:"deleted"`
+}
+
+// validate checks every match is named uniquely, its path regex compiles and its methods are HTTP verbs
+func (p httpRouteGroupParams) validate() error {
+	if p.Name == "" {
+		return ErrInvalidOperationParams(fmt.Errorf("name is required"))
+	}
+	if len(p.Matches) == 0 {
+		return ErrInvalidOperationParams(fmt.Errorf("at least one match is required"))
+	}
+	names := map[string]bool{}
+	for i, m := range p.Matches {
+		if m.Name == "" {
+			return ErrInvalidOperationParams(fmt.Errorf("matches[%d]: name is required", i))
+		}
+		if names[m.Name] {
+			return ErrInvalidOperationParams(fmt.Errorf("matches[%d]: name %s is used by another match", i, m.Name))
+		}
+		names[m.Name] = true
+		if m.PathRegex != "" {
+			if _, err := regexp.Compile(m.PathRegex); err != nil {
+				return ErrInvalidOperationParams(fmt.Errorf("matches[%d]: pathRegex of %s doesn't compile: %v", i, m.Name, err))
+			}
+		}
+		for _, method := range m.Methods {
+			if !httpMethods[method] {
+				return ErrInvalidOperationParams(fmt.Errorf("matches[%d]: method %q of %s is not an HTTP method, methods are upper case", i, method, m.Name))
+			}
+		}
+	}
+	return nil
+}
+
+// applyHTTPRouteGroup creates, or deletes, the HTTPRouteGroup in every cluster with the version watched by the installed mesh
+func (mesh *Mesh) applyHTTPRouteGroup(ctx context.Context, del bool, namespace string, params httpRouteGroupParams, kubeconfigs []string) (*httpRouteGroupResult, error) {
+	if !del {
+		if err := params.validate(); err != nil {
+			return nil, err
+		}
+	} else if params.Name == "" {
+		return nil, ErrInvalidOperationParams(fmt.Errorf("name is required"))
+	}
+
+	res := &httpRouteGroupResult{
+		Name:       params.Name,
+		Namespace:  namespace,
+		APIVersion: fmt.Sprintf("%s/%s", smiSpecsGroup, supportedSMIVersions(mesh.installedMeshVersion(ctx, kubeconfigs))[smiSpecsGroup]),
+		Matches:    []string{},
+		Deleted:    del,
+	}
+	matches := make([]interface{}, 0, len(params.Matches))
+	for _, m := range params.Matches {
+		match := map[string]interface{}{"name": m.Name}
+		if m.PathRegex != "" {
+			match["pathRegex"] = m.PathRegex
+		}
+		if len(m.Methods) > 0 {
+			methods := make([]interface{}, 0, len(m.Methods))
+			for _, method := range m.Methods {
+				methods = append(methods, method)
+			}
+			match["methods"] = methods
+		}
+		matches = append(matches, match)
+		res.Matches = append(res.Matches, m.Name)
+	}
+	group := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": res.APIVersion,
+		"kind":       httpRouteGroupKind,
+		"metadata": map[string]interface{}{
+			"name":      params.Name,
+			"namespace": namespace,
+		},
+		"spec": map[string]interface{}{
+			"matches": matches,
+		},
+	}}
+
+	byt, err := yaml.Marshal(group.Object)
+	if err != nil {
+		return nil, ErrEncodeResult(err)
+	}
+	if err := mesh.applyManifest(byt, del, namespace, kubeconfigs); err != nil {
+		return nil, ErrCustomOperation(err)
+	}
+	return res, nil
+}
@@ -0,0 +1,119 @@
+package traefik
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// inflightOperation is an operation which is queued or running
+type inflightOperation struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// inflightTracker keeps track of the operations which haven't completed yet
+type inflightTracker struct {
+	mx  sync.Mutex
+	wg  sync.WaitGroup
+	seq uint64
+	ops map[uint64]inflightOperation
+	// cancels cancels the context of the operations by operation id
+	cancels map[string]context.CancelFunc
+	// closed rejects the new operations, it is set once the adapter started shutting down
+	closed bool
+}
+
+func newInflightTracker() *inflightTracker {
+	return &inflightTracker{
+		ops:     make(map[uint64]inflightOperation),
+		cancels: make(map[string]context.CancelFunc),
+	}
+}
+
+// add tracks the operation until the returned function is called, it reports
+// false when the tracker is closed and the operation must not run. The operation
+// is cancelled with cancel.
+func (it *inflightTracker) add(id, name string, cancel context.CancelFunc) (func(), bool) {
+	it.mx.Lock()
+	defer it.mx.Unlock()
+
+	if it.closed {
+		return nil, false
+	}
+	it.seq++
+	key := it.seq
+	it.ops[key] = inflightOperation{ID: id, Name: name, StartedAt: time.Now()}
+	it.cancels[id] = cancel
+	it.wg.Add(1)
+	return func() {
+		it.mx.Lock()
+		delete(it.ops, key)
+		delete(it.cancels, id)
+		it.mx.Unlock()
+		it.wg.Done()
+	}, true
+}
+
+// cancel cancels the operation, it reports false when the operation is not in flight
+func (it *inflightTracker) cancel(id string) bool {
+	it.mx.Lock()
+	defer it.mx.Unlock()
+
+	cancel, ok := it.cancels[id]
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+// close rejects the operations added from now on
+func (it *inflightTracker) close() {
+	it.mx.Lock()
+	defer it.mx.Unlock()
+	it.closed = true
+}
+
+// list returns the operations in flight, the oldest first
+func (it *inflightTracker) list() []inflightOperation {
+	it.mx.Lock()
+	defer it.mx.Unlock()
+
+	ops := make([]inflightOperation, 0, len(it.ops))
+	for _, op := range it.ops {
+		ops = append(ops, op)
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		return ops[i].StartedAt.Before(ops[j].StartedAt)
+	})
+	return ops
+}
+
+// wait blocks until every operation completed or the timeout elapsed and
+// returns the operations which are still in flight
+func (it *inflightTracker) wait(timeout time.Duration) []inflightOperation {
+	done := make(chan struct{})
+	go func() {
+		it.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(timeout):
+		return it.list()
+	}
+}
+
+// Drain rejects the new operations and waits up to the timeout for the queued and
+// running operations to complete, it logs the operations abandoned once it elapsed
+func (mesh *Mesh) Drain(timeout time.Duration) {
+	mesh.inflight.close()
+	abandoned := mesh.inflight.wait(timeout)
+	for _, op := range abandoned {
+		mesh.Log.Warn(ErrOperationAbandoned(op.Name, op.ID, time.Since(op.StartedAt)))
+	}
+}
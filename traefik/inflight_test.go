@@ -0,0 +1,78 @@
+package traefik
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/layer5io/meshkit/logger"
+)
+
+func TestDrainAbandonsSlowOperations(t *testing.T) {
+	tests := []struct {
+		name      string
+		finishing []string
+		stuck     []string
+	}{
+		{name: "every operation completes", finishing: []string{"install"}},
+		{name: "operation over the timeout", finishing: []string{"smi-conformance"}, stuck: []string{"traefik_mesh"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log, err := logger.New("test", logger.Options{Format: logger.JsonLogFormat, Output: &buf})
+			if err != nil {
+				t.Fatal(err)
+			}
+			mesh := &Mesh{inflight: newInflightTracker()}
+			mesh.Log = log
+
+			for _, name := range tt.stuck {
+				if _, ok := mesh.inflight.add("op-"+name, name, func() {}); !ok {
+					t.Fatal("the operation was rejected before the drain")
+				}
+			}
+			for _, name := range tt.finishing {
+				done, _ := mesh.inflight.add("op-"+name, name, func() {})
+				time.AfterFunc(10*time.Millisecond, done)
+			}
+
+			start := time.Now()
+			mesh.Drain(200 * time.Millisecond)
+			elapsed := time.Since(start)
+			if len(tt.stuck) == 0 && elapsed >= 200*time.Millisecond {
+				t.Errorf("the drain waited %s for completed operations", elapsed)
+			}
+			if len(tt.stuck) > 0 && elapsed < 200*time.Millisecond {
+				t.Errorf("the drain returned after %s, before the timeout", elapsed)
+			}
+
+			var warnings []map[string]interface{}
+			scanner := bufio.NewScanner(&buf)
+			for scanner.Scan() {
+				entry := map[string]interface{}{}
+				if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+					t.Fatalf("unexpected log line %q", scanner.Text())
+				}
+				if entry["level"] == "warning" {
+					warnings = append(warnings, entry)
+				}
+			}
+			if len(warnings) != len(tt.stuck) {
+				t.Fatalf("expected %d abandoned operations to be logged, got %v", len(tt.stuck), warnings)
+			}
+			for i, name := range tt.stuck {
+				if warnings[i]["code"] != ErrOperationAbandonedCode || !strings.Contains(warnings[i]["msg"].(string), name) {
+					t.Errorf("expected the abandon of %s to be logged, got %v", name, warnings[i])
+				}
+			}
+
+			if _, ok := mesh.inflight.add("op-late", "install", func() {}); ok {
+				t.Fatal("an operation was accepted after the drain")
+			}
+		})
+	}
+}
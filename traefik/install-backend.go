@@ -0,0 +1,106 @@
+package traefik
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	corev1 "k8s.io/api/core/v1"
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// installBackendHelm installs traefik mesh as a helm release, the default
+	installBackendHelm = "helm"
+
+	// installBackendKubectl renders the chart on the adapter and applies the plain manifests,
+	// no helm release is created in the cluster
+	installBackendKubectl = "kubectl"
+
+	// backendAnnotation records on the namespace the backend which installed traefik mesh,
+	// so that the removal cleans up the same way
+	backendAnnotation = "meshery.io/traefik-mesh-backend"
+	// backendVersionAnnotation records on the namespace the version installed with the kubectl
+	// backend, whose manifests are rendered again on removal
+	backendVersionAnnotation = "meshery.io/traefik-mesh-version"
+)
+
+// validateInstallBackend returns the install backend, helm when it is empty
+func validateInstallBackend(backend string) (string, error) {
+	switch backend {
+	case "":
+		return installBackendHelm, nil
+	case installBackendHelm, installBackendKubectl:
+		return backend, nil
+	}
+	return "", ErrInvalidOperationParams(fmt.Errorf("backend must be one of %s or %s, got %q", installBackendHelm, installBackendKubectl, backend))
+}
+
+// installedBackend returns the backend which installed traefik mesh in the namespace along with
+// the version it installed, helm when the namespace holds no record
+func installedBackend(ctx context.Context, kClient *mesherykube.Client, namespace string) (string, string, error) {
+	var ns *corev1.Namespace
+	err := retryOnTransient(ctx, func() (err error) {
+		ns, err = kClient.KubeClient.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+		return err
+	})
+	if kubeerrors.IsNotFound(err) {
+		return installBackendHelm, "", nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+	if ns.Annotations[backendAnnotation] == installBackendKubectl {
+		return installBackendKubectl, ns.Annotations[backendVersionAnnotation], nil
+	}
+	return installBackendHelm, "", nil
+}
+
+// recordInstallBackend annotates the namespace with the backend which installed traefik mesh,
+// the annotations are removed when backend is empty
+func recordInstallBackend(ctx context.Context, kClient *mesherykube.Client, namespace, backend, version string) error {
+	annotations := map[string]interface{}{
+		backendAnnotation:        nil,
+		backendVersionAnnotation: nil,
+	}
+	if backend != "" {
+		annotations[backendAnnotation] = backend
+		annotations[backendVersionAnnotation] = version
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": annotations},
+	})
+	if err != nil {
+		return err
+	}
+	err = retryOnTransient(ctx, func() error {
+		_, err := kClient.KubeClient.CoreV1().Namespaces().Patch(ctx, namespace, types.MergePatchType, patch, metav1.PatchOptions{})
+		return err
+	})
+	if kubeerrors.IsNotFound(err) && backend == "" {
+		// nothing left to clean up
+		return nil
+	}
+	return err
+}
+
+// applyRenderedChart renders the chart at chartPath with the values and applies, or deletes, the manifests
+func applyRenderedChart(kClient *mesherykube.Client, chartPath string, del bool, namespace string, values map[string]interface{}) error {
+	ch, err := loader.Load(chartPath)
+	if err != nil {
+		return err
+	}
+	manifests, err := renderChart(ch, namespace, values)
+	if err != nil {
+		return err
+	}
+	return kClient.ApplyManifest([]byte(manifests), mesherykube.ApplyOptions{
+		Namespace: namespace,
+		Update:    true,
+		Delete:    del,
+	})
+}
@@ -0,0 +1,46 @@
+package traefik
+
+import (
+	"fmt"
+)
+
+const (
+	// installModeSMI installs traefik mesh with the SMI support and its CRDs, the default
+	installModeSMI = "smi"
+
+	// installModeProxyOnly installs the proxies and the controller without the SMI support
+	installModeProxyOnly = "proxy-only"
+)
+
+// validateInstallMode returns the install mode, SMI when it is empty
+func validateInstallMode(mode string) (string, error) {
+	switch mode {
+	case "":
+		return installModeSMI, nil
+	case installModeSMI, installModeProxyOnly:
+		return mode, nil
+	}
+	return "", ErrInvalidOperationParams(fmt.Errorf("mode must be one of %s or %s, got %q", installModeSMI, installModeProxyOnly, mode))
+}
+
+// modeValues returns the helm values toggling the SMI support for the install mode
+func modeValues(mode string) map[string]interface{} {
+	return map[string]interface{}{
+		"smi": map[string]interface{}{
+			"enable": mode != installModeProxyOnly,
+		},
+	}
+}
+
+// valuesMode returns the install mode the smi.enable value selects, empty when the values don't set it
+func valuesMode(values map[string]interface{}) string {
+	smi, _ := values["smi"].(map[string]interface{})
+	enable, ok := smi["enable"].(bool)
+	switch {
+	case !ok:
+		return ""
+	case enable:
+		return installModeSMI
+	}
+	return installModeProxyOnly
+}
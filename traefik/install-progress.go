@@ -0,0 +1,211 @@
+package traefik
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// installProgressEvent is the type of the install progress events, the UI renders them as a progress bar
+const installProgressEvent = "install_progress"
+
+// The stages of an install in the order they are reached
+const (
+	stageNamespaceCreated    = "namespace_created"
+	stageCRDsApplied         = "crds_applied"
+	stageControllerScheduled = "controller_scheduled"
+	stageProxiesReady        = "proxies_ready"
+	stageCompleted           = "completed"
+)
+
+// installStagePercent is the progress of the install once the stage is reached
+var installStagePercent = map[string]int{
+	stageNamespaceCreated:    20,
+	stageCRDsApplied:         50,
+	stageControllerScheduled: 70,
+	stageProxiesReady:        90,
+	stageCompleted:           100,
+}
+
+// installProgress is the payload of an install progress event
+type installProgress struct {
+	Type      string `json:"type"`
+	Stage     string `json:"stage"`
+	Percent   int    `json:"percent"`
+	Cluster   string `json:"cluster,omitempty"`
+	Version   string `json:"version"`
+	Namespace string `json:"namespace"`
+}
+
+// progressFunc reports the stage reached by the install in the cluster
+type progressFunc func(cluster, stage string)
+
+// installProgressReporter returns the reporter streaming the install progress of the operation
+func (mesh *Mesh) installProgressReporter(e *meshes.EventsResponse, version, namespace string) progressFunc {
+	return func(cluster, stage string) {
+		p := installProgress{
+			Type:      installProgressEvent,
+			Stage:     stage,
+			Percent:   installStagePercent[stage],
+			Cluster:   cluster,
+			Version:   version,
+			Namespace: namespace,
+		}
+		byt, err := json.Marshal(p)
+		if err != nil {
+			mesh.Log.Warn(ErrEncodeResult(err))
+			return
+		}
+		mesh.streamUpdate(e, fmt.Sprintf("Installing Traefik service mesh: %d%%", p.Percent), string(byt))
+	}
+}
+
+// waitForMeshComponents waits for the CRDs to be established, for a controller pod of the namespace to be
+// scheduled and for its proxies to be ready, the stages are reported as they are reached. The pods of the controller
+// and of the proxies are diagnosed on each poll of their stages. It gives up after the readiness timeout
+// of the operation, listing the stages reached, the ones which weren't and the last diagnosis of the pods.
+func waitForMeshComponents(ctx context.Context, kClient *mesherykube.Client, namespace string, report func(stage string)) error {
+	stages := []struct {
+		name     string
+		check    func() (bool, string)
+		diagnose bool
+	}{
+		{stageCRDsApplied, func() (bool, string) { return crdsEstablished(ctx, kClient) }, false},
+		{stageControllerScheduled, func() (bool, string) { return controllerScheduled(ctx, kClient, namespace) }, true},
+		{stageProxiesReady, func() (bool, string) { return checkProxyDaemonSets(ctx, kClient, namespace) }, true},
+	}
+
+	var reached []string
+	for i, stage := range stages {
+		var diagnoses []podDiagnosis
+		b := readinessBackoff(ctx)
+		err := backoff.Retry(func() error {
+			if ok, detail := stage.check(); !ok {
+				if stage.diagnose {
+					// the diagnosis of the previous poll is kept when the pods can't be listed
+					if found, err := diagnoseMeshWorkloads(ctx, kClient, namespace); err == nil {
+						diagnoses = found
+					}
+				}
+				return fmt.Errorf("%s: %s", stage.name, detail)
+			}
+			return nil
+		}, backoff.WithContext(b, ctx))
+		if err != nil {
+			pending := make([]string, 0, len(stages)-i)
+			for _, s := range stages[i:] {
+				pending = append(pending, s.name)
+			}
+			return ErrReadinessTimeout(b.GetElapsedTime().Round(time.Second), reached, pending, describeDiagnoses(diagnoses), err)
+		}
+		report(stage.name)
+		reached = append(reached, stage.name)
+	}
+	return nil
+}
+
+// diagnoseMeshWorkloads returns the reasons why the pods of the controller and of the proxies of the namespace aren't ready
+func diagnoseMeshWorkloads(ctx context.Context, kClient *mesherykube.Client, namespace string) ([]podDiagnosis, error) {
+	var deps *appsv1.DeploymentList
+	err := retryOnTransient(ctx, func() (err error) {
+		deps, err = kClient.KubeClient.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{LabelSelector: controllerSelector})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	var dss *appsv1.DaemonSetList
+	err = retryOnTransient(ctx, func() (err error) {
+		dss, err = kClient.KubeClient.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{LabelSelector: proxyPodSelector})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var diagnoses []podDiagnosis
+	for _, dep := range deps.Items {
+		found, err := diagnoseWorkload(ctx, kClient, namespace, scaledWorkload{Kind: "Deployment", Name: dep.Name, Namespace: namespace})
+		if err != nil {
+			return nil, err
+		}
+		diagnoses = append(diagnoses, found...)
+	}
+	for _, ds := range dss.Items {
+		found, err := diagnoseWorkload(ctx, kClient, namespace, scaledWorkload{Kind: "DaemonSet", Name: ds.Name, Namespace: namespace})
+		if err != nil {
+			return nil, err
+		}
+		diagnoses = append(diagnoses, found...)
+	}
+	return diagnoses, nil
+}
+
+// controllerScheduled reports whether a controller pod of the namespace is scheduled to a node
+func controllerScheduled(ctx context.Context, kClient *mesherykube.Client, namespace string) (bool, string) {
+	var pods *corev1.PodList
+	err := retryOnTransient(ctx, func() (err error) {
+		pods, err = kClient.KubeClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: controllerSelector})
+		return err
+	})
+	if err != nil {
+		return false, err.Error()
+	}
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName != "" {
+			return true, fmt.Sprintf("controller pod %s/%s scheduled to node %s", pod.Namespace, pod.Name, pod.Spec.NodeName)
+		}
+	}
+	return false, fmt.Sprintf("no controller pod is scheduled in namespace %s yet", namespace)
+}
+
+// crdsEstablished reports whether the SMI CRDs watched by traefik mesh are established, the
+// resources of a CRD which isn't established yet are rejected by the API server
+func crdsEstablished(ctx context.Context, kClient *mesherykube.Client) (bool, string) {
+	var pending []string
+	for group, plurals := range smiResources {
+		for _, plural := range plurals {
+			name := plural + "." + group
+			var crd *unstructured.Unstructured
+			err := retryOnTransient(ctx, func() (err error) {
+				crd, err = kClient.DynamicKubeClient.Resource(crdResource).Get(ctx, name, metav1.GetOptions{})
+				return err
+			})
+			if err != nil && !kubeerrors.IsNotFound(err) {
+				return false, err.Error()
+			}
+			if err != nil || !crdCondition(crd, "Established") {
+				pending = append(pending, name)
+			}
+		}
+	}
+	if len(pending) > 0 {
+		sort.Strings(pending)
+		return false, fmt.Sprintf("CRDs not established yet: %s", strings.Join(pending, ", "))
+	}
+	return true, "the SMI CRDs are established"
+}
+
+// crdCondition reports whether the condition of the CRD is true
+func crdCondition(crd *unstructured.Unstructured, condition string) bool {
+	conditions, _, _ := unstructured.NestedSlice(crd.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if ok && cond["type"] == condition {
+			return cond["status"] == "True"
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package traefik
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// establishedSMICRDs returns the SMI CRDs watched by traefik mesh, established by the API server
+func establishedSMICRDs(t *testing.T) []runtime.Object {
+	t.Helper()
+	var objs []runtime.Object
+	for group, plurals := range smiResources {
+		for _, plural := range plurals {
+			obj := crd(schema.GroupVersionResource{Group: group, Version: "v1alpha1", Resource: plural}, plural, "")
+			conditions := []interface{}{map[string]interface{}{"type": "Established", "status": "True"}}
+			if err := unstructured.SetNestedSlice(obj.Object, conditions, "status", "conditions"); err != nil {
+				t.Fatal(err)
+			}
+			objs = append(objs, obj)
+		}
+	}
+	return objs
+}
+
+func TestWaitForMeshComponentsScopedToNamespace(t *testing.T) {
+	// only the mesh of other-mesh is ready, the install in traefik-mesh hasn't scheduled anything yet
+	kClient := fakeClient(t, map[string]interface{}{
+		// listed across the namespaces
+		"/api/v1/pods":                                     corev1.PodList{Items: []corev1.Pod{readyControllerPod("other-mesh")}},
+		"/apis/apps/v1/daemonsets":                         appsv1.DaemonSetList{Items: []appsv1.DaemonSet{readyProxyDaemonSet("other-mesh")}},
+		"/api/v1/namespaces/other-mesh/pods":               corev1.PodList{Items: []corev1.Pod{readyControllerPod("other-mesh")}},
+		"/apis/apps/v1/namespaces/other-mesh/daemonsets":   appsv1.DaemonSetList{Items: []appsv1.DaemonSet{readyProxyDaemonSet("other-mesh")}},
+		"/api/v1/namespaces/traefik-mesh/pods":             corev1.PodList{},
+		"/apis/apps/v1/namespaces/traefik-mesh/daemonsets": appsv1.DaemonSetList{},
+	}, establishedSMICRDs(t)...)
+
+	tests := []struct {
+		namespace string
+		reached   []string
+		fails     bool
+	}{
+		{
+			namespace: "other-mesh",
+			reached:   []string{stageCRDsApplied, stageControllerScheduled, stageProxiesReady},
+		},
+		{
+			namespace: "traefik-mesh",
+			reached:   []string{stageCRDsApplied},
+			fails:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.namespace, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			var reached []string
+			err := waitForMeshComponents(ctx, kClient, tt.namespace, func(stage string) { reached = append(reached, stage) })
+			if (err != nil) != tt.fails {
+				t.Fatalf("expected the wait to fail: %v, got %v", tt.fails, err)
+			}
+			if !reflect.DeepEqual(reached, tt.reached) {
+				t.Fatalf("expected the stages %v to be reached, got %v", tt.reached, reached)
+			}
+		})
+	}
+}
@@ -1,43 +1,168 @@
 package traefik
 
 import (
+	"context"
 	"fmt"
 	"sync"
 
 	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshery-adapter-library/meshes"
 	"github.com/layer5io/meshery-adapter-library/status"
+	internalconfig "github.com/layer5io/meshery-traefik-mesh/internal/config"
 	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
 )
 
-func (mesh *Mesh) installTraefikMesh(del bool, version, namespace string, kubeconfigs []string) (string, error) {
+// installParams are the optional parameters of the install operation
+type installParams struct {
+	// Profile selects the set of helm values applied, the default profile of the
+	// config provider is used when it is empty
+	Profile string `yaml:"profile"`
+	// ExistingInstall decides what happens to the clusters where traefik mesh is already
+	// installed: report (the default, the installed version is reported and nothing is
+	// applied), fail, skip or upgrade (in place, to the requested version)
+	ExistingInstall string `yaml:"existingInstall"`
+	// AutoSize overrides the resources of the profile with the sizing suited
+	// to the number of nodes of each cluster
+	AutoSize bool `yaml:"autoSize"`
+	// Version pins the version of traefik mesh installed, the latest one offered is installed when it is empty
+	Version string `yaml:"version"`
+	// Values are the helm values merged over the ones of the profile
+	Values map[string]interface{} `yaml:"values"`
+	// ValuesYAML holds helm values as a YAML document, Values are merged over them
+	ValuesYAML string `yaml:"valuesYaml"`
+	// DryRun renders the manifests of the install without applying anything to the clusters
+	DryRun bool `yaml:"dryRun"`
+	// Mode is smi (the default) or proxy-only, which installs traefik mesh without the SMI support
+	Mode string `yaml:"mode"`
+	// Backend is helm (the default) or kubectl, which applies the rendered manifests without a helm release
+	Backend string `yaml:"backend"`
+
+	resourceMetadataParams `yaml:",inline"`
+	namespaceLockParams    `yaml:",inline"`
+	timeoutParams          `yaml:",inline"`
+}
+
+// installTraefikMesh installs traefik mesh, it returns the status reached along with the decision
+// taken for the clusters where traefik mesh was already installed and the sizing selected,
+// and the inventory of the resources installed in each cluster.
+// The progress of the install is streamed along e, if set, until the proxies are ready.
+// The removal goes through uninstallTraefikMesh, which needs no version.
+func (mesh *Mesh) installTraefikMesh(ctx context.Context, version, namespace string, params installParams, e *meshes.EventsResponse, kubeconfigs []string) (string, map[string]string, map[string][]inventoryItem, error) {
 	mesh.Log.Debug(fmt.Sprintf("Requested install of version: %s", version))
-	mesh.Log.Debug(fmt.Sprintf("Requested action is delete: %v", del))
 	mesh.Log.Debug(fmt.Sprintf("Requested action is in namespace: %s", namespace))
 
 	st := status.Installing
-	if del {
-		st = status.Removing
-	}
 
 	err := mesh.Config.GetObject(adapter.MeshSpecKey, mesh)
 	if err != nil {
-		return st, ErrMeshConfig(err)
+		return st, nil, nil, ErrMeshConfig(err)
+	}
+
+	values, custom, err := mesh.installValues(params)
+	if err != nil {
+		return st, nil, nil, err
+	}
+	if custom {
+		chartPath, err := fetchChart(ctx, traefikMeshRepository(), traefikMeshChart, version)
+		if err != nil {
+			return st, nil, nil, err
+		}
+		if err := validateHelmValues(chartPath, namespace, values); err != nil {
+			return st, nil, nil, err
+		}
 	}
 
-	err = mesh.applyHelmChart(del, version, namespace, kubeconfigs)
+	metadata, err := params.resourceMetadataParams.resolve()
 	if err != nil {
-		return st, ErrApplyHelmChart(err)
+		return st, nil, nil, err
 	}
 
-	st = status.Installed
-	if del {
-		st = status.Removed
+	policy, err := validateExistingInstallPolicy(params.ExistingInstall)
+	if err != nil {
+		return st, nil, nil, err
+	}
+	requested := len(kubeconfigs)
+	kubeconfigs, decisions, err := applyExistingInstallPolicy(ctx, policy, version, namespace, kubeconfigs)
+	if err != nil {
+		return st, decisions, nil, err
+	}
+	if requested != 0 && len(kubeconfigs) == 0 {
+		return statusAlreadyInstalled, decisions, nil, nil
+	}
+	chartPath, err := fetchChart(ctx, traefikMeshRepository(), traefikMeshChart, version)
+	if err != nil {
+		return st, decisions, nil, err
+	}
+	if err := mesh.rejectIncompatibleClusters(ctx, chartPath, e, kubeconfigs); err != nil {
+		return st, decisions, nil, err
 	}
 
-	return st, nil
+	var sizes map[string]clusterSize
+	if params.AutoSize {
+		sizes, err = sizeClusters(ctx, kubeconfigs)
+		if err != nil {
+			return st, decisions, nil, err
+		}
+		decisions = describeSizing(decisions, sizes)
+	}
+
+	backend, err := validateInstallBackend(params.Backend)
+	if err != nil {
+		return st, decisions, nil, err
+	}
+	var progress progressFunc
+	if e != nil {
+		progress = mesh.installProgressReporter(e, version, namespace)
+	}
+	inventory, err := mesh.applyHelmChart(ctx, version, namespace, backend, values, sizingOverrides(sizes), metadata, progress, kubeconfigs)
+	if err != nil {
+		return st, decisions, nil, err
+	}
+	if progress != nil {
+		progress("", stageCompleted)
+	}
+
+	return status.Installed, decisions, inventory, nil
 }
 
-func (mesh *Mesh) applyHelmChart(del bool, version, namespace string, kubeconfigs []string) error {
+// installValues returns the helm values of the profile merged with the values of the request and
+// of the install mode, it reports whether the request holds values of its own.
+func (mesh *Mesh) installValues(params installParams) (map[string]interface{}, bool, error) {
+	profile, err := internalconfig.ResolveProfile(mesh.Config, params.Profile)
+	if err != nil {
+		return nil, false, err
+	}
+	if profile != "" {
+		mesh.Log.Info(fmt.Sprintf("Using install profile: %s", profile))
+	}
+
+	values := internalconfig.Profiles[profile]
+	mode, err := validateInstallMode(params.Mode)
+	if err != nil {
+		return nil, false, err
+	}
+	reqValues, err := requestValues(params)
+	if err != nil {
+		return nil, false, err
+	}
+	// the mode wins over the values of the request
+	values = internalconfig.MergeValues(internalconfig.MergeValues(values, reqValues), modeValues(mode))
+	return values, len(reqValues) != 0, nil
+}
+
+// applyHelmChart installs the chart in every cluster with the backend, the cluster overrides
+// keyed by cluster are merged over the overrides common to every cluster. When progress
+// is set, the install waits for the controller and the proxies, reporting each stage.
+// A cancelled install removes what it applied to the clusters.
+// It returns the inventory of the resources installed in each cluster, the metadata is added to them.
+// The error of a single failing cluster keeps its code, so that Meshery can classify it.
+func (mesh *Mesh) applyHelmChart(ctx context.Context, version, namespace, backend string, overrides map[string]interface{}, clusterOverrides map[string]map[string]interface{}, metadata resourceMetadata, progress progressFunc, kubeconfigs []string) (map[string][]inventoryItem, error) {
+	chartPath, err := fetchChart(ctx, traefikMeshRepository(), traefikMeshChart, version)
+	if err != nil {
+		return nil, err
+	}
+	inventory := map[string][]inventoryItem{}
+
 	var wg sync.WaitGroup
 	var errs []error
 	var errMx sync.Mutex
@@ -52,35 +177,97 @@ func (mesh *Mesh) applyHelmChart(del bool, version, namespace string, kubeconfig
 				errMx.Unlock()
 				return
 			}
-			repo := "https://helm.traefik.io/mesh"
-			chart := "traefik-mesh"
-			var act mesherykube.HelmChartAction
-			if del {
-				act = mesherykube.UNINSTALL
+			cluster := clusterName(k8sconfig, kClient)
+			report := func(stage string) {
+				if progress != nil {
+					progress(cluster, stage)
+				}
+			}
+			values := overrides
+			if o, ok := clusterOverrides[cluster]; ok {
+				values = internalconfig.MergeValues(overrides, o)
+			}
+			// a cancelled install restores what the namespace held before
+			prior, err := mesh.recordPriorInstall(ctx, kClient, namespace)
+			if err != nil {
+				errMx.Lock()
+				errs = append(errs, ErrApplyHelmChart(err))
+				errMx.Unlock()
+				return
+			}
+			// the namespaces created by the install are deleted by the uninstall operation
+			prior.createdNamespace, err = ensureInstallNamespace(ctx, kClient, namespace)
+			if err != nil {
+				errMx.Lock()
+				errs = append(errs, ErrCreateNamespace(namespace, err))
+				errMx.Unlock()
+				return
+			}
+			report(stageNamespaceCreated)
+			if backend == installBackendKubectl {
+				err = applyRenderedChart(kClient, chartPath, false, namespace, values)
 			} else {
-				act = mesherykube.INSTALL
+				err = kClient.ApplyHelmChart(mesherykube.ApplyHelmChartConfig{
+					LocalPath:       chartPath,
+					Namespace:       namespace,
+					Action:          mesherykube.INSTALL,
+					CreateNamespace: true,
+					OverrideValues:  values,
+					Logger:          mesh.helmLogger(internalconfig.LogMaxLineLength()),
+				})
+			}
+			if ctx.Err() != nil {
+				// cancelled while the chart was applied, which helm doesn't interrupt
+				mesh.rollbackInstall(kClient, backend, chartPath, namespace, values, prior)
+				err = ctx.Err()
+			}
+			if err == nil {
+				err = recordInstallBackend(ctx, kClient, namespace, backend, version)
 			}
-			err = kClient.ApplyHelmChart(mesherykube.ApplyHelmChartConfig{
-				ChartLocation: mesherykube.HelmChartLocation{
-					Repository: repo,
-					Chart:      chart,
-					AppVersion: version,
-				},
-				Namespace:       namespace,
-				Action:          act,
-				CreateNamespace: true,
-			})
 			if err != nil {
 				errMx.Lock()
-				errs = append(errs, err)
+				errs = append(errs, ErrApplyHelmChart(err))
 				errMx.Unlock()
 				return
 			}
+			if progress != nil {
+				if err := waitForMeshComponents(ctx, kClient, namespace, report); err != nil {
+					if ctx.Err() != nil {
+						mesh.rollbackInstall(kClient, backend, chartPath, namespace, values, prior)
+					}
+					errMx.Lock()
+					errs = append(errs, err)
+					errMx.Unlock()
+					return
+				}
+			}
+			// the inventory is informative, the install succeeded without it unless it is needed
+			// to add the metadata to the installed resources
+			items, err := mesh.installInventory(ctx, kClient, backend, chartPath, namespace, values)
+			if err == nil && !metadata.empty() {
+				err = mesh.labelInventory(ctx, kClient, items, metadata)
+			}
+			if err != nil && !metadata.empty() {
+				errMx.Lock()
+				errs = append(errs, ErrResourceMetadata(err))
+				errMx.Unlock()
+				return
+			}
+			if err != nil {
+				mesh.Log.Warn(ErrApplyHelmChart(err))
+				return
+			}
+			errMx.Lock()
+			inventory[cluster] = items
+			errMx.Unlock()
 		}(k8sconfig)
 	}
 	wg.Wait()
-	if len(errs) != 0 {
-		return mergeErrors(errs)
+	switch len(errs) {
+	case 0:
+		return inventory, nil
+	case 1:
+		return inventory, errs[0]
 	}
-	return nil
+	return inventory, ErrApplyHelmChart(mergeErrors(errs))
 }
@@ -0,0 +1,68 @@
+package traefik
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/layer5io/meshery-traefik-mesh/internal/config"
+)
+
+// installableVersionsTTL is how long the installable versions are served without
+// fetching the releases and the helm index again
+const installableVersionsTTL = time.Minute
+
+// installableVersion is a release of traefik mesh along with the chart installing it
+type installableVersion struct {
+	AppVersion   string `json:"app_version"`
+	ChartVersion string `json:"chart_version"`
+}
+
+// installableVersionsCache keeps the latest installable versions, the version dropdowns
+// of the UI would otherwise hit the GitHub rate limit
+var installableVersionsCache = struct {
+	sync.Mutex
+	versions  []installableVersion
+	fetchedAt time.Time
+}{}
+
+// installableVersions returns the releases of traefik mesh, latest first, which have a chart in the
+// helm repository. The drafts and the releases without a chart can't be installed and are left out.
+func installableVersions(ctx context.Context) ([]installableVersion, error) {
+	installableVersionsCache.Lock()
+	defer installableVersionsCache.Unlock()
+
+	if installableVersionsCache.versions != nil && time.Since(installableVersionsCache.fetchedAt) < installableVersionsTTL {
+		return append([]installableVersion{}, installableVersionsCache.versions...), nil
+	}
+
+	releasesCtx, cancel := context.WithTimeout(ctx, config.VersionsTimeout())
+	defer cancel()
+	releases, err := config.GetLatestReleases(releasesCtx, 100)
+	if err != nil {
+		return nil, err
+	}
+	index, err := fetchHelmIndex(ctx, traefikMeshRepository())
+	if err != nil {
+		return nil, err
+	}
+
+	versions := []installableVersion{}
+	seen := map[string]bool{}
+	for _, release := range releases {
+		version := normalizeVersion(string(release.Name))
+		if release.Draft || seen[version] {
+			continue
+		}
+		entry, ok := index.entryForAppVersion(traefikMeshChart, version)
+		if !ok {
+			continue
+		}
+		seen[version] = true
+		versions = append(versions, installableVersion{AppVersion: version, ChartVersion: entry.Version})
+	}
+
+	installableVersionsCache.versions = versions
+	installableVersionsCache.fetchedAt = time.Now()
+	return append([]installableVersion{}, versions...), nil
+}
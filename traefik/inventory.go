@@ -0,0 +1,108 @@
+package traefik
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+// clusterScopedKinds are the kinds of the chart which don't belong to the release namespace
+var clusterScopedKinds = map[string]bool{
+	"Namespace":                      true,
+	"CustomResourceDefinition":       true,
+	"ClusterRole":                    true,
+	"ClusterRoleBinding":             true,
+	"PriorityClass":                  true,
+	"APIService":                     true,
+	"MutatingWebhookConfiguration":   true,
+	"ValidatingWebhookConfiguration": true,
+}
+
+// inventoryItem is a resource created by the install
+type inventoryItem struct {
+	APIVersion string `json:"api_version"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace,omitempty"`
+}
+
+// installInventory lists the resources the install created in the cluster: the namespace when the adapter
+// created it, the CRDs of the chart and the resources of the helm release, or of the rendered chart for
+// the kubectl backend. They are sorted by kind, namespace and name.
+func (mesh *Mesh) installInventory(ctx context.Context, kClient *mesherykube.Client, backend, chartPath, namespace string, values map[string]interface{}) ([]inventoryItem, error) {
+	var manifest string
+	if backend == installBackendKubectl {
+		ch, err := loader.Load(chartPath)
+		if err != nil {
+			return nil, err
+		}
+		if manifest, err = renderChart(ch, namespace, values); err != nil {
+			return nil, err
+		}
+	} else {
+		cfg, err := mesh.helmActionConfig(kClient, namespace)
+		if err != nil {
+			return nil, err
+		}
+		rel, err := action.NewGet(cfg).Run(traefikMeshChart)
+		if err != nil {
+			return nil, err
+		}
+		manifest = rel.Manifest
+		// helm installs the CRDs of the chart apart from the release manifest
+		for _, crd := range rel.Chart.CRDObjects() {
+			manifest += "\n---\n" + string(crd.File.Data)
+		}
+	}
+	objs, err := decodeManifest(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]inventoryItem, 0, len(objs)+1)
+	created, err := namespaceCreatedByInstall(ctx, kClient, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		items = append(items, inventoryItem{APIVersion: "v1", Kind: "Namespace", Name: namespace})
+	}
+	for _, obj := range objs {
+		ns := obj.GetNamespace()
+		if ns == "" && !clusterScopedKinds[obj.GetKind()] {
+			ns = namespace
+		}
+		items = append(items, inventoryItem{
+			APIVersion: obj.GetAPIVersion(),
+			Kind:       obj.GetKind(),
+			Name:       obj.GetName(),
+			Namespace:  ns,
+		})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Kind != items[j].Kind {
+			return items[i].Kind < items[j].Kind
+		}
+		if items[i].Namespace != items[j].Namespace {
+			return items[i].Namespace < items[j].Namespace
+		}
+		return items[i].Name < items[j].Name
+	})
+	return items, nil
+}
+
+// describeInventory returns the JSON encoding of the resources installed in each cluster, on a line of its own
+func describeInventory(inventory map[string][]inventoryItem) string {
+	if len(inventory) == 0 {
+		return ""
+	}
+	byt, err := json.Marshal(inventory)
+	if err != nil {
+		return ""
+	}
+	return "\nInstalled resources: " + string(byt)
+}
@@ -0,0 +1,172 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// shadowServiceNameSeparator is the hex encoding of "maesh" which traefik mesh puts
+// between the name and the namespace of the service in the shadow service name
+const shadowServiceNameSeparator = "-6d61657368-"
+
+// ipFamilyMismatch is a shadow service whose IP families don't line up with the
+// cluster or with the service it shadows
+type ipFamilyMismatch struct {
+	ShadowService   string   `json:"shadow_service"`
+	Service         string   `json:"service"`
+	ShadowFamilies  []string `json:"shadow_families"`
+	ServiceFamilies []string `json:"service_families"`
+	Problem         string   `json:"problem"`
+}
+
+// ipFamilyReport is the IP family configuration of the shadow services of a cluster
+type ipFamilyReport struct {
+	ClusterFamilies []string           `json:"cluster_families"`
+	DualStack       bool               `json:"dual_stack"`
+	ShadowServices  int                `json:"shadow_services"`
+	Mismatches      []ipFamilyMismatch `json:"mismatches"`
+}
+
+// checkIPFamilies compares the IP families of the shadow services of every cluster
+// with the families of the cluster and of the services they shadow
+func (mesh *Mesh) checkIPFamilies(ctx context.Context, kubeconfigs []string) (map[string]interface{}, error) {
+	return collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		var nodes *corev1.NodeList
+		err := retryOnTransient(ctx, func() (err error) {
+			nodes, err = kClient.KubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+			return err
+		})
+		if err != nil {
+			return nil, ErrIPFamilies(err)
+		}
+		var shadows, svcs *corev1.ServiceList
+		err = retryOnTransient(ctx, func() (err error) {
+			shadows, err = kClient.KubeClient.CoreV1().Services("").List(ctx, metav1.ListOptions{LabelSelector: shadowServiceSelector})
+			return err
+		})
+		if err != nil {
+			return nil, ErrIPFamilies(err)
+		}
+		err = retryOnTransient(ctx, func() (err error) {
+			svcs, err = kClient.KubeClient.CoreV1().Services("").List(ctx, metav1.ListOptions{})
+			return err
+		})
+		if err != nil {
+			return nil, ErrIPFamilies(err)
+		}
+		return compareIPFamilies(clusterIPFamilies(nodes.Items), shadows.Items, svcs.Items), nil
+	})
+}
+
+// clusterIPFamilies returns the IP families of the pod CIDRs allocated to the nodes
+func clusterIPFamilies(nodes []corev1.Node) []corev1.IPFamily {
+	seen := map[corev1.IPFamily]bool{}
+	var families []corev1.IPFamily
+	for _, node := range nodes {
+		cidrs := node.Spec.PodCIDRs
+		if len(cidrs) == 0 && node.Spec.PodCIDR != "" {
+			cidrs = []string{node.Spec.PodCIDR}
+		}
+		for _, cidr := range cidrs {
+			family := cidrFamily(cidr)
+			if family != "" && !seen[family] {
+				seen[family] = true
+				families = append(families, family)
+			}
+		}
+	}
+	return families
+}
+
+// cidrFamily returns the IP family of the CIDR, an empty family if it is invalid
+func cidrFamily(cidr string) corev1.IPFamily {
+	ip, _, err := net.ParseCIDR(cidr)
+	switch {
+	case err != nil:
+		return ""
+	case ip.To4() != nil:
+		return corev1.IPv4Protocol
+	default:
+		return corev1.IPv6Protocol
+	}
+}
+
+// compareIPFamilies reports the shadow services whose families are not available in the
+// cluster or differ from the families of the services they shadow
+func compareIPFamilies(cluster []corev1.IPFamily, shadows, svcs []corev1.Service) *ipFamilyReport {
+	report := &ipFamilyReport{
+		ClusterFamilies: familyNames(cluster),
+		DualStack:       len(cluster) > 1,
+		Mismatches:      []ipFamilyMismatch{},
+	}
+	available := map[corev1.IPFamily]bool{}
+	for _, f := range cluster {
+		available[f] = true
+	}
+
+	byName := map[string]corev1.Service{}
+	for _, svc := range svcs {
+		byName[svc.Namespace+"/"+svc.Name] = svc
+	}
+
+	report.ShadowServices = len(shadows)
+	for _, shadow := range shadows {
+		name, namespace, ok := shadowedService(shadow)
+		if !ok {
+			continue
+		}
+		svc, ok := byName[namespace+"/"+name]
+		if !ok {
+			continue
+		}
+
+		mismatch := ipFamilyMismatch{
+			ShadowService:   shadow.Namespace + "/" + shadow.Name,
+			Service:         namespace + "/" + name,
+			ShadowFamilies:  familyNames(shadow.Spec.IPFamilies),
+			ServiceFamilies: familyNames(svc.Spec.IPFamilies),
+		}
+		switch {
+		case len(available) > 0 && len(shadow.Spec.IPFamilies) > 0 && !available[shadow.Spec.IPFamilies[0]]:
+			mismatch.Problem = fmt.Sprintf("the shadow service uses %s which is not allocated to the cluster pods", shadow.Spec.IPFamilies[0])
+		case len(shadow.Spec.IPFamilies) > 0 && len(svc.Spec.IPFamilies) > 0 && shadow.Spec.IPFamilies[0] != svc.Spec.IPFamilies[0]:
+			mismatch.Problem = fmt.Sprintf("the clients resolving the service get %s addresses while the shadow service only routes %s", svc.Spec.IPFamilies[0], shadow.Spec.IPFamilies[0])
+		case len(svc.Spec.IPFamilies) > len(shadow.Spec.IPFamilies):
+			mismatch.Problem = "the service is dual-stack while the shadow service is single-stack, the traffic of the other family bypasses the mesh"
+		default:
+			continue
+		}
+		report.Mismatches = append(report.Mismatches, mismatch)
+	}
+
+	sort.Slice(report.Mismatches, func(i, j int) bool {
+		return report.Mismatches[i].ShadowService < report.Mismatches[j].ShadowService
+	})
+	return report
+}
+
+// shadowedService returns the name and namespace of the service shadowed by the shadow
+// service, traefik mesh names them <mesh namespace>-<name>-6d61657368-<namespace>
+func shadowedService(shadow corev1.Service) (string, string, bool) {
+	i := strings.LastIndex(shadow.Name, shadowServiceNameSeparator)
+	prefix := shadow.Namespace + "-"
+	if i < 0 || !strings.HasPrefix(shadow.Name, prefix) || i < len(prefix) {
+		return "", "", false
+	}
+	return shadow.Name[len(prefix):i], shadow.Name[i+len(shadowServiceNameSeparator):], true
+}
+
+func familyNames(families []corev1.IPFamily) []string {
+	names := make([]string, 0, len(families))
+	for _, f := range families {
+		names = append(names, string(f))
+	}
+	return names
+}
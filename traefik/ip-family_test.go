@@ -0,0 +1,76 @@
+package traefik
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func serviceWithFamilies(namespace, name string, families ...corev1.IPFamily) corev1.Service {
+	return corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       corev1.ServiceSpec{IPFamilies: families},
+	}
+}
+
+func TestClusterIPFamilies(t *testing.T) {
+	nodes := []corev1.Node{
+		{Spec: corev1.NodeSpec{PodCIDR: "10.244.0.0/24"}},
+		{Spec: corev1.NodeSpec{PodCIDRs: []string{"10.244.1.0/24", "fd00:10:244:1::/64"}}},
+		{Spec: corev1.NodeSpec{PodCIDR: "not-a-cidr"}},
+	}
+	want := []corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv6Protocol}
+	if got := clusterIPFamilies(nodes); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected the families %v, got %v", want, got)
+	}
+}
+
+func TestCompareIPFamilies(t *testing.T) {
+	v4, v6 := corev1.IPv4Protocol, corev1.IPv6Protocol
+	tests := []struct {
+		name    string
+		cluster []corev1.IPFamily
+		shadow  []corev1.IPFamily
+		service []corev1.IPFamily
+		problem string
+	}{
+		{name: "matching families", cluster: []corev1.IPFamily{v4, v6}, shadow: []corev1.IPFamily{v4}, service: []corev1.IPFamily{v4}},
+		{name: "family not allocated to the pods", cluster: []corev1.IPFamily{v4}, shadow: []corev1.IPFamily{v6}, service: []corev1.IPFamily{v6}, problem: "uses IPv6 which is not allocated"},
+		{name: "primary family mismatch", cluster: []corev1.IPFamily{v4, v6}, shadow: []corev1.IPFamily{v4}, service: []corev1.IPFamily{v6, v4}, problem: "get IPv6 addresses while the shadow service only routes IPv4"},
+		{name: "single-stack shadow of a dual-stack service", cluster: []corev1.IPFamily{v4, v6}, shadow: []corev1.IPFamily{v4}, service: []corev1.IPFamily{v4, v6}, problem: "bypasses the mesh"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shadows := []corev1.Service{
+				serviceWithFamilies("traefik-mesh", "traefik-mesh-reviews-6d61657368-bookinfo", tt.shadow...),
+				// shadows a service which doesn't exist anymore
+				serviceWithFamilies("traefik-mesh", "traefik-mesh-ratings-6d61657368-bookinfo", v6),
+			}
+			svcs := []corev1.Service{serviceWithFamilies("bookinfo", "reviews", tt.service...)}
+
+			report := compareIPFamilies(tt.cluster, shadows, svcs)
+			if report.ShadowServices != 2 || report.DualStack != (len(tt.cluster) > 1) {
+				t.Errorf("unexpected report %+v", report)
+			}
+			if tt.problem == "" {
+				if len(report.Mismatches) != 0 {
+					t.Fatalf("expected no mismatch, got %+v", report.Mismatches)
+				}
+				return
+			}
+			if len(report.Mismatches) != 1 {
+				t.Fatalf("expected one mismatch, got %+v", report.Mismatches)
+			}
+			m := report.Mismatches[0]
+			if m.ShadowService != "traefik-mesh/traefik-mesh-reviews-6d61657368-bookinfo" || m.Service != "bookinfo/reviews" {
+				t.Errorf("the mismatch doesn't point to the reviews service: %+v", m)
+			}
+			if !strings.Contains(m.Problem, tt.problem) {
+				t.Errorf("expected the problem %q, got %q", tt.problem, m.Problem)
+			}
+		})
+	}
+}
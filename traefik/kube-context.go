@@ -0,0 +1,43 @@
+package traefik
+
+import (
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshery-adapter-library/common"
+	internalconfig "github.com/layer5io/meshery-traefik-mesh/internal/config"
+)
+
+// kubeContextParams is the parameter of every operation selecting the context of the kubeconfigs
+type kubeContextParams struct {
+	// KubeContext is the context the operation runs against, the current context when it is empty
+	KubeContext string `yaml:"kubeContext"`
+}
+
+// requestKubeContext returns the context requested by the operation, the configured one when it requests none.
+// The body of the custom operation is a manifest, not parameters.
+func requestKubeContext(opReq adapter.OperationRequest) string {
+	if opReq.OperationName != common.CustomOperation {
+		var params kubeContextParams
+		// the parameters are validated by the operation itself
+		if err := parseOperationParams(opReq.CustomBody, &params); err == nil && params.KubeContext != "" {
+			return params.KubeContext
+		}
+	}
+	return internalconfig.KubeContext()
+}
+
+// selectKubeContext returns the kubeconfigs with the named context as their current context, it
+// fails when a kubeconfig doesn't define the context
+func selectKubeContext(kubeconfigs []string, name string) ([]string, error) {
+	if name == "" {
+		return kubeconfigs, nil
+	}
+	selected := make([]string, 0, len(kubeconfigs))
+	for _, kubeconfig := range kubeconfigs {
+		k, err := internalconfig.SelectKubeContext(kubeconfig, name)
+		if err != nil {
+			return nil, err
+		}
+		selected = append(selected, k)
+	}
+	return selected, nil
+}
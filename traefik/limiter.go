@@ -0,0 +1,72 @@
+package traefik
+
+import (
+	"sync"
+)
+
+// operationLimiter bounds the number of operations running concurrently. Excess
+// operations either wait in a FIFO queue or are rejected.
+type operationLimiter struct {
+	mx      sync.Mutex
+	limit   int
+	reject  bool
+	running int
+	queue   []chan struct{}
+}
+
+// newOperationLimiter returns a limiter for the given number of concurrent
+// operations, a limit lower than 1 doesn't limit the operations at all
+func newOperationLimiter(limit int, reject bool) *operationLimiter {
+	return &operationLimiter{
+		limit:  limit,
+		reject: reject,
+	}
+}
+
+// acquire reserves a slot for an operation. The returned channel is closed once the
+// operation is allowed to run and position is its 1-based position in the queue,
+// 0 if it can run immediately.
+func (ol *operationLimiter) acquire() (position int, ready <-chan struct{}, err error) {
+	ol.mx.Lock()
+	defer ol.mx.Unlock()
+
+	ch := make(chan struct{})
+	if ol.limit < 1 || ol.running < ol.limit {
+		ol.running++
+		close(ch)
+		return 0, ch, nil
+	}
+	if ol.reject {
+		return 0, nil, ErrTooManyOperations(ol.limit)
+	}
+	ol.queue = append(ol.queue, ch)
+	return len(ol.queue), ch, nil
+}
+
+// abandon takes the queued operation waiting on ready out of the queue, it reports false when
+// the operation got its slot meanwhile, which it must release then
+func (ol *operationLimiter) abandon(ready <-chan struct{}) bool {
+	ol.mx.Lock()
+	defer ol.mx.Unlock()
+
+	for i, ch := range ol.queue {
+		if ch == ready {
+			ol.queue = append(ol.queue[:i], ol.queue[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// release frees the slot of a finished operation and hands it to the next queued one
+func (ol *operationLimiter) release() {
+	ol.mx.Lock()
+	defer ol.mx.Unlock()
+
+	if len(ol.queue) > 0 {
+		close(ol.queue[0])
+		ol.queue = ol.queue[1:]
+		return
+	}
+	ol.running--
+}
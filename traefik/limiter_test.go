@@ -0,0 +1,86 @@
+package traefik
+
+import (
+	"testing"
+
+	"github.com/layer5io/meshkit/errors"
+)
+
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestOperationLimiterQueues(t *testing.T) {
+	ol := newOperationLimiter(2, false)
+
+	// two operations run, the next three wait in order
+	var ready []<-chan struct{}
+	for i, want := range []int{0, 0, 1, 2, 3} {
+		position, ch, err := ol.acquire()
+		if err != nil {
+			t.Fatalf("operation %d: unexpected error: %v", i, err)
+		}
+		if position != want {
+			t.Fatalf("operation %d: expected the queue position %d, got %d", i, want, position)
+		}
+		if running := position == 0; isClosed(ch) != running {
+			t.Fatalf("operation %d: expected running to be %v", i, running)
+		}
+		ready = append(ready, ch)
+	}
+
+	// the abandoned operation doesn't take the freed slot
+	if !ol.abandon(ready[3]) {
+		t.Fatal("the queued operation could not be abandoned")
+	}
+	ol.release()
+	if !isClosed(ready[2]) || isClosed(ready[4]) {
+		t.Fatal("expected the first queued operation to run after a release")
+	}
+	ol.release()
+	if !isClosed(ready[4]) {
+		t.Fatal("expected the last queued operation to run after a second release")
+	}
+	if ol.abandon(ready[4]) {
+		t.Fatal("an operation which got its slot was abandoned")
+	}
+
+	ol.release()
+	ol.release()
+	if position, ch, _ := ol.acquire(); position != 0 || !isClosed(ch) {
+		t.Fatalf("expected a free slot once the queue is drained, got the position %d", position)
+	}
+}
+
+func TestOperationLimiterRejects(t *testing.T) {
+	tests := []struct {
+		name     string
+		limit    int
+		admitted int
+	}{
+		{name: "over the limit", limit: 3, admitted: 3},
+		{name: "unlimited", limit: 0, admitted: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ol := newOperationLimiter(tt.limit, true)
+			for i := 0; i < 10; i++ {
+				position, _, err := ol.acquire()
+				if i < tt.admitted {
+					if err != nil || position != 0 {
+						t.Fatalf("operation %d: expected to run, got the position %d and %v", i, position, err)
+					}
+					continue
+				}
+				if code := errors.GetCode(err); code != ErrTooManyOperationsCode {
+					t.Fatalf("operation %d: expected the error code %s, got %s", i, ErrTooManyOperationsCode, code)
+				}
+			}
+		})
+	}
+}
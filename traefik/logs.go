@@ -0,0 +1,29 @@
+package traefik
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// truncatedMarker is appended to the log lines cut at the maximum length
+const truncatedMarker = "… [truncated %d bytes]"
+
+// truncateLogLine cuts the line to max bytes, on a rune boundary, and marks it
+// as truncated so that pathological entries don't overwhelm the logs and the UI
+func truncateLogLine(line string, max int) string {
+	if max <= 0 || len(line) <= max {
+		return line
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(line[cut]) {
+		cut--
+	}
+	return line[:cut] + fmt.Sprintf(truncatedMarker, len(line)-cut)
+}
+
+// helmLogger returns a logger for the helm actions which forwards the truncated lines to the adapter logs
+func (mesh *Mesh) helmLogger(max int) func(string, ...interface{}) {
+	return func(format string, args ...interface{}) {
+		mesh.Log.Debug(truncateLogLine(fmt.Sprintf(format, args...), max))
+	}
+}
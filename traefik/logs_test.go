@@ -0,0 +1,31 @@
+package traefik
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTruncateLogLine(t *testing.T) {
+	accessLog := `10.244.1.7 - - [14/Mar/2023:09:30:00 +0000] "GET /reviews/0?` + strings.Repeat("q=x&", 40) + `" 200 379`
+	tests := []struct {
+		name string
+		line string
+		max  int
+		want string
+	}{
+		{name: "short line", line: "GET /ratings 200", max: 64, want: "GET /ratings 200"},
+		{name: "exactly at the limit", line: "GET /ratings 200", max: 16, want: "GET /ratings 200"},
+		{name: "no limit", line: accessLog, max: 0, want: accessLog},
+		{name: "over-length access log", line: accessLog, max: 48, want: accessLog[:48] + fmt.Sprintf("… [truncated %d bytes]", len(accessLog)-48)},
+		// "é" takes two bytes, the cut moves back to the start of the rune
+		{name: "multibyte rune at the cut", line: "café latte", max: 4, want: "caf… [truncated 8 bytes]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncateLogLine(tt.line, tt.max); got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+)
+
+// middlewareStages is the recommended order of the middlewares of a chain: the
+// requests are filtered and authenticated before they consume the rate limit,
+// the circuit breaker sees the outcome of every attempt and the retries happen last
+var middlewareStages = []struct {
+	stage string
+	types []string
+}{
+	{"access control", []string{"ipWhiteList", "ipAllowList"}},
+	{"authentication", []string{"basicAuth", "digestAuth", "forwardAuth"}},
+	{"rate limiting", []string{"rateLimit", "inFlightReq"}},
+	{"circuit breaking", []string{"circuitBreaker"}},
+	{"retry", []string{"retry"}},
+}
+
+// middlewareOrderParams are the parameters of the middleware order operation
+type middlewareOrderParams struct {
+	// Service is the name of the service whose chains are validated
+	Service string `yaml:"service" required:"true"`
+}
+
+// chainedMiddleware is a middleware of a router chain
+type chainedMiddleware struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Stage string `json:"stage,omitempty"`
+}
+
+// middlewareChain is the middleware chain of a router of the service
+type middlewareChain struct {
+	Router      string              `json:"router"`
+	Middlewares []chainedMiddleware `json:"middlewares"`
+	Warnings    []string            `json:"warnings,omitempty"`
+}
+
+// validateMiddlewareOrder reports the middleware chains of the service in every cluster
+// and flags the middlewares which run in an order contradicting the recommended one
+func (mesh *Mesh) validateMiddlewareOrder(ctx context.Context, namespace string, params middlewareOrderParams, kubeconfigs []string) (map[string]interface{}, error) {
+	if params.Service == "" {
+		return nil, ErrInvalidOperationParams(fmt.Errorf("service is required"))
+	}
+	return collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		conf, err := fetchControllerConfiguration(ctx, kClient)
+		if err != nil {
+			return nil, ErrMiddlewareOrder(err)
+		}
+		return serviceMiddlewareChains(conf, params.Service, namespace), nil
+	})
+}
+
+// serviceMiddlewareChains returns the chains of the routers of the service, traefik mesh
+// names the services of the configuration <name>-<namespace>-<port>-<hash>
+func serviceMiddlewareChains(conf *dynamicConfiguration, name, namespace string) []middlewareChain {
+	prefix := fmt.Sprintf("%s-%s-", name, namespace)
+	chains := []middlewareChain{}
+	for routerName, router := range conf.HTTP.Routers {
+		if !strings.HasPrefix(router.Service, prefix) {
+			continue
+		}
+		chain := middlewareChain{
+			Router:      routerName,
+			Middlewares: []chainedMiddleware{},
+		}
+		for _, mw := range router.Middlewares {
+			typ := middlewareType(conf.HTTP.Middlewares[mw])
+			chain.Middlewares = append(chain.Middlewares, chainedMiddleware{
+				Name:  mw,
+				Type:  typ,
+				Stage: middlewareStage(typ),
+			})
+		}
+		chain.Warnings = middlewareOrderWarnings(chain.Middlewares)
+		chains = append(chains, chain)
+	}
+	sort.Slice(chains, func(i, j int) bool {
+		return chains[i].Router < chains[j].Router
+	})
+	return chains
+}
+
+// middlewareType returns the type of the middleware, which is its only configuration key
+func middlewareType(conf map[string]interface{}) string {
+	for typ := range conf {
+		return typ
+	}
+	return "unknown"
+}
+
+// middlewareStage returns the stage of the middleware type, empty if it has no recommended position
+func middlewareStage(typ string) string {
+	i := stageIndex(typ)
+	if i < 0 {
+		return ""
+	}
+	return middlewareStages[i].stage
+}
+
+func stageIndex(typ string) int {
+	for i, s := range middlewareStages {
+		for _, t := range s.types {
+			if t == typ {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// middlewareOrderWarnings flags every pair of middlewares running in the reverse of the recommended order
+func middlewareOrderWarnings(chain []chainedMiddleware) []string {
+	var warnings []string
+	for i := range chain {
+		si := stageIndex(chain[i].Type)
+		if si < 0 {
+			continue
+		}
+		for j := i + 1; j < len(chain); j++ {
+			sj := stageIndex(chain[j].Type)
+			if sj >= 0 && sj < si {
+				warnings = append(warnings, fmt.Sprintf("%s (%s) runs before %s (%s), %s is expected to run first",
+					chain[i].Name, chain[i].Stage, chain[j].Name, chain[j].Stage, chain[j].Stage))
+			}
+		}
+	}
+	return warnings
+}
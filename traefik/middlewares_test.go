@@ -0,0 +1,86 @@
+package traefik
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// controllerConfiguration is the configuration built by the controller for the reviews and ratings services
+const controllerConfiguration = `{
+  "http": {
+    "routers": {
+      "bookinfo-reviews-9080-6d61657368-direct": {
+        "rule": "Host(` + "`reviews.bookinfo.maesh`" + `)",
+        "service": "reviews-bookinfo-9080-6d61657368",
+        "middlewares": ["reviews-retry", "reviews-ratelimit", "reviews-auth"]
+      },
+      "bookinfo-reviews-9080-6d61657368-split": {
+        "rule": "Host(` + "`reviews.bookinfo.traefik.mesh`" + `)",
+        "service": "reviews-bookinfo-9080-6d61657368",
+        "middlewares": ["reviews-allow", "reviews-auth", "reviews-ratelimit", "reviews-headers"]
+      },
+      "bookinfo-ratings-9080-6d61657368": {
+        "rule": "Host(` + "`ratings.bookinfo.maesh`" + `)",
+        "service": "ratings-bookinfo-9080-6d61657368",
+        "middlewares": ["reviews-retry", "reviews-auth"]
+      }
+    },
+    "middlewares": {
+      "reviews-allow": {"ipAllowList": {"sourceRange": ["10.244.0.0/16"]}},
+      "reviews-auth": {"forwardAuth": {"address": "http://auth.bookinfo:8080"}},
+      "reviews-ratelimit": {"rateLimit": {"average": 100}},
+      "reviews-retry": {"retry": {"attempts": 2}},
+      "reviews-headers": {"headers": {"customRequestHeaders": {"X-Mesh": "traefik"}}}
+    }
+  }
+}`
+
+func TestServiceMiddlewareChains(t *testing.T) {
+	var conf dynamicConfiguration
+	if err := json.Unmarshal([]byte(controllerConfiguration), &conf); err != nil {
+		t.Fatal(err)
+	}
+
+	chains := serviceMiddlewareChains(&conf, "reviews", "bookinfo")
+	if len(chains) != 2 {
+		t.Fatalf("expected the 2 routers of reviews, got %+v", chains)
+	}
+
+	tests := []struct {
+		router   string
+		stages   []string
+		warnings []string
+	}{
+		{
+			router: "bookinfo-reviews-9080-6d61657368-direct",
+			stages: []string{"retry", "rate limiting", "authentication"},
+			warnings: []string{
+				"reviews-retry (retry) runs before reviews-ratelimit (rate limiting), rate limiting is expected to run first",
+				"reviews-retry (retry) runs before reviews-auth (authentication), authentication is expected to run first",
+				"reviews-ratelimit (rate limiting) runs before reviews-auth (authentication), authentication is expected to run first",
+			},
+		},
+		{
+			// the headers middleware has no recommended position
+			router: "bookinfo-reviews-9080-6d61657368-split",
+			stages: []string{"access control", "authentication", "rate limiting", ""},
+		},
+	}
+	for i, tt := range tests {
+		chain := chains[i]
+		if chain.Router != tt.router {
+			t.Fatalf("expected the router %s, got %s", tt.router, chain.Router)
+		}
+		var stages []string
+		for _, mw := range chain.Middlewares {
+			stages = append(stages, mw.Stage)
+		}
+		if !reflect.DeepEqual(stages, tt.stages) {
+			t.Errorf("%s: expected the stages %q, got %q", tt.router, tt.stages, stages)
+		}
+		if !reflect.DeepEqual(chain.Warnings, tt.warnings) {
+			t.Errorf("%s: expected the warnings %q, got %q", tt.router, tt.warnings, chain.Warnings)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package traefik
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/layer5io/meshery-traefik-mesh/internal/config"
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// namespaceLabelsParams are the parameters of the namespace labels operation
+type namespaceLabelsParams struct {
+	// Labels overrides the required labels configured through REQUIRED_NAMESPACE_LABELS
+	Labels map[string]string `yaml:"labels"`
+	// Apply adds the missing labels and fixes the mismatching ones
+	Apply bool `yaml:"apply"`
+}
+
+// labelMismatch is a required label present with an unexpected value
+type labelMismatch struct {
+	Want string `json:"want"`
+	Got  string `json:"got"`
+}
+
+// namespaceLabelCompliance is the label compliance of the mesh namespace in a cluster
+type namespaceLabelCompliance struct {
+	Namespace  string                   `json:"namespace"`
+	Compliant  bool                     `json:"compliant"`
+	Missing    []string                 `json:"missing,omitempty"`
+	Mismatched map[string]labelMismatch `json:"mismatched,omitempty"`
+	Applied    bool                     `json:"applied"`
+}
+
+// checkNamespaceLabels verifies the mesh namespace of every cluster carries the required labels
+func (mesh *Mesh) checkNamespaceLabels(ctx context.Context, namespace string, params namespaceLabelsParams, kubeconfigs []string) (map[string]interface{}, error) {
+	required := params.Labels
+	if len(required) == 0 {
+		var err error
+		required, err = config.RequiredNamespaceLabels()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		var ns *corev1.Namespace
+		err := retryOnTransient(ctx, func() (err error) {
+			ns, err = kClient.KubeClient.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+			return err
+		})
+		if err != nil {
+			return nil, ErrNamespaceLabels(err)
+		}
+
+		res := compareNamespaceLabels(namespace, ns.Labels, required)
+		if res.Compliant || !params.Apply {
+			return res, nil
+		}
+
+		patch, err := json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{"labels": missingLabels(res, required)},
+		})
+		if err != nil {
+			return nil, ErrNamespaceLabels(err)
+		}
+		err = retryOnTransient(ctx, func() error {
+			_, err := kClient.KubeClient.CoreV1().Namespaces().Patch(ctx, namespace, types.MergePatchType, patch, metav1.PatchOptions{})
+			return err
+		})
+		if err != nil {
+			return nil, ErrNamespaceLabels(err)
+		}
+		res.Applied = true
+		return res, nil
+	})
+}
+
+// compareNamespaceLabels compares the labels of the namespace with the required ones,
+// a required label without a value only needs to be present
+func compareNamespaceLabels(namespace string, labels, required map[string]string) *namespaceLabelCompliance {
+	res := &namespaceLabelCompliance{
+		Namespace:  namespace,
+		Mismatched: map[string]labelMismatch{},
+	}
+	for k, want := range required {
+		got, ok := labels[k]
+		switch {
+		case !ok:
+			res.Missing = append(res.Missing, k)
+		case want != "" && got != want:
+			res.Mismatched[k] = labelMismatch{Want: want, Got: got}
+		}
+	}
+	sort.Strings(res.Missing)
+	res.Compliant = len(res.Missing) == 0 && len(res.Mismatched) == 0
+	return res
+}
+
+// missingLabels returns the labels to set on the namespace to make it compliant
+func missingLabels(res *namespaceLabelCompliance, required map[string]string) map[string]string {
+	labels := map[string]string{}
+	for _, k := range res.Missing {
+		labels[k] = required[k]
+	}
+	for k, m := range res.Mismatched {
+		labels[k] = m.Want
+	}
+	return labels
+}
@@ -0,0 +1,81 @@
+package traefik
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	internalconfig "github.com/layer5io/meshery-traefik-mesh/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// namespaceServer serves the traefik-mesh namespace with the given labels and records the patches
+func namespaceServer(t *testing.T, labels map[string]string, patches *[]string) string {
+	t.Helper()
+	srv := fakeAPIServer(t, map[string]interface{}{
+		"/api/v1/namespaces/traefik-mesh": corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "traefik-mesh", Labels: labels}},
+	}, patches)
+	return fakeKubeconfig(srv.URL)
+}
+
+func TestCheckNamespaceLabels(t *testing.T) {
+	t.Setenv(internalconfig.RequiredNamespaceLabelsEnv, "pod-security.kubernetes.io/enforce=privileged,team")
+
+	tests := []struct {
+		name       string
+		labels     map[string]string
+		params     namespaceLabelsParams
+		missing    []string
+		mismatched map[string]labelMismatch
+		patch      string
+	}{
+		{
+			name:   "compliant",
+			labels: map[string]string{"pod-security.kubernetes.io/enforce": "privileged", "team": "mesh"},
+		},
+		{
+			name:    "missing required label",
+			labels:  map[string]string{"pod-security.kubernetes.io/enforce": "privileged"},
+			missing: []string{"team"},
+		},
+		{
+			name:       "mismatched label applied",
+			labels:     map[string]string{"pod-security.kubernetes.io/enforce": "restricted", "team": "mesh"},
+			params:     namespaceLabelsParams{Apply: true},
+			mismatched: map[string]labelMismatch{"pod-security.kubernetes.io/enforce": {Want: "privileged", Got: "restricted"}},
+			patch:      `{"metadata":{"labels":{"pod-security.kubernetes.io/enforce":"privileged"}}}`,
+		},
+		{
+			name:    "labels of the request override the configured ones",
+			labels:  map[string]string{"team": "mesh"},
+			params:  namespaceLabelsParams{Labels: map[string]string{"owner": ""}, Apply: true},
+			missing: []string{"owner"},
+			patch:   `{"metadata":{"labels":{"owner":""}}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patches []string
+			kubeconfig := namespaceServer(t, tt.labels, &patches)
+
+			res, err := (&Mesh{}).checkNamespaceLabels(context.Background(), "traefik-mesh", tt.params, []string{kubeconfig})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := res["fake-cluster"].(*namespaceLabelCompliance)
+			if got.Compliant != (len(tt.missing) == 0 && len(tt.mismatched) == 0) {
+				t.Errorf("unexpected compliance %+v", got)
+			}
+			if !reflect.DeepEqual(got.Missing, tt.missing) {
+				t.Errorf("expected the missing labels %v, got %v", tt.missing, got.Missing)
+			}
+			if len(got.Mismatched) != len(tt.mismatched) || (len(tt.mismatched) > 0 && !reflect.DeepEqual(got.Mismatched, tt.mismatched)) {
+				t.Errorf("expected the mismatched labels %v, got %v", tt.mismatched, got.Mismatched)
+			}
+			if applied := tt.patch != ""; got.Applied != applied || (applied && (len(patches) != 1 || patches[0] != "/api/v1/namespaces/traefik-mesh "+tt.patch)) {
+				t.Errorf("expected the patch %q, got %v", tt.patch, patches)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/layer5io/meshery-adapter-library/meshes"
+)
+
+// namespaceLockParams are the parameters shared by the operations serialized per namespace
+type namespaceLockParams struct {
+	// NoWait fails the operation when another one holds the namespace instead of waiting for it
+	NoWait bool `yaml:"noWait"`
+}
+
+// namespaceLock lets a single operation at a time modify a namespace
+type namespaceLock struct {
+	sem chan struct{}
+	// holder is the operation holding the lock, empty when it is free
+	holder string
+}
+
+// namespaceLocks serializes the operations installing or removing traefik mesh in the same namespace,
+// the operations on different namespaces run in parallel
+type namespaceLocks struct {
+	mx    sync.Mutex
+	locks map[string]*namespaceLock
+}
+
+func newNamespaceLocks() *namespaceLocks {
+	return &namespaceLocks{
+		locks: make(map[string]*namespaceLock),
+	}
+}
+
+// get returns the lock of the namespace, creating it on first use
+func (nl *namespaceLocks) get(namespace string) *namespaceLock {
+	nl.mx.Lock()
+	defer nl.mx.Unlock()
+
+	l, ok := nl.locks[namespace]
+	if !ok {
+		l = &namespaceLock{sem: make(chan struct{}, 1)}
+		nl.locks[namespace] = l
+	}
+	return l
+}
+
+// acquire locks the namespace for the operation and returns the function releasing it. When the
+// namespace is held by another operation, it fails right away if noWait is set and waits otherwise,
+// calling waiting with the holder first.
+func (nl *namespaceLocks) acquire(ctx context.Context, namespace, operation string, noWait bool, waiting func(holder string)) (func(), error) {
+	l := nl.get(namespace)
+	release := func() {
+		nl.mx.Lock()
+		l.holder = ""
+		nl.mx.Unlock()
+		<-l.sem
+	}
+	hold := func() {
+		nl.mx.Lock()
+		l.holder = operation
+		nl.mx.Unlock()
+	}
+
+	select {
+	case l.sem <- struct{}{}:
+		hold()
+		return release, nil
+	default:
+	}
+
+	nl.mx.Lock()
+	holder := l.holder
+	nl.mx.Unlock()
+	if noWait {
+		return nil, ErrNamespaceBusy(namespace, holder)
+	}
+	if waiting != nil {
+		waiting(holder)
+	}
+	select {
+	case l.sem <- struct{}{}:
+		hold()
+		return release, nil
+	case <-ctx.Done():
+		return nil, ErrNamespaceBusy(namespace, holder)
+	}
+}
+
+// lockNamespace locks the namespace for the operation of the event, streaming an update while it waits
+func (mesh *Mesh) lockNamespace(ctx context.Context, namespace, operation string, noWait bool, e *meshes.EventsResponse) (func(), error) {
+	return mesh.namespaceLocks.acquire(ctx, namespace, operation, noWait, func(holder string) {
+		mesh.streamUpdate(e, "Waiting for the namespace", fmt.Sprintf("Operation %s is in progress for namespace %s, waiting for it to complete", holder, namespace))
+	})
+}
@@ -0,0 +1,94 @@
+package traefik
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	"github.com/layer5io/meshkit/errors"
+	"github.com/layer5io/meshkit/logger"
+	"github.com/layer5io/meshkit/utils/events"
+)
+
+func TestLockNamespace(t *testing.T) {
+	tests := []struct {
+		name      string
+		namespace string
+		noWait    bool
+		timeout   time.Duration
+		waits     bool
+		code      string
+	}{
+		{name: "same namespace", namespace: "traefik-mesh", timeout: time.Second, waits: true},
+		{name: "same namespace with noWait", namespace: "traefik-mesh", noWait: true, timeout: time.Second, code: ErrNamespaceBusyCode},
+		{name: "same namespace past the deadline", namespace: "traefik-mesh", timeout: 50 * time.Millisecond, code: ErrNamespaceBusyCode},
+		{name: "other namespace", namespace: "maesh", noWait: true, timeout: time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, err := logger.New("test", logger.Options{Format: logger.JsonLogFormat, Output: &bytes.Buffer{}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			mesh := &Mesh{
+				Adapter:        adapter.Adapter{Log: log, EventStreamer: events.NewEventStreamer()},
+				events:         newEventLog(),
+				history:        newOperationHistory(10),
+				namespaceLocks: newNamespaceLocks(),
+			}
+			unlockFirst, err := mesh.lockNamespace(context.Background(), "traefik-mesh", "install-1", false, &meshes.EventsResponse{OperationId: "install-1"})
+			if err != nil {
+				t.Fatalf("locking a free namespace: %v", err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+			type result struct {
+				unlock func()
+				err    error
+			}
+			done := make(chan result, 1)
+			go func() {
+				unlock, err := mesh.lockNamespace(ctx, tt.namespace, "install-2", tt.noWait, &meshes.EventsResponse{OperationId: "install-2"})
+				done <- result{unlock, err}
+			}()
+
+			if tt.waits {
+				select {
+				case res := <-done:
+					t.Fatalf("the second install didn't wait for the first one: %v", res.err)
+				case <-time.After(50 * time.Millisecond):
+				}
+				unlockFirst()
+			}
+			res := <-done
+			if tt.code != "" {
+				if res.err == nil {
+					t.Fatalf("expected an error with code %s, got none", tt.code)
+				}
+				if code := errors.GetCode(res.err); code != tt.code {
+					t.Fatalf("expected the error code %s, got %s: %v", tt.code, code, res.err)
+				}
+				return
+			}
+			if res.err != nil {
+				t.Fatalf("unexpected error: %v", res.err)
+			}
+			res.unlock()
+
+			exported, err := mesh.exportEvents(exportEventsParams{OperationID: "install-2"})
+			if !tt.waits {
+				if err == nil {
+					t.Fatalf("expected no update for an install which didn't wait, got %+v", exported.Events)
+				}
+				return
+			}
+			if err != nil || len(exported.Events) != 1 || exported.Events[0].Summary != "Waiting for the namespace" {
+				t.Fatalf("expected the wait to be streamed, got %+v: %v", exported, err)
+			}
+		})
+	}
+}
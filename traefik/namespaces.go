@@ -0,0 +1,115 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+
+	internalconfig "github.com/layer5io/meshery-traefik-mesh/internal/config"
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// createdByAnnotation marks the namespaces created by the adapter, only those are deleted on uninstall
+	createdByAnnotation = "meshery.io/created-by"
+	// createdByAdapter is the value of createdByAnnotation set by this adapter
+	createdByAdapter = "meshery-traefik-mesh"
+	// installNamespaceAnnotation marks the namespaces created by the traefik mesh install, the sample
+	// apps create theirs with createdByAnnotation alone, only these are deleted with traefik mesh
+	installNamespaceAnnotation = "meshery.io/traefik-mesh-namespace"
+)
+
+// operationNamespace returns the namespace targeted by the operation, the configured default when the request has none
+func operationNamespace(namespace string) (string, error) {
+	if namespace == "" {
+		return internalconfig.DefaultNamespace(), nil
+	}
+	if errs := validation.IsDNS1123Label(namespace); len(errs) != 0 {
+		return "", ErrInvalidOperationParams(fmt.Errorf("namespace %q is invalid: %v", namespace, errs))
+	}
+	return namespace, nil
+}
+
+// ensureNamespace creates the namespace if it doesn't exist, annotated as created by the adapter
+func ensureNamespace(ctx context.Context, kClient *mesherykube.Client, namespace string) error {
+	_, err := createNamespace(ctx, kClient, namespace, map[string]string{createdByAnnotation: createdByAdapter})
+	return err
+}
+
+// ensureInstallNamespace creates the namespace of the traefik mesh install if it doesn't exist,
+// annotated so that the removal of traefik mesh deletes it. It reports whether it created the namespace.
+func ensureInstallNamespace(ctx context.Context, kClient *mesherykube.Client, namespace string) (bool, error) {
+	return createNamespace(ctx, kClient, namespace, map[string]string{
+		createdByAnnotation:        createdByAdapter,
+		installNamespaceAnnotation: createdByAdapter,
+	})
+}
+
+// createNamespace creates the namespace with the annotations if it doesn't exist, it reports whether it created it
+func createNamespace(ctx context.Context, kClient *mesherykube.Client, namespace string, annotations map[string]string) (bool, error) {
+	exists, err := namespaceExists(ctx, kClient, namespace)
+	if err != nil || exists {
+		return false, err
+	}
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        namespace,
+			Annotations: annotations,
+		},
+	}
+	err = retryOnTransient(ctx, func() error {
+		_, err := kClient.KubeClient.CoreV1().Namespaces().Create(ctx, ns, metav1.CreateOptions{})
+		return err
+	})
+	if kubeerrors.IsAlreadyExists(err) {
+		// created concurrently, by someone else
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// namespaceExists reports whether the namespace exists in the cluster
+func namespaceExists(ctx context.Context, kClient *mesherykube.Client, namespace string) (bool, error) {
+	err := retryOnTransient(ctx, func() error {
+		_, err := kClient.KubeClient.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+		return err
+	})
+	if kubeerrors.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// namespaceCreatedByInstall reports whether the namespace exists and was created by the traefik mesh install
+func namespaceCreatedByInstall(ctx context.Context, kClient *mesherykube.Client, namespace string) (bool, error) {
+	var ns *corev1.Namespace
+	err := retryOnTransient(ctx, func() (err error) {
+		ns, err = kClient.KubeClient.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+		return err
+	})
+	if kubeerrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return ns.Annotations[installNamespaceAnnotation] == createdByAdapter, nil
+}
+
+// deleteCreatedNamespace deletes the namespace if the traefik mesh install created it, it reports whether it was deleted
+func deleteCreatedNamespace(ctx context.Context, kClient *mesherykube.Client, namespace string) (bool, error) {
+	created, err := namespaceCreatedByInstall(ctx, kClient, namespace)
+	if err != nil || !created {
+		return false, err
+	}
+	err = retryOnTransient(ctx, func() error {
+		return kClient.KubeClient.CoreV1().Namespaces().Delete(ctx, namespace, metav1.DeleteOptions{})
+	})
+	if kubeerrors.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
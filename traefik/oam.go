@@ -1,14 +1,17 @@
 package traefik
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
 	"github.com/google/uuid"
 	"github.com/layer5io/meshery-adapter-library/meshes"
+	"github.com/layer5io/meshery-adapter-library/status"
 	"github.com/layer5io/meshery-traefik-mesh/internal/config"
 	"github.com/layer5io/meshkit/models/oam/core/v1alpha1"
 	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 // CompHandler is the type for functions which can handle OAM components
@@ -88,13 +91,22 @@ func (mesh *Mesh) HandleApplicationConfiguration(config v1alpha1.Configuration,
 }
 
 func handleComponentTraefikMesh(mesh *Mesh, comp v1alpha1.Component, isDel bool, kubeconfigs []string) (string, error) {
+	if isDel {
+		if _, err := mesh.uninstallTraefikMesh(context.TODO(), comp.Namespace, kubeconfigs); err != nil {
+			return fmt.Sprintf("%s: %s", comp.Name, status.Removing), err
+		}
+		return fmt.Sprintf("%s: %s", comp.Name, status.Removed), nil
+	}
 	version := comp.Spec.Version
-	msg, err := mesh.installTraefikMesh(isDel, version, comp.Namespace, kubeconfigs)
+	profile, _ := comp.Spec.Settings["profile"].(string)
+	existing, _ := comp.Spec.Settings["existingInstall"].(string)
+	autoSize, _ := comp.Spec.Settings["autoSize"].(bool)
+	msg, decisions, _, err := mesh.installTraefikMesh(context.TODO(), version, comp.Namespace, installParams{Profile: profile, ExistingInstall: existing, AutoSize: autoSize}, nil, kubeconfigs)
 	if err != nil {
 		return fmt.Sprintf("%s: %s", comp.Name, msg), err
 	}
 
-	return fmt.Sprintf("%s: %s", comp.Name, msg), nil
+	return fmt.Sprintf("%s: %s%s", comp.Name, msg, describeDecisions(decisions)), nil
 }
 
 func handleTraefikCoreComponent(
@@ -129,6 +141,21 @@ func handleTraefikCoreComponent(
 		"spec": comp.Spec.Settings,
 	}
 
+	// TrafficSplits of an unsupported version are silently ignored by the mesh
+	if kind == trafficSplitKind && strings.HasPrefix(apiVersion, smiSplitGroup+"/") && !isDel {
+		target := supportedTrafficSplitVersion(mesh.installedMeshVersion(context.TODO(), kubeconfigs))
+		outcome, err := convertTrafficSplit(&unstructured.Unstructured{Object: component}, target)
+		if err != nil {
+			return "", ErrInvalidTrafficSplit(err)
+		}
+		if outcome.Converted {
+			mesh.Log.Info(fmt.Sprintf("converted TrafficSplit %q from %s to %s", comp.Name, outcome.FromVersion, outcome.ToVersion))
+		}
+		if err := mesh.rejectIncompatibleSplit(context.TODO(), &unstructured.Unstructured{Object: component}, comp.Namespace, kubeconfigs); err != nil {
+			return "", err
+		}
+	}
+
 	// Convert to yaml
 	yamlByt, err := yaml.Marshal(component)
 	if err != nil {
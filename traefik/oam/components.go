@@ -0,0 +1,62 @@
+package oam
+
+import (
+	"encoding/json"
+)
+
+// Component describes a meshmodel component advertised to Meshery server
+type Component struct {
+	Name        string `json:"name"`
+	Kind        string `json:"kind"`
+	APIVersion  string `json:"api_version"`
+	DisplayName string `json:"display_name,omitempty"`
+	Version     string `json:"version"`
+	Model       string `json:"model,omitempty"`
+	Category    string `json:"category,omitempty"`
+}
+
+// componentMetadata is the subset of a component definition describing the component
+type componentMetadata struct {
+	Kind        string `json:"kind"`
+	APIVersion  string `json:"apiVersion"`
+	DisplayName string `json:"displayName"`
+	Model       struct {
+		DisplayName string `json:"displayName"`
+		Category    struct {
+			Name string `json:"name"`
+		} `json:"category"`
+	} `json:"model"`
+}
+
+// Components returns the components registered with Meshery server, in the registration
+// order. They are loaded the same way as for the registration.
+func Components() ([]Component, error) {
+	pathSets, err := loadMeshmodelComponents(MeshmodelComponents)
+	if err != nil {
+		return nil, err
+	}
+	sortByRegistrationOrder(pathSets)
+
+	comps := make([]Component, 0, len(pathSets))
+	for _, pathSet := range pathSets {
+		path := pathSet.meshmodelDefinitionPath
+		byt, err := pathSet.read()
+		if err != nil {
+			return nil, err
+		}
+		var md componentMetadata
+		if err := json.Unmarshal(byt, &md); err != nil {
+			return nil, err
+		}
+		comps = append(comps, Component{
+			Name:        componentName(path),
+			Kind:        md.Kind,
+			APIVersion:  md.APIVersion,
+			DisplayName: md.DisplayName,
+			Version:     pathSet.version(),
+			Model:       md.Model.DisplayName,
+			Category:    md.Model.Category.Name,
+		})
+	}
+	return comps, nil
+}
@@ -0,0 +1,39 @@
+package oam
+
+import (
+	"fmt"
+
+	"github.com/layer5io/meshkit/errors"
+)
+
+const (
+	// ErrReloadComponentsCode represents the error which is generated when the
+	// meshmodel components could not be reloaded from the filesystem
+	ErrReloadComponentsCode = "1055"
+
+	// ErrInvalidComponentSchemaCode represents the error which is generated when the
+	// schema of a component definition is not a valid JSON Schema
+	ErrInvalidComponentSchemaCode = "1099"
+
+	// ErrLoadComponentsCode represents the error which is generated when the
+	// meshmodel component definitions to register can't be found or read
+	ErrLoadComponentsCode = "1103"
+)
+
+// ErrReloadComponents is the error when the meshmodel components could not be reloaded
+func ErrReloadComponents(err error) error {
+	return errors.New(ErrReloadComponentsCode, errors.Alert, []string{"Error reloading meshmodel components"}, []string{err.Error()}, []string{"The component definitions on the filesystem are unreadable or invalid"}, []string{"Fix the reported component definitions and reload again"})
+}
+
+// ErrInvalidComponentSchema is the error when the schema of a component definition is not a valid JSON Schema
+func ErrInvalidComponentSchema(component, path string, err error) error {
+	return errors.New(ErrInvalidComponentSchemaCode, errors.Alert, []string{"Invalid component schema"}, []string{fmt.Sprintf("schema of component %s (%s) is not a valid JSON Schema: %v", component, path, err)}, []string{"The component definition was generated from a malformed CRD"}, []string{"Fix the CRD the component is generated from, or the schema of the definition"})
+}
+
+// ErrLoadComponents is the error when the meshmodel component definitions can't be found or read
+func ErrLoadComponents(dir string, err error) error {
+	if dir == "" {
+		dir = "(unresolved)"
+	}
+	return errors.New(ErrLoadComponentsCode, errors.Alert, []string{"Error loading meshmodel components"}, []string{fmt.Sprintf("loading the component definitions from %s: %v", dir, err)}, []string{"The templates directory next to the adapter executable, or in its working directory, is unreadable"}, []string{"Check the permissions of the templates directory, it is only needed for the components generated dynamically"})
+}
@@ -0,0 +1,58 @@
+package oam
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	// registrationOrder holds the names of the components registered first
+	registrationOrder   []string
+	registrationOrderMx sync.RWMutex
+)
+
+// SetRegistrationOrder sets the components to register before the others so that
+// the components depending on them are registered last. The components which are
+// not listed keep the filesystem order.
+func SetRegistrationOrder(order []string) {
+	registrationOrderMx.Lock()
+	defer registrationOrderMx.Unlock()
+	registrationOrder = make([]string, 0, len(order))
+	for _, name := range order {
+		registrationOrder = append(registrationOrder, strings.ToLower(name))
+	}
+}
+
+// componentName returns the name of the component defined at path,
+// e.g. trafficsplit for trafficsplit.meshery.layer5.io_meshmodel.json
+func componentName(path string) string {
+	name := strings.ToLower(filepath.Base(path))
+	if i := strings.IndexAny(name, "._"); i > 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// registrationPriority returns the position of the component in the registration order,
+// the components which are not listed come after the listed ones
+func registrationPriority(path string) int {
+	registrationOrderMx.RLock()
+	defer registrationOrderMx.RUnlock()
+	name := componentName(path)
+	for i, n := range registrationOrder {
+		if n == name {
+			return i
+		}
+	}
+	return len(registrationOrder)
+}
+
+// sortByRegistrationOrder orders the definitions by registration priority, the
+// definitions with the same priority keep their filesystem order
+func sortByRegistrationOrder(pathSets []meshmodelDefinitionPathSet) {
+	sort.SliceStable(pathSets, func(i, j int) bool {
+		return registrationPriority(pathSets[i].meshmodelDefinitionPath) < registrationPriority(pathSets[j].meshmodelDefinitionPath)
+	})
+}
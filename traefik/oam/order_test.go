@@ -0,0 +1,55 @@
+package oam
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegistrationOrder(t *testing.T) {
+	definitions := map[string]string{
+		"v1.4.8/httproutegroup.meshery.layer5.io_meshmodel.json": componentJSON("HTTPRouteGroup"),
+		"v1.4.8/traefikmesh.meshery.layer5.io_meshmodel.json":    componentJSON("TraefikMesh"),
+		"v1.4.8/trafficsplit.meshery.layer5.io_meshmodel.json":   componentJSON("TrafficSplit"),
+		"v1.4.8/traffictarget.meshery.layer5.io_meshmodel.json":  componentJSON("TrafficTarget"),
+	}
+	tests := []struct {
+		name  string
+		order []string
+		want  []string
+	}{
+		{
+			name: "filesystem order by default",
+			want: []string{"HTTPRouteGroup", "TraefikMesh", "TrafficSplit", "TrafficTarget"},
+		},
+		{
+			name:  "listed components first",
+			order: []string{"TraefikMesh", "traffictarget"},
+			want:  []string{"TraefikMesh", "TrafficTarget", "HTTPRouteGroup", "TrafficSplit"},
+		},
+		{
+			name:  "unknown components ignored",
+			order: []string{"meshsync", "trafficsplit"},
+			want:  []string{"TrafficSplit", "HTTPRouteGroup", "TraefikMesh", "TrafficTarget"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetRegistrationOrder(tt.order)
+			t.Cleanup(func() { SetRegistrationOrder(nil) })
+
+			registry := &fakeRegistry{}
+			r := newTestRegistrant(t, registry, definitions)
+			sortByRegistrationOrder(r.definitions)
+			count, err := r.register("ctx-1")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if count != len(definitions) {
+				t.Fatalf("expected %d components registered, got %d", len(definitions), count)
+			}
+			if got := registry.kinds(); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected the registration order %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -2,70 +2,141 @@ package oam
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"sync"
 
-	"github.com/layer5io/meshery-adapter-library/adapter"
-	"github.com/layer5io/meshkit/models/meshmodel/core/types"
+	"github.com/layer5io/meshery-traefik-mesh/templates"
 )
 
-var (
-	basePath, _         = os.Getwd()
-	MeshmodelComponents = filepath.Join(basePath, "templates", "meshmodel", "components")
-)
+// MeshmodelComponents is the directory the components generated dynamically are written to,
+// they are registered along with the components embedded in the adapter
+var MeshmodelComponents = meshmodelComponentsDir()
+
+func meshmodelComponentsDir() string {
+	root, err := templates.Root()
+	if err != nil {
+		// only the embedded components are registered
+		return ""
+	}
+	return filepath.Join(root, "meshmodel", "components")
+}
 
 // AvailableVersions denote the component versions available statically
 var AvailableVersions = map[string]bool{}
 var availableVersionGlobalMutex sync.Mutex
 
+// meshmodelDefinitionPathSet is a component definition, either embedded or generated dynamically
 type meshmodelDefinitionPathSet struct {
+	// meshmodelDefinitionPath is the slash separated path of the definition within fsys, <version>/<file>
 	meshmodelDefinitionPath string
+	fsys                    fs.FS
+}
+
+// read returns the content of the definition
+func (ps meshmodelDefinitionPathSet) read() ([]byte, error) {
+	return fs.ReadFile(ps.fsys, ps.meshmodelDefinitionPath)
+}
+
+// version returns the version of the component, the directory of its definition
+func (ps meshmodelDefinitionPathSet) version() string {
+	return path.Base(path.Dir(ps.meshmodelDefinitionPath))
 }
 
+// RegisterMeshModelComponents registers every meshmodel component with Meshery server
 func RegisterMeshModelComponents(uuid, runtime, host, port string) error {
-	meshmodelRDP := []adapter.MeshModelRegistrantDefinitionPath{}
+	_, err := registerMeshModelComponents(uuid, runtime, host, port, false)
+	return err
+}
+
+// RegisterChangedMeshModelComponents registers the meshmodel components whose definition changed since
+// they were last registered with Meshery server, it returns the number of components registered
+func RegisterChangedMeshModelComponents(uuid, runtime, host, port string) (int, error) {
+	return registerMeshModelComponents(uuid, runtime, host, port, true)
+}
+
+func registerMeshModelComponents(uuid, runtime, host, port string, dedup bool) (int, error) {
 	pathSets, err := loadMeshmodelComponents(MeshmodelComponents)
 	if err != nil {
-		return err
+		return 0, err
 	}
+	sortByRegistrationOrder(pathSets)
+	registrationMx.Lock()
+	lastRegistration = &registrationArgs{uuid: uuid, runtime: runtime, host: host, port: port}
+	registrationMx.Unlock()
+
 	portint, _ := strconv.Atoi(port)
-	for _, pathSet := range pathSets {
-		meshmodelRDP = append(meshmodelRDP, adapter.MeshModelRegistrantDefinitionPath{
-			EntityDefintionPath: pathSet.meshmodelDefinitionPath,
-			Host:                host,
-			Port:                portint,
-			Type:                types.ComponentDefinition,
-		})
+	r := &registrant{
+		definitions:  pathSets,
+		host:         host,
+		port:         portint,
+		httpRegistry: fmt.Sprintf("%s/api/meshmodel/components/register", runtime),
+		batchSize:    int(registrationBatchSize.Load()),
+		dedup:        dedup,
 	}
-
-	return adapter.
-		NewMeshModelRegistrant(meshmodelRDP, fmt.Sprintf("%s/api/meshmodel/components/register", runtime)).
-		Register(uuid)
+	return r.register(uuid)
 }
 
+// loadMeshmodelComponents lists the definitions to register and refreshes the versions available statically
 func loadMeshmodelComponents(basepath string) ([]meshmodelDefinitionPathSet, error) {
-	res := []meshmodelDefinitionPathSet{}
-	if err := filepath.Walk(basepath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
+	res, err := listMeshmodelDefinitions(basepath)
+	if err != nil {
+		return nil, err
+	}
+	for _, pathSet := range res {
+		recordLoadedDefinition(pathSet)
+		availableVersionGlobalMutex.Lock()
+		AvailableVersions[pathSet.version()] = true // Getting available versions already existing on file system
+		availableVersionGlobalMutex.Unlock()
+	}
+	return res, nil
+}
 
-		if info.IsDir() {
+// listMeshmodelDefinitions returns the definitions embedded in the adapter along with the ones generated
+// at basepath, sorted by path. A definition at basepath replaces the embedded one with the same path.
+func listMeshmodelDefinitions(basepath string) ([]meshmodelDefinitionPathSet, error) {
+	definitions := map[string]meshmodelDefinitionPathSet{}
+	walk := func(fsys fs.FS) error {
+		return fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			definitions[path] = meshmodelDefinitionPathSet{meshmodelDefinitionPath: path, fsys: fsys}
 			return nil
-		}
-
-		res = append(res, meshmodelDefinitionPathSet{
-			meshmodelDefinitionPath: path,
 		})
-		availableVersionGlobalMutex.Lock()
-		AvailableVersions[filepath.Base(filepath.Dir(path))] = true // Getting available versions already existing on file system
-		availableVersionGlobalMutex.Unlock()
-		return nil
-	}); err != nil {
+	}
+	if err := walk(templates.MeshmodelComponents()); err != nil {
 		return nil, err
 	}
+	// the directory only exists once components were generated dynamically
+	if basepath != "" {
+		info, err := os.Stat(basepath)
+		switch {
+		case err == nil && info.IsDir():
+			if err := walk(os.DirFS(basepath)); err != nil {
+				return nil, ErrLoadComponents(basepath, err)
+			}
+		case err != nil && !os.IsNotExist(err):
+			return nil, ErrLoadComponents(basepath, err)
+		}
+	}
+	if len(definitions) == 0 {
+		return nil, ErrLoadComponents(basepath, fmt.Errorf("no component definition is embedded in the adapter nor found in the directory"))
+	}
 
+	res := make([]meshmodelDefinitionPathSet, 0, len(definitions))
+	for _, pathSet := range definitions {
+		res = append(res, pathSet)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].meshmodelDefinitionPath < res[j].meshmodelDefinitionPath
+	})
 	return res, nil
 }
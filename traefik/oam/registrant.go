@@ -0,0 +1,264 @@
+package oam
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	backoff "github.com/cenkalti/backoff/v4"
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshkit/models/meshmodel"
+	"github.com/layer5io/meshkit/models/meshmodel/core/types"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1alpha1"
+)
+
+var (
+	// extraMetadata is added to the metadata of every registered component
+	extraMetadata   = map[string]string{}
+	extraMetadataMx sync.RWMutex
+
+	// registrationBatchSize is the number of components registered per request
+	registrationBatchSize atomic.Int64
+
+	// registryTransport sends the registration requests to Meshery server
+	registryTransport atomic.Value
+
+	// registrationBackoffCap is the time after which a registration request is no longer
+	// retried, defaultRegistrationBackoffCap when it isn't set
+	registrationBackoffCap atomic.Int64
+
+	// registeredHashes maps the path of every definition registered to the hash of the
+	// component posted, the unchanged components are skipped by the deduplicated registrations
+	registeredHashes   = map[string]string{}
+	registeredHashesMx sync.Mutex
+)
+
+const defaultRegistrationBackoffCap = 10 * time.Minute
+
+// SetRegistrationBatchSize sets the number of components registered per request with Meshery server
+func SetRegistrationBatchSize(size int) {
+	registrationBatchSize.Store(int64(size))
+}
+
+// SetRegistrationBackoffCap sets the time after which a registration request is no longer retried
+func SetRegistrationBackoffCap(d time.Duration) {
+	registrationBackoffCap.Store(int64(d))
+}
+
+// SetHTTPTransport sets the transport of the registration requests, e.g. to enforce a minimum TLS version
+func SetHTTPTransport(rt http.RoundTripper) {
+	registryTransport.Store(rt)
+}
+
+func registryClient() *http.Client {
+	if rt, ok := registryTransport.Load().(http.RoundTripper); ok {
+		return &http.Client{Transport: rt}
+	}
+	return http.DefaultClient
+}
+
+// SetComponentMetadata sets the metadata added to the registered components, e.g. the
+// team or the environment. The keys already set by the definitions are never overwritten.
+func SetComponentMetadata(md map[string]string) {
+	extraMetadataMx.Lock()
+	defer extraMetadataMx.Unlock()
+	extraMetadata = md
+}
+
+// registrant registers the component definitions with Meshery server. It mirrors
+// adapter.MeshModelRegistrant and enriches the components with the configured metadata.
+type registrant struct {
+	definitions  []meshmodelDefinitionPathSet
+	host         string
+	port         int
+	httpRegistry string
+	// batchSize is the number of components sent per request, they are sent one by one when it is lower than 2
+	batchSize int
+	// dedup skips the components registered before whose content didn't change since
+	dedup bool
+}
+
+// registeredDefinition is a definition along with the hash of the component posted
+type registeredDefinition struct {
+	path, hash string
+}
+
+// unchanged reports whether the component was registered before with the same content
+func (rd registeredDefinition) unchanged() bool {
+	registeredHashesMx.Lock()
+	defer registeredHashesMx.Unlock()
+	return registeredHashes[rd.path] == rd.hash
+}
+
+// recordRegistered records the hashes of the components registered
+func recordRegistered(rds ...registeredDefinition) {
+	registeredHashesMx.Lock()
+	defer registeredHashesMx.Unlock()
+	for _, rd := range rds {
+		registeredHashes[rd.path] = rd.hash
+	}
+}
+
+// register registers every definition, retrying with an exponential backoff for 10 minutes. The
+// definitions whose schema is invalid are skipped, or fail the registration if configured so.
+// It returns the number of components registered.
+func (r *registrant) register(ctxID string) (int, error) {
+	var batch []meshmodel.MeshModelRegistrantData
+	var pending []registeredDefinition
+	count := 0
+	batching := r.batchSize > 1
+	for _, def := range r.definitions {
+		cd, err := readComponentDefinition(def)
+		if err != nil {
+			return count, err
+		}
+		ok, err := checkComponentSchema(cd, def.meshmodelDefinitionPath)
+		if err != nil {
+			return count, err
+		}
+		if !ok {
+			continue
+		}
+		enrichMetadata(&cd)
+		enbyt, err := json.Marshal(cd)
+		if err != nil {
+			return count, adapter.ErrJSONMarshal(err)
+		}
+		sum := sha256.Sum256(enbyt)
+		rd := registeredDefinition{path: def.meshmodelDefinitionPath, hash: hex.EncodeToString(sum[:])}
+		if r.dedup && rd.unchanged() {
+			continue
+		}
+		mrd := meshmodel.MeshModelRegistrantData{
+			Host: meshmodel.Host{
+				Hostname:  r.host,
+				Port:      r.port,
+				ContextID: ctxID,
+			},
+			EntityType: types.ComponentDefinition,
+			Entity:     enbyt,
+		}
+		if !batching {
+			if err := r.post(mrd); err != nil {
+				return count, err
+			}
+			recordRegistered(rd)
+			count++
+			continue
+		}
+		batch = append(batch, mrd)
+		pending = append(pending, rd)
+		if len(batch) == r.batchSize {
+			if batching, err = r.postBatch(batch); err != nil {
+				return count, err
+			}
+			recordRegistered(pending...)
+			count += len(pending)
+			batch, pending = nil, nil
+		}
+	}
+	if len(batch) > 0 {
+		if _, err := r.postBatch(batch); err != nil {
+			return count, err
+		}
+		recordRegistered(pending...)
+		count += len(pending)
+	}
+	return count, nil
+}
+
+// postBatch sends the registrations in a single request. The registrations are sent one by one
+// when the server rejects the batch, it reports whether batching should be tried again.
+func (r *registrant) postBatch(batch []meshmodel.MeshModelRegistrantData) (bool, error) {
+	contentByt, err := json.Marshal(batch)
+	if err != nil {
+		return false, adapter.ErrJSONMarshal(err)
+	}
+	// the registry is given by the adapter configuration and is trustworthy hence,
+	// #nosec
+	resp, err := registryClient().Post(r.httpRegistry, "application/json", bytes.NewReader(contentByt))
+	if err == nil {
+		_ = resp.Body.Close()
+		if registered(resp) {
+			return true, nil
+		}
+	}
+	// the server doesn't support batches, or failed to process this one
+	for _, mrd := range batch {
+		if err := r.post(mrd); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// post sends the registration to Meshery server
+func (r *registrant) post(mrd meshmodel.MeshModelRegistrantData) error {
+	contentByt, err := json.Marshal(mrd)
+	if err != nil {
+		return adapter.ErrJSONMarshal(err)
+	}
+	backoffOpt := backoff.NewExponentialBackOff()
+	backoffOpt.MaxElapsedTime = defaultRegistrationBackoffCap
+	if d := time.Duration(registrationBackoffCap.Load()); d > 0 {
+		backoffOpt.MaxElapsedTime = d
+	}
+	err = backoff.Retry(func() error {
+		// the registry is given by the adapter configuration and is trustworthy hence,
+		// #nosec
+		resp, err := registryClient().Post(r.httpRegistry, "application/json", bytes.NewReader(contentByt))
+		if err != nil {
+			return err
+		}
+		_ = resp.Body.Close()
+		if !registered(resp) {
+			return fmt.Errorf("register process failed, host returned status: %s with status code %d", resp.Status, resp.StatusCode)
+		}
+		return nil
+	}, backoffOpt)
+	if err != nil {
+		return adapter.ErrOAMRetry(err)
+	}
+	return nil
+}
+
+// registered reports whether the server accepted the registration
+func registered(resp *http.Response) bool {
+	return resp.StatusCode == http.StatusCreated || resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusAccepted
+}
+
+// readComponentDefinition decodes the component definition
+func readComponentDefinition(def meshmodelDefinitionPathSet) (v1alpha1.ComponentDefinition, error) {
+	var cd v1alpha1.ComponentDefinition
+	byt, err := def.read()
+	if err != nil {
+		return cd, adapter.ErrOpenOAMDefintionFile(err)
+	}
+	if err := json.Unmarshal(byt, &cd); err != nil {
+		return cd, adapter.ErrJSONMarshal(err)
+	}
+	return cd, nil
+}
+
+// enrichMetadata adds the configured metadata to the component without overwriting its keys
+func enrichMetadata(cd *v1alpha1.ComponentDefinition) {
+	extraMetadataMx.RLock()
+	defer extraMetadataMx.RUnlock()
+	if len(extraMetadata) == 0 {
+		return
+	}
+	if cd.Metadata == nil {
+		cd.Metadata = map[string]interface{}{}
+	}
+	for k, v := range extraMetadata {
+		if _, ok := cd.Metadata[k]; !ok {
+			cd.Metadata[k] = v
+		}
+	}
+}
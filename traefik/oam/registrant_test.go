@@ -0,0 +1,250 @@
+package oam
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/layer5io/meshkit/models/meshmodel"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1alpha1"
+)
+
+// fakeRegistry is a Meshery server recording the components registered with it
+type fakeRegistry struct {
+	mx sync.Mutex
+	// requests counts the registration requests, a batch counts once
+	requests int
+	// payloads are the number of registrations of each request
+	payloads []int
+	// hosts are the hosts of the registrations
+	hosts []meshmodel.Host
+	// components are the registered components in the order they were received
+	components []v1alpha1.ComponentDefinition
+	// rejectBatches answers the batched registrations with an error
+	rejectBatches bool
+}
+
+func (fr *fakeRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	byt, _ := io.ReadAll(r.Body)
+	fr.mx.Lock()
+	defer fr.mx.Unlock()
+	fr.requests++
+
+	var batch []meshmodel.MeshModelRegistrantData
+	if err := json.Unmarshal(byt, &batch); err != nil {
+		var single meshmodel.MeshModelRegistrantData
+		if err := json.Unmarshal(byt, &single); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		batch = append(batch, single)
+	}
+	fr.payloads = append(fr.payloads, len(batch))
+	if fr.rejectBatches && len(byt) > 0 && byt[0] == '[' {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	for _, mrd := range batch {
+		fr.hosts = append(fr.hosts, mrd.Host)
+		var cd v1alpha1.ComponentDefinition
+		if err := json.Unmarshal(mrd.Entity, &cd); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fr.components = append(fr.components, cd)
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+// kinds returns the kinds of the registered components in the order they were received
+func (fr *fakeRegistry) kinds() []string {
+	fr.mx.Lock()
+	defer fr.mx.Unlock()
+	kinds := make([]string, 0, len(fr.components))
+	for _, cd := range fr.components {
+		kinds = append(kinds, cd.Kind)
+	}
+	return kinds
+}
+
+// newTestRegistrant returns a registrant of the definitions, keyed by path, registering with the registry
+func newTestRegistrant(t *testing.T, registry *fakeRegistry, definitions map[string]string) *registrant {
+	t.Helper()
+	srv := httptest.NewServer(registry)
+	t.Cleanup(srv.Close)
+
+	fsys := fstest.MapFS{}
+	paths := make([]string, 0, len(definitions))
+	for p, def := range definitions {
+		fsys[p] = &fstest.MapFile{Data: []byte(def)}
+		paths = append(paths, p)
+	}
+	// the filesystem order
+	sort.Strings(paths)
+	r := &registrant{host: "traefik-mesh", port: 10006, httpRegistry: srv.URL + "/api/meshmodel/components/register"}
+	for _, p := range paths {
+		r.definitions = append(r.definitions, meshmodelDefinitionPathSet{meshmodelDefinitionPath: p, fsys: fsys})
+	}
+	return r
+}
+
+func componentJSON(kind string) string {
+	return fmt.Sprintf(`{"kind":%q,"apiVersion":"core.oam.dev/v1alpha1","displayName":%q,"metadata":{}}`, kind, kind)
+}
+
+func TestRegistrationCarriesComponentMetadata(t *testing.T) {
+	SetComponentMetadata(map[string]string{
+		"team":                    "platform",
+		"environment":             "staging",
+		"adapter.meshery.io/name": "custom-adapter",
+	})
+	t.Cleanup(func() { SetComponentMetadata(map[string]string{}) })
+
+	registry := &fakeRegistry{}
+	r := newTestRegistrant(t, registry, map[string]string{
+		"v1.4.8/traefikmesh.meshery.layer5.io_meshmodel.json":  `{"kind":"TraefikMesh","apiVersion":"core.oam.dev/v1alpha1","metadata":{"adapter.meshery.io/name":"traefik-mesh","category":"addon"}}`,
+		"v1.4.8/trafficsplit.meshery.layer5.io_meshmodel.json": `{"kind":"TrafficSplit","apiVersion":"split.smi-spec.io/v1alpha4"}`,
+	})
+	if _, err := r.register("ctx-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(registry.components) != 2 {
+		t.Fatalf("expected 2 registered components, got %d", len(registry.components))
+	}
+
+	tests := []struct {
+		kind     string
+		metadata map[string]interface{}
+	}{
+		{
+			// the keys set by the definition are kept
+			kind: "TraefikMesh",
+			metadata: map[string]interface{}{
+				"adapter.meshery.io/name": "traefik-mesh",
+				"category":                "addon",
+				"team":                    "platform",
+				"environment":             "staging",
+			},
+		},
+		{
+			kind: "TrafficSplit",
+			metadata: map[string]interface{}{
+				"adapter.meshery.io/name": "custom-adapter",
+				"team":                    "platform",
+				"environment":             "staging",
+			},
+		},
+	}
+	for i, tt := range tests {
+		cd := registry.components[i]
+		if cd.Kind != tt.kind {
+			t.Fatalf("expected the component %s, got %s", tt.kind, cd.Kind)
+		}
+		if !reflect.DeepEqual(cd.Metadata, tt.metadata) {
+			t.Errorf("%s: expected the metadata %v, got %v", tt.kind, tt.metadata, cd.Metadata)
+		}
+	}
+}
+
+func TestRegistrationBatching(t *testing.T) {
+	definitions := map[string]string{}
+	for _, kind := range []string{"A", "B", "C", "D", "E"} {
+		definitions["v1.4.8/"+kind+".json"] = componentJSON(kind)
+	}
+	tests := []struct {
+		name          string
+		batchSize     int
+		rejectBatches bool
+		payloads      []int
+	}{
+		{name: "disabled", batchSize: 0, payloads: []int{1, 1, 1, 1, 1}},
+		{name: "batches of one", batchSize: 1, payloads: []int{1, 1, 1, 1, 1}},
+		{name: "batches with a remainder", batchSize: 2, payloads: []int{2, 2, 1}},
+		{name: "a single batch", batchSize: 10, payloads: []int{5}},
+		{
+			// the rejected batch is sent again one by one and batching stops
+			name:          "unsupported by the server",
+			batchSize:     2,
+			rejectBatches: true,
+			payloads:      []int{2, 1, 1, 1, 1, 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := &fakeRegistry{rejectBatches: tt.rejectBatches}
+			r := newTestRegistrant(t, registry, definitions)
+			r.batchSize = tt.batchSize
+			count, err := r.register("ctx-1")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if count != 5 {
+				t.Errorf("expected 5 registered components, got %d", count)
+			}
+			if !reflect.DeepEqual(registry.payloads, tt.payloads) {
+				t.Errorf("expected the payloads %v, got %v", tt.payloads, registry.payloads)
+			}
+			if kinds := registry.kinds(); !reflect.DeepEqual(kinds, []string{"A", "B", "C", "D", "E"}) {
+				t.Errorf("expected the components in order, got %v", kinds)
+			}
+			want := meshmodel.Host{Hostname: "traefik-mesh", Port: 10006, ContextID: "ctx-1"}
+			for _, host := range registry.hosts {
+				if host != want {
+					t.Errorf("expected the host %+v, got %+v", want, host)
+				}
+			}
+		})
+	}
+}
+
+func TestRegistrationBackoffCap(t *testing.T) {
+	var mx sync.Mutex
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mx.Lock()
+		defer mx.Unlock()
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	t.Cleanup(func() { SetRegistrationBackoffCap(0) })
+
+	tests := []struct {
+		cap     time.Duration
+		retried bool
+	}{
+		// the first retry, after half a second at least, is already past the cap
+		{cap: 100 * time.Millisecond},
+		{cap: 2 * time.Second, retried: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cap.String(), func(t *testing.T) {
+			SetRegistrationBackoffCap(tt.cap)
+			mx.Lock()
+			requests = 0
+			mx.Unlock()
+
+			r := &registrant{host: "traefik-mesh", port: 10006, httpRegistry: srv.URL}
+			start := time.Now()
+			if err := r.post(meshmodel.MeshModelRegistrantData{}); err == nil {
+				t.Fatal("expected the registration to fail")
+			}
+			if elapsed := time.Since(start); elapsed > tt.cap+time.Second {
+				t.Fatalf("retried for %s past the cap of %s", elapsed, tt.cap)
+			}
+			mx.Lock()
+			defer mx.Unlock()
+			if (requests > 1) != tt.retried {
+				t.Fatalf("expected retried %v, got %d requests", tt.retried, requests)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package oam
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ReloadResult reports the outcome of reloading the meshmodel components from the filesystem
+type ReloadResult struct {
+	Components int      `json:"components"`
+	Versions   []string `json:"versions"`
+	Added      []string `json:"added,omitempty"`
+	Changed    []string `json:"changed,omitempty"`
+	Removed    []string `json:"removed,omitempty"`
+	Errors     []string `json:"errors,omitempty"`
+	Registered bool     `json:"registered"`
+}
+
+// componentDefinition is the subset of a meshmodel component definition validated on reload
+type componentDefinition struct {
+	Kind       string `json:"kind"`
+	APIVersion string `json:"apiVersion"`
+	Schema     string `json:"schema"`
+}
+
+// registrationArgs are the arguments of the last registration with Meshery server
+type registrationArgs struct {
+	uuid, runtime, host, port string
+}
+
+var (
+	// loadedDefinitions maps the path of every loaded definition to its checksum
+	loadedDefinitions = map[string]string{}
+	reloadMx          sync.Mutex
+
+	lastRegistration *registrationArgs
+	registrationMx   sync.Mutex
+)
+
+// Reload loads the meshmodel components from the filesystem again, validates them and
+// refreshes the available versions. The components are registered again with Meshery
+// server when they have been registered before and none of them is invalid.
+func Reload() (ReloadResult, error) {
+	res, err := reloadDefinitions()
+	if err != nil {
+		return res, err
+	}
+
+	registrationMx.Lock()
+	args := lastRegistration
+	registrationMx.Unlock()
+	if args != nil {
+		if err := RegisterMeshModelComponents(args.uuid, args.runtime, args.host, args.port); err != nil {
+			return res, ErrReloadComponents(err)
+		}
+		RecordRegistration(StaticRegistration, time.Now())
+		res.Registered = true
+	}
+	return res, nil
+}
+
+// reloadDefinitions validates the definitions on the filesystem and refreshes the available versions
+func reloadDefinitions() (ReloadResult, error) {
+	reloadMx.Lock()
+	defer reloadMx.Unlock()
+
+	res := ReloadResult{Versions: []string{}}
+	definitions := map[string]string{}
+	versions := map[string]bool{}
+	pathSets, err := listMeshmodelDefinitions(MeshmodelComponents)
+	if err != nil {
+		return res, ErrReloadComponents(err)
+	}
+	for _, pathSet := range pathSets {
+		rel := pathSet.meshmodelDefinitionPath
+		byt, err := pathSet.read()
+		if err != nil {
+			res.Errors = append(res.Errors, fmt.Sprintf("%s: %v", rel, err))
+			continue
+		}
+		if err := validateDefinition(byt); err != nil {
+			res.Errors = append(res.Errors, fmt.Sprintf("%s: %v", rel, err))
+			continue
+		}
+
+		sum := sha256.Sum256(byt)
+		definitions[rel] = hex.EncodeToString(sum[:])
+		versions[pathSet.version()] = true
+	}
+
+	for rel, sum := range definitions {
+		prev, ok := loadedDefinitions[rel]
+		switch {
+		case !ok:
+			res.Added = append(res.Added, rel)
+		case prev != sum:
+			res.Changed = append(res.Changed, rel)
+		}
+	}
+	for rel := range loadedDefinitions {
+		if _, ok := definitions[rel]; !ok {
+			res.Removed = append(res.Removed, rel)
+		}
+	}
+	sort.Strings(res.Added)
+	sort.Strings(res.Changed)
+	sort.Strings(res.Removed)
+	res.Components = len(definitions)
+	for v := range versions {
+		res.Versions = append(res.Versions, v)
+	}
+	sort.Strings(res.Versions)
+
+	if len(res.Errors) != 0 {
+		return res, ErrReloadComponents(fmt.Errorf("invalid component definitions: %s", strings.Join(res.Errors, "; ")))
+	}
+
+	loadedDefinitions = definitions
+	availableVersionGlobalMutex.Lock()
+	for v := range AvailableVersions {
+		if !versions[v] {
+			delete(AvailableVersions, v)
+		}
+	}
+	for v := range versions {
+		AvailableVersions[v] = true
+	}
+	availableVersionGlobalMutex.Unlock()
+
+	return res, nil
+}
+
+// recordLoadedDefinition records the checksum of a definition loaded for registration
+// so that the next reload reports the definitions changed since
+func recordLoadedDefinition(def meshmodelDefinitionPathSet) {
+	byt, err := def.read()
+	if err != nil {
+		return
+	}
+	sum := sha256.Sum256(byt)
+	reloadMx.Lock()
+	loadedDefinitions[def.meshmodelDefinitionPath] = hex.EncodeToString(sum[:])
+	reloadMx.Unlock()
+}
+
+// validateDefinition checks that the component definition can be registered
+func validateDefinition(byt []byte) error {
+	var def componentDefinition
+	if err := json.Unmarshal(byt, &def); err != nil {
+		return err
+	}
+	if def.Kind == "" || def.APIVersion == "" {
+		return fmt.Errorf("kind and apiVersion are required")
+	}
+	if err := validateSchema(def.Schema); err != nil {
+		return fmt.Errorf("schema of %s is not a valid JSON Schema: %v", def.Kind, err)
+	}
+	return nil
+}
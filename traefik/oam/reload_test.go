@@ -0,0 +1,87 @@
+package oam
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/layer5io/meshkit/errors"
+)
+
+func writeDefinition(t *testing.T, dir, rel, content string) {
+	t.Helper()
+	p := filepath.Join(dir, rel)
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReloadPicksUpModifiedDefinition(t *testing.T) {
+	dir := t.TempDir()
+	prev := MeshmodelComponents
+	MeshmodelComponents = dir
+	t.Cleanup(func() { MeshmodelComponents = prev })
+
+	const rel = "v9.9.9/MeshFixture.json"
+	writeDefinition(t, dir, rel, `{"kind":"MeshFixture","apiVersion":"core.oam.dev/v1alpha1"}`)
+	res, err := Reload()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !contains(res.Added, rel) || !contains(res.Versions, "v9.9.9") || !AvailableVersions["v9.9.9"] {
+		t.Fatalf("expected the fixture and its version to be added, got %+v", res)
+	}
+
+	steps := []struct {
+		name    string
+		content string
+		changed bool
+		invalid bool
+	}{
+		{name: "unchanged"},
+		{name: "schema added", content: `{"kind":"MeshFixture","apiVersion":"core.oam.dev/v1alpha1","schema":"{\"type\":\"object\"}"}`, changed: true},
+		{name: "invalid schema", content: `{"kind":"MeshFixture","apiVersion":"core.oam.dev/v1alpha1","schema":"{\"type\":\"blob\"}"}`, invalid: true},
+		{name: "fixed after the invalid reload", content: `{"kind":"MeshFixture","apiVersion":"core.oam.dev/v1alpha2","schema":"{\"type\":\"object\"}"}`, changed: true},
+	}
+	for _, st := range steps {
+		if st.content != "" {
+			writeDefinition(t, dir, rel, st.content)
+		}
+		res, err := Reload()
+		if st.invalid {
+			if code := errors.GetCode(err); code != ErrReloadComponentsCode || len(res.Errors) != 1 {
+				t.Fatalf("%s: expected the definition to be reported invalid, got %v and %v", st.name, err, res.Errors)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", st.name, err)
+		}
+		if contains(res.Changed, rel) != st.changed || len(res.Added) != 0 || res.Registered {
+			t.Fatalf("%s: expected changed to be %v, got %+v", st.name, st.changed, res)
+		}
+	}
+
+	if err := os.RemoveAll(filepath.Join(dir, "v9.9.9")); err != nil {
+		t.Fatal(err)
+	}
+	res, err = Reload()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !contains(res.Removed, rel) || AvailableVersions["v9.9.9"] {
+		t.Fatalf("expected the fixture and its version to be removed, got %+v", res)
+	}
+}
+
+func contains(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package oam
+
+import (
+	"sync/atomic"
+
+	"github.com/layer5io/meshkit/logger"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1alpha1"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+var (
+	// failOnInvalidSchema fails the registration on a component with an invalid schema,
+	// the component is skipped with a warning otherwise
+	failOnInvalidSchema atomic.Bool
+
+	// registrationLogger logs the components skipped during the registration
+	registrationLogger atomic.Value
+)
+
+// SetFailOnInvalidSchema decides whether a component with an invalid schema fails the registration
+// or is skipped with a warning
+func SetFailOnInvalidSchema(fail bool) {
+	failOnInvalidSchema.Store(fail)
+}
+
+// SetLogger sets the logger warning about the components skipped during the registration
+func SetLogger(l logger.Handler) {
+	registrationLogger.Store(l)
+}
+
+// warn logs the error if a logger is set
+func warn(err error) {
+	if l, ok := registrationLogger.Load().(logger.Handler); ok {
+		l.Warn(err)
+	}
+}
+
+// validateSchema checks that the schema of the component, if any, is a valid JSON Schema
+func validateSchema(schema string) error {
+	if schema == "" {
+		return nil
+	}
+	sl := gojsonschema.NewSchemaLoader()
+	sl.Validate = true
+	_, err := sl.Compile(gojsonschema.NewStringLoader(schema))
+	return err
+}
+
+// checkComponentSchema validates the schema of the component definition at path. It reports whether
+// the component should be registered, an invalid one is skipped unless the registration must fail.
+func checkComponentSchema(cd v1alpha1.ComponentDefinition, path string) (bool, error) {
+	err := validateSchema(cd.Schema)
+	if err == nil {
+		return true, nil
+	}
+	err = ErrInvalidComponentSchema(cd.Kind, path, err)
+	if failOnInvalidSchema.Load() {
+		return false, err
+	}
+	warn(err)
+	return false, nil
+}
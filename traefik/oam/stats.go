@@ -0,0 +1,85 @@
+package oam
+
+import (
+	"sync"
+	"time"
+
+	"github.com/layer5io/meshery-traefik-mesh/internal/metrics"
+)
+
+// RegistrationKind distinguishes the registration of the static components
+// from the periodic registration of the dynamically generated ones
+type RegistrationKind string
+
+const (
+	// StaticRegistration is the registration of the components shipped with the adapter
+	StaticRegistration RegistrationKind = "static"
+
+	// DynamicRegistration is the registration of the components generated at runtime
+	DynamicRegistration RegistrationKind = "dynamic"
+)
+
+// RegistrationStats summarizes the registrations performed by the adapter
+type RegistrationStats struct {
+	LastStatic  *time.Time `json:"last_static_registration,omitempty"`
+	LastDynamic *time.Time `json:"last_dynamic_registration,omitempty"`
+	Cycles      int        `json:"registration_cycles"`
+	// LastFailure is the time of the latest failed registration, LastError its cause
+	LastFailure *time.Time `json:"last_registration_failure,omitempty"`
+	LastError   string     `json:"last_registration_error,omitempty"`
+}
+
+var (
+	registrationStats   RegistrationStats
+	registrationStatsMx sync.RWMutex
+)
+
+// RecordRegistration records a successful registration of the given kind at time t
+func RecordRegistration(kind RegistrationKind, t time.Time) {
+	metrics.ObserveRegistration(string(kind), nil)
+	registrationStatsMx.Lock()
+	defer registrationStatsMx.Unlock()
+	switch kind {
+	case StaticRegistration:
+		registrationStats.LastStatic = &t
+	case DynamicRegistration:
+		registrationStats.LastDynamic = &t
+	}
+}
+
+// RecordRegistrationFailure records a failed registration of the given kind at time t
+func RecordRegistrationFailure(kind RegistrationKind, err error, t time.Time) {
+	metrics.ObserveRegistration(string(kind), err)
+	registrationStatsMx.Lock()
+	defer registrationStatsMx.Unlock()
+	registrationStats.LastFailure = &t
+	registrationStats.LastError = err.Error()
+}
+
+// Failing reports whether the latest registration failed
+func (rs RegistrationStats) Failing() bool {
+	if rs.LastFailure == nil {
+		return false
+	}
+	for _, t := range []*time.Time{rs.LastStatic, rs.LastDynamic} {
+		if t != nil && !t.Before(*rs.LastFailure) {
+			return false
+		}
+	}
+	return true
+}
+
+// RecordRegistrationCycle records the completion of a dynamic registration cycle,
+// whether or not it registered any components
+func RecordRegistrationCycle() {
+	registrationStatsMx.Lock()
+	defer registrationStatsMx.Unlock()
+	registrationStats.Cycles++
+}
+
+// GetRegistrationStats returns a copy of the registration stats
+func GetRegistrationStats() RegistrationStats {
+	registrationStatsMx.RLock()
+	defer registrationStatsMx.RUnlock()
+	return registrationStats
+}
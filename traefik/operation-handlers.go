@@ -0,0 +1,651 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshery-adapter-library/common"
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	"github.com/layer5io/meshery-adapter-library/status"
+	internalconfig "github.com/layer5io/meshery-traefik-mesh/internal/config"
+	"github.com/layer5io/meshery-traefik-mesh/traefik/oam"
+)
+
+// operationRequest is an operation request along with what ApplyOperation resolved for it
+type operationRequest struct {
+	adapter.OperationRequest
+
+	// namespace is the validated namespace of the request, the default one when it has none
+	namespace string
+	// kubeconfigs are the kubeconfigs of the clusters the operation runs against
+	kubeconfigs []string
+	// operations are the operations registered in the config
+	operations adapter.Operations
+	// event carries the outcome of the operation
+	event *meshes.EventsResponse
+}
+
+// operationFunc runs an operation and returns its result, which is streamed as JSON
+type operationFunc func(ctx context.Context, hh *Mesh, req *operationRequest) (interface{}, error)
+
+// operationHandler runs an operation requested through ApplyOperation
+type operationHandler struct {
+	run operationFunc
+	// parseErr summarizes the event streamed when the parameters are invalid, errMsg is used when it is empty
+	parseErr string
+	// errMsg summarizes the event streamed when the operation fails
+	errMsg string
+	// successMsg summarizes the event streamed along with the result
+	successMsg string
+	// immediate operations run right away, not queued behind the operations they act on
+	immediate bool
+}
+
+// paramsError is returned by the operations whose parameters are invalid
+type paramsError struct {
+	err error
+}
+
+func (pe paramsError) Error() string {
+	return pe.err.Error()
+}
+
+// failure overrides the summary of the event streamed when an operation fails
+type failure struct {
+	summary string
+	err     error
+}
+
+func (f failure) Error() string {
+	return f.err.Error()
+}
+
+// outcome overrides the summary of the event streamed when an operation succeeds. The details are
+// streamed as they are, or the result is streamed as JSON when it is set.
+type outcome struct {
+	summary string
+	details string
+	result  interface{}
+}
+
+// latestVersion returns the latest version of traefik mesh offered for the operation, the versions are
+// resolved when the operation has none yet. It fails when none could be resolved.
+func (req *operationRequest) latestVersion(ctx context.Context) (string, error) {
+	if op, ok := req.operations[req.OperationName]; ok && op != nil && len(op.Versions) != 0 {
+		return string(op.Versions[0]), nil
+	}
+	versions, err := internalconfig.LatestVersions(ctx)
+	if err == nil && len(versions) == 0 {
+		err = fmt.Errorf("no version was resolved")
+	}
+	if err != nil {
+		return "", ErrNoVersion(req.OperationName, err)
+	}
+	return string(versions[0]), nil
+}
+
+// withParams returns the operation running fn with the parameters decoded from the custom body
+func withParams[P any](fn func(ctx context.Context, hh *Mesh, req *operationRequest, params P) (interface{}, error)) operationFunc {
+	return func(ctx context.Context, hh *Mesh, req *operationRequest) (interface{}, error) {
+		var params P
+		if err := parseOperationParams(req.CustomBody, &params); err != nil {
+			return nil, paramsError{err}
+		}
+		return fn(ctx, hh, req, params)
+	}
+}
+
+// handleOperation runs the operation and streams its outcome
+func (mesh *Mesh) handleOperation(ctx context.Context, h operationHandler, req *operationRequest) {
+	res, err := h.run(ctx, mesh, req)
+	if err != nil && ctx.Err() == context.Canceled {
+		// the failure follows the cancel operation, the cancellation is the outcome of the operation
+		err = failure{summary: "Operation cancelled", err: ErrOperationCancelled(req.OperationName, req.OperationID)}
+	}
+	switch err := err.(type) {
+	case nil:
+	case paramsError:
+		summary := h.parseErr
+		if summary == "" {
+			summary = h.errMsg
+		}
+		mesh.streamErr(summary, req.event, err.err)
+		return
+	case failure:
+		mesh.streamErr(err.summary, req.event, err.err)
+		return
+	default:
+		mesh.streamErr(h.errMsg, req.event, err)
+		return
+	}
+
+	o, ok := res.(outcome)
+	switch {
+	case !ok:
+		mesh.streamResult(h.successMsg, req.event, res)
+	case o.result != nil:
+		mesh.streamResult(o.summary, req.event, o.result)
+	default:
+		req.event.Summary = o.summary
+		req.event.Details = o.details
+		mesh.StreamInfo(req.event)
+	}
+}
+
+// operationHandlers are the handlers of the operations served by the adapter, by operation name
+var operationHandlers = map[string]operationHandler{
+	internalconfig.TraefikMeshOperation: {
+		run:      withParams(installOperation),
+		parseErr: "Error while parsing Traefik service mesh install parameters",
+	},
+	common.BookInfoOperation:  sampleAppHandler,
+	common.HTTPBinOperation:   sampleAppHandler,
+	common.ImageHubOperation:  sampleAppHandler,
+	common.EmojiVotoOperation: sampleAppHandler,
+	common.CustomOperation: {
+		run: func(ctx context.Context, hh *Mesh, req *operationRequest) (interface{}, error) {
+			stat, err := hh.applyCustomOperation(req.namespace, req.CustomBody, req.IsDeleteOperation, req.kubeconfigs)
+			if err != nil {
+				return nil, failure{fmt.Sprintf("Error while %s custom operation", stat), err}
+			}
+			return outcome{summary: fmt.Sprintf("Manifest %s successfully", status.Deployed)}, nil
+		},
+	},
+	common.SmiConformanceOperation: {
+		run: smiConformanceOperation,
+	},
+	internalconfig.ProxyMetricsDiffOperation: {
+		errMsg:     "Error while comparing proxy metrics",
+		successMsg: "Proxy metrics compared successfully",
+		run: withParams(func(ctx context.Context, hh *Mesh, req *operationRequest, params proxyMetricsDiffParams) (interface{}, error) {
+			return hh.compareProxyMetrics(ctx, params, req.kubeconfigs, func(window time.Duration) {
+				hh.streamUpdate(req.event, "Proxy metrics baseline captured", fmt.Sprintf("Waiting %s for the change to take effect", window))
+			})
+		}),
+	},
+	internalconfig.TrafficSplitValidationOperation: {
+		errMsg:     "Error while validating TrafficSplits",
+		successMsg: "TrafficSplits validated successfully",
+		run: withParams(func(ctx context.Context, hh *Mesh, req *operationRequest, params trafficSplitValidationParams) (interface{}, error) {
+			return hh.validateTrafficSplits(ctx, params, req.kubeconfigs)
+		}),
+	},
+	internalconfig.AdapterStatusOperation: {
+		successMsg: "Adapter status retrieved successfully",
+		run: func(ctx context.Context, hh *Mesh, req *operationRequest) (interface{}, error) {
+			return hh.status(time.Now()), nil
+		},
+	},
+	internalconfig.AdmissionPreflightOperation: {
+		errMsg:     "Error while running the admission preflight",
+		successMsg: "Admission preflight completed successfully",
+		run: func(ctx context.Context, hh *Mesh, req *operationRequest) (interface{}, error) {
+			version, err := req.latestVersion(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return hh.admissionPreflight(ctx, version, req.namespace, req.kubeconfigs)
+		},
+	},
+	internalconfig.CompatibilityPreflightOperation: {
+		errMsg:     "Error while running the compatibility preflight",
+		successMsg: "Compatibility preflight completed successfully",
+		run: func(ctx context.Context, hh *Mesh, req *operationRequest) (interface{}, error) {
+			version, err := req.latestVersion(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return hh.compatibilityPreflight(ctx, version, req.kubeconfigs)
+		},
+	},
+	internalconfig.ReloadComponentsOperation: {
+		errMsg:     "Error while reloading meshmodel components",
+		successMsg: "Meshmodel components reloaded successfully",
+		run: func(ctx context.Context, hh *Mesh, req *operationRequest) (interface{}, error) {
+			return oam.Reload()
+		},
+	},
+	internalconfig.SplitCoverageOperation: {
+		errMsg:     "Error while computing TrafficSplit coverage",
+		successMsg: "TrafficSplit coverage computed successfully",
+		run: withParams(func(ctx context.Context, hh *Mesh, req *operationRequest, params splitCoverageParams) (interface{}, error) {
+			return hh.splitCoverage(ctx, params, req.kubeconfigs)
+		}),
+	},
+	internalconfig.ControllerConnectivityOperation: {
+		errMsg:     "Error while checking proxy to controller connectivity",
+		successMsg: "Proxy to controller connectivity checked successfully",
+		run: func(ctx context.Context, hh *Mesh, req *operationRequest) (interface{}, error) {
+			return hh.checkControllerConnectivity(ctx, req.kubeconfigs)
+		},
+	},
+	internalconfig.DeprecatedUsageOperation: {
+		errMsg:     "Error while scanning for deprecated SMI API versions",
+		successMsg: "Deprecated SMI API version usage reported successfully",
+		run: func(ctx context.Context, hh *Mesh, req *operationRequest) (interface{}, error) {
+			return hh.reportDeprecatedUsage(ctx, req.kubeconfigs)
+		},
+	},
+	internalconfig.ExportEventsOperation: {
+		errMsg:     "Error while exporting operation events",
+		successMsg: "Operation events exported successfully",
+		run: withParams(func(ctx context.Context, hh *Mesh, req *operationRequest, params exportEventsParams) (interface{}, error) {
+			return hh.exportEvents(params)
+		}),
+	},
+	internalconfig.NamespaceLabelsOperation: {
+		errMsg:     "Error while checking the mesh namespace labels",
+		successMsg: "Mesh namespace labels checked successfully",
+		run: withParams(func(ctx context.Context, hh *Mesh, req *operationRequest, params namespaceLabelsParams) (interface{}, error) {
+			return hh.checkNamespaceLabels(ctx, req.namespace, params, req.kubeconfigs)
+		}),
+	},
+	internalconfig.CanaryAnalysisOperation: {
+		errMsg:     "Error while analysing the canary",
+		successMsg: "Canary analysed successfully",
+		run: withParams(func(ctx context.Context, hh *Mesh, req *operationRequest, params canaryAnalysisParams) (interface{}, error) {
+			return hh.analyseCanary(ctx, params, req.kubeconfigs, func(window time.Duration) {
+				hh.streamUpdate(req.event, "Canary baseline captured", fmt.Sprintf("Observing the backends for %s", window))
+			})
+		}),
+	},
+	internalconfig.RestartReportOperation: {
+		errMsg:     "Error while reporting the mesh pod restarts",
+		successMsg: "Mesh pod restarts reported successfully",
+		run: withParams(func(ctx context.Context, hh *Mesh, req *operationRequest, params restartReportParams) (interface{}, error) {
+			return hh.reportRestarts(ctx, params, req.kubeconfigs)
+		}),
+	},
+	internalconfig.IPFamilyOperation: {
+		errMsg:     "Error while checking the shadow services IP families",
+		successMsg: "Shadow services IP families checked successfully",
+		run: func(ctx context.Context, hh *Mesh, req *operationRequest) (interface{}, error) {
+			return hh.checkIPFamilies(ctx, req.kubeconfigs)
+		},
+	},
+	internalconfig.ReadinessOperation: {
+		errMsg:     "Error while checking the mesh readiness",
+		successMsg: "Mesh readiness checked successfully",
+		run: withParams(func(ctx context.Context, hh *Mesh, req *operationRequest, params readinessParams) (interface{}, error) {
+			return hh.checkReadiness(ctx, req.namespace, params, req.kubeconfigs)
+		}),
+	},
+	internalconfig.CapabilitiesOperation: {
+		errMsg:     "Error while listing the adapter capabilities",
+		successMsg: "Adapter capabilities listed successfully",
+		run: func(ctx context.Context, hh *Mesh, req *operationRequest) (interface{}, error) {
+			return hh.Capabilities()
+		},
+	},
+	internalconfig.MiddlewareOrderOperation: {
+		errMsg:     "Error while validating the middleware order",
+		successMsg: "Middleware order validated successfully",
+		run: withParams(func(ctx context.Context, hh *Mesh, req *operationRequest, params middlewareOrderParams) (interface{}, error) {
+			return hh.validateMiddlewareOrder(ctx, req.namespace, params, req.kubeconfigs)
+		}),
+	},
+	internalconfig.DrainTestOperation: {
+		errMsg:     "Error while testing the connection draining",
+		successMsg: "Connection draining tested successfully",
+		run: withParams(func(ctx context.Context, hh *Mesh, req *operationRequest, params drainTestParams) (interface{}, error) {
+			return hh.testConnectionDraining(ctx, req.namespace, params, req.kubeconfigs)
+		}),
+	},
+	internalconfig.SplitPortsOperation: {
+		errMsg:     "Error while verifying the TrafficSplit backend ports",
+		successMsg: "TrafficSplit backend ports verified successfully",
+		run: withParams(func(ctx context.Context, hh *Mesh, req *operationRequest, params splitPortsParams) (interface{}, error) {
+			return hh.verifySplitPorts(ctx, req.namespace, params, req.kubeconfigs)
+		}),
+	},
+	internalconfig.SMICountsOperation: {
+		errMsg:     "Error while counting the SMI resources",
+		successMsg: "SMI resources counted successfully",
+		run: func(ctx context.Context, hh *Mesh, req *operationRequest) (interface{}, error) {
+			return hh.reportResourceCounts(ctx, req.kubeconfigs)
+		},
+	},
+	internalconfig.PortNamesOperation: {
+		errMsg:     "Error while checking the service port names",
+		successMsg: "Service port names checked successfully",
+		run: withParams(func(ctx context.Context, hh *Mesh, req *operationRequest, params portNamesParams) (interface{}, error) {
+			// without a namespace, the services of every namespace are checked
+			return hh.checkPortNames(ctx, req.Namespace, params, req.kubeconfigs)
+		}),
+	},
+	internalconfig.StaleConfigOperation: {
+		errMsg:     "Error while comparing the proxy configurations",
+		successMsg: "Proxy configurations compared successfully",
+		run: func(ctx context.Context, hh *Mesh, req *operationRequest) (interface{}, error) {
+			return hh.reportStaleProxies(ctx, req.kubeconfigs)
+		},
+	},
+	internalconfig.CNICompatibilityOperation: {
+		errMsg:     "Error while checking the CNI compatibility",
+		successMsg: "CNI compatibility checked successfully",
+		run: func(ctx context.Context, hh *Mesh, req *operationRequest) (interface{}, error) {
+			return hh.checkCNICompatibility(ctx, req.kubeconfigs)
+		},
+	},
+	internalconfig.OrphansOperation: {
+		errMsg:     "Error while looking for orphaned SMI resources",
+		successMsg: "Orphaned SMI resources reported successfully",
+		run: withParams(func(ctx context.Context, hh *Mesh, req *operationRequest, params orphansParams) (interface{}, error) {
+			return hh.reportOrphans(ctx, params, req.kubeconfigs)
+		}),
+	},
+	internalconfig.RouteTraceOperation: {
+		errMsg:     "Error while tracing the routing of the request",
+		successMsg: "Routing of the request traced successfully",
+		run: withParams(func(ctx context.Context, hh *Mesh, req *operationRequest, params routeTraceParams) (interface{}, error) {
+			return hh.traceRoute(ctx, req.namespace, params, req.kubeconfigs)
+		}),
+	},
+	internalconfig.ConfigValidationOperation: {
+		successMsg: "Adapter configuration validated successfully",
+		run: func(ctx context.Context, hh *Mesh, req *operationRequest) (interface{}, error) {
+			return validateConfig(), nil
+		},
+	},
+	internalconfig.SelectorLinkageOperation: {
+		errMsg:     "Error while checking the shadow service selectors",
+		successMsg: "Shadow service selectors checked successfully",
+		run: withParams(func(ctx context.Context, hh *Mesh, req *operationRequest, params selectorLinkageParams) (interface{}, error) {
+			return hh.checkSelectorLinkage(ctx, params, req.kubeconfigs)
+		}),
+	},
+	internalconfig.PruneFilesOperation: {
+		errMsg:     "Error while pruning the stored files",
+		successMsg: "Stored files pruned successfully",
+		run: func(ctx context.Context, hh *Mesh, req *operationRequest) (interface{}, error) {
+			return PruneStoredFiles(time.Now())
+		},
+	},
+	internalconfig.TraefikMeshUninstallOperation: {
+		parseErr: "Error while parsing the Traefik service mesh uninstall parameters",
+		run:      withParams(uninstallOperation),
+	},
+	internalconfig.TrafficSplitOperation: {
+		parseErr:   "Error while parsing the TrafficSplit parameters",
+		errMsg:     "Error while applying the TrafficSplit",
+		successMsg: "TrafficSplit applied successfully",
+		run: withParams(func(ctx context.Context, hh *Mesh, req *operationRequest, params trafficSplitParams) (interface{}, error) {
+			return hh.applyTrafficSplit(ctx, req.IsDeleteOperation, req.namespace, params, req.kubeconfigs)
+		}),
+	},
+	internalconfig.BookInfoOperation:         embeddedSampleHandler,
+	internalconfig.BookInfoTeardownOperation: embeddedSampleHandler,
+	internalconfig.HTTPBinOperation:          embeddedSampleHandler,
+	internalconfig.OperationHistoryOperation: {
+		successMsg: "Operation history retrieved successfully",
+		run: func(ctx context.Context, hh *Mesh, req *operationRequest) (interface{}, error) {
+			return hh.OperationHistory(), nil
+		},
+	},
+	internalconfig.TrafficTargetOperation: {
+		parseErr:   "Error while parsing the TrafficTarget parameters",
+		errMsg:     "Error while applying the TrafficTarget",
+		successMsg: "TrafficTarget applied successfully",
+		run: withParams(func(ctx context.Context, hh *Mesh, req *operationRequest, params trafficTargetParams) (interface{}, error) {
+			return hh.applyTrafficTarget(ctx, req.IsDeleteOperation, req.namespace, params, req.kubeconfigs)
+		}),
+	},
+	internalconfig.HTTPRouteGroupOperation: {
+		parseErr: "Error while parsing the HTTPRouteGroup parameters",
+		errMsg:   "Error while applying the HTTPRouteGroup",
+		run: withParams(func(ctx context.Context, hh *Mesh, req *operationRequest, params httpRouteGroupParams) (interface{}, error) {
+			res, err := hh.applyHTTPRouteGroup(ctx, req.IsDeleteOperation, req.namespace, params, req.kubeconfigs)
+			if err != nil {
+				return nil, err
+			}
+			return outcome{summary: fmt.Sprintf("HTTPRouteGroup %s applied successfully", res.Name), result: res}, nil
+		}),
+	},
+	internalconfig.ScaleOperation: {
+		parseErr:   "Error while parsing the scale parameters",
+		errMsg:     "Error while scaling Traefik service mesh",
+		successMsg: "Traefik service mesh scaled successfully",
+		run: withParams(func(ctx context.Context, hh *Mesh, req *operationRequest, params scaleParams) (interface{}, error) {
+			ctx, cancel, err := params.withTimeout(ctx)
+			if err != nil {
+				return nil, paramsError{err}
+			}
+			defer cancel()
+			return hh.scaleTraefikMesh(ctx, req.namespace, params, req.event, req.kubeconfigs)
+		}),
+	},
+	internalconfig.CancelOperation: {
+		parseErr:  "Error while parsing the cancel parameters",
+		errMsg:    "Error while cancelling the operation",
+		immediate: true,
+		run: withParams(func(ctx context.Context, hh *Mesh, req *operationRequest, params cancelParams) (interface{}, error) {
+			res, err := hh.cancelOperation(params)
+			if err != nil {
+				return nil, err
+			}
+			return outcome{summary: fmt.Sprintf("Operation %s cancelled", params.OperationID), result: res}, nil
+		}),
+	},
+	internalconfig.ProxyLogsOperation: {
+		parseErr:   "Error while parsing the proxy logs parameters",
+		errMsg:     "Error while fetching the proxy logs",
+		successMsg: "Proxy logs fetched successfully",
+		run: withParams(func(ctx context.Context, hh *Mesh, req *operationRequest, params proxyLogsParams) (interface{}, error) {
+			return hh.fetchProxyLogs(ctx, req.namespace, params, req.kubeconfigs)
+		}),
+	},
+	internalconfig.InstallableVersionsOperation: {
+		errMsg:     "Error while listing the installable Traefik Mesh versions",
+		successMsg: "Installable Traefik Mesh versions listed successfully",
+		run: func(ctx context.Context, hh *Mesh, req *operationRequest) (interface{}, error) {
+			return installableVersions(ctx)
+		},
+	},
+	internalconfig.UpgradeOperation: {
+		run:      withParams(upgradeOperation),
+		parseErr: "Error while parsing Traefik service mesh upgrade parameters",
+		errMsg:   "Error while upgrading Traefik service mesh",
+	},
+	internalconfig.RollbackOperation: {
+		parseErr:   "Error while parsing Traefik service mesh rollback parameters",
+		errMsg:     "Error while rolling back Traefik service mesh",
+		successMsg: "Traefik service mesh rolled back successfully",
+		run: withParams(func(ctx context.Context, hh *Mesh, req *operationRequest, params rollbackParams) (interface{}, error) {
+			ctx, cancel, err := params.withTimeout(ctx)
+			if err != nil {
+				return nil, paramsError{err}
+			}
+			defer cancel()
+			unlock, err := hh.lockNamespace(ctx, req.namespace, req.OperationName, params.NoWait, req.event)
+			if err != nil {
+				return nil, failure{"Namespace busy", err}
+			}
+			defer unlock()
+			return hh.rollbackTraefikMesh(ctx, req.namespace, params, req.kubeconfigs)
+		}),
+	},
+	internalconfig.SMIManifestOperation: {
+		parseErr: "Error while parsing the SMI manifest parameters",
+		errMsg:   "Error while applying the SMI manifest",
+		run: withParams(func(ctx context.Context, hh *Mesh, req *operationRequest, params smiManifestParams) (interface{}, error) {
+			res, err := hh.applySMIManifest(ctx, req.IsDeleteOperation, req.namespace, params, req.kubeconfigs)
+			if err != nil {
+				return nil, err
+			}
+			failed := 0
+			for _, r := range res {
+				// a result which is not a report has no failed documents to count
+				if report, ok := r.(smiManifestReport); ok {
+					failed += report.Failed
+				}
+			}
+			summary := "SMI manifest applied successfully"
+			if failed > 0 {
+				summary = fmt.Sprintf("SMI manifest partially applied, %d documents failed", failed)
+			}
+			return outcome{summary: summary, result: res}, nil
+		}),
+	},
+	internalconfig.MeshHealthOperation: {
+		errMsg:     "Error while validating the Traefik service mesh health",
+		successMsg: "Traefik service mesh health validated successfully",
+		run: func(ctx context.Context, hh *Mesh, req *operationRequest) (interface{}, error) {
+			return hh.validateMeshHealth(ctx, req.namespace, req.event, req.kubeconfigs)
+		},
+	},
+}
+
+// sampleAppHandler deploys the sample applications of the adapter library from their templates
+var sampleAppHandler = operationHandler{
+	run: func(ctx context.Context, hh *Mesh, req *operationRequest) (interface{}, error) {
+		op := req.operations[req.OperationName]
+		appName := op.AdditionalProperties[common.ServiceName]
+		stat, err := hh.installSampleApp(req.namespace, req.IsDeleteOperation, op.Templates, req.kubeconfigs)
+		if err != nil {
+			return nil, failure{fmt.Sprintf("Error while %s %s application", stat, appName), err}
+		}
+		return outcome{
+			summary: fmt.Sprintf("%s application %s successfully", appName, stat),
+			details: fmt.Sprintf("The %s application is now %s.", appName, stat),
+		}, nil
+	},
+}
+
+// embeddedSampleHandler deploys or removes the sample applications embedded in the adapter
+var embeddedSampleHandler = operationHandler{
+	run: func(ctx context.Context, hh *Mesh, req *operationRequest) (interface{}, error) {
+		app := embeddedSampleApps[req.operations[req.OperationName].AdditionalProperties[internalconfig.ServiceName]]
+		del := req.IsDeleteOperation || req.OperationName == internalconfig.BookInfoTeardownOperation
+		stat, done := status.Deploying, status.Deployed
+		if del {
+			stat, done = status.Removing, status.Removed
+		}
+		res, err := hh.deployEmbeddedSample(ctx, app, del, req.namespace, req.event, req.kubeconfigs)
+		if err != nil {
+			return nil, failure{fmt.Sprintf("Error while %s the %s application", stat, app.Name), err}
+		}
+		return outcome{summary: fmt.Sprintf("The %s application %s successfully", app.Name, done), result: res}, nil
+	},
+}
+
+// installOperation installs, or removes, traefik mesh in the namespace of the request
+func installOperation(ctx context.Context, hh *Mesh, req *operationRequest, params installParams) (interface{}, error) {
+	ctx, cancel, err := params.withTimeout(ctx)
+	if err != nil {
+		return nil, paramsError{err}
+	}
+	defer cancel()
+	if req.IsDeleteOperation {
+		return uninstallOperation(ctx, hh, req, params.namespaceLockParams)
+	}
+	mode, err := validateInstallMode(params.Mode)
+	if err != nil {
+		return nil, paramsError{err}
+	}
+	backend, err := validateInstallBackend(params.Backend)
+	if err != nil {
+		return nil, paramsError{err}
+	}
+	var version string
+	if params.Version != "" {
+		if version, err = resolvePinnedVersion(ctx, params.Version); err != nil {
+			return nil, failure{"Error while resolving the pinned Traefik service mesh version", err}
+		}
+	} else if version, err = req.latestVersion(ctx); err != nil {
+		return nil, failure{"Error while resolving the Traefik service mesh version", err}
+	}
+	if params.DryRun {
+		res, err := hh.dryRunTraefikMesh(ctx, version, req.namespace, params, req.kubeconfigs)
+		if err != nil {
+			return nil, failure{"Error while rendering Traefik service mesh (dry run)", err}
+		}
+		return outcome{summary: "Traefik service mesh rendered successfully (dry run), nothing was applied", result: res}, nil
+	}
+	unlock, err := hh.lockNamespace(ctx, req.namespace, req.OperationName, params.NoWait, req.event)
+	if err != nil {
+		return nil, failure{"Namespace busy", err}
+	}
+	defer unlock()
+	stat, decisions, inventory, err := hh.installTraefikMesh(ctx, version, req.namespace, params, req.event, req.kubeconfigs)
+	if err != nil {
+		return nil, failure{fmt.Sprintf("Error while %s Traefik service mesh", stat), err}
+	}
+	if stat == statusAlreadyInstalled {
+		return outcome{
+			summary: "Traefik service mesh already installed",
+			details: fmt.Sprintf("Traefik service mesh is already installed, nothing was applied.%s", describeDecisions(decisions)),
+		}, nil
+	}
+	return outcome{
+		summary: fmt.Sprintf("Traefik service mesh %s successfully", stat),
+		details: fmt.Sprintf("The Traefik service mesh %s is now %s in namespace %s in %s mode with the %s backend.%s%s", version, stat, req.namespace, mode, backend, describeDecisions(decisions), describeInventory(inventory)),
+	}, nil
+}
+
+// uninstallOperation removes traefik mesh from the namespace of the request, for the uninstall
+// operation and the removal requested through the install operation alike
+func uninstallOperation(ctx context.Context, hh *Mesh, req *operationRequest, params namespaceLockParams) (interface{}, error) {
+	unlock, err := hh.lockNamespace(ctx, req.namespace, req.OperationName, params.NoWait, req.event)
+	if err != nil {
+		return nil, failure{"Namespace busy", err}
+	}
+	defer unlock()
+	hh.streamUpdate(req.event, "Deleting Traefik service mesh", fmt.Sprintf("Removing the Traefik service mesh release from %d cluster(s)", len(req.kubeconfigs)))
+	res, err := hh.uninstallTraefikMesh(ctx, req.namespace, req.kubeconfigs)
+	if err != nil {
+		return nil, failure{"Error while deleting Traefik service mesh", err}
+	}
+	return outcome{summary: "Traefik service mesh deleted successfully", result: res}, nil
+}
+
+// upgradeOperation upgrades the traefik mesh release of the namespace of the request
+func upgradeOperation(ctx context.Context, hh *Mesh, req *operationRequest, params upgradeParams) (interface{}, error) {
+	ctx, cancel, err := params.withTimeout(ctx)
+	if err != nil {
+		return nil, paramsError{err}
+	}
+	defer cancel()
+	var version string
+	if params.Version != "" {
+		if version, err = resolvePinnedVersion(ctx, params.Version); err != nil {
+			return nil, failure{"Error while resolving the pinned Traefik service mesh version", err}
+		}
+	} else if version, err = req.latestVersion(ctx); err != nil {
+		return nil, failure{"Error while resolving the Traefik service mesh version", err}
+	}
+	unlock, err := hh.lockNamespace(ctx, req.namespace, req.OperationName, params.NoWait, req.event)
+	if err != nil {
+		return nil, failure{"Namespace busy", err}
+	}
+	defer unlock()
+	res, err := hh.upgradeTraefikMesh(ctx, version, req.namespace, params, req.event, req.kubeconfigs)
+	if err != nil {
+		return nil, err
+	}
+	return outcome{summary: fmt.Sprintf("Traefik service mesh upgraded to %s successfully", version), result: res}, nil
+}
+
+// smiConformanceOperation runs the SMI conformance tests against the mesh, the outcome of each
+// category of tests is streamed as it completes
+func smiConformanceOperation(ctx context.Context, hh *Mesh, req *operationRequest) (interface{}, error) {
+	name := req.operations[req.OperationName].Description
+	resp, err := hh.RunSMITest(adapter.SMITestOptions{
+		Ctx:         ctx,
+		OperationID: req.event.OperationId,
+		Manifest:    SMIManifest,
+		Namespace:   "meshery",
+		Labels:      make(map[string]string),
+		Annotations: make(map[string]string),
+		Kubeconfigs: req.kubeconfigs,
+	})
+	if err != nil {
+		return nil, failure{fmt.Sprintf("Error while %s %s test", status.Running, name), err}
+	}
+	res := summarizeConformance(resp)
+	for _, c := range res.Categories {
+		hh.streamUpdate(req.event, fmt.Sprintf("%s %s: %d passed, %d failed", name, c.Category, c.Passed, c.Failed), fmt.Sprintf("%d conformance cases ran for %s", len(c.Cases), c.Category))
+	}
+	return outcome{summary: fmt.Sprintf("%s test %s successfully", name, status.Completed), result: res}, nil
+}
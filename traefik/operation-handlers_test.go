@@ -0,0 +1,23 @@
+package traefik
+
+import (
+	"testing"
+
+	internalconfig "github.com/layer5io/meshery-traefik-mesh/internal/config"
+)
+
+func TestOperationHandlersCoverOperations(t *testing.T) {
+	for name := range internalconfig.Operations {
+		if _, ok := operationHandlers[name]; !ok {
+			t.Errorf("operation %s is offered without a handler", name)
+		}
+	}
+	for name, h := range operationHandlers {
+		if _, ok := internalconfig.Operations[name]; !ok {
+			t.Errorf("the handler of %s serves an operation which is not offered", name)
+		}
+		if h.run == nil {
+			t.Errorf("the handler of %s doesn't run anything", name)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package traefik
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	internalconfig "github.com/layer5io/meshery-traefik-mesh/internal/config"
+	"gopkg.in/yaml.v2"
+)
+
+// operationParamTypes are the parameters decoded from the custom body of the operations which take some
+var operationParamTypes = map[string]interface{}{
+	internalconfig.TraefikMeshOperation:            installParams{},
+	internalconfig.TraefikMeshUninstallOperation:   namespaceLockParams{},
+	internalconfig.UpgradeOperation:                upgradeParams{},
+	internalconfig.RollbackOperation:               rollbackParams{},
+	internalconfig.ScaleOperation:                  scaleParams{},
+	internalconfig.CancelOperation:                 cancelParams{},
+	internalconfig.TrafficSplitOperation:           trafficSplitParams{},
+	internalconfig.TrafficTargetOperation:          trafficTargetParams{},
+	internalconfig.HTTPRouteGroupOperation:         httpRouteGroupParams{},
+	internalconfig.SMIManifestOperation:            smiManifestParams{},
+	internalconfig.TrafficSplitValidationOperation: trafficSplitValidationParams{},
+	internalconfig.SplitCoverageOperation:          splitCoverageParams{},
+	internalconfig.SplitPortsOperation:             splitPortsParams{},
+	internalconfig.ProxyMetricsDiffOperation:       proxyMetricsDiffParams{},
+	internalconfig.ProxyLogsOperation:              proxyLogsParams{},
+	internalconfig.ExportEventsOperation:           exportEventsParams{},
+	internalconfig.NamespaceLabelsOperation:        namespaceLabelsParams{},
+	internalconfig.CanaryAnalysisOperation:         canaryAnalysisParams{},
+	internalconfig.RestartReportOperation:          restartReportParams{},
+	internalconfig.ReadinessOperation:              readinessParams{},
+	internalconfig.MiddlewareOrderOperation:        middlewareOrderParams{},
+	internalconfig.DrainTestOperation:              drainTestParams{},
+	internalconfig.PortNamesOperation:              portNamesParams{},
+	internalconfig.OrphansOperation:                orphansParams{},
+	internalconfig.RouteTraceOperation:             routeTraceParams{},
+	internalconfig.SelectorLinkageOperation:        selectorLinkageParams{},
+}
+
+// OperationParameter describes a parameter read from the custom body of an operation request
+type OperationParameter struct {
+	// Name is the path of the parameter in the body, e.g. backends[].service for the
+	// service of every backend
+	Name string `json:"name"`
+	// Type is the YAML type of the parameter: string, integer, number, boolean, array or object
+	Type     string `json:"type"`
+	Required bool   `json:"required,omitempty"`
+}
+
+// describeParams lists the parameters decoded from the custom body into v, which is a struct.
+// The fields of the nested objects follow the object they belong to.
+func describeParams(v interface{}) []OperationParameter {
+	return appendParams(nil, "", reflect.TypeOf(v))
+}
+
+// appendParams appends the fields of the struct type t to params, their names prefixed with prefix
+func appendParams(params []OperationParameter, prefix string, t reflect.Type) []OperationParameter {
+	for _, f := range paramFields(t) {
+		params = append(params, OperationParameter{
+			Name:     prefix + f.name,
+			Type:     paramType(f.typ),
+			Required: f.required,
+		})
+		switch {
+		case f.typ.Kind() == reflect.Struct:
+			params = appendParams(params, prefix+f.name+".", f.typ)
+		case f.typ.Kind() == reflect.Slice && derefType(f.typ.Elem()).Kind() == reflect.Struct:
+			params = appendParams(params, prefix+f.name+"[].", derefType(f.typ.Elem()))
+		}
+	}
+	return params
+}
+
+// paramField is a field of a parameters struct decoded from YAML
+type paramField struct {
+	name     string
+	typ      reflect.Type
+	required bool
+}
+
+// paramFields returns the fields decoded into the struct type t, the fields of the inlined structs included
+func paramFields(t reflect.Type) []paramField {
+	var fields []paramField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		ft := derefType(field.Type)
+		if field.Anonymous && opts == "inline" && ft.Kind() == reflect.Struct {
+			fields = append(fields, paramFields(ft)...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			// the default of yaml.v2
+			name = strings.ToLower(field.Name)
+		}
+		fields = append(fields, paramField{name: name, typ: ft, required: field.Tag.Get("required") == "true"})
+	}
+	return fields
+}
+
+// validateOperationParams checks the custom body of the request against the parameters of the operation
+// before it runs, every missing, unknown or mistyped field is reported at once. The required fields are
+// only needed to apply the operation, not to delete what it applied.
+func validateOperationParams(operation, body string, del bool) error {
+	params, ok := operationParamTypes[operation]
+	if !ok {
+		return nil
+	}
+	var doc interface{}
+	if strings.TrimSpace(body) != "" {
+		if err := yaml.Unmarshal([]byte(body), &doc); err != nil {
+			return ErrInvalidOperationParams(err)
+		}
+	}
+	if doc == nil {
+		doc = map[interface{}]interface{}{}
+	}
+	entries, ok := doc.(map[interface{}]interface{})
+	if !ok {
+		return ErrInvalidOperationParams(fmt.Errorf("the parameters must be an object of fields, got %v", doc))
+	}
+	problems := checkParamFields(nil, "", reflect.TypeOf(params), entries, !del)
+	if len(problems) != 0 {
+		return ErrInvalidOperationParams(mergeErrors(problems))
+	}
+	return nil
+}
+
+// checkParamValue appends to problems the mismatches between the decoded value found at path and the type t
+func checkParamValue(problems []error, path string, t reflect.Type, value interface{}, checkRequired bool) []error {
+	t = derefType(t)
+	if value == nil {
+		// null decodes to the zero value
+		return problems
+	}
+	switch t.Kind() {
+	case reflect.Interface:
+		return problems
+	case reflect.String:
+		// yaml.v2 decodes every scalar into a string
+		switch value.(type) {
+		case []interface{}, map[interface{}]interface{}:
+			return append(problems, invalidParam(path, t, value))
+		}
+	case reflect.Bool:
+		if _, ok := value.(bool); !ok {
+			return append(problems, invalidParam(path, t, value))
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch v := value.(type) {
+		case int, int64, uint64:
+		case float64:
+			if v != float64(int64(v)) {
+				return append(problems, invalidParam(path, t, value))
+			}
+		default:
+			return append(problems, invalidParam(path, t, value))
+		}
+	case reflect.Float32, reflect.Float64:
+		switch value.(type) {
+		case int, int64, uint64, float64:
+		default:
+			return append(problems, invalidParam(path, t, value))
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := value.([]interface{})
+		if !ok {
+			return append(problems, invalidParam(path, t, value))
+		}
+		for i, item := range items {
+			problems = checkParamValue(problems, fmt.Sprintf("%s[%d]", path, i), t.Elem(), item, checkRequired)
+		}
+	case reflect.Map:
+		entries, ok := value.(map[interface{}]interface{})
+		if !ok {
+			return append(problems, invalidParam(path, t, value))
+		}
+		for k, v := range entries {
+			problems = checkParamValue(problems, fmt.Sprintf("%s.%v", path, k), t.Elem(), v, checkRequired)
+		}
+	case reflect.Struct:
+		entries, ok := value.(map[interface{}]interface{})
+		if !ok {
+			return append(problems, invalidParam(path, t, value))
+		}
+		problems = checkParamFields(problems, path, t, entries, checkRequired)
+	}
+	return problems
+}
+
+// checkParamFields appends to problems the unknown, missing and mistyped fields of the entries decoded
+// at path into the struct type t
+func checkParamFields(problems []error, path string, t reflect.Type, entries map[interface{}]interface{}, checkRequired bool) []error {
+	prefix := path
+	if prefix != "" {
+		prefix += "."
+	}
+	fields := map[string]paramField{}
+	for _, f := range paramFields(t) {
+		fields[f.name] = f
+	}
+
+	keys := make([]string, 0, len(entries))
+	values := make(map[string]interface{}, len(entries))
+	for k, v := range entries {
+		key := fmt.Sprint(k)
+		keys = append(keys, key)
+		values[key] = v
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if path == "" && key == correlationIDParam {
+			continue
+		}
+		f, ok := fields[key]
+		if !ok {
+			problems = append(problems, fmt.Errorf("unknown field %s%s", prefix, key))
+			continue
+		}
+		problems = checkParamValue(problems, prefix+key, f.typ, values[key], checkRequired)
+	}
+	if !checkRequired {
+		return problems
+	}
+	for _, f := range paramFields(t) {
+		if !f.required {
+			continue
+		}
+		if v, ok := values[f.name]; !ok || v == nil || v == "" || isEmptyList(v) {
+			problems = append(problems, fmt.Errorf("missing required field %s%s", prefix, f.name))
+		}
+	}
+	return problems
+}
+
+// isEmptyList reports whether the decoded value is a list without items
+func isEmptyList(v interface{}) bool {
+	items, ok := v.([]interface{})
+	return ok && len(items) == 0
+}
+
+// invalidParam describes a value whose type doesn't match the type t of the field at path
+func invalidParam(path string, t reflect.Type, value interface{}) error {
+	got := "object"
+	switch value.(type) {
+	case string:
+		return fmt.Errorf("invalid field %s: expected %s, got string %q", path, paramType(t), value)
+	case bool:
+		got = "boolean"
+	case int, int64, uint64:
+		got = "integer"
+	case float64:
+		got = "number"
+	case []interface{}:
+		got = "array"
+	}
+	return fmt.Errorf("invalid field %s: expected %s, got %s %v", path, paramType(t), got, value)
+}
+
+// paramType returns the YAML type of the values decoded into t
+func paramType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	}
+	return "any"
+}
+
+// derefType returns the type pointed to by t, or t when it isn't a pointer
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
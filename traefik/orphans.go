@@ -0,0 +1,132 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// orphansParams are the parameters of the orphaned resources operation
+type orphansParams struct {
+	// Cleanup deletes the orphaned resources, the resources owned by another object
+	// (e.g. the TrafficSplits of a Flagger canary) are left to their owner
+	Cleanup bool `yaml:"cleanup"`
+	// DryRun submits the deletions with a server side dry run
+	DryRun bool `yaml:"dryRun"`
+}
+
+// orphanedResource is an SMI resource left behind by an uninstalled traefik mesh
+type orphanedResource struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Owner is the object controlling the resource, it is never cleaned up
+	Owner   string `json:"owner,omitempty"`
+	Deleted bool   `json:"deleted"`
+}
+
+// orphansReport lists the orphaned SMI resources of a cluster
+type orphansReport struct {
+	MeshInstalled bool               `json:"mesh_installed"`
+	DryRun        bool               `json:"dry_run,omitempty"`
+	Orphans       []orphanedResource `json:"orphans"`
+}
+
+// reportOrphans lists the SMI resources of every cluster where the traefik mesh
+// controller is no longer running and optionally deletes them
+func (mesh *Mesh) reportOrphans(ctx context.Context, params orphansParams, kubeconfigs []string) (map[string]interface{}, error) {
+	return collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		return clusterOrphans(ctx, kClient, params)
+	})
+}
+
+// clusterOrphans looks for the orphaned SMI resources of the cluster, scanning several namespaces at once
+func clusterOrphans(ctx context.Context, kClient *mesherykube.Client, params orphansParams) (*orphansReport, error) {
+	report := &orphansReport{
+		DryRun:  params.Cleanup && params.DryRun,
+		Orphans: []orphanedResource{},
+	}
+
+	var deps *appsv1.DeploymentList
+	err := retryOnTransient(ctx, func() (err error) {
+		deps, err = kClient.KubeClient.AppsV1().Deployments("").List(ctx, metav1.ListOptions{LabelSelector: controllerSelector})
+		return err
+	})
+	if err != nil {
+		return nil, ErrOrphans(err)
+	}
+	// the resources are in use as long as the controller runs
+	if len(deps.Items) > 0 {
+		report.MeshInstalled = true
+		return report, nil
+	}
+
+	resources, err := servedSMIResources(ctx, kClient)
+	if err != nil {
+		return nil, ErrOrphans(err)
+	}
+	namespaces, err := listNamespaces(ctx, kClient)
+	if err != nil {
+		return nil, ErrOrphans(err)
+	}
+	res, err := forEachNamespace(namespaces, func(ns string) (interface{}, error) {
+		return namespaceOrphans(ctx, kClient, ns, resources, params)
+	})
+	if err != nil {
+		return nil, ErrOrphans(err)
+	}
+	for _, r := range res {
+		report.Orphans = append(report.Orphans, r.([]orphanedResource)...)
+	}
+	sort.Slice(report.Orphans, func(i, j int) bool {
+		a, b := report.Orphans[i], report.Orphans[j]
+		return a.Kind+a.Namespace+a.Name < b.Kind+b.Namespace+b.Name
+	})
+	return report, nil
+}
+
+// namespaceOrphans lists, and optionally deletes, the SMI resources of the namespace
+func namespaceOrphans(ctx context.Context, kClient *mesherykube.Client, namespace string, resources []smiResource, params orphansParams) ([]orphanedResource, error) {
+	var orphans []orphanedResource
+	for _, res := range resources {
+		var objs *unstructured.UnstructuredList
+		err := retryOnTransient(ctx, func() (err error) {
+			objs, err = kClient.DynamicKubeClient.Resource(res.gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objs.Items {
+			orphan := orphanedResource{
+				Kind:      res.kind,
+				Namespace: obj.GetNamespace(),
+				Name:      obj.GetName(),
+			}
+			if owner := metav1.GetControllerOf(&obj); owner != nil {
+				orphan.Owner = fmt.Sprintf("%s/%s", owner.Kind, owner.Name)
+			}
+			if params.Cleanup && orphan.Owner == "" {
+				opts := metav1.DeleteOptions{}
+				if params.DryRun {
+					opts.DryRun = []string{metav1.DryRunAll}
+				}
+				err := retryOnTransient(ctx, func() error {
+					return kClient.DynamicKubeClient.Resource(res.gvr).Namespace(namespace).Delete(ctx, obj.GetName(), opts)
+				})
+				if err != nil && !kubeerrors.IsNotFound(err) {
+					return nil, err
+				}
+				orphan.Deleted = true
+			}
+			orphans = append(orphans, orphan)
+		}
+	}
+	return orphans, nil
+}
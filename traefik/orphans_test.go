@@ -0,0 +1,109 @@
+package traefik
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestClusterOrphans(t *testing.T) {
+	canary := smiObject(trafficSplitGVR, trafficSplitKind, "podinfo")
+	canary.SetNamespace("test")
+	isController := true
+	canary.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "flagger.app/v1beta1", Kind: "Canary", Name: "podinfo", Controller: &isController}})
+
+	controller := appsv1.DeploymentList{Items: []appsv1.Deployment{{ObjectMeta: metav1.ObjectMeta{Name: "traefik-mesh-controller", Namespace: "maesh"}}}}
+	orphans := []orphanedResource{
+		{Kind: "TrafficSplit", Namespace: "bookinfo", Name: "reviews-rollout"},
+		{Kind: "TrafficSplit", Namespace: "test", Name: "podinfo", Owner: "Canary/podinfo"},
+		{Kind: "TrafficTarget", Namespace: "bookinfo", Name: "reviews-access"},
+	}
+	deletedOrphans := []orphanedResource{
+		{Kind: "TrafficSplit", Namespace: "bookinfo", Name: "reviews-rollout", Deleted: true},
+		orphans[1],
+		{Kind: "TrafficTarget", Namespace: "bookinfo", Name: "reviews-access", Deleted: true},
+	}
+
+	tests := []struct {
+		name        string
+		deployments appsv1.DeploymentList
+		params      orphansParams
+		want        *orphansReport
+		deleted     []string
+	}{
+		{
+			name:        "controller running",
+			deployments: controller,
+			params:      orphansParams{Cleanup: true},
+			want:        &orphansReport{MeshInstalled: true, Orphans: []orphanedResource{}},
+		},
+		{
+			name: "controller absent",
+			want: &orphansReport{Orphans: orphans},
+		},
+		{
+			// the canary split is left to flagger
+			name:    "cleanup",
+			params:  orphansParams{Cleanup: true},
+			want:    &orphansReport{Orphans: deletedOrphans},
+			deleted: []string{"reviews-access", "reviews-rollout"},
+		},
+		{
+			// the fake client doesn't pass the delete options on, the API server skips the dry run deletions
+			name:    "cleanup dry run",
+			params:  orphansParams{Cleanup: true, DryRun: true},
+			want:    &orphansReport{DryRun: true, Orphans: deletedOrphans},
+			deleted: []string{"reviews-access", "reviews-rollout"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kClient := fakeClient(t, map[string]interface{}{
+				"/apis/apps/v1/deployments": tt.deployments,
+				"/api/v1/namespaces": corev1.NamespaceList{Items: []corev1.Namespace{
+					{ObjectMeta: metav1.ObjectMeta{Name: "bookinfo"}},
+					{ObjectMeta: metav1.ObjectMeta{Name: "test"}},
+				}},
+			}, []runtime.Object{
+				crd(trafficSplitGVR, trafficSplitKind, ""),
+				crd(trafficTargetGVR, "TrafficTarget", ""),
+				smiObject(trafficSplitGVR, trafficSplitKind, "reviews-rollout"),
+				smiObject(trafficTargetGVR, "TrafficTarget", "reviews-access"),
+				canary,
+			}...)
+
+			var mx sync.Mutex
+			deleted := []string{}
+			kClient.DynamicKubeClient.(*dynamicfake.FakeDynamicClient).PrependReactor("delete", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				mx.Lock()
+				defer mx.Unlock()
+				deleted = append(deleted, action.(k8stesting.DeleteActionImpl).Name)
+				return false, nil, nil
+			})
+
+			report, err := clusterOrphans(context.Background(), kClient, tt.params)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(report, tt.want) {
+				t.Errorf("expected the report %+v, got %+v", tt.want, report)
+			}
+			if tt.deleted == nil {
+				tt.deleted = []string{}
+			}
+			sort.Strings(deleted)
+			if !reflect.DeepEqual(deleted, tt.deleted) {
+				t.Errorf("expected the deletions %v, got %v", tt.deleted, deleted)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package traefik
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// parseOperationParams decodes the parameters of an operation which are
+// passed as a YAML (or JSON) document in the custom body of the request
+func parseOperationParams(body string, v interface{}) error {
+	if strings.TrimSpace(body) == "" {
+		return nil
+	}
+
+	if err := yaml.Unmarshal([]byte(body), v); err != nil {
+		return ErrInvalidOperationParams(err)
+	}
+	return nil
+}
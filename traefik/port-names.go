@@ -0,0 +1,169 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// trafficTypeAnnotation overrides the traffic type of a service in traefik mesh
+	trafficTypeAnnotation = "mesh.traefik.io/traffic-type"
+
+	portUnnamed         = "unnamed"
+	portNonConventional = "non-conventional"
+)
+
+// conventionalPortName matches the port names prefixed with the protocol they serve, e.g. http-web
+var conventionalPortName = regexp.MustCompile(`^(http|http2|https|h2c|grpc|grpc-web|ws|wss|tcp|udp|tls)(-[a-z0-9-]*)?$`)
+
+// wellKnownPorts are the protocols suggested for the ports without another hint
+var wellKnownPorts = map[int32]string{
+	80:    "http",
+	443:   "https",
+	8080:  "http",
+	8443:  "https",
+	9090:  "http",
+	50051: "grpc",
+}
+
+// portNamesParams are the parameters of the port names operation
+type portNamesParams struct {
+	// Fix names the unnamed ports with the suggested names, the ports with a
+	// non-conventional name are only reported as renaming them may break the
+	// ingresses and monitors referencing them
+	Fix bool `yaml:"fix"`
+}
+
+// portNameFinding is a service port without a conventional name
+type portNameFinding struct {
+	Service   string `json:"service"`
+	Namespace string `json:"namespace"`
+	Port      int32  `json:"port"`
+	Name      string `json:"name,omitempty"`
+	Issue     string `json:"issue"`
+	Suggested string `json:"suggested"`
+	Fixed     bool   `json:"fixed"`
+}
+
+// checkPortNames reports the ports of the meshed services of the namespace, of every
+// namespace when it is empty, which are unnamed or not prefixed with their protocol
+func (mesh *Mesh) checkPortNames(ctx context.Context, namespace string, params portNamesParams, kubeconfigs []string) (map[string]interface{}, error) {
+	shadow, err := labels.Parse(shadowServiceSelector)
+	if err != nil {
+		return nil, ErrPortNames(err)
+	}
+	return collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		var svcs *corev1.ServiceList
+		err := retryOnTransient(ctx, func() (err error) {
+			svcs, err = kClient.KubeClient.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+			return err
+		})
+		if err != nil {
+			return nil, ErrPortNames(err)
+		}
+
+		findings := []portNameFinding{}
+		for i := range svcs.Items {
+			svc := &svcs.Items[i]
+			if shadow.Matches(labels.Set(svc.Labels)) {
+				continue
+			}
+			found := portNameFindings(svc)
+			if params.Fix && fixPortNames(svc, found) {
+				err := retryOnTransient(ctx, func() error {
+					_, err := kClient.KubeClient.CoreV1().Services(svc.Namespace).Update(ctx, svc, metav1.UpdateOptions{})
+					return err
+				})
+				if err != nil {
+					return nil, ErrPortNames(err)
+				}
+				for j := range found {
+					found[j].Fixed = found[j].Issue == portUnnamed
+				}
+			}
+			findings = append(findings, found...)
+		}
+		sort.SliceStable(findings, func(i, j int) bool {
+			return findings[i].Namespace+"/"+findings[i].Service < findings[j].Namespace+"/"+findings[j].Service
+		})
+		return findings, nil
+	})
+}
+
+// portNameFindings returns the ports of the service which are unnamed or not
+// prefixed with their protocol along with the name suggested for them
+func portNameFindings(svc *corev1.Service) []portNameFinding {
+	taken := map[string]bool{}
+	for _, p := range svc.Spec.Ports {
+		taken[p.Name] = true
+	}
+	var findings []portNameFinding
+	for _, p := range svc.Spec.Ports {
+		issue := ""
+		switch {
+		case p.Name == "":
+			issue = portUnnamed
+		case !conventionalPortName.MatchString(p.Name):
+			issue = portNonConventional
+		default:
+			continue
+		}
+		suggested := suggestedPortName(svc, p)
+		if taken[suggested] {
+			suggested = fmt.Sprintf("%s-%d", suggested, p.Port)
+		}
+		taken[suggested] = true
+		findings = append(findings, portNameFinding{
+			Service:   svc.Name,
+			Namespace: svc.Namespace,
+			Port:      p.Port,
+			Name:      p.Name,
+			Issue:     issue,
+			Suggested: suggested,
+		})
+	}
+	return findings
+}
+
+// suggestedPortName derives the protocol of the port from its app protocol, the traffic
+// type of the service, the well known port numbers and finally its transport protocol
+func suggestedPortName(svc *corev1.Service, port corev1.ServicePort) string {
+	if port.AppProtocol != nil {
+		if name := strings.ToLower(*port.AppProtocol); conventionalPortName.MatchString(name) {
+			return name
+		}
+	}
+	if tt := strings.ToLower(svc.Annotations[trafficTypeAnnotation]); tt == "tcp" || tt == "udp" {
+		return tt
+	}
+	if name, ok := wellKnownPorts[port.Port]; ok && protocolOf(port) == corev1.ProtocolTCP {
+		return name
+	}
+	return strings.ToLower(string(protocolOf(port)))
+}
+
+// fixPortNames names the unnamed ports of the service with the suggested names,
+// it reports whether the service was modified
+func fixPortNames(svc *corev1.Service, findings []portNameFinding) bool {
+	fixed := false
+	for _, f := range findings {
+		if f.Issue != portUnnamed {
+			continue
+		}
+		for i := range svc.Spec.Ports {
+			if svc.Spec.Ports[i].Port == f.Port && svc.Spec.Ports[i].Name == "" {
+				svc.Spec.Ports[i].Name = f.Suggested
+				fixed = true
+			}
+		}
+	}
+	return fixed
+}
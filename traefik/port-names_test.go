@@ -0,0 +1,86 @@
+package traefik
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPortNameFindings(t *testing.T) {
+	grpc := "GRPC"
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		ports       []corev1.ServicePort
+		want        []portNameFinding
+	}{
+		{
+			name:  "conventional names",
+			ports: []corev1.ServicePort{{Name: "http", Port: 9080}, {Name: "grpc-api", Port: 50051}, {Name: "tcp-db", Port: 5432}},
+		},
+		{
+			name:  "unnamed well known port",
+			ports: []corev1.ServicePort{{Port: 8080}},
+			want:  []portNameFinding{{Port: 8080, Issue: portUnnamed, Suggested: "http"}},
+		},
+		{
+			name:  "unnamed port with an app protocol",
+			ports: []corev1.ServicePort{{Port: 9000, AppProtocol: &grpc}},
+			want:  []portNameFinding{{Port: 9000, Issue: portUnnamed, Suggested: "grpc"}},
+		},
+		{
+			name:        "unnamed port of a tcp service",
+			annotations: map[string]string{trafficTypeAnnotation: "TCP"},
+			ports:       []corev1.ServicePort{{Port: 80}},
+			want:        []portNameFinding{{Port: 80, Issue: portUnnamed, Suggested: "tcp"}},
+		},
+		{
+			name:  "unnamed udp port",
+			ports: []corev1.ServicePort{{Port: 8080, Protocol: corev1.ProtocolUDP}},
+			want:  []portNameFinding{{Port: 8080, Issue: portUnnamed, Suggested: "udp"}},
+		},
+		{
+			// the suggested name is already used by another port of the service
+			name:  "non-conventional names",
+			ports: []corev1.ServicePort{{Name: "web", Port: 80}, {Name: "http", Port: 9080}, {Name: "Admin", Port: 8443}},
+			want: []portNameFinding{
+				{Port: 80, Name: "web", Issue: portNonConventional, Suggested: "http-80"},
+				{Port: 8443, Name: "Admin", Issue: portNonConventional, Suggested: "https"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "reviews", Namespace: "bookinfo", Annotations: tt.annotations},
+				Spec:       corev1.ServiceSpec{Ports: tt.ports},
+			}
+			for i := range tt.want {
+				tt.want[i].Service, tt.want[i].Namespace = "reviews", "bookinfo"
+			}
+			if got := portNameFindings(svc); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected the findings %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFixPortNames(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "reviews", Namespace: "bookinfo"},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 8080}, {Name: "web", Port: 80}}},
+	}
+	if !fixPortNames(svc, portNameFindings(svc)) {
+		t.Fatal("expected the unnamed port to be fixed")
+	}
+	// the non-conventional name may be referenced by ingresses, it is kept
+	want := []corev1.ServicePort{{Name: "http", Port: 8080}, {Name: "web", Port: 80}}
+	if !reflect.DeepEqual(svc.Spec.Ports, want) {
+		t.Fatalf("expected the ports %+v, got %+v", want, svc.Spec.Ports)
+	}
+	if fixPortNames(svc, portNameFindings(svc)) {
+		t.Fatal("the named ports were modified again")
+	}
+}
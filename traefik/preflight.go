@@ -0,0 +1,224 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+)
+
+const (
+	admissionAdmitted = "admitted"
+	admissionMutated  = "mutated"
+	admissionRejected = "rejected"
+	admissionSkipped  = "skipped"
+)
+
+// webhookNameRegex extracts the webhook name from the error returned by the API server
+var webhookNameRegex = regexp.MustCompile(`admission webhook "([^"]+)"`)
+
+// admissionFeedback is the admission chain's response to a single resource of the mesh
+type admissionFeedback struct {
+	Kind      string   `json:"kind"`
+	Name      string   `json:"name"`
+	Namespace string   `json:"namespace,omitempty"`
+	Status    string   `json:"status"`
+	Webhook   string   `json:"webhook,omitempty"`
+	Message   string   `json:"message,omitempty"`
+	Mutations []string `json:"mutations,omitempty"`
+}
+
+// admissionReport summarizes the admission preflight of a cluster
+type admissionReport struct {
+	Namespace string              `json:"namespace"`
+	Rejected  int                 `json:"rejected"`
+	Mutated   int                 `json:"mutated"`
+	Resources []admissionFeedback `json:"resources"`
+	// SuggestedSizing is the sizing suited to the cluster, applied by installing with autoSize
+	SuggestedSizing *clusterSize `json:"suggested_sizing,omitempty"`
+}
+
+// admissionPreflight renders the traefik mesh chart and submits every resource to the
+// admission chain of each cluster with a server side dry run, reporting the resources
+// rejected or mutated by webhooks
+func (mesh *Mesh) admissionPreflight(ctx context.Context, version, namespace string, kubeconfigs []string) (map[string]interface{}, error) {
+	chartPath, err := fetchChart(ctx, traefikMeshRepository(), traefikMeshChart, version)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := mesherykube.ConvertHelmChartToK8sManifest(mesherykube.ApplyHelmChartConfig{LocalPath: chartPath})
+	if err != nil {
+		return nil, ErrAdmissionPreflight(err)
+	}
+
+	return collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		// decoded for every cluster as the dry run mutates the objects
+		objs, err := decodeManifest(string(manifest))
+		if err != nil {
+			return nil, ErrAdmissionPreflight(err)
+		}
+		report, err := dryRunAdmission(ctx, kClient, objs, namespace)
+		if err != nil {
+			return nil, err
+		}
+		// the suggestion is best effort, the preflight doesn't require listing the nodes
+		if size, err := sizeCluster(ctx, kClient); err == nil {
+			report.SuggestedSizing = &size
+		}
+		return report, nil
+	})
+}
+
+// dryRunAdmission submits the objects to the API server with a server side dry run
+func dryRunAdmission(ctx context.Context, kClient *mesherykube.Client, objs []*unstructured.Unstructured, namespace string) (*admissionReport, error) {
+	var groups []*restmapper.APIGroupResources
+	err := retryOnTransient(ctx, func() (err error) {
+		groups, err = restmapper.GetAPIGroupResources(kClient.KubeClient.Discovery())
+		return err
+	})
+	if err != nil {
+		return nil, ErrAdmissionPreflight(err)
+	}
+	mapper := restmapper.NewDiscoveryRESTMapper(groups)
+
+	report := &admissionReport{
+		Namespace: namespace,
+		Resources: []admissionFeedback{},
+	}
+
+	// namespaced resources can't be dry run in a namespace which doesn't exist yet
+	nsExists := true
+	if _, err := kClient.KubeClient.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{}); kubeerrors.IsNotFound(err) {
+		nsExists = false
+	}
+
+	for _, obj := range objs {
+		gvk := obj.GroupVersionKind()
+		fb := admissionFeedback{
+			Kind: gvk.Kind,
+			Name: obj.GetName(),
+		}
+
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			fb.Status = admissionSkipped
+			fb.Message = fmt.Sprintf("resource type is not served by the cluster yet: %v", err)
+			report.Resources = append(report.Resources, fb)
+			continue
+		}
+
+		var ri dynamic.ResourceInterface = kClient.DynamicKubeClient.Resource(mapping.Resource)
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			if !nsExists {
+				fb.Namespace = namespace
+				fb.Status = admissionSkipped
+				fb.Message = fmt.Sprintf("namespace %q doesn't exist yet", namespace)
+				report.Resources = append(report.Resources, fb)
+				continue
+			}
+			obj.SetNamespace(namespace)
+			fb.Namespace = namespace
+			ri = kClient.DynamicKubeClient.Resource(mapping.Resource).Namespace(namespace)
+		}
+
+		submitted := obj.DeepCopy()
+		var res *unstructured.Unstructured
+		err = retryOnTransient(ctx, func() (err error) {
+			res, err = ri.Create(ctx, obj, metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}})
+			if kubeerrors.IsAlreadyExists(err) {
+				var existing *unstructured.Unstructured
+				existing, err = ri.Get(ctx, obj.GetName(), metav1.GetOptions{})
+				if err == nil {
+					obj.SetResourceVersion(existing.GetResourceVersion())
+					res, err = ri.Update(ctx, obj, metav1.UpdateOptions{DryRun: []string{metav1.DryRunAll}})
+				}
+			}
+			return err
+		})
+
+		switch {
+		case err != nil:
+			fb.Status = admissionRejected
+			fb.Message = err.Error()
+			if m := webhookNameRegex.FindStringSubmatch(err.Error()); len(m) == 2 {
+				fb.Webhook = m[1]
+			}
+			report.Rejected++
+		default:
+			fb.Mutations = objectMutations(submitted.Object, res.Object)
+			fb.Status = admissionAdmitted
+			if len(fb.Mutations) > 0 {
+				fb.Status = admissionMutated
+				report.Mutated++
+			}
+		}
+		report.Resources = append(report.Resources, fb)
+	}
+
+	return report, nil
+}
+
+// objectMutations returns the paths of the submitted fields which were changed by
+// the admission chain along with the labels and annotations it added. Fields which
+// were not submitted are ignored as they are mostly defaulted by the API server.
+func objectMutations(submitted, admitted map[string]interface{}) []string {
+	var paths []string
+	for _, field := range []string{"labels", "annotations"} {
+		sub, _, _ := unstructured.NestedStringMap(submitted, "metadata", field)
+		adm, _, _ := unstructured.NestedStringMap(admitted, "metadata", field)
+		for k, v := range adm {
+			if sv, ok := sub[k]; !ok || sv != v {
+				paths = append(paths, fmt.Sprintf("metadata.%s.%s", field, k))
+			}
+		}
+	}
+
+	for k, v := range submitted {
+		if k == "metadata" || k == "status" {
+			continue
+		}
+		paths = append(paths, changedPaths(k, v, admitted[k])...)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// changedPaths compares the submitted value with the admitted one recursively
+func changedPaths(path string, submitted, admitted interface{}) []string {
+	switch sub := submitted.(type) {
+	case map[string]interface{}:
+		adm, ok := admitted.(map[string]interface{})
+		if !ok {
+			return []string{path}
+		}
+		var paths []string
+		for k, v := range sub {
+			paths = append(paths, changedPaths(path+"."+k, v, adm[k])...)
+		}
+		return paths
+	case []interface{}:
+		adm, ok := admitted.([]interface{})
+		if !ok || len(adm) != len(sub) {
+			return []string{path}
+		}
+		var paths []string
+		for i := range sub {
+			paths = append(paths, changedPaths(fmt.Sprintf("%s[%d]", path, i), sub[i], adm[i])...)
+		}
+		return paths
+	default:
+		// numbers are decoded as float64 from the manifest and int64 from the API server
+		if fmt.Sprint(submitted) != fmt.Sprint(admitted) {
+			return []string{path}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,120 @@
+package traefik
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// admissionAPIServer serves the discovery of the core group and the mesh namespace
+func admissionAPIServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	responses := map[string]interface{}{
+		"/api":  metav1.APIVersions{Versions: []string{"v1"}},
+		"/apis": metav1.APIGroupList{},
+		"/api/v1": metav1.APIResourceList{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: []string{"create", "get", "update"}},
+				{Name: "serviceaccounts", Kind: "ServiceAccount", Namespaced: true, Verbs: []string{"create", "get", "update"}},
+			},
+		},
+		"/api/v1/namespaces/traefik-mesh": map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Namespace",
+			"metadata":   map[string]interface{}{"name": "traefik-mesh"},
+		},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestDryRunAdmissionReportsWebhookFeedback(t *testing.T) {
+	srv := admissionAPIServer(t)
+	kube, err := kubernetes.NewForConfig(&rest.Config{Host: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dyn := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	dyn.PrependReactor("create", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, &kubeerrors.StatusError{ErrStatus: metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    http.StatusForbidden,
+			Reason:  metav1.StatusReasonForbidden,
+			Message: `admission webhook "validation.gatekeeper.sh" denied the request: [must-have-owner] missing required label owner`,
+		}}
+	})
+	dyn.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		obj := action.(k8stesting.CreateAction).GetObject().(*unstructured.Unstructured).DeepCopy()
+		obj.SetLabels(map[string]string{"owner": "platform"})
+		return true, obj, nil
+	})
+	kClient := &mesherykube.Client{KubeClient: kube, DynamicKubeClient: dyn}
+
+	objs, err := decodeManifest(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: traefik-mesh-config
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: traefik-mesh-controller
+---
+apiVersion: access.smi-spec.io/v1alpha2
+kind: TrafficTarget
+metadata:
+  name: reviews
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := dryRunAdmission(context.Background(), kClient, objs, "traefik-mesh")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Rejected != 1 || report.Mutated != 1 || len(report.Resources) != 3 {
+		t.Fatalf("expected one rejected and one mutated resource, got %+v", report)
+	}
+
+	tests := []struct {
+		kind      string
+		status    string
+		webhook   string
+		mutations []string
+	}{
+		{kind: "ConfigMap", status: admissionRejected, webhook: "validation.gatekeeper.sh"},
+		{kind: "ServiceAccount", status: admissionMutated, mutations: []string{"metadata.labels.owner"}},
+		{kind: "TrafficTarget", status: admissionSkipped},
+	}
+	for i, tt := range tests {
+		fb := report.Resources[i]
+		if fb.Kind != tt.kind || fb.Status != tt.status || fb.Webhook != tt.webhook {
+			t.Errorf("expected the %s to be %s by %q, got %+v", tt.kind, tt.status, tt.webhook, fb)
+		}
+		if len(fb.Mutations) != len(tt.mutations) || (len(tt.mutations) > 0 && fb.Mutations[0] != tt.mutations[0]) {
+			t.Errorf("expected the %s mutations %v, got %v", tt.kind, tt.mutations, fb.Mutations)
+		}
+	}
+}
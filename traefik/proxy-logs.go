@@ -0,0 +1,99 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	internalconfig "github.com/layer5io/meshery-traefik-mesh/internal/config"
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// defaultProxyLogTailLines is the number of lines fetched per proxy pod when the request sets none
+	defaultProxyLogTailLines = 100
+	// maxProxyLogTailLines caps the number of lines fetched per proxy pod, the logs are returned in the event
+	maxProxyLogTailLines = 1000
+)
+
+// proxyLogsParams are the parameters of the proxy logs operation
+type proxyLogsParams struct {
+	// TailLines is the number of the latest lines fetched from each proxy pod
+	TailLines int64 `yaml:"tailLines"`
+}
+
+// proxyLogsResult holds the latest log lines of the proxies of a cluster, prefixed by pod name
+type proxyLogsResult struct {
+	Namespace string `json:"namespace"`
+	TailLines int64  `json:"tail_lines"`
+	Pods      int    `json:"pods"`
+	Logs      string `json:"logs"`
+	// Errors are the pods whose logs could not be fetched
+	Errors []string `json:"errors,omitempty"`
+}
+
+// tailLines returns the number of lines to fetch, the default when unset and capped to the maximum
+func (p proxyLogsParams) tailLines() (int64, error) {
+	switch {
+	case p.TailLines < 0:
+		return 0, ErrInvalidOperationParams(fmt.Errorf("tailLines must be positive, got %d", p.TailLines))
+	case p.TailLines == 0:
+		return defaultProxyLogTailLines, nil
+	case p.TailLines > maxProxyLogTailLines:
+		return maxProxyLogTailLines, nil
+	}
+	return p.TailLines, nil
+}
+
+// fetchProxyLogs returns the latest log lines of the proxy pods of the namespace in every cluster
+func (mesh *Mesh) fetchProxyLogs(ctx context.Context, namespace string, params proxyLogsParams, kubeconfigs []string) (map[string]interface{}, error) {
+	tail, err := params.tailLines()
+	if err != nil {
+		return nil, err
+	}
+
+	return collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		var pods *corev1.PodList
+		err := retryOnTransient(ctx, func() (err error) {
+			pods, err = kClient.KubeClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: proxyPodSelector})
+			return err
+		})
+		if err != nil {
+			return nil, ErrProxyLogs(err)
+		}
+		if len(pods.Items) == 0 {
+			return nil, ErrProxyLogs(fmt.Errorf("no traefik mesh proxy pod in namespace %s", namespace))
+		}
+		sort.Slice(pods.Items, func(i, j int) bool {
+			return pods.Items[i].Name < pods.Items[j].Name
+		})
+
+		res := proxyLogsResult{Namespace: namespace, TailLines: tail, Pods: len(pods.Items)}
+		var logs strings.Builder
+		for _, pod := range pods.Items {
+			var raw []byte
+			err := retryOnTransient(ctx, func() (err error) {
+				raw, err = kClient.KubeClient.CoreV1().Pods(namespace).GetLogs(pod.Name, &corev1.PodLogOptions{TailLines: &tail}).DoRaw(ctx)
+				return err
+			})
+			if err != nil {
+				res.Errors = append(res.Errors, fmt.Sprintf("%s: %v", pod.Name, err))
+				continue
+			}
+			if len(raw) == 0 {
+				continue
+			}
+			for _, line := range strings.Split(strings.TrimSuffix(string(raw), "\n"), "\n") {
+				fmt.Fprintf(&logs, "[%s] %s\n", pod.Name, truncateLogLine(line, internalconfig.LogMaxLineLength()))
+			}
+		}
+		if len(res.Errors) == len(pods.Items) {
+			return nil, ErrProxyLogs(fmt.Errorf("%s", strings.Join(res.Errors, "; ")))
+		}
+		res.Logs = logs.String()
+		return res, nil
+	})
+}
@@ -0,0 +1,272 @@
+package traefik
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// proxyPodSelector selects the traefik mesh proxy pods
+	proxyPodSelector = "app=maesh,component=maesh-mesh"
+
+	// proxyMetricsPort is the port on which the proxies expose prometheus metrics
+	proxyMetricsPort = "8080"
+
+	serviceRequestsMetric = "traefik_service_requests_total"
+	serviceDurationMetric = "traefik_service_request_duration_seconds"
+
+	defaultMetricsWindow = time.Minute
+	maxMetricsWindow     = 30 * time.Minute
+)
+
+// serviceMetrics holds the cumulative request counters of a service as seen by the proxies
+type serviceMetrics struct {
+	Requests     float64 `json:"requests"`
+	Errors       float64 `json:"errors"`
+	LatencySum   float64 `json:"latency_sum_seconds"`
+	LatencyCount float64 `json:"latency_count"`
+	// Codes counts the requests per response status code
+	Codes map[string]float64 `json:"codes,omitempty"`
+}
+
+// errorRate returns the ratio of 5xx responses to all requests
+func (sm serviceMetrics) errorRate() float64 {
+	if sm.Requests == 0 {
+		return 0
+	}
+	return sm.Errors / sm.Requests
+}
+
+// avgLatency returns the average request latency in seconds
+func (sm serviceMetrics) avgLatency() float64 {
+	if sm.LatencyCount == 0 {
+		return 0
+	}
+	return sm.LatencySum / sm.LatencyCount
+}
+
+// sub returns the metrics accumulated since the earlier metrics b
+func (sm serviceMetrics) sub(b serviceMetrics) serviceMetrics {
+	res := serviceMetrics{
+		Requests:     sm.Requests - b.Requests,
+		Errors:       sm.Errors - b.Errors,
+		LatencySum:   sm.LatencySum - b.LatencySum,
+		LatencyCount: sm.LatencyCount - b.LatencyCount,
+		Codes:        map[string]float64{},
+	}
+	for code, n := range sm.Codes {
+		if d := n - b.Codes[code]; d > 0 {
+			res.Codes[code] = d
+		}
+	}
+	return res
+}
+
+// metricsSnapshot is the aggregate of all proxy metrics at a point in time
+type metricsSnapshot struct {
+	Time     time.Time                  `json:"time"`
+	Services map[string]*serviceMetrics `json:"services"`
+}
+
+// serviceMetricsDelta compares the traffic of a service before and during the window
+type serviceMetricsDelta struct {
+	Service             string  `json:"service"`
+	WindowRequests      float64 `json:"window_requests"`
+	ErrorRateBefore     float64 `json:"error_rate_before"`
+	ErrorRateAfter      float64 `json:"error_rate_after"`
+	ErrorRateDelta      float64 `json:"error_rate_delta"`
+	AvgLatencyBefore    float64 `json:"avg_latency_before_seconds"`
+	AvgLatencyAfter     float64 `json:"avg_latency_after_seconds"`
+	AvgLatencyDelta     float64 `json:"avg_latency_delta_seconds"`
+	RegressionSuspected bool    `json:"regression_suspected"`
+}
+
+// proxyMetricsDiffParams are the parameters of the proxy metrics diff operation
+type proxyMetricsDiffParams struct {
+	Service string `yaml:"service"`
+	Window  string `yaml:"window"`
+}
+
+// window returns the validated observation window
+func (p proxyMetricsDiffParams) window() (time.Duration, error) {
+	if p.Window == "" {
+		return defaultMetricsWindow, nil
+	}
+	d, err := time.ParseDuration(p.Window)
+	if err != nil {
+		return 0, ErrInvalidOperationParams(err)
+	}
+	if d <= 0 || d > maxMetricsWindow {
+		return 0, ErrInvalidOperationParams(fmt.Errorf("window %s must be greater than 0 and at most %s", d, maxMetricsWindow))
+	}
+	return d, nil
+}
+
+// snapshotProxyMetrics scrapes every proxy pod and aggregates the service metrics
+// whose name contains the filter
+func snapshotProxyMetrics(ctx context.Context, kClient *mesherykube.Client, filter string) (*metricsSnapshot, error) {
+	var pods *corev1.PodList
+	err := retryOnTransient(ctx, func() (err error) {
+		pods, err = kClient.KubeClient.CoreV1().Pods("").List(ctx, metav1.ListOptions{LabelSelector: proxyPodSelector})
+		return err
+	})
+	if err != nil {
+		return nil, ErrScrapeProxyMetrics(err)
+	}
+
+	snapshot := &metricsSnapshot{
+		Time:     time.Now(),
+		Services: make(map[string]*serviceMetrics),
+	}
+	for _, pod := range pods.Items {
+		var raw []byte
+		err := retryOnTransient(ctx, func() (err error) {
+			raw, err = kClient.KubeClient.CoreV1().Pods(pod.Namespace).ProxyGet("http", pod.Name, proxyMetricsPort, "/metrics", nil).DoRaw(ctx)
+			return err
+		})
+		if err != nil {
+			return nil, ErrScrapeProxyMetrics(err)
+		}
+		if err := snapshot.add(raw, filter); err != nil {
+			return nil, ErrScrapeProxyMetrics(err)
+		}
+	}
+	return snapshot, nil
+}
+
+// add parses the prometheus text exposition and adds it to the snapshot
+func (ms *metricsSnapshot) add(raw []byte, filter string) error {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+
+	if family, ok := families[serviceRequestsMetric]; ok {
+		for _, m := range family.GetMetric() {
+			sm := ms.service(labelValue(m, "service"), filter)
+			if sm == nil {
+				continue
+			}
+			code := labelValue(m, "code")
+			sm.Requests += m.GetCounter().GetValue()
+			sm.Codes[code] += m.GetCounter().GetValue()
+			if strings.HasPrefix(code, "5") {
+				sm.Errors += m.GetCounter().GetValue()
+			}
+		}
+	}
+
+	if family, ok := families[serviceDurationMetric]; ok {
+		for _, m := range family.GetMetric() {
+			sm := ms.service(labelValue(m, "service"), filter)
+			if sm == nil {
+				continue
+			}
+			sm.LatencySum += m.GetHistogram().GetSampleSum()
+			sm.LatencyCount += float64(m.GetHistogram().GetSampleCount())
+		}
+	}
+	return nil
+}
+
+// service returns the metrics of the named service, nil if the name doesn't match the filter
+func (ms *metricsSnapshot) service(name, filter string) *serviceMetrics {
+	if name == "" || !strings.Contains(name, filter) {
+		return nil
+	}
+	if _, ok := ms.Services[name]; !ok {
+		ms.Services[name] = &serviceMetrics{Codes: map[string]float64{}}
+	}
+	return ms.Services[name]
+}
+
+// aggregate sums the metrics of the services whose name contains the filter
+func (ms *metricsSnapshot) aggregate(filter string) serviceMetrics {
+	res := serviceMetrics{Codes: map[string]float64{}}
+	for name, sm := range ms.Services {
+		if !strings.Contains(name, filter) {
+			continue
+		}
+		res.Requests += sm.Requests
+		res.Errors += sm.Errors
+		res.LatencySum += sm.LatencySum
+		res.LatencyCount += sm.LatencyCount
+		for code, n := range sm.Codes {
+			res.Codes[code] += n
+		}
+	}
+	return res
+}
+
+// labelValue returns the value of the named label on the metric
+func labelValue(m *dto.Metric, name string) string {
+	for _, lp := range m.GetLabel() {
+		if lp.GetName() == name {
+			return lp.GetValue()
+		}
+	}
+	return ""
+}
+
+// diffSnapshots compares the lifetime rates up to the first snapshot with the
+// rates observed between the two snapshots
+func diffSnapshots(before, after *metricsSnapshot) []serviceMetricsDelta {
+	deltas := []serviceMetricsDelta{}
+	for name, a := range after.Services {
+		b, ok := before.Services[name]
+		if !ok {
+			b = &serviceMetrics{}
+		}
+		window := a.sub(*b)
+		d := serviceMetricsDelta{
+			Service:          name,
+			WindowRequests:   window.Requests,
+			ErrorRateBefore:  b.errorRate(),
+			ErrorRateAfter:   window.errorRate(),
+			AvgLatencyBefore: b.avgLatency(),
+			AvgLatencyAfter:  window.avgLatency(),
+		}
+		d.ErrorRateDelta = d.ErrorRateAfter - d.ErrorRateBefore
+		d.AvgLatencyDelta = d.AvgLatencyAfter - d.AvgLatencyBefore
+		d.RegressionSuspected = d.ErrorRateDelta > 0 || (d.AvgLatencyBefore > 0 && d.AvgLatencyAfter > 1.5*d.AvgLatencyBefore)
+		deltas = append(deltas, d)
+	}
+	return deltas
+}
+
+// compareProxyMetrics snapshots the proxy metrics, waits for the window to elapse
+// and returns the per-service delta for every cluster
+func (mesh *Mesh) compareProxyMetrics(ctx context.Context, params proxyMetricsDiffParams, kubeconfigs []string, waiting func(time.Duration)) (map[string]interface{}, error) {
+	window, err := params.window()
+	if err != nil {
+		return nil, err
+	}
+
+	return collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		before, err := snapshotProxyMetrics(ctx, kClient, params.Service)
+		if err != nil {
+			return nil, err
+		}
+		waiting(window)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(window):
+		}
+		after, err := snapshotProxyMetrics(ctx, kClient, params.Service)
+		if err != nil {
+			return nil, err
+		}
+		return diffSnapshots(before, after), nil
+	})
+}
@@ -0,0 +1,131 @@
+package traefik
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// scrape returns the snapshot of the given prometheus exposition of a proxy
+func scrape(t *testing.T, filter string, expositions ...string) *metricsSnapshot {
+	t.Helper()
+	ms := &metricsSnapshot{Time: time.Now(), Services: map[string]*serviceMetrics{}}
+	for _, raw := range expositions {
+		if err := ms.add([]byte(raw), filter); err != nil {
+			t.Fatalf("parsing the exposition: %v", err)
+		}
+	}
+	return ms
+}
+
+const (
+	metricsBefore = `# TYPE traefik_service_requests_total counter
+traefik_service_requests_total{service="default-reviews-9080",code="200"} 98
+traefik_service_requests_total{service="default-reviews-9080",code="503"} 2
+traefik_service_requests_total{service="default-ratings-9080",code="200"} 50
+# TYPE traefik_service_request_duration_seconds histogram
+traefik_service_request_duration_seconds_bucket{service="default-reviews-9080",le="+Inf"} 100
+traefik_service_request_duration_seconds_sum{service="default-reviews-9080"} 10
+traefik_service_request_duration_seconds_count{service="default-reviews-9080"} 100
+traefik_service_request_duration_seconds_bucket{service="default-ratings-9080",le="+Inf"} 50
+traefik_service_request_duration_seconds_sum{service="default-ratings-9080"} 5
+traefik_service_request_duration_seconds_count{service="default-ratings-9080"} 50
+`
+	metricsAfter = `# TYPE traefik_service_requests_total counter
+traefik_service_requests_total{service="default-reviews-9080",code="200"} 178
+traefik_service_requests_total{service="default-reviews-9080",code="503"} 22
+traefik_service_requests_total{service="default-ratings-9080",code="200"} 100
+# TYPE traefik_service_request_duration_seconds histogram
+traefik_service_request_duration_seconds_bucket{service="default-reviews-9080",le="+Inf"} 200
+traefik_service_request_duration_seconds_sum{service="default-reviews-9080"} 40
+traefik_service_request_duration_seconds_count{service="default-reviews-9080"} 200
+traefik_service_request_duration_seconds_bucket{service="default-ratings-9080",le="+Inf"} 100
+traefik_service_request_duration_seconds_sum{service="default-ratings-9080"} 10
+traefik_service_request_duration_seconds_count{service="default-ratings-9080"} 100
+`
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	deltas := diffSnapshots(scrape(t, "", metricsBefore), scrape(t, "", metricsAfter))
+	byService := map[string]serviceMetricsDelta{}
+	for _, d := range deltas {
+		byService[d.Service] = d
+	}
+
+	tests := []struct {
+		service    string
+		requests   float64
+		rateBefore float64
+		rateAfter  float64
+		latAfter   float64
+		regression bool
+	}{
+		// 80 successes and 20 failures during the window, the latency tripled
+		{service: "default-reviews-9080", requests: 100, rateBefore: 0.02, rateAfter: 0.2, latAfter: 0.3, regression: true},
+		{service: "default-ratings-9080", requests: 50, rateBefore: 0, rateAfter: 0, latAfter: 0.1, regression: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.service, func(t *testing.T) {
+			d, ok := byService[tt.service]
+			if !ok {
+				t.Fatalf("no delta for %s in %v", tt.service, deltas)
+			}
+			if d.WindowRequests != tt.requests {
+				t.Errorf("window requests: expected %v, got %v", tt.requests, d.WindowRequests)
+			}
+			if !almostEqual(d.ErrorRateBefore, tt.rateBefore) || !almostEqual(d.ErrorRateAfter, tt.rateAfter) {
+				t.Errorf("error rates: expected %v then %v, got %v then %v", tt.rateBefore, tt.rateAfter, d.ErrorRateBefore, d.ErrorRateAfter)
+			}
+			if !almostEqual(d.ErrorRateDelta, tt.rateAfter-tt.rateBefore) {
+				t.Errorf("error rate delta: expected %v, got %v", tt.rateAfter-tt.rateBefore, d.ErrorRateDelta)
+			}
+			if !almostEqual(d.AvgLatencyAfter, tt.latAfter) {
+				t.Errorf("latency after: expected %v, got %v", tt.latAfter, d.AvgLatencyAfter)
+			}
+			if d.RegressionSuspected != tt.regression {
+				t.Errorf("regression suspected: expected %v, got %v", tt.regression, d.RegressionSuspected)
+			}
+		})
+	}
+}
+
+func TestSnapshotServiceFilter(t *testing.T) {
+	ms := scrape(t, "reviews", metricsBefore)
+	if _, ok := ms.Services["default-ratings-9080"]; ok {
+		t.Fatalf("the filtered out service was kept: %v", ms.Services)
+	}
+	reviews := ms.aggregate("reviews")
+	if reviews.Requests != 100 || reviews.Errors != 2 || reviews.Codes["503"] != 2 {
+		t.Fatalf("unexpected aggregate of reviews: %+v", reviews)
+	}
+}
+
+func TestProxyMetricsWindow(t *testing.T) {
+	tests := []struct {
+		window  string
+		want    time.Duration
+		invalid bool
+	}{
+		{window: "", want: defaultMetricsWindow},
+		{window: "90s", want: 90 * time.Second},
+		{window: "0s", invalid: true},
+		{window: "31m", invalid: true},
+		{window: "soon", invalid: true},
+	}
+	for _, tt := range tests {
+		got, err := proxyMetricsDiffParams{Window: tt.window}.window()
+		if tt.invalid {
+			if err == nil {
+				t.Errorf("window %q: expected an error, got %s", tt.window, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("window %q: expected %s, got %s (%v)", tt.window, tt.want, got, err)
+		}
+	}
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
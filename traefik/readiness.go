@@ -0,0 +1,299 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	readinessComponents    = "components"
+	readinessCRDs          = "crds"
+	readinessProxyCoverage = "proxy_coverage"
+	readinessDNS           = "dns"
+	readinessTraffic       = "traffic"
+)
+
+// readinessCheckOrder lists the readiness checks in the order they run
+var readinessCheckOrder = []string{readinessComponents, readinessCRDs, readinessProxyCoverage, readinessDNS, readinessTraffic}
+
+// smiResources are the plural names of the SMI resources watched by traefik mesh, keyed by group
+var smiResources = map[string][]string{
+	smiSplitGroup:  {"trafficsplits"},
+	smiSpecsGroup:  {"httproutegroups", "tcproutes"},
+	smiAccessGroup: {"traffictargets"},
+}
+
+// readinessParams are the parameters of the readiness operation
+type readinessParams struct {
+	// Skip disables the named checks: components, crds, proxy_coverage, dns or traffic
+	Skip []string `yaml:"skip"`
+}
+
+// readinessCheckResult is the outcome of a single readiness check, its score
+// goes from 0 (nothing works) to 1 (everything works)
+type readinessCheckResult struct {
+	Name   string  `json:"name"`
+	Passed bool    `json:"passed"`
+	Score  float64 `json:"score"`
+	Detail string  `json:"detail"`
+}
+
+// readinessReport is the aggregate readiness of the mesh in a cluster
+type readinessReport struct {
+	Ready  bool                   `json:"ready"`
+	Score  int                    `json:"score"`
+	Checks []readinessCheckResult `json:"checks"`
+}
+
+// readinessCheck runs a check of the mesh installed in the namespace against a cluster
+type readinessCheck func(ctx context.Context, kClient *mesherykube.Client, namespace string) readinessCheckResult
+
+// checkReadiness runs the enabled readiness checks of the mesh installed in the namespace against every
+// cluster and aggregates them into a score
+func (mesh *Mesh) checkReadiness(ctx context.Context, namespace string, params readinessParams, kubeconfigs []string) (map[string]interface{}, error) {
+	checks := map[string]readinessCheck{
+		readinessComponents: checkComponentHealth,
+		// the CRDs are cluster scoped
+		readinessCRDs: func(ctx context.Context, kClient *mesherykube.Client, _ string) readinessCheckResult {
+			return checkCRDPresence(ctx, kClient)
+		},
+		readinessProxyCoverage: checkProxyCoverage,
+		readinessDNS:           checkDNSResolution,
+		readinessTraffic:       checkSyntheticTraffic,
+	}
+	skip := map[string]bool{}
+	for _, name := range params.Skip {
+		if _, ok := checks[name]; !ok {
+			return nil, ErrInvalidOperationParams(fmt.Errorf("unknown readiness check %q, expected one of %s", name, strings.Join(readinessCheckOrder, ", ")))
+		}
+		skip[name] = true
+	}
+
+	return collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		var results []readinessCheckResult
+		for _, name := range readinessCheckOrder {
+			if skip[name] {
+				continue
+			}
+			res := checks[name](ctx, kClient, namespace)
+			res.Name = name
+			results = append(results, res)
+		}
+		return aggregateReadiness(results), nil
+	})
+}
+
+// aggregateReadiness averages the scores of the checks into a score out of 100,
+// the mesh is ready only when every check passed
+func aggregateReadiness(results []readinessCheckResult) *readinessReport {
+	report := &readinessReport{
+		Ready:  true,
+		Checks: []readinessCheckResult{},
+	}
+	if len(results) == 0 {
+		return report
+	}
+	var total float64
+	for _, res := range results {
+		total += res.Score
+		report.Ready = report.Ready && res.Passed
+		report.Checks = append(report.Checks, res)
+	}
+	report.Score = int(math.Round(total / float64(len(results)) * 100))
+	return report
+}
+
+// ratioResult builds the result of a check which passes when all the items are in the expected state
+func ratioResult(ok, total int, what, state string) readinessCheckResult {
+	if total == 0 {
+		return readinessCheckResult{Detail: fmt.Sprintf("no %s found", what)}
+	}
+	return readinessCheckResult{
+		Passed: ok == total,
+		Score:  float64(ok) / float64(total),
+		Detail: fmt.Sprintf("%d/%d %s %s", ok, total, what, state),
+	}
+}
+
+// failedResult builds the result of a check which could not run
+func failedResult(err error) readinessCheckResult {
+	return readinessCheckResult{Detail: err.Error()}
+}
+
+// checkComponentHealth checks that the controller deployment and the proxy pods of the namespace are ready
+func checkComponentHealth(ctx context.Context, kClient *mesherykube.Client, namespace string) readinessCheckResult {
+	var deps *appsv1.DeploymentList
+	err := retryOnTransient(ctx, func() (err error) {
+		deps, err = kClient.KubeClient.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{LabelSelector: controllerSelector})
+		return err
+	})
+	if err != nil {
+		return failedResult(err)
+	}
+	pods, err := listProxyPods(ctx, kClient, namespace)
+	if err != nil {
+		return failedResult(err)
+	}
+
+	ok, total := 0, 0
+	for _, dep := range deps.Items {
+		total++
+		if dep.Status.AvailableReplicas > 0 && dep.Status.AvailableReplicas == dep.Status.Replicas {
+			ok++
+		}
+	}
+	for _, pod := range pods {
+		total++
+		if podReady(pod) {
+			ok++
+		}
+	}
+	return ratioResult(ok, total, "controller and proxy components", "ready")
+}
+
+// checkCRDPresence checks that the SMI CRDs serve the versions watched by the installed mesh
+func checkCRDPresence(ctx context.Context, kClient *mesherykube.Client) readinessCheckResult {
+	meshVersion, _ := detectMeshVersion(ctx, kClient)
+	supported := supportedSMIVersions(meshVersion)
+
+	var crds *unstructured.UnstructuredList
+	err := retryOnTransient(ctx, func() (err error) {
+		crds, err = kClient.DynamicKubeClient.Resource(crdResource).List(ctx, metav1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return failedResult(err)
+	}
+	served := map[string]bool{}
+	for _, crd := range crds.Items {
+		group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+		if version, ok := supported[group]; ok && versionServed(crd, version) {
+			plural, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "plural")
+			served[plural+"."+group] = true
+		}
+	}
+
+	ok, total := 0, 0
+	var missing []string
+	for group, plurals := range smiResources {
+		for _, plural := range plurals {
+			total++
+			if served[plural+"."+group] {
+				ok++
+				continue
+			}
+			missing = append(missing, fmt.Sprintf("%s.%s/%s", plural, group, supported[group]))
+		}
+	}
+	res := ratioResult(ok, total, "SMI CRDs", "served")
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		res.Detail += fmt.Sprintf(", missing %s", strings.Join(missing, ", "))
+	}
+	return res
+}
+
+// checkProxyCoverage checks that a ready proxy of the namespace runs on every schedulable node
+func checkProxyCoverage(ctx context.Context, kClient *mesherykube.Client, namespace string) readinessCheckResult {
+	var nodes *corev1.NodeList
+	err := retryOnTransient(ctx, func() (err error) {
+		nodes, err = kClient.KubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return failedResult(err)
+	}
+	pods, err := listProxyPods(ctx, kClient, namespace)
+	if err != nil {
+		return failedResult(err)
+	}
+
+	covered := map[string]bool{}
+	for _, pod := range pods {
+		if podReady(pod) {
+			covered[pod.Spec.NodeName] = true
+		}
+	}
+	ok, total := 0, 0
+	for _, node := range nodes.Items {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		total++
+		if covered[node.Name] {
+			ok++
+		}
+	}
+	return ratioResult(ok, total, "schedulable nodes", "covered by a ready proxy")
+}
+
+// checkDNSResolution checks that a proxy of the namespace resolves the controller service name
+func checkDNSResolution(ctx context.Context, kClient *mesherykube.Client, namespace string) readinessCheckResult {
+	prober := execProber{kClient: kClient}
+	url, pods, err := connectivityTargets(ctx, kClient, namespace)
+	if err != nil {
+		return failedResult(err)
+	}
+	for _, pod := range pods {
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		err := prober.probe(ctx, pod, url)
+		msg := ""
+		if err != nil {
+			msg = strings.ToLower(err.Error())
+		}
+		switch {
+		case strings.HasPrefix(msg, "exec:"):
+			return readinessCheckResult{Detail: fmt.Sprintf("could not exec into proxy %s/%s: %v", pod.Namespace, pod.Name, err)}
+		case strings.Contains(msg, "bad address") || strings.Contains(msg, "resolve"):
+			return readinessCheckResult{Detail: fmt.Sprintf("proxy %s/%s could not resolve the controller: %v", pod.Namespace, pod.Name, err)}
+		}
+		return readinessCheckResult{Passed: true, Score: 1, Detail: fmt.Sprintf("proxy %s/%s resolved the controller service", pod.Namespace, pod.Name)}
+	}
+	return readinessCheckResult{Detail: "no running proxy to resolve from"}
+}
+
+// checkSyntheticTraffic checks that every proxy of the namespace reaches the controller
+func checkSyntheticTraffic(ctx context.Context, kClient *mesherykube.Client, namespace string) readinessCheckResult {
+	res, err := probeControllerConnectivity(ctx, kClient, namespace, execProber{kClient: kClient})
+	if err != nil {
+		return failedResult(err)
+	}
+	return ratioResult(len(res.Proxies)-res.Unreachable, len(res.Proxies), "proxies", "reaching the controller")
+}
+
+// listProxyPods lists the proxy pods of the namespace, of all the namespaces when it is empty
+func listProxyPods(ctx context.Context, kClient *mesherykube.Client, namespace string) ([]corev1.Pod, error) {
+	var pods *corev1.PodList
+	err := retryOnTransient(ctx, func() (err error) {
+		pods, err = kClient.KubeClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: proxyPodSelector})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pods.Items, nil
+}
+
+// podReady reports whether the pod is running with the Ready condition
+func podReady(pod corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAggregateReadiness(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []readinessCheckResult
+		ready   bool
+		score   int
+	}{
+		{
+			name:  "every check skipped",
+			ready: true,
+			score: 0,
+		},
+		{
+			name: "every check passed",
+			results: []readinessCheckResult{
+				ratioResult(1, 1, "controller deployments", "ready"),
+				ratioResult(4, 4, "SMI CRDs", "present"),
+			},
+			ready: true,
+			score: 100,
+		},
+		{
+			name: "mixed results",
+			results: []readinessCheckResult{
+				ratioResult(1, 1, "controller deployments", "ready"),
+				ratioResult(3, 4, "SMI CRDs", "present"),
+				ratioResult(2, 3, "meshed pods", "covered"),
+				failedResult(fmt.Errorf("no proxy pod found")),
+			},
+			// (1 + 0.75 + 0.667 + 0) / 4
+			ready: false,
+			score: 60,
+		},
+		{
+			name: "nothing to check",
+			results: []readinessCheckResult{
+				ratioResult(0, 0, "proxy pods", "ready"),
+			},
+			ready: false,
+			score: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := aggregateReadiness(tt.results)
+			if report.Ready != tt.ready || report.Score != tt.score {
+				t.Fatalf("expected ready %v with the score %d, got %v with %d", tt.ready, tt.score, report.Ready, report.Score)
+			}
+			if len(report.Checks) != len(tt.results) {
+				t.Fatalf("expected the detail of %d checks, got %d", len(tt.results), len(report.Checks))
+			}
+		})
+	}
+}
+
+func TestRatioResultDetail(t *testing.T) {
+	res := ratioResult(3, 4, "SMI CRDs", "present")
+	if res.Passed || res.Score != 0.75 || res.Detail != "3/4 SMI CRDs present" {
+		t.Fatalf("unexpected result %+v", res)
+	}
+}
+
+func TestCheckReadinessRejectsUnknownCheck(t *testing.T) {
+	_, err := (&Mesh{}).checkReadiness(context.Background(), "traefik-mesh", readinessParams{Skip: []string{readinessDNS, "latency"}}, nil)
+	if err == nil {
+		t.Fatal("expected the unknown check to be rejected")
+	}
+}
+
+func TestReadinessChecksScopedToNamespace(t *testing.T) {
+	controller := appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "other-mesh", Name: "traefik-mesh-controller"},
+		Status:     appsv1.DeploymentStatus{Replicas: 1, AvailableReplicas: 1},
+	}
+	proxy := proxyPod("traefik-mesh-proxy-x2k4f", "node-1", corev1.PodRunning)
+	proxy.Namespace = "other-mesh"
+	proxy.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+	kClient := fakeClient(t, map[string]interface{}{
+		"/api/v1/nodes": corev1.NodeList{Items: []corev1.Node{{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}}},
+		// listed across the namespaces
+		"/apis/apps/v1/deployments": appsv1.DeploymentList{Items: []appsv1.Deployment{controller}},
+		"/api/v1/pods":              corev1.PodList{Items: []corev1.Pod{proxy}},
+		"/apis/apps/v1/namespaces/other-mesh/deployments":   appsv1.DeploymentList{Items: []appsv1.Deployment{controller}},
+		"/api/v1/namespaces/other-mesh/pods":                corev1.PodList{Items: []corev1.Pod{proxy}},
+		"/apis/apps/v1/namespaces/traefik-mesh/deployments": appsv1.DeploymentList{},
+		"/api/v1/namespaces/traefik-mesh/pods":              corev1.PodList{},
+	})
+
+	tests := []struct {
+		namespace string
+		ready     bool
+	}{
+		{namespace: "other-mesh", ready: true},
+		// the mesh of the other namespace doesn't make the one of the operation ready
+		{namespace: "traefik-mesh", ready: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.namespace, func(t *testing.T) {
+			if res := checkComponentHealth(context.Background(), kClient, tt.namespace); res.Passed != tt.ready {
+				t.Errorf("expected the components check to pass: %v, got %+v", tt.ready, res)
+			}
+			if res := checkProxyCoverage(context.Background(), kClient, tt.namespace); res.Passed != tt.ready {
+				t.Errorf("expected the proxy coverage check to pass: %v, got %+v", tt.ready, res)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	internalconfig "github.com/layer5io/meshery-traefik-mesh/internal/config"
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+)
+
+// resourceMetadataFieldManager owns the labels and annotations added by the adapter to the resources
+// it installed, apart from the fields owned by helm or by the kubectl backend
+const resourceMetadataFieldManager = "meshery-traefik-mesh-metadata"
+
+// reservedMetadataKeys are the labels set by the traefik mesh chart
+var reservedMetadataKeys = map[string]bool{
+	"app":       true,
+	"component": true,
+	"chart":     true,
+	"release":   true,
+	"heritage":  true,
+}
+
+// reservedMetadataPrefixes are the prefixes of the keys set by the chart, helm and the adapter
+var reservedMetadataPrefixes = []string{"app.kubernetes.io/", "helm.sh/", "meta.helm.sh/", "meshery.io/"}
+
+// resourceMetadataParams are the labels and annotations of the resources created by an operation,
+// they are merged over the ones of RESOURCE_LABELS and RESOURCE_ANNOTATIONS
+type resourceMetadataParams struct {
+	Labels      map[string]string `yaml:"labels"`
+	Annotations map[string]string `yaml:"annotations"`
+}
+
+// resourceMetadata is the validated metadata added to the resources created by an operation
+type resourceMetadata struct {
+	labels      map[string]string
+	annotations map[string]string
+}
+
+// resolve merges the metadata of the request over the configured one and validates the result,
+// every invalid key or value is reported at once
+func (p resourceMetadataParams) resolve() (resourceMetadata, error) {
+	labels, err := internalconfig.ResourceLabels()
+	if err != nil {
+		return resourceMetadata{}, ErrInvalidOperationParams(fmt.Errorf("%s: %v", internalconfig.ResourceLabelsEnv, err))
+	}
+	annotations, err := internalconfig.ResourceAnnotations()
+	if err != nil {
+		return resourceMetadata{}, ErrInvalidOperationParams(fmt.Errorf("%s: %v", internalconfig.ResourceAnnotationsEnv, err))
+	}
+	for k, v := range p.Labels {
+		labels[k] = v
+	}
+	for k, v := range p.Annotations {
+		annotations[k] = v
+	}
+
+	var problems []error
+	for _, k := range sortedKeys(labels) {
+		problems = append(problems, metadataKeyProblems("label", k)...)
+		for _, msg := range validation.IsValidLabelValue(labels[k]) {
+			problems = append(problems, fmt.Errorf("label %s: value %q: %s", k, labels[k], msg))
+		}
+	}
+	for _, k := range sortedKeys(annotations) {
+		problems = append(problems, metadataKeyProblems("annotation", k)...)
+	}
+	if len(problems) != 0 {
+		return resourceMetadata{}, ErrInvalidOperationParams(mergeErrors(problems))
+	}
+	return resourceMetadata{labels: labels, annotations: annotations}, nil
+}
+
+// metadataKeyProblems reports a key which isn't a qualified name, or which the chart, helm or the adapter sets
+func metadataKeyProblems(kind, key string) []error {
+	var problems []error
+	for _, msg := range validation.IsQualifiedName(key) {
+		problems = append(problems, fmt.Errorf("%s %s: %s", kind, key, msg))
+	}
+	if reservedMetadataKeys[key] {
+		problems = append(problems, fmt.Errorf("%s %s is set by the traefik mesh chart", kind, key))
+	}
+	for _, prefix := range reservedMetadataPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			problems = append(problems, fmt.Errorf("%s %s is reserved, the keys prefixed with %s are set by the chart, helm or the adapter", kind, key, prefix))
+		}
+	}
+	return problems
+}
+
+// empty reports whether there is no metadata to add
+func (rm resourceMetadata) empty() bool {
+	return len(rm.labels) == 0 && len(rm.annotations) == 0
+}
+
+// merge adds the metadata to the object, the keys the object sets already keep their value
+func (rm resourceMetadata) merge(obj *unstructured.Unstructured) {
+	obj.SetLabels(mergeMissing(obj.GetLabels(), rm.labels))
+	obj.SetAnnotations(mergeMissing(obj.GetAnnotations(), rm.annotations))
+}
+
+// mergeMissing adds the entries of add missing from m
+func mergeMissing(m, add map[string]string) map[string]string {
+	if len(add) == 0 {
+		return m
+	}
+	if m == nil {
+		m = map[string]string{}
+	}
+	for k, v := range add {
+		if _, ok := m[k]; !ok {
+			m[k] = v
+		}
+	}
+	return m
+}
+
+// labelInventory adds the metadata to the resources of the inventory with a server side apply of their
+// labels and annotations only, the pod templates are left alone. A key the resource carries already with
+// another value is kept as is, so that the selectors of the chart never change.
+func (mesh *Mesh) labelInventory(ctx context.Context, kClient *mesherykube.Client, items []inventoryItem, rm resourceMetadata) error {
+	var groups []*restmapper.APIGroupResources
+	err := retryOnTransient(ctx, func() (err error) {
+		groups, err = restmapper.GetAPIGroupResources(kClient.KubeClient.Discovery())
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	mapper := restmapper.NewDiscoveryRESTMapper(groups)
+
+	var problems []error
+	for _, item := range items {
+		gv, err := schema.ParseGroupVersion(item.APIVersion)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("%s %s: %v", item.Kind, item.Name, err))
+			continue
+		}
+		mapping, err := mapper.RESTMapping(gv.WithKind(item.Kind).GroupKind(), gv.Version)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("%s %s: %v", item.Kind, item.Name, err))
+			continue
+		}
+		var ri dynamic.ResourceInterface = kClient.DynamicKubeClient.Resource(mapping.Resource)
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			ri = kClient.DynamicKubeClient.Resource(mapping.Resource).Namespace(item.Namespace)
+		}
+		if err := mesh.applyResourceMetadata(ctx, ri, item, rm); err != nil {
+			problems = append(problems, fmt.Errorf("%s %s: %v", item.Kind, item.Name, err))
+		}
+	}
+	if len(problems) != 0 {
+		return mergeErrors(problems)
+	}
+	return nil
+}
+
+// applyResourceMetadata applies the labels and annotations of the metadata which don't collide with
+// the ones of the live resource
+func (mesh *Mesh) applyResourceMetadata(ctx context.Context, ri dynamic.ResourceInterface, item inventoryItem, rm resourceMetadata) error {
+	var live *unstructured.Unstructured
+	err := retryOnTransient(ctx, func() (err error) {
+		live, err = ri.Get(ctx, item.Name, metav1.GetOptions{})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(item.APIVersion)
+	obj.SetKind(item.Kind)
+	obj.SetName(item.Name)
+	obj.SetNamespace(item.Namespace)
+	obj.SetLabels(mesh.nonCollidingMetadata(item, "label", live.GetLabels(), rm.labels))
+	obj.SetAnnotations(mesh.nonCollidingMetadata(item, "annotation", live.GetAnnotations(), rm.annotations))
+	return retryOnTransient(ctx, func() error {
+		_, err := ri.Apply(ctx, item.Name, obj, metav1.ApplyOptions{FieldManager: resourceMetadataFieldManager})
+		return err
+	})
+}
+
+// nonCollidingMetadata returns the entries of want which the live resource doesn't carry with another value,
+// the collisions are logged
+func (mesh *Mesh) nonCollidingMetadata(item inventoryItem, kind string, live, want map[string]string) map[string]string {
+	kept := map[string]string{}
+	for _, k := range sortedKeys(want) {
+		if v, ok := live[k]; ok && v != want[k] {
+			mesh.Log.Warn(ErrApplyHelmChart(fmt.Errorf("%s %s of %s %s is set to %q already, %q is not applied", kind, k, item.Kind, item.Name, v, want[k])))
+			continue
+		}
+		kept[k] = want[k]
+	}
+	return kept
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
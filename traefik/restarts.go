@@ -0,0 +1,103 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// defaultRestartThreshold is the number of restarts from which a pod is flagged
+const defaultRestartThreshold = 5
+
+// restartReportParams are the parameters of the restart report operation
+type restartReportParams struct {
+	// Threshold overrides the number of restarts from which a pod is flagged
+	Threshold int32 `yaml:"threshold"`
+}
+
+// podRestarts are the restarts of a mesh pod over its lifetime
+type podRestarts struct {
+	Pod                   string `json:"pod"`
+	Component             string `json:"component"`
+	Restarts              int32  `json:"restarts"`
+	Excessive             bool   `json:"excessive"`
+	LastTerminationReason string `json:"last_termination_reason,omitempty"`
+	LastTerminationAt     string `json:"last_termination_at,omitempty"`
+}
+
+// restartReport lists the restarts of the controller and proxy pods of a cluster
+type restartReport struct {
+	Threshold int32         `json:"threshold"`
+	Excessive int           `json:"excessive"`
+	Pods      []podRestarts `json:"pods"`
+}
+
+// reportRestarts reports the restarts of the controller and proxy pods of every cluster
+func (mesh *Mesh) reportRestarts(ctx context.Context, params restartReportParams, kubeconfigs []string) (map[string]interface{}, error) {
+	threshold := params.Threshold
+	if threshold <= 0 {
+		threshold = defaultRestartThreshold
+	}
+	return collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		report := &restartReport{
+			Threshold: threshold,
+			Pods:      []podRestarts{},
+		}
+		for component, selector := range map[string]string{"controller": controllerSelector, "proxy": proxyPodSelector} {
+			var pods *corev1.PodList
+			err := retryOnTransient(ctx, func() (err error) {
+				pods, err = kClient.KubeClient.CoreV1().Pods("").List(ctx, metav1.ListOptions{LabelSelector: selector})
+				return err
+			})
+			if err != nil {
+				return nil, ErrRestartReport(err)
+			}
+			for _, pod := range pods.Items {
+				pr := countRestarts(pod, component, threshold)
+				if pr.Excessive {
+					report.Excessive++
+				}
+				report.Pods = append(report.Pods, pr)
+			}
+		}
+		// the most restarted pods first
+		sort.Slice(report.Pods, func(i, j int) bool {
+			a, b := report.Pods[i], report.Pods[j]
+			if a.Restarts != b.Restarts {
+				return a.Restarts > b.Restarts
+			}
+			return a.Pod < b.Pod
+		})
+		return report, nil
+	})
+}
+
+// countRestarts sums the restarts of the containers of the pod and picks the
+// termination reason of the container which terminated last
+func countRestarts(pod corev1.Pod, component string, threshold int32) podRestarts {
+	pr := podRestarts{
+		Pod:       fmt.Sprintf("%s/%s", pod.Namespace, pod.Name),
+		Component: component,
+	}
+	var last metav1.Time
+	for _, cs := range pod.Status.ContainerStatuses {
+		pr.Restarts += cs.RestartCount
+		term := cs.LastTerminationState.Terminated
+		if term == nil || term.FinishedAt.Before(&last) {
+			continue
+		}
+		last = term.FinishedAt
+		pr.LastTerminationReason = term.Reason
+		if term.ExitCode != 0 {
+			pr.LastTerminationReason = strings.TrimSpace(fmt.Sprintf("%s (exit code %d)", term.Reason, term.ExitCode))
+		}
+		pr.LastTerminationAt = term.FinishedAt.UTC().Format("2006-01-02T15:04:05Z")
+	}
+	pr.Excessive = pr.Restarts >= threshold
+	return pr
+}
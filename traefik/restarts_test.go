@@ -0,0 +1,86 @@
+package traefik
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func terminated(reason string, exitCode int32, at time.Time) corev1.ContainerState {
+	return corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+		Reason:     reason,
+		ExitCode:   exitCode,
+		FinishedAt: metav1.NewTime(at),
+	}}
+}
+
+func TestCountRestarts(t *testing.T) {
+	now := time.Date(2023, 3, 14, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		statuses   []corev1.ContainerStatus
+		restarts   int32
+		excessive  bool
+		reason     string
+		terminated string
+	}{
+		{
+			name:     "never restarted",
+			statuses: []corev1.ContainerStatus{{Name: "traefik-mesh-proxy"}},
+		},
+		{
+			name: "crash looping proxy",
+			statuses: []corev1.ContainerStatus{{
+				Name:                 "traefik-mesh-proxy",
+				RestartCount:         12,
+				LastTerminationState: terminated("Error", 1, now),
+			}},
+			restarts:   12,
+			excessive:  true,
+			reason:     "Error (exit code 1)",
+			terminated: "2023-03-14T09:30:00Z",
+		},
+		{
+			name: "latest termination of several containers",
+			statuses: []corev1.ContainerStatus{
+				{Name: "controller", RestartCount: 3, LastTerminationState: terminated("OOMKilled", 137, now.Add(-time.Hour))},
+				{Name: "sidecar", RestartCount: 2, LastTerminationState: terminated("Completed", 0, now.Add(-time.Minute))},
+			},
+			restarts:   5,
+			excessive:  true,
+			reason:     "Completed",
+			terminated: "2023-03-14T09:29:00Z",
+		},
+		{
+			name: "below the threshold",
+			statuses: []corev1.ContainerStatus{{
+				Name:                 "controller",
+				RestartCount:         4,
+				LastTerminationState: terminated("OOMKilled", 137, now),
+			}},
+			restarts:   4,
+			reason:     "OOMKilled (exit code 137)",
+			terminated: "2023-03-14T09:30:00Z",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "traefik-mesh-proxy-x2k9f", Namespace: "traefik-mesh"},
+				Status:     corev1.PodStatus{ContainerStatuses: tt.statuses},
+			}
+			pr := countRestarts(pod, "proxy", defaultRestartThreshold)
+			if pr.Pod != "traefik-mesh/traefik-mesh-proxy-x2k9f" || pr.Component != "proxy" {
+				t.Errorf("unexpected pod %s of component %s", pr.Pod, pr.Component)
+			}
+			if pr.Restarts != tt.restarts || pr.Excessive != tt.excessive {
+				t.Errorf("expected %d restarts, excessive %v, got %d, %v", tt.restarts, tt.excessive, pr.Restarts, pr.Excessive)
+			}
+			if pr.LastTerminationReason != tt.reason || pr.LastTerminationAt != tt.terminated {
+				t.Errorf("expected the last termination %q at %q, got %q at %q", tt.reason, tt.terminated, pr.LastTerminationReason, pr.LastTerminationAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package traefik
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/layer5io/meshery-traefik-mesh/internal/config"
+)
+
+// retainedDirs are the directories of the config root path the adapter writes files to
+var retainedDirs = []string{"exports", "snapshots", "charts"}
+
+// prunedDir reports the files removed from a directory
+type prunedDir struct {
+	Removed []string `json:"removed"`
+	Kept    int      `json:"kept"`
+}
+
+// PruneStoredFiles removes, from every directory the adapter writes to, the files older
+// than the configured maximum age and the oldest files beyond the configured maximum count
+func PruneStoredFiles(now time.Time) (map[string]*prunedDir, error) {
+	maxFiles, maxAge := config.RetentionMaxFiles(), config.RetentionMaxAge()
+	report := make(map[string]*prunedDir, len(retainedDirs))
+	var errs []error
+	for _, dir := range retainedDirs {
+		pruned, err := pruneDir(path.Join(config.RootPath(), dir), now, maxFiles, maxAge)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		report[dir] = pruned
+	}
+	if len(errs) != 0 {
+		return report, ErrPruneFiles(mergeErrors(errs))
+	}
+	return report, nil
+}
+
+// pruneDir prunes the regular files of the directory, the newest first are kept
+func pruneDir(dir string, now time.Time, maxFiles int, maxAge time.Duration) (*prunedDir, error) {
+	pruned := &prunedDir{Removed: []string{}}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return pruned, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	type file struct {
+		name    string
+		modTime time.Time
+	}
+	var files []file
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			// removed meanwhile
+			continue
+		}
+		files = append(files, file{name: entry.Name(), modTime: info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.After(files[j].modTime)
+	})
+
+	var errs []error
+	for i, f := range files {
+		if i < maxFiles && now.Sub(f.modTime) <= maxAge {
+			pruned.Kept++
+			continue
+		}
+		if err := os.Remove(path.Join(dir, f.name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+			continue
+		}
+		pruned.Removed = append(pruned.Removed, f.name)
+	}
+	if len(errs) != 0 {
+		return pruned, mergeErrors(errs)
+	}
+	return pruned, nil
+}
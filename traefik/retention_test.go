@@ -0,0 +1,89 @@
+package traefik
+
+import (
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestPruneDir(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	// the age of each file
+	ages := map[string]time.Duration{
+		"smi-counts.json":            time.Hour,
+		"op-41-diagnostics.json":     2 * 24 * time.Hour,
+		"traefik-mesh-4.1.1.tgz":     5 * 24 * time.Hour,
+		"op-12-diagnostics.json":     10 * 24 * time.Hour,
+		"op-3-export.json":           40 * 24 * time.Hour,
+		"traefik-mesh-3.0.0.tgz.tmp": 90 * 24 * time.Hour,
+	}
+
+	tests := []struct {
+		name     string
+		maxFiles int
+		maxAge   time.Duration
+		removed  []string
+	}{
+		{name: "within the retention", maxFiles: 10, maxAge: 365 * 24 * time.Hour, removed: []string{}},
+		{
+			name:     "beyond the maximum count",
+			maxFiles: 3,
+			maxAge:   365 * 24 * time.Hour,
+			removed:  []string{"op-12-diagnostics.json", "op-3-export.json", "traefik-mesh-3.0.0.tgz.tmp"},
+		},
+		{
+			name:     "beyond the maximum age",
+			maxFiles: 10,
+			maxAge:   7 * 24 * time.Hour,
+			removed:  []string{"op-12-diagnostics.json", "op-3-export.json", "traefik-mesh-3.0.0.tgz.tmp"},
+		},
+		{
+			name:     "beyond both",
+			maxFiles: 1,
+			maxAge:   30 * 24 * time.Hour,
+			removed:  []string{"op-12-diagnostics.json", "op-3-export.json", "op-41-diagnostics.json", "traefik-mesh-3.0.0.tgz.tmp", "traefik-mesh-4.1.1.tgz"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, age := range ages {
+				file := path.Join(dir, name)
+				if err := os.WriteFile(file, []byte(name), 0600); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Chtimes(file, now.Add(-age), now.Add(-age)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			// the directories are never pruned
+			if err := os.Mkdir(path.Join(dir, "bin"), 0750); err != nil {
+				t.Fatal(err)
+			}
+
+			pruned, err := pruneDir(dir, now, tt.maxFiles, tt.maxAge)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			sort.Strings(pruned.Removed)
+			if !reflect.DeepEqual(pruned.Removed, tt.removed) || pruned.Kept != len(ages)-len(tt.removed) {
+				t.Fatalf("expected %v removed and %d kept, got %+v", tt.removed, len(ages)-len(tt.removed), pruned)
+			}
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != pruned.Kept+1 {
+				t.Fatalf("expected %d files and the bin directory left, got %d entries", pruned.Kept, len(entries))
+			}
+		})
+	}
+
+	pruned, err := pruneDir(path.Join(t.TempDir(), "snapshots"), now, 1, time.Hour)
+	if err != nil || len(pruned.Removed) != 0 || pruned.Kept != 0 {
+		t.Fatalf("expected nothing to prune in a missing directory, got %+v: %v", pruned, err)
+	}
+}
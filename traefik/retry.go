@@ -0,0 +1,46 @@
+package traefik
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/layer5io/meshery-traefik-mesh/internal/config"
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// retryOnTransient calls fn until it succeeds, returns a permanent error or the
+// configured number of attempts is exhausted. Only the errors which are likely to
+// go away on their own, like throttling and timeouts, are retried.
+func retryOnTransient(ctx context.Context, fn func() error) error {
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = config.KubeRetryInitialInterval()
+	b.MaxElapsedTime = config.BackoffCap(config.KubeBackoffPath)
+	attempts := config.KubeRetryMaxAttempts()
+
+	return backoff.Retry(func() error {
+		err := fn()
+		if err != nil && !isTransientKubeError(err) {
+			return backoff.Permanent(err)
+		}
+		return err
+	}, backoff.WithContext(backoff.WithMaxRetries(b, uint64(attempts-1)), ctx))
+}
+
+// isTransientKubeError reports whether the error returned by the kubernetes API
+// is worth retrying. Not found, forbidden and invalid requests never are.
+func isTransientKubeError(err error) bool {
+	switch {
+	case kubeerrors.IsTooManyRequests(err),
+		kubeerrors.IsServerTimeout(err),
+		kubeerrors.IsTimeout(err),
+		kubeerrors.IsInternalError(err),
+		kubeerrors.IsServiceUnavailable(err),
+		kubeerrors.IsUnexpectedServerError(err):
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
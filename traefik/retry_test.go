@@ -0,0 +1,117 @@
+package traefik
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	internalconfig "github.com/layer5io/meshery-traefik-mesh/internal/config"
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestRetryOnTransient(t *testing.T) {
+	t.Setenv(internalconfig.KubeRetryMaxAttemptsEnv, "3")
+	t.Setenv(internalconfig.KubeRetryInitialIntervalEnv, "1ms")
+
+	gr := schema.GroupResource{Group: smiSplitGroup, Resource: "trafficsplits"}
+	throttled := kubeerrors.NewTooManyRequests("client rate limited", 1)
+	timeout := &net.OpError{Op: "dial", Err: timeoutErr{}}
+	tests := []struct {
+		name     string
+		errs     []error
+		attempts int
+		failed   bool
+	}{
+		{name: "throttled then served", errs: []error{throttled}, attempts: 2},
+		{name: "server errors then served", errs: []error{kubeerrors.NewInternalError(net.ErrClosed), kubeerrors.NewServerTimeout(gr, "get", 1)}, attempts: 3},
+		{name: "network timeout then served", errs: []error{timeout}, attempts: 2},
+		{name: "attempts exhausted", errs: []error{throttled, throttled, throttled, throttled}, attempts: 3, failed: true},
+		{name: "not found is permanent", errs: []error{kubeerrors.NewNotFound(gr, "reviews")}, attempts: 1, failed: true},
+		{name: "forbidden is permanent", errs: []error{kubeerrors.NewForbidden(gr, "reviews", net.ErrClosed)}, attempts: 1, failed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dyn := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "split.smi-spec.io/v1alpha4",
+				"kind":       "TrafficSplit",
+				"metadata":   map[string]interface{}{"name": "reviews", "namespace": "bookinfo"},
+			}})
+			attempts := 0
+			dyn.PrependReactor("get", "trafficsplits", func(k8stesting.Action) (bool, runtime.Object, error) {
+				attempts++
+				if attempts <= len(tt.errs) {
+					return true, nil, tt.errs[attempts-1]
+				}
+				return false, nil, nil
+			})
+
+			var split *unstructured.Unstructured
+			err := retryOnTransient(context.Background(), func() (err error) {
+				split, err = dyn.Resource(trafficSplitGVR).Namespace("bookinfo").Get(context.Background(), "reviews", metav1.GetOptions{})
+				return err
+			})
+			if attempts != tt.attempts {
+				t.Errorf("expected %d attempts, got %d", tt.attempts, attempts)
+			}
+			if tt.failed {
+				if err == nil {
+					t.Fatal("expected the call to fail")
+				}
+				return
+			}
+			if err != nil || split.GetName() != "reviews" {
+				t.Fatalf("expected the split to be served, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRetryOnTransientStopsWithContext(t *testing.T) {
+	t.Setenv(internalconfig.KubeRetryInitialIntervalEnv, "1h")
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	err := retryOnTransient(ctx, func() error {
+		attempts++
+		cancel()
+		return kubeerrors.NewTooManyRequests("client rate limited", 1)
+	})
+	if err == nil || attempts != 1 {
+		t.Fatalf("expected the cancelled call to stop after one attempt, got %d attempts and %v", attempts, err)
+	}
+}
+
+// timeoutErr is a network error which timed out
+type timeoutErr struct{}
+
+func (timeoutErr) Error() string   { return "i/o timeout" }
+func (timeoutErr) Timeout() bool   { return true }
+func (timeoutErr) Temporary() bool { return true }
+
+func TestRetryOnTransientHonoursBackoffCap(t *testing.T) {
+	t.Setenv(internalconfig.KubeRetryMaxAttemptsEnv, "1000")
+	t.Setenv(internalconfig.KubeRetryInitialIntervalEnv, "5ms")
+	t.Setenv(internalconfig.BackoffMaxElapsedTimeEnv+"_"+internalconfig.KubeBackoffPath, "100ms")
+
+	attempts := 0
+	start := time.Now()
+	err := retryOnTransient(context.Background(), func() error {
+		attempts++
+		return kubeerrors.NewTooManyRequests("client rate limited", 1)
+	})
+	if !kubeerrors.IsTooManyRequests(err) {
+		t.Fatalf("expected the last error to be returned, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("retried for %s past the cap", elapsed)
+	}
+	if attempts < 2 || attempts >= 1000 {
+		t.Fatalf("expected the cap to stop the retries before the attempts run out, got %d attempts", attempts)
+	}
+}
@@ -0,0 +1,125 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// rollbackParams are the parameters of the rollback operation
+type rollbackParams struct {
+	// Revision is the helm revision to roll back to, the one preceding the deployed revision when it is 0
+	Revision int `yaml:"revision"`
+
+	namespaceLockParams `yaml:",inline"`
+	timeoutParams       `yaml:",inline"`
+}
+
+// rollbackResult is the outcome of the rollback in a cluster
+type rollbackResult struct {
+	Release     string `json:"release"`
+	Namespace   string `json:"namespace"`
+	FromVersion string `json:"from_version"`
+	// RolledBackTo is the revision whose chart and values were restored
+	RolledBackTo int `json:"rolled_back_to"`
+	// Revision is the revision created by the rollback
+	Revision int    `json:"revision"`
+	Version  string `json:"version"`
+}
+
+// rollbackTraefikMesh rolls the traefik mesh release of the namespace back to the revision in every
+// cluster and waits for the rollout
+func (mesh *Mesh) rollbackTraefikMesh(ctx context.Context, namespace string, params rollbackParams, kubeconfigs []string) (map[string]interface{}, error) {
+	if params.Revision < 0 {
+		return nil, ErrInvalidOperationParams(fmt.Errorf("revision must be a positive number, got %d", params.Revision))
+	}
+
+	return collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		name, releaseNamespace, err := findTraefikMeshRelease(ctx, kClient, namespace)
+		if err != nil {
+			return nil, ErrRollback(err)
+		}
+		if name == "" {
+			return nil, ErrRollback(fmt.Errorf("traefik mesh is not installed with helm in namespace %s", namespace))
+		}
+		cfg, err := mesh.helmActionConfig(kClient, releaseNamespace)
+		if err != nil {
+			return nil, ErrRollback(err)
+		}
+		current, target, err := rollbackRelease(cfg, name, params.Revision)
+		if err != nil {
+			return nil, ErrRollback(fmt.Errorf("release %s in namespace %s: %v", name, releaseNamespace, err))
+		}
+		if err := waitForMeshRollout(ctx, kClient, releaseNamespace); err != nil {
+			return nil, err
+		}
+		rolledBack, err := action.NewGet(cfg).Run(name)
+		if err != nil {
+			return nil, ErrRollback(err)
+		}
+		version := normalizeVersion(rolledBack.Chart.Metadata.AppVersion)
+		if err := recordInstallBackend(ctx, kClient, releaseNamespace, installBackendHelm, version); err != nil {
+			return nil, ErrRollback(err)
+		}
+		return rollbackResult{
+			Release:      name,
+			Namespace:    releaseNamespace,
+			FromVersion:  normalizeVersion(current.Chart.Metadata.AppVersion),
+			RolledBackTo: target.Version,
+			Revision:     rolledBack.Version,
+			Version:      version,
+		}, nil
+	})
+}
+
+// rollbackRelease rolls the release back to the revision, the one preceding the latest when revision is 0.
+// It returns the latest revision before the rollback along with the revision rolled back to.
+func rollbackRelease(cfg *action.Configuration, name string, revision int) (*release.Release, *release.Release, error) {
+	history, err := action.NewHistory(cfg).Run(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	current, target, err := rollbackTarget(history, revision)
+	if err != nil {
+		return nil, nil, err
+	}
+	rollback := action.NewRollback(cfg)
+	rollback.Version = target.Version
+	if err := rollback.Run(name); err != nil {
+		return nil, nil, err
+	}
+	return current, target, nil
+}
+
+// rollbackTarget returns the latest revision of the history along with the revision to roll back to,
+// the one preceding the latest when revision is 0
+func rollbackTarget(history []*release.Release, revision int) (*release.Release, *release.Release, error) {
+	if len(history) == 0 {
+		return nil, nil, fmt.Errorf("the release has no history")
+	}
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].Version < history[j].Version
+	})
+	current := history[len(history)-1]
+	if revision == 0 {
+		if len(history) < 2 {
+			return nil, nil, fmt.Errorf("revision %d is the only revision, there is nothing to roll back to", current.Version)
+		}
+		return current, history[len(history)-2], nil
+	}
+	if revision == current.Version {
+		return nil, nil, fmt.Errorf("revision %d is the latest revision", revision)
+	}
+	revisions := make([]int, 0, len(history))
+	for _, rel := range history {
+		if rel.Version == revision {
+			return current, rel, nil
+		}
+		revisions = append(revisions, rel.Version)
+	}
+	return nil, nil, fmt.Errorf("revision %d is not in the history, the revisions available are %v", revision, revisions)
+}
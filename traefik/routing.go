@@ -0,0 +1,191 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+)
+
+// maxServiceDepth bounds the nesting of the weighted services followed to the servers
+const maxServiceDepth = 5
+
+// routeTraceParams are the parameters of the route trace operation
+type routeTraceParams struct {
+	// Service is the name of the service the request is sent to
+	Service string `yaml:"service" required:"true"`
+	// Port restricts the trace to the routers of a port of the service
+	Port int `yaml:"port"`
+	// Request holds the attributes of the request, the host defaults to the mesh
+	// name of the service, the path to / and the method to GET
+	Request routeRequest `yaml:"request"`
+}
+
+// routerEvaluation is the outcome of matching the request against a router
+type routerEvaluation struct {
+	Router   string `json:"router"`
+	Rule     string `json:"rule"`
+	Priority int    `json:"priority"`
+	Matched  bool   `json:"matched"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// routeBackend is a service the request may be forwarded to and its share of the traffic
+type routeBackend struct {
+	Service string   `json:"service"`
+	Percent float64  `json:"percent"`
+	Servers []string `json:"servers,omitempty"`
+}
+
+// routeTrace explains how traefik mesh routes the request
+type routeTrace struct {
+	Request     routeRequest        `json:"request"`
+	Evaluated   []routerEvaluation  `json:"evaluated"`
+	Router      string              `json:"router,omitempty"`
+	Middlewares []chainedMiddleware `json:"middlewares,omitempty"`
+	Backends    []routeBackend      `json:"backends,omitempty"`
+	Explanation []string            `json:"explanation"`
+}
+
+// traceRoute computes, in every cluster, the router and the backends the request to
+// the service would be routed to from the configuration of the controller, which the
+// TrafficSplits, TrafficTargets and HTTPRouteGroups are translated to
+func (mesh *Mesh) traceRoute(ctx context.Context, namespace string, params routeTraceParams, kubeconfigs []string) (map[string]interface{}, error) {
+	if params.Service == "" {
+		return nil, ErrInvalidOperationParams(fmt.Errorf("service is required"))
+	}
+	req := params.Request
+	if req.Host == "" {
+		req.Host = fmt.Sprintf("%s.%s.maesh", params.Service, namespace)
+	}
+	if req.Path == "" {
+		req.Path = "/"
+	}
+	if req.Method == "" {
+		req.Method = "GET"
+	}
+	prefix := fmt.Sprintf("%s-%s-", params.Service, namespace)
+	if params.Port > 0 {
+		prefix = fmt.Sprintf("%s%d-", prefix, params.Port)
+	}
+
+	return collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		conf, err := fetchControllerConfiguration(ctx, kClient)
+		if err != nil {
+			return nil, ErrRouteTrace(err)
+		}
+		return traceRequest(conf, prefix, req), nil
+	})
+}
+
+// traceRequest matches the request against the routers of the services with the prefix,
+// by decreasing priority as traefik does, and resolves the service of the first match
+func traceRequest(conf *dynamicConfiguration, prefix string, req routeRequest) *routeTrace {
+	trace := &routeTrace{
+		Request:     req,
+		Evaluated:   []routerEvaluation{},
+		Explanation: []string{},
+	}
+	for name, router := range conf.HTTP.Routers {
+		if !strings.HasPrefix(router.Service, prefix) {
+			continue
+		}
+		priority := router.Priority
+		if priority == 0 {
+			priority = len(router.Rule)
+		}
+		trace.Evaluated = append(trace.Evaluated, routerEvaluation{Router: name, Rule: router.Rule, Priority: priority})
+	}
+	if len(trace.Evaluated) == 0 {
+		trace.Explanation = append(trace.Explanation, "No router of the controller configuration targets the service, it is not meshed or has no port matching the request")
+		return trace
+	}
+	sort.Slice(trace.Evaluated, func(i, j int) bool {
+		a, b := trace.Evaluated[i], trace.Evaluated[j]
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		return a.Router < b.Router
+	})
+
+	for i := range trace.Evaluated {
+		ev := &trace.Evaluated[i]
+		if trace.Router != "" {
+			ev.Reason = fmt.Sprintf("not evaluated, %s has a higher priority", trace.Router)
+			continue
+		}
+		node, err := parseRule(ev.Rule)
+		if err == nil {
+			ev.Matched, err = node.match(req)
+		}
+		switch {
+		case err != nil:
+			ev.Reason = err.Error()
+		case ev.Matched:
+			trace.Router = ev.Router
+		default:
+			ev.Reason = "the rule doesn't match the request"
+		}
+	}
+	if trace.Router == "" {
+		trace.Explanation = append(trace.Explanation, "No router matches the request, traefik mesh answers it with a 404")
+		return trace
+	}
+
+	router := conf.HTTP.Routers[trace.Router]
+	trace.Explanation = append(trace.Explanation, fmt.Sprintf("The request matches the rule of %s, the router with the highest priority among the matching ones", trace.Router))
+	for _, mw := range router.Middlewares {
+		typ := middlewareType(conf.HTTP.Middlewares[mw])
+		trace.Middlewares = append(trace.Middlewares, chainedMiddleware{Name: mw, Type: typ, Stage: middlewareStage(typ)})
+		if typ == "ipWhiteList" || typ == "ipAllowList" {
+			trace.Explanation = append(trace.Explanation, fmt.Sprintf("%s only lets through the sources allowed by the TrafficTargets of the service", mw))
+		}
+	}
+	trace.Backends = resolveBackends(conf, router.Service, 100, 0)
+	if len(trace.Backends) > 1 {
+		trace.Explanation = append(trace.Explanation, fmt.Sprintf("%s splits the traffic between %d backends as set by a TrafficSplit", router.Service, len(trace.Backends)))
+	}
+	return trace
+}
+
+// resolveBackends follows the weighted services down to the load balancers and
+// returns them along with the share of the traffic they receive
+func resolveBackends(conf *dynamicConfiguration, name string, percent float64, depth int) []routeBackend {
+	svc, ok := conf.HTTP.Services[name]
+	if !ok || depth > maxServiceDepth {
+		return []routeBackend{{Service: name, Percent: percent}}
+	}
+	if svc.Weighted == nil {
+		backend := routeBackend{Service: name, Percent: percent}
+		if svc.LoadBalancer != nil {
+			for _, s := range svc.LoadBalancer.Servers {
+				backend.Servers = append(backend.Servers, s.URL)
+			}
+		}
+		return []routeBackend{backend}
+	}
+
+	total := 0
+	for _, ws := range svc.Weighted.Services {
+		total += weightOf(ws.Weight)
+	}
+	var backends []routeBackend
+	for _, ws := range svc.Weighted.Services {
+		share := 0.0
+		if total > 0 {
+			share = percent * float64(weightOf(ws.Weight)) / float64(total)
+		}
+		backends = append(backends, resolveBackends(conf, ws.Name, share, depth+1)...)
+	}
+	return backends
+}
+
+// weightOf returns the weight of a weighted service, traefik defaults it to 1
+func weightOf(w *int) int {
+	if w == nil {
+		return 1
+	}
+	return *w
+}
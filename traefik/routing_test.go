@@ -0,0 +1,119 @@
+package traefik
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// routingConfiguration routes the reviews API to a split of two versions and the rest of the traffic directly
+const routingConfiguration = `{
+  "http": {
+    "routers": {
+      "bookinfo-reviews-9080-6d61657368-direct": {
+        "rule": "Host(` + "`reviews.bookinfo.maesh`" + `) || Host(` + "`10.96.0.12`" + `)",
+        "service": "reviews-bookinfo-9080-6d61657368",
+        "middlewares": ["reviews-retry"]
+      },
+      "bookinfo-reviews-9080-6d61657368-api": {
+        "rule": "(Host(` + "`reviews.bookinfo.maesh`" + `) || Host(` + "`10.96.0.12`" + `)) && PathPrefix(` + "`/api`" + `) && Method(` + "`POST`" + `)",
+        "service": "reviews-bookinfo-9080-split",
+        "middlewares": ["reviews-allow"]
+      },
+      "bookinfo-ratings-9080-6d61657368": {
+        "rule": "Host(` + "`ratings.bookinfo.maesh`" + `)",
+        "service": "ratings-bookinfo-9080-6d61657368"
+      }
+    },
+    "middlewares": {
+      "reviews-allow": {"ipAllowList": {"sourceRange": ["10.244.1.7"]}},
+      "reviews-retry": {"retry": {"attempts": 2}}
+    },
+    "services": {
+      "reviews-bookinfo-9080-6d61657368": {"loadBalancer": {"servers": [{"url": "http://10.244.1.10:9080"}]}},
+      "reviews-bookinfo-9080-split": {"weighted": {"services": [
+        {"name": "reviews-v1-bookinfo-9080", "weight": 3},
+        {"name": "reviews-v2-bookinfo-9080", "weight": 1}
+      ]}},
+      "reviews-v1-bookinfo-9080": {"loadBalancer": {"servers": [{"url": "http://10.244.1.11:9080"}, {"url": "http://10.244.2.11:9080"}]}},
+      "reviews-v2-bookinfo-9080": {"loadBalancer": {"servers": [{"url": "http://10.244.1.12:9080"}]}}
+    }
+  }
+}`
+
+func TestTraceRequest(t *testing.T) {
+	var conf dynamicConfiguration
+	if err := json.Unmarshal([]byte(routingConfiguration), &conf); err != nil {
+		t.Fatal(err)
+	}
+	direct := []routeBackend{{Service: "reviews-bookinfo-9080-6d61657368", Percent: 100, Servers: []string{"http://10.244.1.10:9080"}}}
+	split := []routeBackend{
+		{Service: "reviews-v1-bookinfo-9080", Percent: 75, Servers: []string{"http://10.244.1.11:9080", "http://10.244.2.11:9080"}},
+		{Service: "reviews-v2-bookinfo-9080", Percent: 25, Servers: []string{"http://10.244.1.12:9080"}},
+	}
+
+	tests := []struct {
+		name        string
+		prefix      string
+		req         routeRequest
+		router      string
+		middlewares []string
+		backends    []routeBackend
+		evaluated   int
+	}{
+		{
+			// the api router has the longer rule, it is evaluated first
+			name:        "request to the split api",
+			prefix:      "reviews-bookinfo-",
+			req:         routeRequest{Host: "reviews.bookinfo.maesh:9080", Path: "/api/v1/reviews", Method: "POST"},
+			router:      "bookinfo-reviews-9080-6d61657368-api",
+			middlewares: []string{"reviews-allow"},
+			backends:    split,
+			evaluated:   2,
+		},
+		{
+			name:        "request to the service IP",
+			prefix:      "reviews-bookinfo-9080-",
+			req:         routeRequest{Host: "10.96.0.12", Path: "/api/v1/reviews", Method: "GET"},
+			router:      "bookinfo-reviews-9080-6d61657368-direct",
+			middlewares: []string{"reviews-retry"},
+			backends:    direct,
+			evaluated:   2,
+		},
+		{
+			name:      "unknown host",
+			prefix:    "reviews-bookinfo-",
+			req:       routeRequest{Host: "reviews.default.maesh", Path: "/", Method: "GET"},
+			evaluated: 2,
+		},
+		{
+			name:   "service without router",
+			prefix: "details-bookinfo-",
+			req:    routeRequest{Host: "details.bookinfo.maesh", Path: "/", Method: "GET"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trace := traceRequest(&conf, tt.prefix, tt.req)
+			if trace.Router != tt.router {
+				t.Fatalf("expected the router %q, got %q: %+v", tt.router, trace.Router, trace.Evaluated)
+			}
+			if len(trace.Evaluated) != tt.evaluated {
+				t.Fatalf("expected %d evaluated routers, got %+v", tt.evaluated, trace.Evaluated)
+			}
+			var middlewares []string
+			for _, mw := range trace.Middlewares {
+				middlewares = append(middlewares, mw.Name)
+			}
+			if !reflect.DeepEqual(middlewares, tt.middlewares) {
+				t.Errorf("expected the middlewares %v, got %v", tt.middlewares, middlewares)
+			}
+			if !reflect.DeepEqual(trace.Backends, tt.backends) {
+				t.Errorf("expected the backends %+v, got %+v", tt.backends, trace.Backends)
+			}
+			if len(trace.Explanation) == 0 {
+				t.Error("the routing decision is not explained")
+			}
+		})
+	}
+}
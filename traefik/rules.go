@@ -0,0 +1,281 @@
+package traefik
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// routeRequest holds the attributes of a request matched against the router rules
+type routeRequest struct {
+	Host    string            `yaml:"host" json:"host"`
+	Path    string            `yaml:"path" json:"path"`
+	Method  string            `yaml:"method" json:"method"`
+	Headers map[string]string `yaml:"headers" json:"headers,omitempty"`
+}
+
+// header returns the value of the header, the names are case insensitive
+func (r routeRequest) header(name string) (string, bool) {
+	for k, v := range r.Headers {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// host returns the host of the request without its port
+func (r routeRequest) host() string {
+	if h, _, err := net.SplitHostPort(r.Host); err == nil {
+		return h
+	}
+	return r.Host
+}
+
+// ruleNode is a node of a parsed router rule
+type ruleNode interface {
+	match(req routeRequest) (bool, error)
+}
+
+type orNode struct{ left, right ruleNode }
+
+type andNode struct{ left, right ruleNode }
+
+type notNode struct{ node ruleNode }
+
+type matcherNode struct {
+	name string
+	args []string
+}
+
+func (n orNode) match(req routeRequest) (bool, error) {
+	ok, err := n.left.match(req)
+	if err != nil || ok {
+		return ok, err
+	}
+	return n.right.match(req)
+}
+
+func (n andNode) match(req routeRequest) (bool, error) {
+	ok, err := n.left.match(req)
+	if err != nil || !ok {
+		return ok, err
+	}
+	return n.right.match(req)
+}
+
+func (n notNode) match(req routeRequest) (bool, error) {
+	ok, err := n.node.match(req)
+	return !ok, err
+}
+
+// match evaluates the matchers generated by traefik mesh, the matchers depending on
+// attributes which are not part of the request (e.g. ClientIP) can't be evaluated
+func (n matcherNode) match(req routeRequest) (bool, error) {
+	switch n.name {
+	case "Host":
+		for _, arg := range n.args {
+			if strings.EqualFold(arg, req.host()) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "HostRegexp":
+		return matchTemplates(n.args, strings.ToLower(req.host()), `[^.]+`, true)
+	case "Path":
+		return matchTemplates(n.args, req.Path, `[^/]+`, true)
+	case "PathPrefix":
+		return matchTemplates(n.args, req.Path, `[^/]+`, false)
+	case "Method":
+		for _, arg := range n.args {
+			if strings.EqualFold(arg, req.Method) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "Headers", "HeadersRegexp":
+		if len(n.args) != 2 {
+			return false, fmt.Errorf("%s expects a header name and a value", n.name)
+		}
+		v, ok := req.header(n.args[0])
+		if !ok {
+			return false, nil
+		}
+		if n.name == "Headers" {
+			return v == n.args[1], nil
+		}
+		re, err := regexp.Compile(n.args[1])
+		if err != nil {
+			return false, err
+		}
+		return re.MatchString(v), nil
+	}
+	return false, fmt.Errorf("the %s matcher can't be evaluated", n.name)
+}
+
+// templateVariable matches the {name} and {name:regexp} variables of the path and host templates
+var templateVariable = regexp.MustCompile(`\{[^{}:]+(:[^{}]*(\{[^{}]*\}[^{}]*)*)?\}`)
+
+// matchTemplates reports whether the value matches any of the templates, a variable
+// without a regexp matches a segment (def). The whole value must match when exact is set.
+func matchTemplates(templates []string, value, def string, exact bool) (bool, error) {
+	for _, tpl := range templates {
+		var sb strings.Builder
+		sb.WriteString("^")
+		last := 0
+		for _, loc := range templateVariable.FindAllStringIndex(tpl, -1) {
+			sb.WriteString(regexp.QuoteMeta(tpl[last:loc[0]]))
+			variable := tpl[loc[0]+1 : loc[1]-1]
+			if i := strings.Index(variable, ":"); i >= 0 {
+				sb.WriteString("(?:" + variable[i+1:] + ")")
+			} else {
+				sb.WriteString(def)
+			}
+			last = loc[1]
+		}
+		sb.WriteString(regexp.QuoteMeta(tpl[last:]))
+		if exact {
+			sb.WriteString("$")
+		}
+		re, err := regexp.Compile(sb.String())
+		if err != nil {
+			return false, err
+		}
+		if re.MatchString(value) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ruleParser parses the rules of the traefik routers, e.g.
+// Host(`svc.ns.maesh`) && (PathPrefix(`/api`) || !Method(`GET`))
+type ruleParser struct {
+	rule string
+	pos  int
+}
+
+// parseRule parses the router rule
+func parseRule(rule string) (ruleNode, error) {
+	p := &ruleParser{rule: rule}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if p.pos != len(p.rule) {
+		return nil, fmt.Errorf("unexpected %q at position %d of rule %q", p.rule[p.pos:], p.pos, rule)
+	}
+	return node, nil
+}
+
+func (p *ruleParser) parseOr() (ruleNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *ruleParser) parseAnd() (ruleNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *ruleParser) parseUnary() (ruleNode, error) {
+	switch {
+	case p.consume("!"):
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{node: node}, nil
+	case p.consume("("):
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(")") {
+			return nil, fmt.Errorf("missing ) in rule %q", p.rule)
+		}
+		return node, nil
+	}
+	return p.parseMatcher()
+}
+
+func (p *ruleParser) parseMatcher() (ruleNode, error) {
+	p.skipSpaces()
+	start := p.pos
+	for p.pos < len(p.rule) && (unicode.IsLetter(rune(p.rule[p.pos])) || unicode.IsDigit(rune(p.rule[p.pos]))) {
+		p.pos++
+	}
+	name := p.rule[start:p.pos]
+	if name == "" || !p.consume("(") {
+		return nil, fmt.Errorf("expected a matcher at position %d of rule %q", start, p.rule)
+	}
+	node := matcherNode{name: name}
+	for {
+		arg, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		node.args = append(node.args, arg)
+		if p.consume(")") {
+			return node, nil
+		}
+		if !p.consume(",") {
+			return nil, fmt.Errorf("expected , or ) at position %d of rule %q", p.pos, p.rule)
+		}
+	}
+}
+
+// parseString parses a string quoted with backticks or double quotes
+func (p *ruleParser) parseString() (string, error) {
+	p.skipSpaces()
+	if p.pos >= len(p.rule) || (p.rule[p.pos] != '`' && p.rule[p.pos] != '"') {
+		return "", fmt.Errorf("expected a quoted string at position %d of rule %q", p.pos, p.rule)
+	}
+	quote := p.rule[p.pos]
+	end := strings.IndexByte(p.rule[p.pos+1:], quote)
+	if end < 0 {
+		return "", fmt.Errorf("unterminated string at position %d of rule %q", p.pos, p.rule)
+	}
+	s := p.rule[p.pos+1 : p.pos+1+end]
+	p.pos += end + 2
+	return s, nil
+}
+
+// consume skips the token if it is next in the rule
+func (p *ruleParser) consume(token string) bool {
+	p.skipSpaces()
+	if strings.HasPrefix(p.rule[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+func (p *ruleParser) skipSpaces() {
+	for p.pos < len(p.rule) && p.rule[p.pos] == ' ' {
+		p.pos++
+	}
+}
@@ -1,6 +1,7 @@
 package traefik
 
 import (
+	"context"
 	"sync"
 
 	"github.com/layer5io/meshery-adapter-library/adapter"
@@ -15,6 +16,15 @@ func (mesh *Mesh) installSampleApp(namespace string, del bool, templates []adapt
 		st = status.Removing
 	}
 
+	if !del {
+		_, err := collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+			return nil, ensureNamespace(context.TODO(), kClient, namespace)
+		})
+		if err != nil {
+			return st, ErrSampleApp(err)
+		}
+	}
+
 	for _, template := range templates {
 		err := mesh.applyManifest([]byte(template.String()), del, namespace, kubeconfigs)
 		if err != nil {
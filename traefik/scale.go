@@ -0,0 +1,236 @@
+package traefik
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	// maxControllerReplicas is the highest number of controller replicas accepted, a single
+	// replica is active at a time, the others stand by
+	maxControllerReplicas = 10
+)
+
+var (
+	// proxyCPURange and proxyMemoryRange bound the proxy resources accepted by the scale operation
+	proxyCPURange    = [2]resource.Quantity{resource.MustParse("10m"), resource.MustParse("8")}
+	proxyMemoryRange = [2]resource.Quantity{resource.MustParse("16Mi"), resource.MustParse("16Gi")}
+)
+
+// scaleParams are the parameters of the scale operation, the settings left empty are kept.
+// The changes are overwritten by the next install or upgrade of traefik mesh.
+type scaleParams struct {
+	// ControllerReplicas is the number of replicas of the controller deployment
+	ControllerReplicas *int32 `yaml:"controllerReplicas"`
+	// ProxyRequests and ProxyLimits are the resources of the proxy daemonset containers
+	ProxyRequests proxyResources `yaml:"proxyRequests"`
+	ProxyLimits   proxyResources `yaml:"proxyLimits"`
+
+	timeoutParams `yaml:",inline"`
+}
+
+// proxyResources are the cpu and memory quantities of a proxy container, e.g. 100m and 128Mi
+type proxyResources struct {
+	CPU    string `yaml:"cpu"`
+	Memory string `yaml:"memory"`
+}
+
+// scaledWorkload is a deployment or a daemonset changed by the scale operation
+type scaledWorkload struct {
+	Kind      string            `json:"kind"`
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace"`
+	Replicas  *int32            `json:"replicas,omitempty"`
+	Requests  map[string]string `json:"requests,omitempty"`
+	Limits    map[string]string `json:"limits,omitempty"`
+}
+
+// validate checks that a setting is given and that the values are within the accepted ranges,
+// it returns the requests and the limits of the proxies
+func (p scaleParams) validate() (corev1.ResourceList, corev1.ResourceList, error) {
+	requests, err := p.ProxyRequests.resourceList("proxyRequests")
+	if err != nil {
+		return nil, nil, err
+	}
+	limits, err := p.ProxyLimits.resourceList("proxyLimits")
+	if err != nil {
+		return nil, nil, err
+	}
+	if p.ControllerReplicas == nil && len(requests) == 0 && len(limits) == 0 {
+		return nil, nil, ErrInvalidOperationParams(fmt.Errorf("at least one of controllerReplicas, proxyRequests or proxyLimits is required"))
+	}
+	if r := p.ControllerReplicas; r != nil && (*r < 1 || *r > maxControllerReplicas) {
+		return nil, nil, ErrInvalidOperationParams(fmt.Errorf("controllerReplicas must be between 1 and %d, got %d", maxControllerReplicas, *r))
+	}
+	for name, req := range requests {
+		if limit, ok := limits[name]; ok && req.Cmp(limit) > 0 {
+			return nil, nil, ErrInvalidOperationParams(fmt.Errorf("proxyRequests.%s %s exceeds proxyLimits.%s %s", name, req.String(), name, limit.String()))
+		}
+	}
+	return requests, limits, nil
+}
+
+// resourceList parses the quantities set and checks they are within the accepted ranges
+func (pr proxyResources) resourceList(field string) (corev1.ResourceList, error) {
+	list := corev1.ResourceList{}
+	for _, r := range []struct {
+		name  corev1.ResourceName
+		value string
+		rng   [2]resource.Quantity
+	}{
+		{corev1.ResourceCPU, pr.CPU, proxyCPURange},
+		{corev1.ResourceMemory, pr.Memory, proxyMemoryRange},
+	} {
+		if r.value == "" {
+			continue
+		}
+		q, err := resource.ParseQuantity(r.value)
+		if err != nil {
+			return nil, ErrInvalidOperationParams(fmt.Errorf("%s.%s: %v", field, r.name, err))
+		}
+		if q.Cmp(r.rng[0]) < 0 || q.Cmp(r.rng[1]) > 0 {
+			return nil, ErrInvalidOperationParams(fmt.Errorf("%s.%s must be between %s and %s, got %s", field, r.name, r.rng[0].String(), r.rng[1].String(), r.value))
+		}
+		list[r.name] = q
+	}
+	return list, nil
+}
+
+// scaleTraefikMesh scales the controller and updates the resources of the proxies in every cluster, the
+// new values are streamed along e once applied. It returns once the rollouts completed.
+func (mesh *Mesh) scaleTraefikMesh(ctx context.Context, namespace string, params scaleParams, e *meshes.EventsResponse, kubeconfigs []string) (map[string]interface{}, error) {
+	requests, limits, err := params.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		var scaled []scaledWorkload
+		if params.ControllerReplicas != nil {
+			controllers, err := scaleControllers(ctx, kClient, namespace, *params.ControllerReplicas)
+			if err != nil {
+				return nil, ErrScale(err)
+			}
+			scaled = append(scaled, controllers...)
+		}
+		if len(requests) != 0 || len(limits) != 0 {
+			proxies, err := setProxyResources(ctx, kClient, namespace, requests, limits)
+			if err != nil {
+				return nil, ErrScale(err)
+			}
+			scaled = append(scaled, proxies...)
+		}
+		if len(scaled) == 0 {
+			return nil, ErrScale(fmt.Errorf("traefik mesh is not installed in namespace %s", namespace))
+		}
+
+		if byt, err := json.Marshal(scaled); err == nil {
+			mesh.streamUpdate(e, fmt.Sprintf("Traefik service mesh scaled in %s, waiting for the rollout", kClient.RestConfig.Host), string(byt))
+		}
+		if err := waitForRollout(ctx, kClient, namespace, scaled); err != nil {
+			return nil, err
+		}
+		return scaled, nil
+	})
+}
+
+// scaleControllers sets the replicas of the controller deployments of the namespace
+func scaleControllers(ctx context.Context, kClient *mesherykube.Client, namespace string, replicas int32) ([]scaledWorkload, error) {
+	var deps *appsv1.DeploymentList
+	err := retryOnTransient(ctx, func() (err error) {
+		deps, err = kClient.KubeClient.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{LabelSelector: controllerSelector})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	patch := []byte(fmt.Sprintf(`{"spec":{"replicas":%d}}`, replicas))
+	var scaled []scaledWorkload
+	for _, dep := range deps.Items {
+		err := retryOnTransient(ctx, func() error {
+			_, err := kClient.KubeClient.AppsV1().Deployments(namespace).Patch(ctx, dep.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		r := replicas
+		scaled = append(scaled, scaledWorkload{Kind: "Deployment", Name: dep.Name, Namespace: namespace, Replicas: &r})
+	}
+	return scaled, nil
+}
+
+// setProxyResources merges the requests and limits into the resources of the containers of the proxy daemonsets of the namespace
+func setProxyResources(ctx context.Context, kClient *mesherykube.Client, namespace string, requests, limits corev1.ResourceList) ([]scaledWorkload, error) {
+	var dss *appsv1.DaemonSetList
+	err := retryOnTransient(ctx, func() (err error) {
+		dss, err = kClient.KubeClient.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{LabelSelector: proxyPodSelector})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	var scaled []scaledWorkload
+	for _, item := range dss.Items {
+		var ds *appsv1.DaemonSet
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() (err error) {
+			ds, err = kClient.KubeClient.AppsV1().DaemonSets(namespace).Get(ctx, item.Name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			for i := range ds.Spec.Template.Spec.Containers {
+				res := &ds.Spec.Template.Spec.Containers[i].Resources
+				res.Requests = mergeResources(res.Requests, requests)
+				res.Limits = mergeResources(res.Limits, limits)
+			}
+			ds, err = kClient.KubeClient.AppsV1().DaemonSets(namespace).Update(ctx, ds, metav1.UpdateOptions{})
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		w := scaledWorkload{Kind: "DaemonSet", Name: ds.Name, Namespace: namespace}
+		if containers := ds.Spec.Template.Spec.Containers; len(containers) > 0 {
+			w.Requests = describeResources(containers[0].Resources.Requests)
+			w.Limits = describeResources(containers[0].Resources.Limits)
+		}
+		scaled = append(scaled, w)
+	}
+	return scaled, nil
+}
+
+// mergeResources returns the resources with the quantities of overrides replacing theirs
+func mergeResources(resources, overrides corev1.ResourceList) corev1.ResourceList {
+	if len(overrides) == 0 {
+		return resources
+	}
+	merged := corev1.ResourceList{}
+	for name, q := range resources {
+		merged[name] = q
+	}
+	for name, q := range overrides {
+		merged[name] = q
+	}
+	return merged
+}
+
+func describeResources(resources corev1.ResourceList) map[string]string {
+	if len(resources) == 0 {
+		return nil
+	}
+	desc := make(map[string]string, len(resources))
+	for name, q := range resources {
+		desc[string(name)] = q.String()
+	}
+	return desc
+}
@@ -0,0 +1,172 @@
+package traefik
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	shadowSelectsNoProxy = "shadow_selects_no_proxy"
+	shadowedServiceGone  = "shadowed_service_missing"
+	serviceSelectsNoPod  = "service_selects_no_pod"
+)
+
+// selectorLinkageParams are the parameters of the selector linkage operation
+type selectorLinkageParams struct {
+	// Reconcile points the selector of the shadow services which select no proxy back
+	// to the proxy pods. The selectors of the meshed services are only reported.
+	Reconcile bool `yaml:"reconcile"`
+}
+
+// selectorMismatch is a broken link between a shadow service, the proxies and the service it shadows
+type selectorMismatch struct {
+	Shadow  string `json:"shadow"`
+	Service string `json:"service"`
+	Issue   string `json:"issue"`
+	Details string `json:"details"`
+	Fixed   bool   `json:"fixed"`
+}
+
+// checkSelectorLinkage verifies, in every cluster, that each shadow service selects the
+// proxy pods and that the service it shadows still exists and selects pods
+func (mesh *Mesh) checkSelectorLinkage(ctx context.Context, params selectorLinkageParams, kubeconfigs []string) (map[string]interface{}, error) {
+	proxyLabels, err := labels.ConvertSelectorToLabelsMap(proxyPodSelector)
+	if err != nil {
+		return nil, ErrSelectorLinkage(err)
+	}
+	return collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		var shadows *corev1.ServiceList
+		err := retryOnTransient(ctx, func() (err error) {
+			shadows, err = kClient.KubeClient.CoreV1().Services("").List(ctx, metav1.ListOptions{LabelSelector: shadowServiceSelector})
+			return err
+		})
+		if err != nil {
+			return nil, ErrSelectorLinkage(err)
+		}
+
+		// the pods are listed once per namespace, the shadow services share the mesh namespace
+		pods := map[string][]corev1.Pod{}
+		podsOf := func(namespace string) ([]corev1.Pod, error) {
+			if p, ok := pods[namespace]; ok {
+				return p, nil
+			}
+			var list *corev1.PodList
+			err := retryOnTransient(ctx, func() (err error) {
+				list, err = kClient.KubeClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+				return err
+			})
+			if err != nil {
+				return nil, err
+			}
+			pods[namespace] = list.Items
+			return list.Items, nil
+		}
+
+		mismatches := []selectorMismatch{}
+		for _, shadow := range shadows.Items {
+			name, namespace, ok := shadowedService(shadow)
+			if !ok {
+				continue
+			}
+			m := selectorMismatch{
+				Shadow:  fmt.Sprintf("%s/%s", shadow.Namespace, shadow.Name),
+				Service: fmt.Sprintf("%s/%s", namespace, name),
+			}
+
+			proxies, err := podsOf(shadow.Namespace)
+			if err != nil {
+				return nil, ErrSelectorLinkage(err)
+			}
+			if !selectsAny(shadow.Spec.Selector, proxies) {
+				m.Issue = shadowSelectsNoProxy
+				m.Details = fmt.Sprintf("the selector %s of the shadow service matches no proxy pod, the requests to the service are not routed", labels.Set(shadow.Spec.Selector))
+				if params.Reconcile {
+					if err := setServiceSelector(ctx, kClient, shadow, proxyLabels); err != nil {
+						return nil, ErrSelectorLinkage(err)
+					}
+					m.Fixed = true
+				}
+				mismatches = append(mismatches, m)
+			}
+
+			var svc *corev1.Service
+			err = retryOnTransient(ctx, func() (err error) {
+				svc, err = kClient.KubeClient.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+				return err
+			})
+			if kubeerrors.IsNotFound(err) {
+				m.Issue = shadowedServiceGone
+				m.Details = "the shadowed service no longer exists, the controller removes the shadow service once it catches up"
+				m.Fixed = false
+				mismatches = append(mismatches, m)
+				continue
+			}
+			if err != nil {
+				return nil, ErrSelectorLinkage(err)
+			}
+			// the services without a selector have their endpoints managed by hand
+			if len(svc.Spec.Selector) == 0 {
+				continue
+			}
+			backends, err := podsOf(namespace)
+			if err != nil {
+				return nil, ErrSelectorLinkage(err)
+			}
+			if !selectsAny(svc.Spec.Selector, backends) {
+				m.Issue = serviceSelectsNoPod
+				m.Details = fmt.Sprintf("the selector %s of the service matches no pod, the proxies have no endpoint to route to", labels.Set(svc.Spec.Selector))
+				m.Fixed = false
+				mismatches = append(mismatches, m)
+			}
+		}
+		sort.SliceStable(mismatches, func(i, j int) bool {
+			return mismatches[i].Shadow < mismatches[j].Shadow
+		})
+		return mismatches, nil
+	})
+}
+
+// selectsAny reports whether the service selector matches any of the pods, an empty selector matches none
+func selectsAny(selector map[string]string, pods []corev1.Pod) bool {
+	if len(selector) == 0 {
+		return false
+	}
+	sel := labels.SelectorFromSet(selector)
+	for _, pod := range pods {
+		if sel.Matches(labels.Set(pod.Labels)) {
+			return true
+		}
+	}
+	return false
+}
+
+// setServiceSelector replaces the selector of the service
+func setServiceSelector(ctx context.Context, kClient *mesherykube.Client, svc corev1.Service, selector map[string]string) error {
+	// the null removes the keys of the current selector which are not part of the new one
+	sel := map[string]interface{}{}
+	for k := range svc.Spec.Selector {
+		sel[k] = nil
+	}
+	for k, v := range selector {
+		sel[k] = v
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{"selector": sel},
+	})
+	if err != nil {
+		return err
+	}
+	return retryOnTransient(ctx, func() error {
+		_, err := kClient.KubeClient.CoreV1().Services(svc.Namespace).Patch(ctx, svc.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		return err
+	})
+}
@@ -0,0 +1,107 @@
+package traefik
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// pod returns a pod of the namespace with the labels
+func pod(namespace, name string, lbls map[string]string) corev1.Pod {
+	return corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: lbls}}
+}
+
+func TestCheckSelectorLinkage(t *testing.T) {
+	shadow := func(service string, selector map[string]string) corev1.Service {
+		return corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "traefik-mesh", Name: "traefik-mesh-" + service + "-6d61657368-bookinfo", Labels: map[string]string{"app": "maesh", "type": "shadow"}},
+			Spec:       corev1.ServiceSpec{Selector: selector},
+		}
+	}
+	backing := func(name string, selector map[string]string) corev1.Service {
+		return corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "bookinfo", Name: name},
+			Spec:       corev1.ServiceSpec{Selector: selector},
+		}
+	}
+	proxies := map[string]string{"app": "maesh", "component": "maesh-mesh"}
+	responses := map[string]interface{}{
+		"/api/v1/services": corev1.ServiceList{Items: []corev1.Service{
+			shadow("reviews", proxies),
+			// the selector of a previous chart version
+			shadow("ratings", map[string]string{"app": "maesh", "role": "proxy"}),
+			shadow("details", proxies),
+			shadow("external", proxies),
+		}},
+		"/api/v1/namespaces/traefik-mesh/pods": corev1.PodList{Items: []corev1.Pod{
+			pod("traefik-mesh", "traefik-mesh-proxy-x2k4f", proxies),
+			pod("traefik-mesh", "traefik-mesh-controller-7d9c5", map[string]string{"app": "maesh", "component": "controller"}),
+		}},
+		"/api/v1/namespaces/bookinfo/pods": corev1.PodList{Items: []corev1.Pod{
+			pod("bookinfo", "reviews-v1-5b8f7", map[string]string{"app": "reviews", "version": "v1"}),
+			pod("bookinfo", "ratings-v1-6c9d4", map[string]string{"app": "rating", "version": "v1"}),
+		}},
+		"/api/v1/namespaces/bookinfo/services/reviews":  backing("reviews", map[string]string{"app": "reviews"}),
+		"/api/v1/namespaces/bookinfo/services/ratings":  backing("ratings", map[string]string{"app": "ratings"}),
+		"/api/v1/namespaces/bookinfo/services/external": backing("external", nil),
+		// the patched shadow service
+		"/api/v1/namespaces/traefik-mesh/services/traefik-mesh-ratings-6d61657368-bookinfo": shadow("ratings", proxies),
+	}
+
+	tests := []struct {
+		name    string
+		params  selectorLinkageParams
+		fixed   bool
+		patches []string
+	}{
+		{name: "report", patches: []string{}},
+		{
+			name:    "reconcile",
+			params:  selectorLinkageParams{Reconcile: true},
+			fixed:   true,
+			patches: []string{`/api/v1/namespaces/traefik-mesh/services/traefik-mesh-ratings-6d61657368-bookinfo {"spec":{"selector":{"app":"maesh","component":"maesh-mesh","role":null}}}`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patches := []string{}
+			srv := fakeAPIServer(t, responses, &patches)
+
+			res, err := (&Mesh{}).checkSelectorLinkage(context.Background(), tt.params, []string{fakeKubeconfig(srv.URL)})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			want := []selectorMismatch{
+				{
+					Shadow:  "traefik-mesh/traefik-mesh-details-6d61657368-bookinfo",
+					Service: "bookinfo/details",
+					Issue:   shadowedServiceGone,
+					Details: "the shadowed service no longer exists, the controller removes the shadow service once it catches up",
+				},
+				{
+					Shadow:  "traefik-mesh/traefik-mesh-ratings-6d61657368-bookinfo",
+					Service: "bookinfo/ratings",
+					Issue:   shadowSelectsNoProxy,
+					Details: "the selector app=maesh,role=proxy of the shadow service matches no proxy pod, the requests to the service are not routed",
+					Fixed:   tt.fixed,
+				},
+				{
+					// the selector of the service is only reported
+					Shadow:  "traefik-mesh/traefik-mesh-ratings-6d61657368-bookinfo",
+					Service: "bookinfo/ratings",
+					Issue:   serviceSelectsNoPod,
+					Details: "the selector app=ratings of the service matches no pod, the proxies have no endpoint to route to",
+				},
+			}
+			if got := res["fake-cluster"]; !reflect.DeepEqual(got, want) {
+				t.Errorf("expected the mismatches %+v, got %+v", want, got)
+			}
+			if !reflect.DeepEqual(patches, tt.patches) {
+				t.Errorf("expected the patches %q, got %q", tt.patches, patches)
+			}
+		})
+	}
+}
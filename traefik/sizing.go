@@ -0,0 +1,75 @@
+package traefik
+
+import (
+	"context"
+
+	internalconfig "github.com/layer5io/meshery-traefik-mesh/internal/config"
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// clusterSize is the sizing suited to a cluster given its number of nodes
+type clusterSize struct {
+	Nodes  int    `json:"nodes"`
+	Sizing string `json:"sizing"`
+}
+
+// sizeCluster counts the nodes of the cluster and selects the matching sizing
+func sizeCluster(ctx context.Context, kClient *mesherykube.Client) (clusterSize, error) {
+	var nodes *corev1.NodeList
+	err := retryOnTransient(ctx, func() (err error) {
+		nodes, err = kClient.KubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return clusterSize{}, ErrClusterSizing(err)
+	}
+	return clusterSize{
+		Nodes:  len(nodes.Items),
+		Sizing: internalconfig.SizingForNodes(len(nodes.Items)),
+	}, nil
+}
+
+// sizeClusters selects the sizing of every cluster, keyed by cluster
+func sizeClusters(ctx context.Context, kubeconfigs []string) (map[string]clusterSize, error) {
+	res, err := collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		return sizeCluster(ctx, kClient)
+	})
+	if err != nil {
+		return nil, err
+	}
+	sizes := make(map[string]clusterSize, len(res))
+	for cluster, r := range res {
+		sizes[cluster] = r.(clusterSize)
+	}
+	return sizes, nil
+}
+
+// sizingOverrides returns the helm values of the sizing of every cluster, keyed by cluster
+func sizingOverrides(sizes map[string]clusterSize) map[string]map[string]interface{} {
+	overrides := make(map[string]map[string]interface{}, len(sizes))
+	for cluster, size := range sizes {
+		overrides[cluster] = internalconfig.Sizings[size.Sizing]
+	}
+	return overrides
+}
+
+// describeSizing adds the sizing selected for each cluster to the decisions taken for it
+func describeSizing(decisions map[string]string, sizes map[string]clusterSize) map[string]string {
+	if len(sizes) == 0 {
+		return decisions
+	}
+	described := make(map[string]string, len(decisions)+len(sizes))
+	for cluster, decision := range decisions {
+		described[cluster] = decision
+	}
+	for cluster, size := range sizes {
+		sizing := internalconfig.DescribeSizing(size.Sizing, size.Nodes)
+		if d, ok := described[cluster]; ok {
+			sizing = d + ", " + sizing
+		}
+		described[cluster] = sizing
+	}
+	return described
+}
@@ -0,0 +1,68 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	internalconfig "github.com/layer5io/meshery-traefik-mesh/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// nodes returns a list of n nodes
+func nodes(n int) corev1.NodeList {
+	list := corev1.NodeList{}
+	for i := 0; i < n; i++ {
+		list.Items = append(list.Items, corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("node-%d", i)}})
+	}
+	return list
+}
+
+func TestSizeCluster(t *testing.T) {
+	tests := []struct {
+		nodes  int
+		sizing string
+	}{
+		{nodes: 1, sizing: internalconfig.SmallSizing},
+		{nodes: 3, sizing: internalconfig.SmallSizing},
+		{nodes: 4, sizing: internalconfig.MediumSizing},
+		{nodes: 20, sizing: internalconfig.MediumSizing},
+		{nodes: 21, sizing: internalconfig.LargeSizing},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d nodes", tt.nodes), func(t *testing.T) {
+			kClient := fakeClient(t, map[string]interface{}{"/api/v1/nodes": nodes(tt.nodes)})
+			size, err := sizeCluster(context.Background(), kClient)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if size != (clusterSize{Nodes: tt.nodes, Sizing: tt.sizing}) {
+				t.Fatalf("expected the %s sizing for %d nodes, got %+v", tt.sizing, tt.nodes, size)
+			}
+		})
+	}
+}
+
+func TestDescribeSizing(t *testing.T) {
+	decisions := map[string]string{"dev": "installed the chart 4.1.1"}
+	sizes := map[string]clusterSize{
+		"dev":  {Nodes: 2, Sizing: internalconfig.SmallSizing},
+		"prod": {Nodes: 40, Sizing: internalconfig.LargeSizing},
+	}
+	want := map[string]string{
+		"dev":  "installed the chart 4.1.1, small sizing for 2 nodes",
+		"prod": "large sizing for 40 nodes",
+	}
+	if got := describeSizing(decisions, sizes); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected the decisions %v, got %v", want, got)
+	}
+	if got := describeSizing(decisions, nil); !reflect.DeepEqual(got, decisions) {
+		t.Fatalf("the decisions changed without sizing: %v", got)
+	}
+	overrides := sizingOverrides(sizes)
+	if !reflect.DeepEqual(overrides["prod"], internalconfig.Sizings[internalconfig.LargeSizing]) {
+		t.Fatalf("expected the values of the large sizing, got %v", overrides["prod"])
+	}
+}
@@ -0,0 +1,57 @@
+package traefik
+
+import (
+	"strings"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+)
+
+// smiConformanceCategories are the categories the SMI conformance cases are reported by
+var smiConformanceCategories = []string{"traffic-access", "traffic-specs", "traffic-split", "traffic-metrics"}
+
+// conformanceCategory is the outcome of the SMI conformance cases of a category
+type conformanceCategory struct {
+	Category string            `json:"category"`
+	Passed   int               `json:"passed"`
+	Failed   int               `json:"failed"`
+	Cases    []*adapter.Detail `json:"cases"`
+}
+
+// smiConformanceResult is the result of the SMI conformance operation, Raw is the
+// response of the conformance runner for the server to persist as is
+type smiConformanceResult struct {
+	Categories []conformanceCategory `json:"categories"`
+	Raw        adapter.Response      `json:"raw"`
+}
+
+// summarizeConformance groups the conformance cases by category, the categories without cases are reported empty
+func summarizeConformance(resp adapter.Response) *smiConformanceResult {
+	categories := make([]conformanceCategory, len(smiConformanceCategories))
+	for i, name := range smiConformanceCategories {
+		categories[i] = conformanceCategory{Category: name, Cases: []*adapter.Detail{}}
+	}
+	for _, d := range resp.MoreDetails {
+		i := conformanceCategoryOf(d.SmiSpecification)
+		if i < 0 {
+			continue
+		}
+		categories[i].Cases = append(categories[i].Cases, d)
+		if strings.EqualFold(d.Status, "passed") {
+			categories[i].Passed++
+		} else {
+			categories[i].Failed++
+		}
+	}
+	return &smiConformanceResult{Categories: categories, Raw: resp}
+}
+
+// conformanceCategoryOf returns the index of the category of the SMI specification, -1 if it has none
+func conformanceCategoryOf(spec string) int {
+	spec = strings.ToLower(spec)
+	for i, keyword := range []string{"access", "spec", "split", "metric"} {
+		if strings.Contains(spec, keyword) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,238 @@
+package traefik
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/layer5io/meshery-traefik-mesh/internal/config"
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// maxCountSnapshots bounds the number of snapshots kept per cluster, the oldest are dropped first
+	maxCountSnapshots = 100
+
+	// middlewareKind counts the middlewares of the dynamic configuration built by the controller
+	middlewareKind = "Middleware"
+)
+
+// countSnapshotsMx serializes the updates of the snapshot file
+var countSnapshotsMx sync.Mutex
+
+// countSnapshot is the number of SMI resources and middlewares of a cluster at a point in time
+type countSnapshot struct {
+	Time   time.Time      `json:"time"`
+	Counts map[string]int `json:"counts"`
+}
+
+// countTrend is the growth of the resource counts since the oldest stored snapshot
+type countTrend struct {
+	Since     time.Time          `json:"since"`
+	Snapshots int                `json:"snapshots"`
+	Changes   map[string]int     `json:"changes"`
+	PerDay    map[string]float64 `json:"per_day"`
+}
+
+// resourceCounts is the result of the SMI counts operation for a cluster
+type resourceCounts struct {
+	Counts map[string]int `json:"counts"`
+	Trend  *countTrend    `json:"trend,omitempty"`
+}
+
+// reportResourceCounts counts the SMI resources and middlewares of every cluster, stores
+// the counts as a snapshot and reports the trend over the stored snapshots
+func (mesh *Mesh) reportResourceCounts(ctx context.Context, kubeconfigs []string) (map[string]interface{}, error) {
+	now := time.Now()
+	res, err := collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		return countResources(ctx, kClient)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	countSnapshotsMx.Lock()
+	defer countSnapshotsMx.Unlock()
+	stored, err := loadCountSnapshots()
+	if err != nil {
+		return nil, ErrSMICounts(err)
+	}
+	report := make(map[string]interface{}, len(res))
+	for cluster, r := range res {
+		counts := r.(map[string]int)
+		report[cluster] = resourceCounts{
+			Counts: counts,
+			Trend:  trendOf(stored[cluster], counts, now),
+		}
+		snapshots := append(stored[cluster], countSnapshot{Time: now, Counts: counts})
+		if len(snapshots) > maxCountSnapshots {
+			snapshots = snapshots[len(snapshots)-maxCountSnapshots:]
+		}
+		stored[cluster] = snapshots
+	}
+	if err := saveCountSnapshots(stored); err != nil {
+		return nil, ErrSMICounts(err)
+	}
+	return report, nil
+}
+
+// smiResource is an SMI resource type served by the cluster
+type smiResource struct {
+	kind string
+	gvr  schema.GroupVersionResource
+}
+
+// servedSMIResources returns the SMI resource types of the CRDs installed in the cluster
+func servedSMIResources(ctx context.Context, kClient *mesherykube.Client) ([]smiResource, error) {
+	var crds *unstructured.UnstructuredList
+	err := retryOnTransient(ctx, func() (err error) {
+		crds, err = kClient.DynamicKubeClient.Resource(crdResource).List(ctx, metav1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []smiResource
+	for _, crd := range crds.Items {
+		group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+		if group != smiSplitGroup && group != smiSpecsGroup && group != smiAccessGroup {
+			continue
+		}
+		version := servedVersion(crd)
+		if version == "" {
+			continue
+		}
+		kind, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "kind")
+		plural, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "plural")
+		resources = append(resources, smiResource{
+			kind: kind,
+			gvr:  schema.GroupVersionResource{Group: group, Version: version, Resource: plural},
+		})
+	}
+	return resources, nil
+}
+
+// countResources counts the objects of every SMI CRD of the cluster by kind. The
+// middlewares are counted when the controller API is reachable.
+func countResources(ctx context.Context, kClient *mesherykube.Client) (map[string]int, error) {
+	resources, err := servedSMIResources(ctx, kClient)
+	if err != nil {
+		return nil, ErrSMICounts(err)
+	}
+
+	counts := map[string]int{}
+	for _, res := range resources {
+		var objs *unstructured.UnstructuredList
+		err := retryOnTransient(ctx, func() (err error) {
+			objs, err = kClient.DynamicKubeClient.Resource(res.gvr).List(ctx, metav1.ListOptions{})
+			return err
+		})
+		if err != nil {
+			return nil, ErrSMICounts(err)
+		}
+		counts[res.kind] = len(objs.Items)
+	}
+
+	if conf, err := fetchControllerConfiguration(ctx, kClient); err == nil {
+		counts[middlewareKind] = len(conf.HTTP.Middlewares)
+	}
+	return counts, nil
+}
+
+// servedVersion returns the first version served by the CRD, any served version lists every object
+func servedVersion(crd unstructured.Unstructured) string {
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	for _, v := range versions {
+		version, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := version["name"].(string)
+		if served, _ := version["served"].(bool); served {
+			return name
+		}
+	}
+	return ""
+}
+
+// trendOf compares the counts with the oldest snapshot, nil when no snapshot was stored yet
+func trendOf(snapshots []countSnapshot, counts map[string]int, now time.Time) *countTrend {
+	if len(snapshots) == 0 {
+		return nil
+	}
+	oldest := snapshots[0]
+	trend := &countTrend{
+		Since:     oldest.Time,
+		Snapshots: len(snapshots),
+		Changes:   map[string]int{},
+		PerDay:    map[string]float64{},
+	}
+	days := now.Sub(oldest.Time).Hours() / 24
+	for _, kind := range countedKinds(oldest.Counts, counts) {
+		change := counts[kind] - oldest.Counts[kind]
+		trend.Changes[kind] = change
+		if days > 0 {
+			trend.PerDay[kind] = float64(change) / days
+		}
+	}
+	return trend
+}
+
+// countedKinds returns the kinds counted in any of the counts, sorted
+func countedKinds(counts ...map[string]int) []string {
+	seen := map[string]bool{}
+	var kinds []string
+	for _, c := range counts {
+		for kind := range c {
+			if !seen[kind] {
+				seen[kind] = true
+				kinds = append(kinds, kind)
+			}
+		}
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+func countSnapshotsFile() string {
+	return path.Join(config.RootPath(), "snapshots", "smi-counts.json")
+}
+
+// loadCountSnapshots reads the snapshots stored for every cluster, keyed by cluster
+func loadCountSnapshots() (map[string][]countSnapshot, error) {
+	stored := map[string][]countSnapshot{}
+	byt, err := os.ReadFile(countSnapshotsFile())
+	if errors.Is(err, fs.ErrNotExist) {
+		return stored, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(byt, &stored); err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+// saveCountSnapshots writes the snapshots of every cluster
+func saveCountSnapshots(stored map[string][]countSnapshot) error {
+	byt, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+	file := countSnapshotsFile()
+	if err := os.MkdirAll(path.Dir(file), 0750); err != nil {
+		return err
+	}
+	return os.WriteFile(file, byt, 0600)
+}
@@ -0,0 +1,117 @@
+package traefik
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// crd returns the CRD of the resource serving its version, and storing without serving the unserved one
+func crd(gvr schema.GroupVersionResource, kind, unserved string) *unstructured.Unstructured {
+	versions := []interface{}{map[string]interface{}{"name": gvr.Version, "served": true}}
+	if unserved != "" {
+		versions = append([]interface{}{map[string]interface{}{"name": unserved, "served": false}}, versions...)
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": gvr.Resource + "." + gvr.Group},
+		"spec": map[string]interface{}{
+			"group":    gvr.Group,
+			"names":    map[string]interface{}{"kind": kind, "plural": gvr.Resource},
+			"versions": versions,
+		},
+	}}
+}
+
+// smiObject returns an SMI object of the resource in the bookinfo namespace
+func smiObject(gvr schema.GroupVersionResource, kind, name string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": gvr.GroupVersion().String(),
+		"kind":       kind,
+		"metadata":   map[string]interface{}{"name": name, "namespace": "bookinfo"},
+	}}
+}
+
+func TestCountResources(t *testing.T) {
+	objs := []runtime.Object{
+		crd(trafficSplitGVR, trafficSplitKind, "v1alpha2"),
+		crd(trafficTargetGVR, "TrafficTarget", ""),
+		crd(httpRouteGroupGVR, "HTTPRouteGroup", ""),
+		crd(schema.GroupVersionResource{Group: "traefik.io", Version: "v1alpha1", Resource: "middlewares"}, "Middleware", ""),
+		smiObject(trafficSplitGVR, trafficSplitKind, "reviews-rollout"),
+		smiObject(trafficSplitGVR, trafficSplitKind, "ratings-rollout"),
+		smiObject(trafficTargetGVR, "TrafficTarget", "reviews-access"),
+		smiObject(httpRouteGroupGVR, "HTTPRouteGroup", "reviews-routes"),
+		smiObject(httpRouteGroupGVR, "HTTPRouteGroup", "ratings-routes"),
+		smiObject(httpRouteGroupGVR, "HTTPRouteGroup", "details-routes"),
+	}
+
+	tests := []struct {
+		name       string
+		controller bool
+		want       map[string]int
+	}{
+		{
+			// the middlewares are those of the controller configuration, not the traefik CRD
+			name:       "reachable controller",
+			controller: true,
+			want:       map[string]int{trafficSplitKind: 2, "TrafficTarget": 1, "HTTPRouteGroup": 3, middlewareKind: 5},
+		},
+		{
+			name: "unreachable controller",
+			want: map[string]int{trafficSplitKind: 2, "TrafficTarget": 1, "HTTPRouteGroup": 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			responses := map[string]interface{}{}
+			if tt.controller {
+				responses["/api/v1/services"] = corev1.ServiceList{Items: []corev1.Service{{
+					ObjectMeta: metav1.ObjectMeta{Name: "traefik-mesh-controller", Namespace: "maesh"},
+				}}}
+				responses["/api/v1/namespaces/maesh/services/http:traefik-mesh-controller:9000/proxy"+controllerConfigurationPath] = json.RawMessage(controllerConfiguration)
+			}
+			counts, err := countResources(context.Background(), fakeClient(t, responses, objs...))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(counts, tt.want) {
+				t.Fatalf("expected the counts %v, got %v", tt.want, counts)
+			}
+		})
+	}
+}
+
+func TestTrendOf(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	if trend := trendOf(nil, map[string]int{trafficSplitKind: 2}, now); trend != nil {
+		t.Fatalf("expected no trend without snapshots, got %+v", trend)
+	}
+
+	snapshots := []countSnapshot{
+		{Time: now.Add(-4 * 24 * time.Hour), Counts: map[string]int{trafficSplitKind: 2, "TrafficTarget": 4, "HTTPRouteGroup": 1}},
+		{Time: now.Add(-24 * time.Hour), Counts: map[string]int{trafficSplitKind: 5, "TrafficTarget": 4}},
+	}
+	counts := map[string]int{trafficSplitKind: 10, "TrafficTarget": 2, middlewareKind: 3}
+	trend := trendOf(snapshots, counts, now)
+
+	want := &countTrend{
+		Since:     snapshots[0].Time,
+		Snapshots: 2,
+		// the trend is measured since the oldest snapshot, kinds gone or new count as well
+		Changes: map[string]int{trafficSplitKind: 8, "TrafficTarget": -2, "HTTPRouteGroup": -1, middlewareKind: 3},
+		PerDay:  map[string]float64{trafficSplitKind: 2, "TrafficTarget": -0.5, "HTTPRouteGroup": -0.25, middlewareKind: 0.75},
+	}
+	if !reflect.DeepEqual(trend, want) {
+		t.Fatalf("expected the trend %+v, got %+v", want, trend)
+	}
+}
@@ -0,0 +1,151 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// tcpRouteKind is the kind of the SMI TCPRoute resource
+	tcpRouteKind = "TCPRoute"
+
+	// smiFieldManager owns the fields of the SMI resources applied server side by the adapter
+	smiFieldManager = "meshery-traefik-mesh"
+)
+
+// smiManifestResources are the plural names of the SMI kinds accepted in the manifests, all of them are namespaced
+var smiManifestResources = map[schema.GroupKind]string{
+	{Group: smiSplitGroup, Kind: trafficSplitKind}:   "trafficsplits",
+	{Group: smiSpecsGroup, Kind: httpRouteGroupKind}: "httproutegroups",
+	{Group: smiSpecsGroup, Kind: tcpRouteKind}:       "tcproutes",
+	{Group: smiAccessGroup, Kind: trafficTargetKind}: "traffictargets",
+}
+
+// smiManifestParams are the parameters of the SMI manifest operation
+type smiManifestParams struct {
+	// Manifest holds the SMI resources, as YAML or JSON documents
+	Manifest string `yaml:"manifest" required:"true"`
+	// Force takes the ownership of the fields managed by other field managers instead of failing on the conflicts
+	Force bool `yaml:"force"`
+
+	resourceMetadataParams `yaml:",inline"`
+}
+
+// smiDocumentResult is the outcome of a single document of the manifest
+type smiDocumentResult struct {
+	// Document is the position of the document in the manifest, from 0
+	Document   int    `json:"document"`
+	APIVersion string `json:"api_version"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace"`
+	Succeeded  bool   `json:"succeeded"`
+	Error      string `json:"error,omitempty"`
+}
+
+// smiManifestReport is the outcome of the SMI manifest in a cluster
+type smiManifestReport struct {
+	Succeeded int                 `json:"succeeded"`
+	Failed    int                 `json:"failed"`
+	Documents []smiDocumentResult `json:"documents"`
+}
+
+// decodeSMIManifest decodes the documents of the manifest and checks that they are supported SMI resources of the
+// namespace, every invalid document is reported at once and nothing is applied
+func decodeSMIManifest(manifest, namespace string) ([]*unstructured.Unstructured, error) {
+	if strings.TrimSpace(manifest) == "" {
+		return nil, ErrInvalidOperationParams(fmt.Errorf("manifest is required"))
+	}
+	objs, err := decodeManifest(manifest)
+	if err != nil {
+		return nil, ErrInvalidOperationParams(fmt.Errorf("manifest: %v", err))
+	}
+	if len(objs) == 0 {
+		return nil, ErrInvalidOperationParams(fmt.Errorf("manifest holds no document"))
+	}
+	var problems []error
+	for i, obj := range objs {
+		gvk := obj.GroupVersionKind()
+		if _, ok := smiManifestResources[gvk.GroupKind()]; !ok {
+			problems = append(problems, fmt.Errorf("document %d: %s %s is not a supported SMI kind", i, obj.GetAPIVersion(), obj.GetKind()))
+			continue
+		}
+		if obj.GetName() == "" {
+			problems = append(problems, fmt.Errorf("document %d: %s has no name", i, gvk.Kind))
+		}
+		if ns := obj.GetNamespace(); ns != "" && ns != namespace {
+			problems = append(problems, fmt.Errorf("document %d: %s %s is in namespace %s, not in the target namespace %s", i, gvk.Kind, obj.GetName(), ns, namespace))
+		}
+	}
+	if len(problems) != 0 {
+		return nil, ErrInvalidOperationParams(mergeErrors(problems))
+	}
+	return objs, nil
+}
+
+// applySMIManifest applies, or deletes, the SMI resources of the manifest to the namespace in every cluster with
+// a server side apply. A document failing doesn't stop the others, the outcome of each is reported.
+func (mesh *Mesh) applySMIManifest(ctx context.Context, del bool, namespace string, params smiManifestParams, kubeconfigs []string) (map[string]interface{}, error) {
+	objs, err := decodeSMIManifest(params.Manifest, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if !del {
+		metadata, err := params.resourceMetadataParams.resolve()
+		if err != nil {
+			return nil, err
+		}
+		// the labels and annotations of the documents win over the configured ones
+		for _, obj := range objs {
+			metadata.merge(obj)
+		}
+	}
+
+	return collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		report := smiManifestReport{Documents: make([]smiDocumentResult, 0, len(objs))}
+		for i, obj := range objs {
+			res := smiDocumentResult{
+				Document:   i,
+				APIVersion: obj.GetAPIVersion(),
+				Kind:       obj.GetKind(),
+				Name:       obj.GetName(),
+				Namespace:  namespace,
+			}
+			if err := applySMIDocument(ctx, kClient, obj.DeepCopy(), del, namespace, params.Force); err != nil {
+				res.Error = err.Error()
+				report.Failed++
+			} else {
+				res.Succeeded = true
+				report.Succeeded++
+			}
+			report.Documents = append(report.Documents, res)
+		}
+		return report, nil
+	})
+}
+
+// applySMIDocument applies the resource server side, or deletes it. A resource which doesn't exist is deleted already.
+func applySMIDocument(ctx context.Context, kClient *mesherykube.Client, obj *unstructured.Unstructured, del bool, namespace string, force bool) error {
+	gvk := obj.GroupVersionKind()
+	gvr := gvk.GroupVersion().WithResource(smiManifestResources[gvk.GroupKind()])
+	ri := kClient.DynamicKubeClient.Resource(gvr).Namespace(namespace)
+	obj.SetNamespace(namespace)
+	return retryOnTransient(ctx, func() error {
+		if del {
+			err := ri.Delete(ctx, obj.GetName(), metav1.DeleteOptions{})
+			if kubeerrors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		_, err := ri.Apply(ctx, obj.GetName(), obj, metav1.ApplyOptions{FieldManager: smiFieldManager, Force: force})
+		return err
+	})
+}
@@ -0,0 +1,285 @@
+package traefik
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// smiSplitGroup is the API group of the SMI TrafficSplit resource
+	smiSplitGroup = "split.smi-spec.io"
+
+	// smiSpecsGroup is the API group of the SMI HTTPRouteGroup and TCPRoute resources
+	smiSpecsGroup = "specs.smi-spec.io"
+
+	// smiAccessGroup is the API group of the SMI TrafficTarget resource
+	smiAccessGroup = "access.smi-spec.io"
+
+	// trafficSplitKind is the kind of the SMI TrafficSplit resource
+	trafficSplitKind = "TrafficSplit"
+
+	// controllerSelector selects the traefik mesh controller deployment
+	controllerSelector = "app=maesh,component=controller"
+
+	// defaultTrafficSplitVersion is the TrafficSplit version expected by the
+	// mesh versions registered with the adapter
+	defaultTrafficSplitVersion = "v1alpha4"
+)
+
+// trafficSplitVersions lists the TrafficSplit versions from the oldest to the newest
+var trafficSplitVersions = []string{"v1alpha1", "v1alpha2", "v1alpha3", "v1alpha4"}
+
+// supportedSMIVersions returns the SMI API version watched by the given traefik mesh
+// version for each SMI group. Traefik Mesh moved to the latest SMI versions in v1.4.
+func supportedSMIVersions(meshVersion string) map[string]string {
+	v := strings.TrimPrefix(normalizeVersion(meshVersion), "v")
+	if meshVersion != "" && (strings.HasPrefix(v, "1.0") || strings.HasPrefix(v, "1.1") || strings.HasPrefix(v, "1.2") || strings.HasPrefix(v, "1.3")) {
+		return map[string]string{
+			smiSplitGroup:  "v1alpha3",
+			smiSpecsGroup:  "v1alpha3",
+			smiAccessGroup: "v1alpha2",
+		}
+	}
+	return map[string]string{
+		smiSplitGroup:  defaultTrafficSplitVersion,
+		smiSpecsGroup:  "v1alpha4",
+		smiAccessGroup: "v1alpha3",
+	}
+}
+
+// supportedTrafficSplitVersion returns the TrafficSplit version watched by the given traefik mesh version
+func supportedTrafficSplitVersion(meshVersion string) string {
+	return supportedSMIVersions(meshVersion)[smiSplitGroup]
+}
+
+// trafficSplitOutcome reports the validation outcome of a single TrafficSplit
+type trafficSplitOutcome struct {
+	Name        string   `json:"name"`
+	Namespace   string   `json:"namespace,omitempty"`
+	FromVersion string   `json:"from_version"`
+	ToVersion   string   `json:"to_version"`
+	Converted   bool     `json:"converted"`
+	Notes       []string `json:"notes,omitempty"`
+}
+
+// trafficSplitValidationParams are the parameters of the TrafficSplit validation operation
+type trafficSplitValidationParams struct {
+	// Manifest holds the kubernetes resources to validate, non TrafficSplit resources are ignored
+	Manifest string `yaml:"manifest" required:"true"`
+	// MeshVersion overrides the version of traefik mesh detected in the cluster
+	MeshVersion string `yaml:"meshVersion"`
+	// Convert converts the mismatching TrafficSplits instead of rejecting them
+	Convert bool `yaml:"convert"`
+}
+
+// trafficSplitValidationResult is the result of the TrafficSplit validation operation
+type trafficSplitValidationResult struct {
+	MeshVersion      string                `json:"mesh_version,omitempty"`
+	SupportedVersion string                `json:"supported_version"`
+	TrafficSplits    []trafficSplitOutcome `json:"traffic_splits"`
+	Manifest         string                `json:"manifest,omitempty"`
+}
+
+// validateTrafficSplits checks the TrafficSplits of the manifest against the version
+// supported by the installed mesh and converts them if requested
+func (mesh *Mesh) validateTrafficSplits(ctx context.Context, params trafficSplitValidationParams, kubeconfigs []string) (*trafficSplitValidationResult, error) {
+	if strings.TrimSpace(params.Manifest) == "" {
+		return nil, ErrInvalidOperationParams(fmt.Errorf("manifest is required"))
+	}
+
+	meshVersion := params.MeshVersion
+	if meshVersion == "" {
+		meshVersion = mesh.installedMeshVersion(ctx, kubeconfigs)
+	}
+
+	res := &trafficSplitValidationResult{
+		MeshVersion:      meshVersion,
+		SupportedVersion: supportedTrafficSplitVersion(meshVersion),
+		TrafficSplits:    []trafficSplitOutcome{},
+	}
+
+	objs, err := decodeManifest(params.Manifest)
+	if err != nil {
+		return nil, ErrInvalidTrafficSplit(err)
+	}
+
+	var errs []error
+	var docs []string
+	for _, obj := range objs {
+		if obj.GroupVersionKind().Group == smiSplitGroup && obj.GetKind() == trafficSplitKind {
+			outcome, err := convertTrafficSplit(obj, res.SupportedVersion)
+			if err == nil && outcome.FromVersion != outcome.ToVersion && !params.Convert {
+				err = fmt.Errorf("TrafficSplit %q uses %s/%s while the installed mesh supports %s/%s", obj.GetName(), smiSplitGroup, outcome.FromVersion, smiSplitGroup, outcome.ToVersion)
+				outcome.Converted = false
+			}
+			res.TrafficSplits = append(res.TrafficSplits, outcome)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+
+		byt, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return nil, ErrInvalidTrafficSplit(err)
+		}
+		docs = append(docs, string(byt))
+	}
+
+	if len(errs) != 0 {
+		return res, ErrInvalidTrafficSplit(mergeErrors(errs))
+	}
+	if params.Convert {
+		res.Manifest = strings.Join(docs, "---\n")
+	}
+	return res, nil
+}
+
+// convertTrafficSplit rewrites the TrafficSplit in place to the target version.
+// Fields which can't be represented in the target version are reported as errors
+// rather than dropped, since the mesh would otherwise route differently than intended.
+func convertTrafficSplit(obj *unstructured.Unstructured, target string) (trafficSplitOutcome, error) {
+	from := obj.GroupVersionKind().Version
+	outcome := trafficSplitOutcome{
+		Name:        obj.GetName(),
+		Namespace:   obj.GetNamespace(),
+		FromVersion: from,
+		ToVersion:   target,
+	}
+
+	fromIdx, targetIdx := versionIndex(from), versionIndex(target)
+	if fromIdx < 0 {
+		return outcome, fmt.Errorf("TrafficSplit %q uses unknown version %s/%s", obj.GetName(), smiSplitGroup, from)
+	}
+	if fromIdx == targetIdx {
+		return outcome, nil
+	}
+
+	// v1alpha1 uses quantities as weights and allows FQDNs as the root service
+	if fromIdx == 0 {
+		if err := convertV1alpha1Weights(obj); err != nil {
+			return outcome, fmt.Errorf("TrafficSplit %q: %v", obj.GetName(), err)
+		}
+		if svc, ok, _ := unstructured.NestedString(obj.Object, "spec", "service"); ok && strings.Contains(svc, ".") {
+			_ = unstructured.SetNestedField(obj.Object, strings.Split(svc, ".")[0], "spec", "service")
+			outcome.Notes = append(outcome.Notes, fmt.Sprintf("root service %q shortened to %q", svc, strings.Split(svc, ".")[0]))
+		}
+		outcome.Notes = append(outcome.Notes, "backend weights converted from quantities to integers")
+	}
+
+	// matches were introduced with v1alpha3
+	if targetIdx < 2 {
+		if matches, ok, _ := unstructured.NestedSlice(obj.Object, "spec", "matches"); ok && len(matches) > 0 {
+			return outcome, fmt.Errorf("TrafficSplit %q uses matches which are not supported by %s/%s", obj.GetName(), smiSplitGroup, target)
+		}
+	}
+	if targetIdx == 0 {
+		return outcome, fmt.Errorf("TrafficSplit %q can't be converted to the deprecated %s/%s", obj.GetName(), smiSplitGroup, target)
+	}
+
+	obj.SetAPIVersion(fmt.Sprintf("%s/%s", smiSplitGroup, target))
+	outcome.Converted = true
+	return outcome, nil
+}
+
+// convertV1alpha1Weights replaces the quantity weights of a v1alpha1 split with
+// integers, "500m" becomes 500 and "1" becomes 1000 to keep the ratio intact
+func convertV1alpha1Weights(obj *unstructured.Unstructured) error {
+	backends, ok, err := unstructured.NestedSlice(obj.Object, "spec", "backends")
+	if err != nil || !ok {
+		return fmt.Errorf("spec.backends is missing or invalid")
+	}
+
+	for i, b := range backends {
+		backend, ok := b.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("spec.backends[%d] is invalid", i)
+		}
+		q, err := resource.ParseQuantity(fmt.Sprint(backend["weight"]))
+		if err != nil {
+			return fmt.Errorf("spec.backends[%d].weight: %v", i, err)
+		}
+		backend["weight"] = q.MilliValue()
+	}
+	return unstructured.SetNestedSlice(obj.Object, backends, "spec", "backends")
+}
+
+// versionIndex returns the position of the version in trafficSplitVersions, -1 if unknown
+func versionIndex(version string) int {
+	for i, v := range trafficSplitVersions {
+		if v == version {
+			return i
+		}
+	}
+	return -1
+}
+
+// decodeManifest decodes a multi document YAML or JSON manifest
+func decodeManifest(manifest string) ([]*unstructured.Unstructured, error) {
+	var objs []*unstructured.Unstructured
+	decoder := k8syaml.NewYAMLOrJSONDecoder(bytes.NewBufferString(manifest), 4096)
+	for {
+		obj := map[string]interface{}{}
+		if err := decoder.Decode(&obj); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		if len(obj) == 0 {
+			continue
+		}
+		objs = append(objs, &unstructured.Unstructured{Object: obj})
+	}
+	return objs, nil
+}
+
+// installedMeshVersion returns the version of traefik mesh installed in the first
+// cluster, an empty string if it couldn't be detected
+func (mesh *Mesh) installedMeshVersion(ctx context.Context, kubeconfigs []string) string {
+	if len(kubeconfigs) == 0 {
+		return ""
+	}
+	kClient, err := mesherykube.New([]byte(kubeconfigs[0]))
+	if err != nil {
+		mesh.Log.Warn(ErrDetectMeshVersion(err))
+		return ""
+	}
+	version, err := detectMeshVersion(ctx, kClient)
+	if err != nil {
+		mesh.Log.Warn(err)
+	}
+	return version
+}
+
+// detectMeshVersion returns the version of traefik mesh running in the cluster
+// from the image tag of the controller deployment
+func detectMeshVersion(ctx context.Context, kClient *mesherykube.Client) (string, error) {
+	var deps *appsv1.DeploymentList
+	err := retryOnTransient(ctx, func() (err error) {
+		deps, err = kClient.KubeClient.AppsV1().Deployments("").List(ctx, metav1.ListOptions{LabelSelector: controllerSelector})
+		return err
+	})
+	if err != nil {
+		return "", ErrDetectMeshVersion(err)
+	}
+	for _, dep := range deps.Items {
+		for _, c := range dep.Spec.Template.Spec.Containers {
+			if i := strings.LastIndex(c.Image, ":"); i >= 0 && !strings.Contains(c.Image[i:], "/") {
+				return normalizeVersion(c.Image[i+1:]), nil
+			}
+		}
+	}
+	return "", ErrDetectMeshVersion(fmt.Errorf("traefik mesh controller not found"))
+}
@@ -0,0 +1,142 @@
+package traefik
+
+import (
+	"context"
+	"testing"
+
+	"github.com/layer5io/meshkit/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const v1alpha1Split = `apiVersion: split.smi-spec.io/v1alpha1
+kind: TrafficSplit
+metadata:
+  name: reviews-rollout
+  namespace: bookinfo
+spec:
+  service: reviews.bookinfo.svc.cluster.local
+  backends:
+  - service: reviews-v1
+    weight: 900m
+  - service: reviews-v2
+    weight: 100m
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: reviews
+  namespace: bookinfo
+`
+
+func TestValidateTrafficSplitsConvertsV1alpha1(t *testing.T) {
+	mesh := &Mesh{}
+	res, err := mesh.validateTrafficSplits(context.Background(), trafficSplitValidationParams{
+		Manifest:    v1alpha1Split,
+		MeshVersion: "v1.4.8",
+		Convert:     true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.SupportedVersion != "v1alpha4" {
+		t.Fatalf("expected mesh v1.4.8 to support v1alpha4, got %s", res.SupportedVersion)
+	}
+	if len(res.TrafficSplits) != 1 || !res.TrafficSplits[0].Converted || res.TrafficSplits[0].FromVersion != "v1alpha1" {
+		t.Fatalf("expected the v1alpha1 split to be converted, got %+v", res.TrafficSplits)
+	}
+
+	objs, err := decodeManifest(res.Manifest)
+	if err != nil {
+		t.Fatalf("the converted manifest doesn't decode: %v", err)
+	}
+	if len(objs) != 2 {
+		t.Fatalf("expected the split and the service to be kept, got %d documents", len(objs))
+	}
+	split := objs[0]
+	if split.GetAPIVersion() != "split.smi-spec.io/v1alpha4" {
+		t.Errorf("expected split.smi-spec.io/v1alpha4, got %s", split.GetAPIVersion())
+	}
+	if svc, _, _ := unstructured.NestedString(split.Object, "spec", "service"); svc != "reviews" {
+		t.Errorf("expected the root service to be shortened to reviews, got %s", svc)
+	}
+	backends, _, _ := unstructured.NestedSlice(split.Object, "spec", "backends")
+	weights := []int64{}
+	for _, b := range backends {
+		switch w := b.(map[string]interface{})["weight"].(type) {
+		case int64:
+			weights = append(weights, w)
+		case float64:
+			weights = append(weights, int64(w))
+		}
+	}
+	if len(weights) != 2 || weights[0] != 900 || weights[1] != 100 {
+		t.Errorf("expected the weights 900 and 100, got %v", weights)
+	}
+}
+
+func TestValidateTrafficSplitsMismatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		meshVersion string
+		manifest    string
+		convert     bool
+		wantErr     bool
+		to          string
+	}{
+		{
+			name:        "older version rejected without convert",
+			meshVersion: "v1.4.8",
+			manifest:    v1alpha1Split,
+			wantErr:     true,
+			to:          "v1alpha4",
+		},
+		{
+			name:        "supported version accepted",
+			meshVersion: "v1.3.2",
+			manifest: `apiVersion: split.smi-spec.io/v1alpha3
+kind: TrafficSplit
+metadata:
+  name: ratings
+spec:
+  service: ratings
+  backends:
+  - service: ratings-v1
+    weight: 50
+`,
+			to: "v1alpha3",
+		},
+		{
+			name:        "unknown version rejected",
+			meshVersion: "v1.4.8",
+			convert:     true,
+			manifest: `apiVersion: split.smi-spec.io/v1beta1
+kind: TrafficSplit
+metadata:
+  name: details
+spec:
+  service: details
+`,
+			wantErr: true,
+			to:      "v1alpha4",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := (&Mesh{}).validateTrafficSplits(context.Background(), trafficSplitValidationParams{
+				Manifest:    tt.manifest,
+				MeshVersion: tt.meshVersion,
+				Convert:     tt.convert,
+			}, nil)
+			if tt.wantErr {
+				if code := errors.GetCode(err); code != ErrInvalidTrafficSplitCode {
+					t.Fatalf("expected the error code %s, got %s: %v", ErrInvalidTrafficSplitCode, code, err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.SupportedVersion != tt.to || len(res.TrafficSplits) != 1 || res.TrafficSplits[0].ToVersion != tt.to {
+				t.Fatalf("expected one split validated against %s, got %+v", tt.to, res)
+			}
+		})
+	}
+}
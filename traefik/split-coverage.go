@@ -0,0 +1,186 @@
+package traefik
+
+import (
+	"context"
+	"sort"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// shadowServiceSelector selects the shadow services created by traefik mesh
+	shadowServiceSelector = "app=maesh,type=shadow"
+)
+
+// systemNamespaces are left out of the coverage report unless requested explicitly
+var systemNamespaces = map[string]bool{
+	"kube-system":     true,
+	"kube-public":     true,
+	"kube-node-lease": true,
+}
+
+// splitCoverageParams are the parameters of the split coverage operation
+type splitCoverageParams struct {
+	// Namespaces restricts the report to the given namespaces
+	Namespaces []string `yaml:"namespaces"`
+}
+
+// namespaceSplitCoverage reports how many of the services of a namespace which
+// could be split across several versions actually have a TrafficSplit
+type namespaceSplitCoverage struct {
+	Services   int      `json:"services"`
+	Splittable int      `json:"splittable"`
+	Split      int      `json:"split"`
+	Coverage   float64  `json:"coverage"`
+	Candidates []string `json:"canary_candidates"`
+}
+
+// splitCoverage computes the per namespace TrafficSplit coverage of every cluster
+func (mesh *Mesh) splitCoverage(ctx context.Context, params splitCoverageParams, kubeconfigs []string) (map[string]interface{}, error) {
+	return collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		return clusterSplitCoverage(ctx, kClient, params.Namespaces)
+	})
+}
+
+// clusterSplitCoverage computes the TrafficSplit coverage of the namespaces of the cluster,
+// every namespace but the system ones when none is given, scanning several namespaces at once
+func clusterSplitCoverage(ctx context.Context, kClient *mesherykube.Client, namespaces []string) (map[string]*namespaceSplitCoverage, error) {
+	if len(namespaces) == 0 {
+		all, err := listNamespaces(ctx, kClient)
+		if err != nil {
+			return nil, ErrSplitCoverage(err)
+		}
+		for _, ns := range all {
+			if !systemNamespaces[ns] {
+				namespaces = append(namespaces, ns)
+			}
+		}
+	}
+
+	shadow, err := labels.Parse(shadowServiceSelector)
+	if err != nil {
+		return nil, ErrSplitCoverage(err)
+	}
+
+	res, err := forEachNamespace(namespaces, func(ns string) (interface{}, error) {
+		return namespaceCoverage(ctx, kClient, ns, shadow)
+	})
+	if err != nil {
+		return nil, ErrSplitCoverage(err)
+	}
+	report := map[string]*namespaceSplitCoverage{}
+	for ns, r := range res {
+		// the namespaces without services are left out of the report
+		if cov := r.(*namespaceSplitCoverage); cov.Services > 0 {
+			report[ns] = cov
+		}
+	}
+	return report, nil
+}
+
+// namespaceCoverage computes the TrafficSplit coverage of the namespace
+func namespaceCoverage(ctx context.Context, kClient *mesherykube.Client, namespace string, shadow labels.Selector) (*namespaceSplitCoverage, error) {
+	var svcs *corev1.ServiceList
+	err := retryOnTransient(ctx, func() (err error) {
+		svcs, err = kClient.KubeClient.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	var deps *appsv1.DeploymentList
+	err = retryOnTransient(ctx, func() (err error) {
+		deps, err = kClient.KubeClient.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	splits, err := listNamespacedTrafficSplits(ctx, kClient, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	// services referenced as the root service of a TrafficSplit
+	splitServices := map[string]bool{}
+	for _, split := range splits {
+		svc, _, _ := unstructured.NestedString(split.Object, "spec", "service")
+		splitServices[svc] = true
+	}
+
+	// pod template labels of the deployments
+	podLabels := make([]labels.Set, 0, len(deps.Items))
+	for _, dep := range deps.Items {
+		podLabels = append(podLabels, labels.Set(dep.Spec.Template.Labels))
+	}
+
+	cov := &namespaceSplitCoverage{Candidates: []string{}}
+	for _, svc := range svcs.Items {
+		if shadow.Matches(labels.Set(svc.Labels)) {
+			continue
+		}
+		cov.Services++
+
+		if len(svc.Spec.Selector) == 0 {
+			continue
+		}
+		selector := labels.SelectorFromSet(svc.Spec.Selector)
+		backends := 0
+		for _, l := range podLabels {
+			if selector.Matches(l) {
+				backends++
+			}
+		}
+		hasSplit := splitServices[svc.Name]
+		if backends < 2 && !hasSplit {
+			continue
+		}
+
+		cov.Splittable++
+		if hasSplit {
+			cov.Split++
+			continue
+		}
+		cov.Candidates = append(cov.Candidates, svc.Name)
+	}
+
+	sort.Strings(cov.Candidates)
+	if cov.Splittable > 0 {
+		cov.Coverage = float64(cov.Split) / float64(cov.Splittable)
+	}
+	return cov, nil
+}
+
+// listTrafficSplits lists the TrafficSplits of all the namespaces
+func listTrafficSplits(ctx context.Context, kClient *mesherykube.Client) ([]unstructured.Unstructured, error) {
+	return listNamespacedTrafficSplits(ctx, kClient, metav1.NamespaceAll)
+}
+
+// listNamespacedTrafficSplits lists the TrafficSplits of the namespace, trying the
+// SMI API versions from the newest to the oldest one served by the cluster
+func listNamespacedTrafficSplits(ctx context.Context, kClient *mesherykube.Client, namespace string) ([]unstructured.Unstructured, error) {
+	var err error
+	for i := len(trafficSplitVersions) - 1; i >= 0; i-- {
+		gvr := schema.GroupVersionResource{Group: smiSplitGroup, Version: trafficSplitVersions[i], Resource: "trafficsplits"}
+		var list *unstructured.UnstructuredList
+		err = retryOnTransient(ctx, func() (err error) {
+			list, err = kClient.DynamicKubeClient.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
+			return err
+		})
+		if err == nil {
+			return list.Items, nil
+		}
+	}
+	// no TrafficSplit version is served when the SMI CRDs aren't installed
+	if kubeerrors.IsNotFound(err) {
+		return nil, nil
+	}
+	return nil, err
+}
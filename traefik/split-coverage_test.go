@@ -0,0 +1,109 @@
+package traefik
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func service(name string, selector, lbls map[string]string) corev1.Service {
+	return corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: lbls},
+		Spec:       corev1.ServiceSpec{Selector: selector},
+	}
+}
+
+func deployment(name string, podLabels map[string]string) appsv1.Deployment {
+	return appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: podLabels}},
+		},
+	}
+}
+
+func TestClusterSplitCoverage(t *testing.T) {
+	responses := map[string]interface{}{
+		"/api/v1/namespaces": corev1.NamespaceList{Items: []corev1.Namespace{
+			{ObjectMeta: metav1.ObjectMeta{Name: "bookinfo"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "emojivoto"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "empty"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+		}},
+		"/api/v1/namespaces/bookinfo/services": corev1.ServiceList{Items: []corev1.Service{
+			service("reviews", map[string]string{"app": "reviews"}, nil),
+			service("ratings", map[string]string{"app": "ratings"}, nil),
+			service("details", map[string]string{"app": "details"}, nil),
+			service("productpage", map[string]string{"app": "productpage"}, nil),
+			service("reviews-6d4f-shadow", map[string]string{"app": "reviews"}, map[string]string{"app": "maesh", "type": "shadow"}),
+		}},
+		"/apis/apps/v1/namespaces/bookinfo/deployments": appsv1.DeploymentList{Items: []appsv1.Deployment{
+			deployment("reviews-v1", map[string]string{"app": "reviews", "version": "v1"}),
+			deployment("reviews-v2", map[string]string{"app": "reviews", "version": "v2"}),
+			deployment("ratings-v1", map[string]string{"app": "ratings", "version": "v1"}),
+			deployment("ratings-v2", map[string]string{"app": "ratings", "version": "v2"}),
+			deployment("details-v1", map[string]string{"app": "details", "version": "v1"}),
+			deployment("productpage-v1", map[string]string{"app": "productpage", "version": "v1"}),
+		}},
+		"/api/v1/namespaces/emojivoto/services": corev1.ServiceList{Items: []corev1.Service{
+			service("web-svc", map[string]string{"app": "web"}, nil),
+			service("emoji-svc", nil, nil),
+		}},
+		"/apis/apps/v1/namespaces/emojivoto/deployments": appsv1.DeploymentList{Items: []appsv1.Deployment{
+			deployment("web", map[string]string{"app": "web"}),
+		}},
+		"/api/v1/namespaces/empty/services":                corev1.ServiceList{},
+		"/apis/apps/v1/namespaces/empty/deployments":       appsv1.DeploymentList{},
+		"/api/v1/namespaces/kube-system/services":          corev1.ServiceList{Items: []corev1.Service{service("kube-dns", nil, nil)}},
+		"/apis/apps/v1/namespaces/kube-system/deployments": appsv1.DeploymentList{},
+	}
+	split := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "split.smi-spec.io/v1alpha4",
+		"kind":       "TrafficSplit",
+		"metadata":   map[string]interface{}{"name": "ratings-rollout", "namespace": "bookinfo"},
+		"spec":       map[string]interface{}{"service": "ratings"},
+	}}
+	kClient := fakeClient(t, responses, split)
+
+	tests := []struct {
+		name       string
+		namespaces []string
+		want       map[string]namespaceSplitCoverage
+	}{
+		{
+			name: "every namespace but the system ones",
+			want: map[string]namespaceSplitCoverage{
+				"bookinfo":  {Services: 4, Splittable: 2, Split: 1, Coverage: 0.5, Candidates: []string{"reviews"}},
+				"emojivoto": {Services: 2, Candidates: []string{}},
+			},
+		},
+		{
+			name:       "requested system namespace",
+			namespaces: []string{"kube-system", "emojivoto"},
+			want: map[string]namespaceSplitCoverage{
+				"kube-system": {Services: 1, Candidates: []string{}},
+				"emojivoto":   {Services: 2, Candidates: []string{}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := clusterSplitCoverage(context.Background(), kClient, tt.namespaces)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := map[string]namespaceSplitCoverage{}
+			for ns, cov := range report {
+				got[ns] = *cov
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// splitPortsParams are the parameters of the split ports operation
+type splitPortsParams struct {
+	// Manifest holds the TrafficSplits to verify, the TrafficSplits of the cluster are verified when it is empty
+	Manifest string `yaml:"manifest"`
+}
+
+// incompatibleBackend is a backend of a TrafficSplit which doesn't expose the ports of the root service
+type incompatibleBackend struct {
+	Service      string   `json:"service"`
+	MissingPorts []string `json:"missing_ports,omitempty"`
+	Reason       string   `json:"reason"`
+}
+
+// splitPortsReport is the port compatibility of the backends of a TrafficSplit
+type splitPortsReport struct {
+	Name         string                `json:"name"`
+	Namespace    string                `json:"namespace"`
+	Service      string                `json:"service"`
+	Compatible   bool                  `json:"compatible"`
+	Incompatible []incompatibleBackend `json:"incompatible_backends,omitempty"`
+}
+
+// verifySplitPorts verifies the TrafficSplits of the manifest, or of the cluster, in every cluster
+func (mesh *Mesh) verifySplitPorts(ctx context.Context, namespace string, params splitPortsParams, kubeconfigs []string) (map[string]interface{}, error) {
+	var objs []*unstructured.Unstructured
+	fromCluster := strings.TrimSpace(params.Manifest) == ""
+	if !fromCluster {
+		decoded, err := decodeManifest(params.Manifest)
+		if err != nil {
+			return nil, ErrInvalidOperationParams(err)
+		}
+		for _, obj := range decoded {
+			if obj.GroupVersionKind().Group == smiSplitGroup && obj.GetKind() == trafficSplitKind {
+				objs = append(objs, obj)
+			}
+		}
+	}
+
+	return collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		splits := objs
+		if fromCluster {
+			items, err := listTrafficSplits(ctx, kClient)
+			if err != nil {
+				return nil, ErrSplitPorts(err)
+			}
+			for i := range items {
+				splits = append(splits, &items[i])
+			}
+		}
+		reports := []splitPortsReport{}
+		for _, split := range splits {
+			report, err := clusterSplitPorts(ctx, kClient, split, namespace)
+			if err != nil {
+				return nil, ErrSplitPorts(err)
+			}
+			reports = append(reports, report)
+		}
+		return reports, nil
+	})
+}
+
+// rejectIncompatibleSplit fails when a backend of the TrafficSplit doesn't expose the ports
+// of the root service in any of the clusters, the mesh would otherwise route to a closed port
+func (mesh *Mesh) rejectIncompatibleSplit(ctx context.Context, split *unstructured.Unstructured, namespace string, kubeconfigs []string) error {
+	res, err := collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		return clusterSplitPorts(ctx, kClient, split, namespace)
+	})
+	if err != nil {
+		return ErrSplitPorts(err)
+	}
+	var errs []error
+	for cluster, r := range res {
+		report := r.(splitPortsReport)
+		for _, b := range report.Incompatible {
+			errs = append(errs, fmt.Errorf("%s: backend %s of TrafficSplit %q: %s", cluster, b.Service, report.Name, b.Reason))
+		}
+	}
+	if len(errs) != 0 {
+		return ErrSplitPorts(mergeErrors(errs))
+	}
+	return nil
+}
+
+// clusterSplitPorts fetches the root and backend services of the TrafficSplit and compares their ports
+func clusterSplitPorts(ctx context.Context, kClient *mesherykube.Client, split *unstructured.Unstructured, namespace string) (splitPortsReport, error) {
+	ns := split.GetNamespace()
+	if ns == "" {
+		ns = namespace
+	}
+	root, _, _ := unstructured.NestedString(split.Object, "spec", "service")
+	names := []string{root}
+	backends, _, _ := unstructured.NestedSlice(split.Object, "spec", "backends")
+	for _, b := range backends {
+		if backend, ok := b.(map[string]interface{}); ok {
+			name, _ := backend["service"].(string)
+			names = append(names, name)
+		}
+	}
+
+	services := map[string]*corev1.Service{}
+	for _, name := range names {
+		if _, ok := services[name]; ok || name == "" {
+			continue
+		}
+		var svc *corev1.Service
+		err := retryOnTransient(ctx, func() (err error) {
+			svc, err = kClient.KubeClient.CoreV1().Services(ns).Get(ctx, name, metav1.GetOptions{})
+			return err
+		})
+		if kubeerrors.IsNotFound(err) {
+			services[name] = nil
+			continue
+		}
+		if err != nil {
+			return splitPortsReport{}, err
+		}
+		services[name] = svc
+	}
+	return compareSplitPorts(split.GetName(), ns, root, names[1:], services), nil
+}
+
+// compareSplitPorts checks that every backend exposes the ports of the root service with the same protocol
+func compareSplitPorts(name, namespace, root string, backends []string, services map[string]*corev1.Service) splitPortsReport {
+	report := splitPortsReport{
+		Name:      name,
+		Namespace: namespace,
+		Service:   root,
+	}
+	rootSvc := services[root]
+	for _, backend := range backends {
+		svc := services[backend]
+		switch {
+		case backend == "":
+			report.Incompatible = append(report.Incompatible, incompatibleBackend{Reason: "the backend has no service"})
+			continue
+		case svc == nil:
+			report.Incompatible = append(report.Incompatible, incompatibleBackend{Service: backend, Reason: "the service doesn't exist"})
+			continue
+		case rootSvc == nil:
+			// nothing to compare with, the root service is reported below
+			continue
+		}
+		var missing []string
+		for _, rp := range rootSvc.Spec.Ports {
+			if !exposesPort(svc, rp) {
+				missing = append(missing, fmt.Sprintf("%d/%s", rp.Port, protocolOf(rp)))
+			}
+		}
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			report.Incompatible = append(report.Incompatible, incompatibleBackend{
+				Service:      backend,
+				MissingPorts: missing,
+				Reason:       fmt.Sprintf("the service doesn't expose the ports %s of the root service", strings.Join(missing, ", ")),
+			})
+		}
+	}
+	if rootSvc == nil {
+		report.Incompatible = append(report.Incompatible, incompatibleBackend{Service: root, Reason: "the root service doesn't exist"})
+	}
+	report.Compatible = len(report.Incompatible) == 0
+	return report
+}
+
+// exposesPort reports whether the service exposes the port with the same protocol
+func exposesPort(svc *corev1.Service, port corev1.ServicePort) bool {
+	for _, p := range svc.Spec.Ports {
+		if p.Port == port.Port && protocolOf(p) == protocolOf(port) {
+			return true
+		}
+	}
+	return false
+}
+
+// protocolOf returns the protocol of the port, TCP when it is not set
+func protocolOf(port corev1.ServicePort) corev1.Protocol {
+	if port.Protocol == "" {
+		return corev1.ProtocolTCP
+	}
+	return port.Protocol
+}
@@ -0,0 +1,89 @@
+package traefik
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// servicePorts returns a service exposing the ports
+func servicePorts(name string, ports ...corev1.ServicePort) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "bookinfo"},
+		Spec:       corev1.ServiceSpec{Ports: ports},
+	}
+}
+
+func TestClusterSplitPorts(t *testing.T) {
+	web := corev1.ServicePort{Name: "http", Port: 9080}
+	metrics := corev1.ServicePort{Name: "metrics", Port: 9090, Protocol: corev1.ProtocolTCP}
+	kClient := fakeClient(t, map[string]interface{}{
+		"/api/v1/namespaces/bookinfo/services/reviews":    servicePorts("reviews", web, metrics),
+		"/api/v1/namespaces/bookinfo/services/reviews-v1": servicePorts("reviews-v1", web, metrics),
+		"/api/v1/namespaces/bookinfo/services/reviews-v2": servicePorts("reviews-v2", corev1.ServicePort{Port: 9080, Protocol: corev1.ProtocolTCP}, metrics, corev1.ServicePort{Port: 15000}),
+		"/api/v1/namespaces/bookinfo/services/reviews-v3": servicePorts("reviews-v3", corev1.ServicePort{Port: 8080}, corev1.ServicePort{Port: 9090, Protocol: corev1.ProtocolUDP}),
+	})
+
+	tests := []struct {
+		name         string
+		root         string
+		backends     []interface{}
+		incompatible []incompatibleBackend
+	}{
+		{
+			// the protocol defaults to TCP and the extra ports of a backend are fine
+			name:     "compatible backends",
+			root:     "reviews",
+			backends: []interface{}{map[string]interface{}{"service": "reviews-v1"}, map[string]interface{}{"service": "reviews-v2"}},
+		},
+		{
+			name:     "backend with incompatible ports",
+			root:     "reviews",
+			backends: []interface{}{map[string]interface{}{"service": "reviews-v1"}, map[string]interface{}{"service": "reviews-v3"}},
+			incompatible: []incompatibleBackend{{
+				Service:      "reviews-v3",
+				MissingPorts: []string{"9080/TCP", "9090/TCP"},
+				Reason:       "the service doesn't expose the ports 9080/TCP, 9090/TCP of the root service",
+			}},
+		},
+		{
+			name:         "missing backend",
+			root:         "reviews",
+			backends:     []interface{}{map[string]interface{}{"service": "reviews-v4"}},
+			incompatible: []incompatibleBackend{{Service: "reviews-v4", Reason: "the service doesn't exist"}},
+		},
+		{
+			name:         "missing root service",
+			root:         "ratings",
+			backends:     []interface{}{map[string]interface{}{"service": "reviews-v1"}},
+			incompatible: []incompatibleBackend{{Service: "ratings", Reason: "the root service doesn't exist"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			split := &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "split.smi-spec.io/v1alpha4",
+				"kind":       "TrafficSplit",
+				"metadata":   map[string]interface{}{"name": "reviews-rollout"},
+				"spec":       map[string]interface{}{"service": tt.root, "backends": tt.backends},
+			}}
+			report, err := clusterSplitPorts(context.Background(), kClient, split, "bookinfo")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if report.Namespace != "bookinfo" || report.Service != tt.root {
+				t.Errorf("expected the split of %s in bookinfo, got %s in %s", tt.root, report.Service, report.Namespace)
+			}
+			if report.Compatible != (len(tt.incompatible) == 0) {
+				t.Errorf("expected compatible %v, got %v", len(tt.incompatible) == 0, report.Compatible)
+			}
+			if !reflect.DeepEqual(report.Incompatible, tt.incompatible) {
+				t.Errorf("expected the incompatible backends %+v, got %+v", tt.incompatible, report.Incompatible)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package traefik
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// proxyRawDataPath is served by the proxy API with the runtime configuration of the proxy
+	proxyRawDataPath = "/api/rawdata"
+
+	// controllerProvider is the provider the proxies load the controller configuration with,
+	// the names of the runtime configuration are qualified with it
+	controllerProvider = "@http"
+)
+
+// proxyRawData is the subset of the runtime configuration of a proxy
+type proxyRawData struct {
+	Routers     map[string]httpRouter             `json:"routers"`
+	Middlewares map[string]map[string]interface{} `json:"middlewares"`
+}
+
+// staleProxy is a proxy whose configuration differs from the one of the controller
+type staleProxy struct {
+	Pod            string   `json:"pod"`
+	Node           string   `json:"node"`
+	Fingerprint    string   `json:"fingerprint"`
+	MissingRouters []string `json:"missing_routers,omitempty"`
+	ExtraRouters   []string `json:"extra_routers,omitempty"`
+}
+
+// staleConfigReport lists the proxies of a cluster lagging behind the controller
+type staleConfigReport struct {
+	Fingerprint string       `json:"fingerprint"`
+	Proxies     int          `json:"proxies"`
+	Stale       []staleProxy `json:"stale"`
+}
+
+// reportStaleProxies compares the configuration applied by every ready proxy with the
+// current configuration of the controller. The proxies poll the controller, a proxy
+// reported right after a change may catch up on its next poll.
+func (mesh *Mesh) reportStaleProxies(ctx context.Context, kubeconfigs []string) (map[string]interface{}, error) {
+	return collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		conf, err := fetchControllerConfiguration(ctx, kClient)
+		if err != nil {
+			return nil, ErrStaleConfig(err)
+		}
+		pods, err := listProxyPods(ctx, kClient, "")
+		if err != nil {
+			return nil, ErrStaleConfig(err)
+		}
+
+		want := routerFingerprints(conf.HTTP.Routers, conf.HTTP.Middlewares)
+		report := &staleConfigReport{
+			Fingerprint: configFingerprint(want),
+			Stale:       []staleProxy{},
+		}
+		for _, pod := range pods {
+			if !podReady(pod) {
+				continue
+			}
+			raw, err := fetchProxyRawData(ctx, kClient, pod)
+			if err != nil {
+				return nil, ErrStaleConfig(err)
+			}
+			report.Proxies++
+			got := routerFingerprints(unqualify(raw.Routers), unqualifyMiddlewares(raw.Middlewares))
+			if stale, ok := compareProxyConfig(want, got); ok {
+				stale.Pod = fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
+				stale.Node = pod.Spec.NodeName
+				report.Stale = append(report.Stale, stale)
+			}
+		}
+		return report, nil
+	})
+}
+
+// fetchProxyRawData fetches the runtime configuration of the proxy through the API server proxy
+func fetchProxyRawData(ctx context.Context, kClient *mesherykube.Client, pod corev1.Pod) (*proxyRawData, error) {
+	var raw []byte
+	err := retryOnTransient(ctx, func() (err error) {
+		raw, err = kClient.KubeClient.CoreV1().Pods(pod.Namespace).ProxyGet("http", pod.Name, proxyMetricsPort, proxyRawDataPath, nil).DoRaw(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	var data proxyRawData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// unqualify keeps the routers loaded from the controller and strips the provider from their names
+func unqualify(routers map[string]httpRouter) map[string]httpRouter {
+	res := make(map[string]httpRouter, len(routers))
+	for name, r := range routers {
+		if !strings.HasSuffix(name, controllerProvider) {
+			continue
+		}
+		mws := make([]string, 0, len(r.Middlewares))
+		for _, mw := range r.Middlewares {
+			mws = append(mws, strings.TrimSuffix(mw, controllerProvider))
+		}
+		res[strings.TrimSuffix(name, controllerProvider)] = httpRouter{
+			Rule:        r.Rule,
+			Service:     strings.TrimSuffix(r.Service, controllerProvider),
+			Middlewares: mws,
+		}
+	}
+	return res
+}
+
+// unqualifyMiddlewares keeps the middlewares loaded from the controller and strips the provider from their names
+func unqualifyMiddlewares(middlewares map[string]map[string]interface{}) map[string]map[string]interface{} {
+	res := make(map[string]map[string]interface{}, len(middlewares))
+	for name, mw := range middlewares {
+		if strings.HasSuffix(name, controllerProvider) {
+			res[strings.TrimSuffix(name, controllerProvider)] = mw
+		}
+	}
+	return res
+}
+
+// routerFingerprints returns a fingerprint of every router, covering its rule, service
+// and middleware chain along with the type of each middleware
+func routerFingerprints(routers map[string]httpRouter, middlewares map[string]map[string]interface{}) map[string]string {
+	res := make(map[string]string, len(routers))
+	for name, r := range routers {
+		var sb strings.Builder
+		sb.WriteString(r.Rule + "\n" + r.Service)
+		for _, mw := range r.Middlewares {
+			sb.WriteString("\n" + mw + " " + middlewareType(middlewares[mw]))
+		}
+		res[name] = sb.String()
+	}
+	return res
+}
+
+// configFingerprint hashes the router fingerprints into a short configuration version
+func configFingerprint(routers map[string]string) string {
+	names := make([]string, 0, len(routers))
+	for name := range routers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	h := sha256.New()
+	for _, name := range names {
+		h.Write([]byte(name + "\n" + routers[name] + "\n"))
+	}
+	return hex.EncodeToString(h.Sum(nil))[:12]
+}
+
+// compareProxyConfig reports the routers missing from or differing in the proxy
+// configuration and the routers the controller removed, ok is false when the
+// proxy serves the current configuration
+func compareProxyConfig(want, got map[string]string) (staleProxy, bool) {
+	stale := staleProxy{Fingerprint: configFingerprint(got)}
+	if stale.Fingerprint == configFingerprint(want) {
+		return stale, false
+	}
+	for name, fp := range want {
+		if g, ok := got[name]; !ok || g != fp {
+			stale.MissingRouters = append(stale.MissingRouters, name)
+		}
+	}
+	for name := range got {
+		if _, ok := want[name]; !ok {
+			stale.ExtraRouters = append(stale.ExtraRouters, name)
+		}
+	}
+	sort.Strings(stale.MissingRouters)
+	sort.Strings(stale.ExtraRouters)
+	return stale, true
+}
@@ -0,0 +1,86 @@
+package traefik
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// qualifiedRawData returns the runtime configuration of a proxy which loaded the controller configuration
+func qualifiedRawData(conf *dynamicConfiguration) *proxyRawData {
+	raw := &proxyRawData{
+		// the routers of the other providers are ignored
+		Routers:     map[string]httpRouter{"api@internal": {Rule: "PathPrefix(`/api`)", Service: "api@internal"}},
+		Middlewares: map[string]map[string]interface{}{},
+	}
+	for name, r := range conf.HTTP.Routers {
+		mws := []string{}
+		for _, mw := range r.Middlewares {
+			mws = append(mws, mw+controllerProvider)
+		}
+		raw.Routers[name+controllerProvider] = httpRouter{Rule: r.Rule, Service: r.Service + controllerProvider, Middlewares: mws}
+	}
+	for name, mw := range conf.HTTP.Middlewares {
+		raw.Middlewares[name+controllerProvider] = mw
+	}
+	return raw
+}
+
+func TestCompareProxyConfig(t *testing.T) {
+	var conf dynamicConfiguration
+	if err := json.Unmarshal([]byte(controllerConfiguration), &conf); err != nil {
+		t.Fatal(err)
+	}
+	want := routerFingerprints(conf.HTTP.Routers, conf.HTTP.Middlewares)
+
+	tests := []struct {
+		name    string
+		lag     func(raw *proxyRawData)
+		missing []string
+		extra   []string
+	}{
+		{
+			name: "current configuration",
+			lag:  func(raw *proxyRawData) {},
+		},
+		{
+			name: "router not applied yet",
+			lag: func(raw *proxyRawData) {
+				delete(raw.Routers, "bookinfo-ratings-9080-6d61657368"+controllerProvider)
+			},
+			missing: []string{"bookinfo-ratings-9080-6d61657368"},
+		},
+		{
+			name: "previous middleware",
+			lag: func(raw *proxyRawData) {
+				raw.Middlewares["reviews-headers"+controllerProvider] = map[string]interface{}{"stripPrefix": map[string]interface{}{}}
+			},
+			missing: []string{"bookinfo-reviews-9080-6d61657368-split"},
+		},
+		{
+			name: "router removed by the controller",
+			lag: func(raw *proxyRawData) {
+				raw.Routers["bookinfo-details-9080-6d61657368"+controllerProvider] = httpRouter{Rule: "Host(`details.bookinfo.maesh`)", Service: "details-bookinfo-9080-6d61657368"}
+			},
+			extra: []string{"bookinfo-details-9080-6d61657368"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := qualifiedRawData(&conf)
+			tt.lag(raw)
+			got := routerFingerprints(unqualify(raw.Routers), unqualifyMiddlewares(raw.Middlewares))
+
+			stale, ok := compareProxyConfig(want, got)
+			if ok != (tt.missing != nil || tt.extra != nil) {
+				t.Fatalf("expected stale %v, got %v: %+v", !ok, ok, stale)
+			}
+			if !reflect.DeepEqual(stale.MissingRouters, tt.missing) || !reflect.DeepEqual(stale.ExtraRouters, tt.extra) {
+				t.Fatalf("expected the routers %v missing and %v extra, got %v and %v", tt.missing, tt.extra, stale.MissingRouters, stale.ExtraRouters)
+			}
+			if ok == (stale.Fingerprint == configFingerprint(want)) {
+				t.Fatalf("unexpected fingerprint %s of the proxy, the controller's is %s", stale.Fingerprint, configFingerprint(want))
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package traefik
+
+import (
+	"time"
+
+	internalconfig "github.com/layer5io/meshery-traefik-mesh/internal/config"
+	"github.com/layer5io/meshery-traefik-mesh/traefik/oam"
+)
+
+// adapterStatus reports the uptime of the adapter and the state of the component registration
+type adapterStatus struct {
+	StartedAt time.Time `json:"started_at"`
+	Uptime    string    `json:"uptime"`
+	// Degraded is the reason why the cluster operations are unavailable
+	Degraded string `json:"degraded,omitempty"`
+	oam.RegistrationStats
+}
+
+// status returns the status of the adapter at the given time
+func (mesh *Mesh) status(now time.Time) adapterStatus {
+	st := adapterStatus{
+		StartedAt:         mesh.StartedAt,
+		Uptime:            now.Sub(mesh.StartedAt).Round(time.Second).String(),
+		RegistrationStats: oam.GetRegistrationStats(),
+	}
+	if mesh.degraded != nil {
+		st.Degraded = mesh.degraded.Error()
+	}
+	return st
+}
+
+// configValidation lists the problems of the adapter configuration
+type configValidation struct {
+	Valid    bool     `json:"valid"`
+	Problems []string `json:"problems"`
+}
+
+// validateConfig validates the configuration of the adapter as done on startup
+func validateConfig() configValidation {
+	problems := internalconfig.ConfigProblems()
+	if problems == nil {
+		problems = []string{}
+	}
+	return configValidation{
+		Valid:    len(problems) == 0,
+		Problems: problems,
+	}
+}
@@ -0,0 +1,49 @@
+package traefik
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/layer5io/meshery-traefik-mesh/traefik/oam"
+)
+
+func TestStatusReflectsRegistrations(t *testing.T) {
+	started := time.Date(2023, 3, 1, 10, 0, 0, 0, time.UTC)
+	mesh := &Mesh{StartedAt: started}
+
+	static := started.Add(2 * time.Second)
+	oam.RecordRegistration(oam.StaticRegistration, static)
+	failure := started.Add(time.Minute)
+	oam.RecordRegistrationFailure(oam.DynamicRegistration, fmt.Errorf("meshery server unreachable"), failure)
+	oam.RecordRegistrationCycle()
+
+	st := mesh.status(started.Add(90 * time.Minute))
+	if !st.StartedAt.Equal(started) || st.Uptime != "1h30m0s" {
+		t.Errorf("expected the uptime 1h30m0s since %s, got %s since %s", started, st.Uptime, st.StartedAt)
+	}
+	if st.LastStatic == nil || !st.LastStatic.Equal(static) {
+		t.Errorf("expected the static registration at %s, got %v", static, st.LastStatic)
+	}
+	if st.LastDynamic != nil {
+		t.Errorf("expected no dynamic registration, got %s", st.LastDynamic)
+	}
+	if st.LastFailure == nil || !st.LastFailure.Equal(failure) || st.LastError != "meshery server unreachable" || !st.Failing() {
+		t.Errorf("expected the failed dynamic registration to be reported, got %+v", st.RegistrationStats)
+	}
+
+	dynamic := started.Add(11 * time.Minute)
+	oam.RecordRegistration(oam.DynamicRegistration, dynamic)
+	oam.RecordRegistrationCycle()
+
+	st = mesh.status(started.Add(2 * time.Hour))
+	if st.LastDynamic == nil || !st.LastDynamic.Equal(dynamic) {
+		t.Errorf("expected the dynamic registration at %s, got %v", dynamic, st.LastDynamic)
+	}
+	if st.Cycles != 2 {
+		t.Errorf("expected 2 registration cycles, got %d", st.Cycles)
+	}
+	if st.Failing() {
+		t.Errorf("the registration is still failing after the successful dynamic registration")
+	}
+}
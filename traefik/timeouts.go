@@ -0,0 +1,51 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/layer5io/meshery-traefik-mesh/internal/config"
+)
+
+// maxOperationTimeout bounds the timeout an operation request may ask for
+const maxOperationTimeout = 2 * time.Hour
+
+// timeoutParams are the parameters of the operations waiting for the resources they apply to become ready
+type timeoutParams struct {
+	// Timeout is how long the operation may take, waiting for the resources to become ready included,
+	// e.g. 20m. The timeout of the operation in OPERATION_TIMEOUTS applies when it is empty.
+	Timeout string `yaml:"timeout"`
+}
+
+// withTimeout returns the context of the operation cancelled once the requested timeout elapsed,
+// ctx when none was requested. The returned function releases the context.
+func (p timeoutParams) withTimeout(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	if p.Timeout == "" {
+		return ctx, func() {}, nil
+	}
+	d, err := time.ParseDuration(p.Timeout)
+	if err != nil || d <= 0 || d > maxOperationTimeout {
+		return nil, nil, ErrInvalidOperationParams(fmt.Errorf("timeout must be a positive duration up to %s, e.g. 20m, got %q", maxOperationTimeout, p.Timeout))
+	}
+	ctx, cancel := context.WithTimeout(ctx, d)
+	return ctx, cancel, nil
+}
+
+// readinessTimeout returns the time the wait loops of the operation wait for, until the deadline of
+// the operation or, without one, the readiness backoff cap
+func readinessTimeout(ctx context.Context) time.Duration {
+	if deadline, ok := ctx.Deadline(); ok {
+		return time.Until(deadline)
+	}
+	return config.BackoffCap(config.ReadinessBackoffPath)
+}
+
+// readinessBackoff returns the backoff of the wait loops, it gives up after the readiness timeout of the operation
+func readinessBackoff(ctx context.Context) *backoff.ExponentialBackOff {
+	b := backoff.NewExponentialBackOff()
+	b.MaxInterval = 10 * time.Second
+	b.MaxElapsedTime = readinessTimeout(ctx)
+	return b
+}
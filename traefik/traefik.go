@@ -3,13 +3,17 @@ package traefik
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/layer5io/meshery-adapter-library/adapter"
-	"github.com/layer5io/meshery-adapter-library/common"
 	"github.com/layer5io/meshery-adapter-library/meshes"
 	"github.com/layer5io/meshery-adapter-library/status"
 	internalconfig "github.com/layer5io/meshery-traefik-mesh/internal/config"
+	"github.com/layer5io/meshery-traefik-mesh/internal/store"
 	"github.com/layer5io/meshery-traefik-mesh/traefik/oam"
 	meshkitCfg "github.com/layer5io/meshkit/config"
 	"github.com/layer5io/meshkit/errors"
@@ -25,21 +29,103 @@ const (
 	SMIManifest = "https://raw.githubusercontent.com/layer5io/learn-layer5/master/smi-conformance/manifest.yml"
 )
 
+// clusterIndependentOperations are still served when the adapter is degraded
+var clusterIndependentOperations = map[string]bool{
+	internalconfig.AdapterStatusOperation: true,
+	internalconfig.ExportEventsOperation:  true,
+	internalconfig.CapabilitiesOperation:  true,
+	// the configuration is validated to find out why the adapter is degraded
+	internalconfig.ConfigValidationOperation: true,
+	internalconfig.PruneFilesOperation:       true,
+	internalconfig.OperationHistoryOperation: true,
+	internalconfig.CancelOperation:           true,
+	// the versions come from GitHub and the helm repository
+	internalconfig.InstallableVersionsOperation: true,
+}
+
 // Mesh represents the traefik-mesh adapter and embeds adapter.Adapter
 type Mesh struct {
 	adapter.Adapter // Type Embedded
+
+	// StartedAt is the time at which the adapter service was started
+	StartedAt time.Time
+
+	// Version is the version of the adapter build
+	Version string
+
+	// limiter bounds the number of operations running concurrently
+	limiter *operationLimiter
+
+	// events keeps the events streamed for the latest operations
+	events *eventLog
+
+	// inflight tracks the queued and running operations
+	inflight *inflightTracker
+
+	// history keeps the latest operations along with their outcome
+	history *operationHistory
+
+	// namespaceLocks serializes the installs and removals targeting the same namespace
+	namespaceLocks *namespaceLocks
+
+	// degraded is the reason why the operations which need a cluster are
+	// unavailable, nil when the adapter is healthy
+	degraded error
+
+	// defaultKubeconfigs are used by the requests which don't carry any kubeconfig,
+	// e.g. the kubeconfig built from the service account when running in a cluster
+	defaultKubeconfigs []string
+
+	// resultStore receives the operation results which are too large to be
+	// streamed inline, nil if no object storage is configured
+	resultStore *store.ObjectStore
 }
 
 // New initializes treafik-mesh handler.
-func New(c meshkitCfg.Handler, l logger.Handler, kc meshkitCfg.Handler, e *events.EventStreamer) adapter.Handler {
-	return &Mesh{
+func New(c meshkitCfg.Handler, l logger.Handler, kc meshkitCfg.Handler, e *events.EventStreamer) *Mesh {
+	mesh := &Mesh{
 		Adapter: adapter.Adapter{
 			Config:            c,
 			Log:               l,
 			KubeconfigHandler: kc,
 			EventStreamer:     e,
 		},
+		StartedAt: time.Now(),
+		limiter:   newOperationLimiter(internalconfig.MaxConcurrentOperations(), internalconfig.RejectExcessOperations()),
+		events:    newEventLog(),
+		inflight:  newInflightTracker(),
+		history:   newOperationHistory(internalconfig.OperationHistorySize()),
+
+		namespaceLocks: newNamespaceLocks(),
+	}
+
+	if cfg, ok := internalconfig.ResultStore(); ok {
+		rs, err := store.New(cfg)
+		if err != nil {
+			l.Warn(err)
+		}
+		mesh.resultStore = rs
 	}
+
+	return mesh
+}
+
+// SetDegraded marks the operations which need a cluster as unavailable for the given reason
+func (mesh *Mesh) SetDegraded(reason error) {
+	mesh.degraded = reason
+}
+
+// SetDefaultKubeconfigs sets the kubeconfigs used by the requests which don't carry any
+func (mesh *Mesh) SetDefaultKubeconfigs(kubeconfigs []string) {
+	mesh.defaultKubeconfigs = kubeconfigs
+}
+
+// requestKubeconfigs returns the kubeconfigs of the request, or the default ones when it carries none
+func (mesh *Mesh) requestKubeconfigs(kubeconfigs []string) []string {
+	if len(kubeconfigs) == 0 {
+		return mesh.defaultKubeconfigs
+	}
+	return kubeconfigs
 }
 
 // CreateKubeconfigs creates and writes passed kubeconfig onto the filesystem
@@ -93,7 +179,7 @@ func (mesh *Mesh) ApplyOperation(ctx context.Context, opReq adapter.OperationReq
 	if err != nil {
 		return err
 	}
-	kubeconfigs := opReq.K8sConfigs
+	kubeconfigs := mesh.requestKubeconfigs(opReq.K8sConfigs)
 	operations := make(adapter.Operations)
 	err = mesh.Config.GetObject(adapter.OperationsKey, &operations)
 	if err != nil {
@@ -108,80 +194,88 @@ func (mesh *Mesh) ApplyOperation(ctx context.Context, opReq adapter.OperationReq
 		ComponentName: internalconfig.ServerConfig["name"],
 	}
 
-	switch opReq.OperationName {
-	case internalconfig.TraefikMeshOperation:
-		go func(hh *Mesh, ee *meshes.EventsResponse) {
-			version := string(operations[opReq.OperationName].Versions[0])
-			stat, err := hh.installTraefikMesh(opReq.IsDeleteOperation, version, opReq.Namespace, kubeconfigs)
-			if err != nil {
-				summary := fmt.Sprintf("Error while %s Traefik service mesh", stat)
-				hh.streamErr(summary, ee, err)
-				return
-			}
-			ee.Summary = fmt.Sprintf("Traefik service mesh %s successfully", stat)
-			ee.Details = fmt.Sprintf("The Traefik service mesh is now %s.", stat)
-			hh.StreamInfo(ee)
-		}(mesh, e)
-	case common.BookInfoOperation, common.HTTPBinOperation, common.ImageHubOperation, common.EmojiVotoOperation:
-		go func(hh *Mesh, ee *meshes.EventsResponse) {
-			appName := operations[opReq.OperationName].AdditionalProperties[common.ServiceName]
-			stat, err := hh.installSampleApp(opReq.Namespace, opReq.IsDeleteOperation, operations[opReq.OperationName].Templates, kubeconfigs)
-			if err != nil {
-				summary := fmt.Sprintf("Error while %s %s application", stat, appName)
-				hh.streamErr(summary, ee, err)
-				return
-			}
-			ee.Summary = fmt.Sprintf("%s application %s successfully", appName, stat)
-			ee.Details = fmt.Sprintf("The %s application is now %s.", appName, stat)
-			hh.StreamInfo(ee)
-		}(mesh, e)
-	case common.CustomOperation:
-		go func(hh *Mesh, ee *meshes.EventsResponse) {
-			stat, err := hh.applyCustomOperation(opReq.Namespace, opReq.CustomBody, opReq.IsDeleteOperation, kubeconfigs)
-			if err != nil {
-				summary := fmt.Sprintf("Error while %s custom operation", stat)
-				hh.streamErr(summary, ee, err)
-				return
-			}
-			ee.Summary = fmt.Sprintf("Manifest %s successfully", status.Deployed)
-			ee.Details = ""
-			hh.StreamInfo(ee)
-		}(mesh, e)
-	case common.SmiConformanceOperation:
-		go func(hh *Mesh, ee *meshes.EventsResponse) {
-			name := operations[opReq.OperationName].Description
-			_, err := hh.RunSMITest(adapter.SMITestOptions{
-				Ctx:         context.TODO(),
-				OperationID: ee.OperationId,
-				Manifest:    SMIManifest,
-				Namespace:   "meshery",
-				Labels:      make(map[string]string),
-				Annotations: make(map[string]string),
-				Kubeconfigs: kubeconfigs,
-			})
-			if err != nil {
-				summary := fmt.Sprintf("Error while %s %s test", status.Running, name)
-				hh.streamErr(summary, ee, err)
-				return
-			}
-			ee.Summary = fmt.Sprintf("%s test %s successfully", name, status.Completed)
-			ee.Details = ""
-			hh.StreamInfo(ee)
-		}(mesh, e)
-	default:
+	namespace, nsErr := operationNamespace(opReq.Namespace)
+	if nsErr != nil {
+		namespace = opReq.Namespace
+	}
+	var targetVersion string
+	if op, ok := operations[opReq.OperationName]; ok && op != nil && len(op.Versions) != 0 {
+		targetVersion = string(op.Versions[0])
+	}
+	correlationID, correlationErr := requestCorrelationID(ctx, opReq.CustomBody)
+	mesh.history.start(opReq.OperationID, opReq.OperationName, namespace, targetVersion, correlationID)
+	mesh.operationLog(opReq.OperationID).Info("Operation requested")
+	sendCorrelationID(ctx, correlationID)
+	if correlationErr != nil {
+		mesh.streamErr("Invalid correlation id", e, correlationErr)
+		mesh.finishOperation(e.OperationId)
+		return nil
+	}
+
+	if mesh.degraded != nil && !clusterIndependentOperations[opReq.OperationName] {
+		mesh.streamErr("Operation unavailable", e, mesh.degraded)
+		mesh.finishOperation(e.OperationId)
+		return nil
+	}
+	if nsErr != nil {
+		mesh.streamErr("Invalid operation namespace", e, nsErr)
+		mesh.finishOperation(e.OperationId)
+		return nil
+	}
+	// the default kubeconfigs have a single context
+	if len(opReq.K8sConfigs) != 0 {
+		if kubeconfigs, err = selectKubeContext(kubeconfigs, requestKubeContext(opReq)); err != nil {
+			mesh.streamErr("Invalid kubeconfig context", e, err)
+			mesh.finishOperation(e.OperationId)
+			return nil
+		}
+	}
+	if err := validateOperationParams(opReq.OperationName, opReq.CustomBody, opReq.IsDeleteOperation); err != nil {
+		mesh.streamErr("Invalid operation parameters", e, err)
+		mesh.finishOperation(e.OperationId)
+		return nil
+	}
+
+	h, ok := operationHandlers[opReq.OperationName]
+	if !ok {
 		mesh.streamErr("Invalid operation", e, ErrOpInvalid)
+		mesh.finishOperation(e.OperationId)
+		return nil
+	}
+	req := &operationRequest{
+		OperationRequest: opReq,
+		namespace:        namespace,
+		kubeconfigs:      kubeconfigs,
+		operations:       operations,
+		event:            e,
 	}
+	if h.immediate {
+		mesh.handleOperation(ctx, h, req)
+		mesh.finishOperation(e.OperationId)
+		return nil
+	}
+	mesh.runOperation(opReq.OperationName, e, func(ctx context.Context, hh *Mesh, ee *meshes.EventsResponse) {
+		hh.handleOperation(ctx, h, req)
+	})
 
 	return nil
 }
 
 // ProcessOAM will handles the grpc invocation for handling OAM objects
 func (mesh *Mesh) ProcessOAM(ctx context.Context, oamReq adapter.OAMRequest) (string, error) {
+	if mesh.degraded != nil {
+		return "", mesh.degraded
+	}
 	err := mesh.CreateKubeconfigs(oamReq.K8sConfigs)
 	if err != nil {
 		return "", err
 	}
-	kubeconfigs := oamReq.K8sConfigs
+	kubeconfigs := mesh.requestKubeconfigs(oamReq.K8sConfigs)
+	if len(oamReq.K8sConfigs) != 0 {
+		if kubeconfigs, err = selectKubeContext(kubeconfigs, internalconfig.KubeContext()); err != nil {
+			return "", err
+		}
+	}
 	var comps []v1alpha1.Component
 	for _, acomp := range oamReq.OamComps {
 		comp, err := oam.ParseApplicationComponent(acomp)
@@ -230,6 +324,118 @@ func (mesh *Mesh) ProcessOAM(ctx context.Context, oamReq adapter.OAMRequest) (st
 	return msg1 + "\n" + msg2, nil
 }
 
+// streamResult streams the structured result of an operation as JSON in the event details
+func (mesh *Mesh) streamResult(summary string, e *meshes.EventsResponse, result interface{}) {
+	byt, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		mesh.streamErr("Error while encoding operation result", e, ErrEncodeResult(err))
+		return
+	}
+	e.Summary = summary
+	e.Details = string(byt)
+	if mesh.resultStore != nil && len(byt) > internalconfig.ResultStoreThreshold() {
+		ref, err := mesh.uploadResult(e.OperationId, byt)
+		if err != nil {
+			mesh.Log.Warn(err)
+		} else {
+			e.Details = ref
+		}
+	}
+	if limit := internalconfig.ResultMaxSize(); len(e.Details) > limit {
+		mesh.streamErr("Error while streaming operation result", e, ErrResultTooLarge(len(byt), limit))
+		return
+	}
+	mesh.StreamInfo(e)
+}
+
+// resultReference points to an operation result uploaded to the object storage
+type resultReference struct {
+	URL    string `json:"result_url"`
+	Size   int    `json:"size_bytes"`
+	SHA256 string `json:"sha256"`
+}
+
+// uploadResult uploads the encoded result and returns the reference to it as JSON
+func (mesh *Mesh) uploadResult(operationID string, byt []byte) (string, error) {
+	key := fmt.Sprintf("%s/%s-%d.json", internalconfig.ServerConfig["name"], operationID, time.Now().Unix())
+	url, err := mesh.resultStore.Put(context.TODO(), key, "application/json", byt)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(byt)
+	ref, err := json.Marshal(resultReference{URL: url, Size: len(byt), SHA256: hex.EncodeToString(sum[:])})
+	if err != nil {
+		return "", ErrEncodeResult(err)
+	}
+	return string(ref), nil
+}
+
+// runOperation runs the operation in the background once the concurrency limit
+// allows it. Queued operations are notified of their position in the queue.
+func (mesh *Mesh) runOperation(name string, e *meshes.EventsResponse, fn func(context.Context, *Mesh, *meshes.EventsResponse)) {
+	// the operation outlives the request, it is only cancelled by the cancel operation
+	ctx, cancel := context.WithCancel(context.Background())
+	done, ok := mesh.inflight.add(e.OperationId, name, cancel)
+	if !ok {
+		cancel()
+		mesh.streamErr("Adapter shutting down", e, ErrShuttingDown(name))
+		mesh.finishOperation(e.OperationId)
+		return
+	}
+	position, ready, err := mesh.limiter.acquire()
+	if err != nil {
+		cancel()
+		done()
+		mesh.streamErr("Too many concurrent operations", e, err)
+		mesh.finishOperation(e.OperationId)
+		return
+	}
+	if position > 0 {
+		mesh.streamUpdate(e, "Operation queued", fmt.Sprintf("Too many concurrent operations, the operation is at position %d in the queue", position))
+	}
+
+	go func() {
+		defer done()
+		defer cancel()
+		defer mesh.finishOperation(e.OperationId)
+		select {
+		case <-ready:
+		case <-ctx.Done():
+			// a queued operation cancelled before its turn gives up its place in the queue
+			if mesh.limiter.abandon(ready) {
+				mesh.streamErr("Operation cancelled", e, ErrOperationCancelled(name, e.OperationId))
+				return
+			}
+		}
+		defer mesh.limiter.release()
+		if ctx.Err() != nil {
+			// cancelled as its turn came, the operation never ran and the cancellation is its outcome
+			mesh.streamErr("Operation cancelled", e, ErrOperationCancelled(name, e.OperationId))
+			return
+		}
+		// the records logged by the operation carry its fields
+		hh := *mesh
+		hh.Log = mesh.operationLog(e.OperationId)
+		// helm, the downloads and the API calls of the operation stop at its deadline
+		opCtx, opCancel := context.WithTimeout(ctx, internalconfig.OperationTimeout(name))
+		hh.Log.Info("Operation started")
+		fn(opCtx, &hh, e)
+		opCancel()
+	}()
+}
+
+// streamUpdate streams an intermediate event of the operation without
+// altering the event which carries the final outcome
+func (mesh *Mesh) streamUpdate(e *meshes.EventsResponse, summary, details string) {
+	mesh.StreamInfo(&meshes.EventsResponse{
+		OperationId:   e.OperationId,
+		Summary:       summary,
+		Details:       details,
+		Component:     e.Component,
+		ComponentName: e.ComponentName,
+	})
+}
+
 func (mesh *Mesh) streamErr(summary string, e *meshes.EventsResponse, err error) {
 	e.Summary = summary
 	e.Details = err.Error()
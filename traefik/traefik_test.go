@@ -0,0 +1,166 @@
+package traefik
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/layer5io/meshery-adapter-library/adapter"
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	internalconfig "github.com/layer5io/meshery-traefik-mesh/internal/config"
+	"github.com/layer5io/meshery-traefik-mesh/internal/store"
+	"github.com/layer5io/meshkit/logger"
+	"github.com/layer5io/meshkit/utils/events"
+)
+
+func TestUploadResult(t *testing.T) {
+	uploaded := map[string][]byte{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uploaded[r.URL.Path], _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	rs, err := store.New(store.Config{Endpoint: srv.URL, Bucket: "diagnostics"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mesh := &Mesh{resultStore: rs}
+	bundle := []byte(`{"logs":"` + strings.Repeat("x", 4096) + `"}`)
+
+	details, err := mesh.uploadResult("op-42", bundle)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var ref resultReference
+	if err := json.Unmarshal([]byte(details), &ref); err != nil {
+		t.Fatalf("the details are not a result reference: %v", err)
+	}
+	sum := sha256.Sum256(bundle)
+	if ref.Size != len(bundle) || ref.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("unexpected reference %+v", ref)
+	}
+	path := strings.TrimPrefix(ref.URL, srv.URL)
+	if !strings.HasPrefix(path, "/diagnostics/") || !strings.Contains(path, "op-42-") {
+		t.Fatalf("unexpected reference URL %s", ref.URL)
+	}
+	if string(uploaded[path]) != string(bundle) {
+		t.Fatalf("the referenced object was not uploaded, got %d objects", len(uploaded))
+	}
+}
+
+func TestStreamResultSizeLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	rs, err := store.New(store.Config{Endpoint: srv.URL, Bucket: "diagnostics"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(internalconfig.ResultMaxSizeEnv, "1024")
+	t.Setenv(internalconfig.ResultStoreThresholdEnv, "512")
+
+	tests := []struct {
+		name   string
+		result interface{}
+		store  *store.ObjectStore
+		code   string
+	}{
+		{name: "within the limit", result: map[string]string{"logs": strings.Repeat("x", 256)}},
+		{name: "over the limit", result: map[string]string{"logs": strings.Repeat("x", 2048)}, code: ErrResultTooLargeCode},
+		// the reference to the uploaded result is streamed instead
+		{name: "over the limit with a result store", result: map[string]string{"logs": strings.Repeat("x", 2048)}, store: rs},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, err := logger.New("test", logger.Options{Format: logger.JsonLogFormat, Output: &bytes.Buffer{}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			mesh := &Mesh{
+				Adapter:     adapter.Adapter{Log: log, EventStreamer: events.NewEventStreamer()},
+				events:      newEventLog(),
+				history:     newOperationHistory(10),
+				resultStore: tt.store,
+			}
+			mesh.streamResult("Diagnostics collected", &meshes.EventsResponse{OperationId: "op-42"}, tt.result)
+
+			res, err := mesh.exportEvents(exportEventsParams{OperationID: "op-42"})
+			if err != nil || len(res.Events) != 1 {
+				t.Fatalf("expected a single event, got %+v: %v", res, err)
+			}
+			event := res.Events[0]
+			if event.ErrorCode != tt.code {
+				t.Fatalf("expected the error code %q, got %q: %s", tt.code, event.ErrorCode, event.Details)
+			}
+			if tt.code == "" {
+				if len(event.Details) > 1024 {
+					t.Fatalf("streamed %d bytes over the limit", len(event.Details))
+				}
+				return
+			}
+			if !strings.Contains(event.Details, "exceeds the limit of 1024 bytes") || !strings.Contains(event.SuggestedRemediation, internalconfig.ResultStoreEndpointEnv) {
+				t.Fatalf("expected the guidance to export to object storage, got %+v", event)
+			}
+		})
+	}
+}
+
+func TestCancelledOperationSingleOutcome(t *testing.T) {
+	log, err := logger.New("test", logger.Options{Format: logger.JsonLogFormat, Output: &bytes.Buffer{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mesh := &Mesh{
+		Adapter:  adapter.Adapter{Log: log, EventStreamer: events.NewEventStreamer()},
+		events:   newEventLog(),
+		history:  newOperationHistory(10),
+		inflight: newInflightTracker(),
+		limiter:  newOperationLimiter(1, false),
+	}
+	started := make(chan struct{})
+	h := operationHandler{
+		errMsg: "Error while installing the Traefik service mesh",
+		run: func(ctx context.Context, hh *Mesh, req *operationRequest) (interface{}, error) {
+			close(started)
+			<-ctx.Done()
+			return nil, ErrApplyHelmChart(ctx.Err())
+		},
+	}
+	req := &operationRequest{
+		OperationRequest: adapter.OperationRequest{OperationName: internalconfig.TraefikMeshOperation, OperationID: "install-1"},
+		event:            &meshes.EventsResponse{OperationId: "install-1"},
+	}
+	mesh.history.start("install-1", internalconfig.TraefikMeshOperation, "traefik-mesh", "", "")
+	mesh.runOperation(internalconfig.TraefikMeshOperation, req.event, func(ctx context.Context, hh *Mesh, _ *meshes.EventsResponse) {
+		hh.handleOperation(ctx, h, req)
+	})
+
+	<-started
+	if !mesh.inflight.cancel("install-1") {
+		t.Fatal("expected the install to be in flight")
+	}
+	if pending := mesh.inflight.wait(time.Second); len(pending) != 0 {
+		t.Fatalf("the cancelled install didn't complete: %+v", pending)
+	}
+
+	exported, err := mesh.exportEvents(exportEventsParams{OperationID: "install-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var outcomes []recordedEvent
+	for _, ev := range exported.Events {
+		if ev.Type == "error" {
+			outcomes = append(outcomes, ev)
+		}
+	}
+	if len(outcomes) != 1 || outcomes[0].Summary != "Operation cancelled" || outcomes[0].ErrorCode != ErrOperationCancelledCode {
+		t.Fatalf("expected the cancellation as the single outcome, got %+v", outcomes)
+	}
+}
@@ -0,0 +1,111 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// trafficSplitParams are the parameters of the TrafficSplit operation
+type trafficSplitParams struct {
+	// Name is the name of the TrafficSplit, <service>-split when it is empty
+	Name string `yaml:"name"`
+	// Service is the root service the clients address
+	Service string `yaml:"service" required:"true"`
+	// Backends are the services the traffic of the root service is split between
+	Backends []splitBackend `yaml:"backends" required:"true"`
+}
+
+// splitBackend is a backend service of a TrafficSplit along with its weight
+type splitBackend struct {
+	Service string `yaml:"service" required:"true"`
+	Weight  int    `yaml:"weight"`
+}
+
+// trafficSplitResult is the result of the TrafficSplit operation
+type trafficSplitResult struct {
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace"`
+	APIVersion string `json:"api_version"`
+	Deleted    bool   `json:"deleted"`
+}
+
+// validate checks the root service is set and every backend is a service with a non-negative weight
+func (p trafficSplitParams) validate() error {
+	if p.Service == "" {
+		return ErrInvalidOperationParams(fmt.Errorf("service is required"))
+	}
+	if len(p.Backends) == 0 {
+		return ErrInvalidOperationParams(fmt.Errorf("at least one backend is required"))
+	}
+	total := 0
+	for i, b := range p.Backends {
+		if b.Service == "" {
+			return ErrInvalidOperationParams(fmt.Errorf("backends[%d]: service is required", i))
+		}
+		if b.Weight < 0 {
+			return ErrInvalidOperationParams(fmt.Errorf("backends[%d]: weight of %s must be a non-negative integer, got %d", i, b.Service, b.Weight))
+		}
+		total += b.Weight
+	}
+	if total == 0 {
+		return ErrInvalidOperationParams(fmt.Errorf("the weights of the backends add up to 0, no traffic would be routed"))
+	}
+	return nil
+}
+
+// applyTrafficSplit creates, or deletes, the TrafficSplit in every cluster. The split uses the
+// version watched by the installed mesh and is rejected when a backend is not a service.
+func (mesh *Mesh) applyTrafficSplit(ctx context.Context, del bool, namespace string, params trafficSplitParams, kubeconfigs []string) (*trafficSplitResult, error) {
+	if params.Name == "" && params.Service != "" {
+		params.Name = params.Service + "-split"
+	}
+	if !del {
+		if err := params.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	res := &trafficSplitResult{
+		Name:       params.Name,
+		Namespace:  namespace,
+		APIVersion: fmt.Sprintf("%s/%s", smiSplitGroup, supportedTrafficSplitVersion(mesh.installedMeshVersion(ctx, kubeconfigs))),
+		Deleted:    del,
+	}
+	backends := make([]interface{}, 0, len(params.Backends))
+	for _, b := range params.Backends {
+		backends = append(backends, map[string]interface{}{
+			"service": b.Service,
+			"weight":  int64(b.Weight),
+		})
+	}
+	split := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": res.APIVersion,
+		"kind":       trafficSplitKind,
+		"metadata": map[string]interface{}{
+			"name":      params.Name,
+			"namespace": namespace,
+		},
+		"spec": map[string]interface{}{
+			"service":  params.Service,
+			"backends": backends,
+		},
+	}}
+
+	if !del {
+		if err := mesh.rejectIncompatibleSplit(ctx, split, namespace, kubeconfigs); err != nil {
+			return nil, err
+		}
+	}
+
+	byt, err := yaml.Marshal(split.Object)
+	if err != nil {
+		return nil, ErrInvalidTrafficSplit(err)
+	}
+	if err := mesh.applyManifest(byt, del, namespace, kubeconfigs); err != nil {
+		return nil, ErrCustomOperation(err)
+	}
+	return res, nil
+}
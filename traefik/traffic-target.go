@@ -0,0 +1,206 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// trafficTargetKind is the kind of the SMI TrafficTarget resource
+	trafficTargetKind = "TrafficTarget"
+
+	// httpRouteGroupKind is the kind of the SMI HTTPRouteGroup resource
+	httpRouteGroupKind = "HTTPRouteGroup"
+)
+
+// trafficTargetParams are the parameters of the TrafficTarget operation
+type trafficTargetParams struct {
+	// Name is the name of the TrafficTarget, <destination>-target when it is empty
+	Name string `yaml:"name"`
+	// Destination is the service account of the pods the traffic is allowed to
+	Destination string `yaml:"destination" required:"true"`
+	// Sources are the identities allowed to send traffic to the destination
+	Sources []trafficIdentity `yaml:"sources" required:"true"`
+	// Rules are the HTTP routes the sources are allowed to use
+	Rules []trafficRule `yaml:"rules" required:"true"`
+}
+
+// trafficIdentity is a service account, in the namespace of the operation when it has none
+type trafficIdentity struct {
+	ServiceAccount string `yaml:"serviceAccount" required:"true"`
+	Namespace      string `yaml:"namespace"`
+}
+
+// trafficRule allows the matches of an HTTPRouteGroup, all of them when it lists none
+type trafficRule struct {
+	RouteGroup string   `yaml:"routeGroup" required:"true"`
+	Matches    []string `yaml:"matches"`
+}
+
+// trafficTargetResult is the result of the TrafficTarget operation
+type trafficTargetResult struct {
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace"`
+	APIVersion string `json:"api_version"`
+	Deleted    bool   `json:"deleted"`
+}
+
+// validate checks the destination, the sources and the rules are set
+func (p trafficTargetParams) validate() error {
+	if p.Destination == "" {
+		return ErrInvalidOperationParams(fmt.Errorf("destination is required"))
+	}
+	if len(p.Sources) == 0 {
+		return ErrInvalidOperationParams(fmt.Errorf("at least one source is required"))
+	}
+	for i, s := range p.Sources {
+		if s.ServiceAccount == "" {
+			return ErrInvalidOperationParams(fmt.Errorf("sources[%d]: serviceAccount is required", i))
+		}
+	}
+	if len(p.Rules) == 0 {
+		return ErrInvalidOperationParams(fmt.Errorf("at least one rule is required"))
+	}
+	for i, r := range p.Rules {
+		if r.RouteGroup == "" {
+			return ErrInvalidOperationParams(fmt.Errorf("rules[%d]: routeGroup is required", i))
+		}
+	}
+	return nil
+}
+
+// identities returns the service accounts referenced by the TrafficTarget, the destination first
+func (p trafficTargetParams) identities(namespace string) []trafficIdentity {
+	ids := []trafficIdentity{{ServiceAccount: p.Destination, Namespace: namespace}}
+	for _, s := range p.Sources {
+		if s.Namespace == "" {
+			s.Namespace = namespace
+		}
+		ids = append(ids, s)
+	}
+	return ids
+}
+
+// applyTrafficTarget creates, or deletes, the TrafficTarget in every cluster. The target uses the
+// version watched by the installed mesh and is rejected when a service account doesn't exist.
+func (mesh *Mesh) applyTrafficTarget(ctx context.Context, del bool, namespace string, params trafficTargetParams, kubeconfigs []string) (*trafficTargetResult, error) {
+	if params.Name == "" && params.Destination != "" {
+		params.Name = params.Destination + "-target"
+	}
+	if !del {
+		if err := params.validate(); err != nil {
+			return nil, err
+		}
+		if err := rejectMissingServiceAccounts(ctx, params.identities(namespace), kubeconfigs); err != nil {
+			return nil, err
+		}
+	}
+
+	res := &trafficTargetResult{
+		Name:       params.Name,
+		Namespace:  namespace,
+		APIVersion: fmt.Sprintf("%s/%s", smiAccessGroup, supportedSMIVersions(mesh.installedMeshVersion(ctx, kubeconfigs))[smiAccessGroup]),
+		Deleted:    del,
+	}
+	sources := make([]interface{}, 0, len(params.Sources))
+	for _, id := range params.identities(namespace)[1:] {
+		sources = append(sources, map[string]interface{}{
+			"kind":      "ServiceAccount",
+			"name":      id.ServiceAccount,
+			"namespace": id.Namespace,
+		})
+	}
+	rules := make([]interface{}, 0, len(params.Rules))
+	for _, r := range params.Rules {
+		rule := map[string]interface{}{
+			"kind": httpRouteGroupKind,
+			"name": r.RouteGroup,
+		}
+		if len(r.Matches) > 0 {
+			matches := make([]interface{}, 0, len(r.Matches))
+			for _, m := range r.Matches {
+				matches = append(matches, m)
+			}
+			rule["matches"] = matches
+		}
+		rules = append(rules, rule)
+	}
+	target := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": res.APIVersion,
+		"kind":       trafficTargetKind,
+		"metadata": map[string]interface{}{
+			"name":      params.Name,
+			"namespace": namespace,
+		},
+		"spec": map[string]interface{}{
+			"destination": map[string]interface{}{
+				"kind":      "ServiceAccount",
+				"name":      params.Destination,
+				"namespace": namespace,
+			},
+			"sources": sources,
+			"rules":   rules,
+		},
+	}}
+
+	byt, err := yaml.Marshal(target.Object)
+	if err != nil {
+		return nil, ErrEncodeResult(err)
+	}
+	if err := mesh.applyManifest(byt, del, namespace, kubeconfigs); err != nil {
+		return nil, ErrCustomOperation(err)
+	}
+	return res, nil
+}
+
+// rejectMissingServiceAccounts fails with the service accounts which don't exist, per cluster
+func rejectMissingServiceAccounts(ctx context.Context, ids []trafficIdentity, kubeconfigs []string) error {
+	res, err := collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		return missingServiceAccounts(ctx, kClient, ids)
+	})
+	if err != nil {
+		return ErrMissingServiceAccounts(nil, err)
+	}
+	var missing []string
+	for cluster, r := range res {
+		for _, sa := range r.([]string) {
+			missing = append(missing, fmt.Sprintf("%s: %s", cluster, sa))
+		}
+	}
+	if len(missing) != 0 {
+		sort.Strings(missing)
+		return ErrMissingServiceAccounts(missing, nil)
+	}
+	return nil
+}
+
+// missingServiceAccounts returns the service accounts which don't exist in the cluster, as namespace/name
+func missingServiceAccounts(ctx context.Context, kClient *mesherykube.Client, ids []trafficIdentity) ([]string, error) {
+	var missing []string
+	seen := map[trafficIdentity]bool{}
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		err := retryOnTransient(ctx, func() error {
+			_, err := kClient.KubeClient.CoreV1().ServiceAccounts(id.Namespace).Get(ctx, id.ServiceAccount, metav1.GetOptions{})
+			return err
+		})
+		if kubeerrors.IsNotFound(err) {
+			missing = append(missing, fmt.Sprintf("%s/%s", id.Namespace, id.ServiceAccount))
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return missing, nil
+}
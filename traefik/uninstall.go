@@ -0,0 +1,129 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	"helm.sh/helm/v3/pkg/action"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// traefikMeshReleases are the names of the traefik mesh helm releases, maesh being the name used before the rename
+var traefikMeshReleases = []string{traefikMeshChart, "maesh"}
+
+// uninstallResult is the outcome of the uninstall in a cluster
+type uninstallResult struct {
+	// Release is the name of the removed release, empty if traefik mesh was not installed
+	Release   string `json:"release,omitempty"`
+	Namespace string `json:"namespace"`
+	// Backend is the install backend whose resources were removed
+	Backend          string `json:"backend,omitempty"`
+	NamespaceDeleted bool   `json:"namespace_deleted"`
+	Details          string `json:"details"`
+}
+
+// uninstallTraefikMesh removes the traefik mesh release of the namespace in every cluster along with the
+// namespace when the install created it, the manifests applied by the kubectl backend are deleted instead
+// when there is no release. The clusters where traefik mesh is not installed are reported, not failed.
+func (mesh *Mesh) uninstallTraefikMesh(ctx context.Context, namespace string, kubeconfigs []string) (map[string]interface{}, error) {
+	return collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		release, releaseNamespace, err := findTraefikMeshRelease(ctx, kClient, namespace)
+		if err != nil {
+			return nil, ErrUninstall(err)
+		}
+		var res uninstallResult
+		if release == "" {
+			removed, err := uninstallRendered(ctx, kClient, namespace)
+			if err != nil {
+				return nil, err
+			}
+			if !removed {
+				return uninstallResult{
+					Namespace: namespace,
+					Details:   "traefik mesh is not installed, nothing to remove",
+				}, nil
+			}
+			releaseNamespace = namespace
+			res = uninstallResult{
+				Namespace: namespace,
+				Backend:   installBackendKubectl,
+				Details:   "manifests applied by the kubectl backend removed",
+			}
+		} else {
+			// the release holds what it installed, the chart is not needed to remove it
+			cfg, err := mesh.helmActionConfig(kClient, releaseNamespace)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := action.NewUninstall(cfg).Run(release); err != nil {
+				return nil, ErrApplyHelmChart(err)
+			}
+			res = uninstallResult{
+				Release:   release,
+				Namespace: releaseNamespace,
+				Backend:   installBackendHelm,
+				Details:   fmt.Sprintf("helm release %s removed", release),
+			}
+		}
+
+		res.NamespaceDeleted, err = deleteCreatedNamespace(ctx, kClient, releaseNamespace)
+		if err != nil {
+			return nil, ErrUninstall(err)
+		}
+		if res.NamespaceDeleted {
+			res.Details += fmt.Sprintf(", namespace %s deleted", releaseNamespace)
+		} else {
+			res.Details += fmt.Sprintf(", namespace %s kept as it was not created by the adapter", releaseNamespace)
+		}
+		return res, nil
+	})
+}
+
+// uninstallRendered deletes the manifests applied by the kubectl backend in the namespace, rendered from
+// the version recorded by the install. It reports whether traefik mesh was installed with the kubectl backend.
+func uninstallRendered(ctx context.Context, kClient *mesherykube.Client, namespace string) (bool, error) {
+	backend, installed, err := installedBackend(ctx, kClient, namespace)
+	if err != nil {
+		return false, ErrUninstall(err)
+	}
+	if backend != installBackendKubectl {
+		return false, nil
+	}
+	if installed == "" {
+		return false, ErrUninstall(fmt.Errorf("the version installed with the kubectl backend in namespace %s is not recorded", namespace))
+	}
+	chartPath, err := fetchChart(ctx, traefikMeshRepository(), traefikMeshChart, installed)
+	if err != nil {
+		return false, ErrApplyHelmChart(err)
+	}
+	if err := applyRenderedChart(kClient, chartPath, true, namespace, nil); err != nil {
+		return false, ErrApplyHelmChart(err)
+	}
+	if err := recordInstallBackend(ctx, kClient, namespace, "", ""); err != nil {
+		return false, ErrUninstall(err)
+	}
+	return true, nil
+}
+
+// findTraefikMeshRelease returns the name and the namespace of the traefik mesh release installed in the namespace, if any
+func findTraefikMeshRelease(ctx context.Context, kClient *mesherykube.Client, namespace string) (string, string, error) {
+	selector := fmt.Sprintf("owner=helm,name in (%s)", strings.Join(traefikMeshReleases, ","))
+	var secrets *corev1.SecretList
+	err := retryOnTransient(ctx, func() (err error) {
+		secrets, err = kClient.KubeClient.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+		return err
+	})
+	if err != nil {
+		return "", "", err
+	}
+	for _, s := range secrets.Items {
+		// uninstalled releases kept with --keep-history are not an install
+		if s.Labels["status"] != "uninstalled" {
+			return s.Labels["name"], s.Namespace, nil
+		}
+	}
+	return "", "", nil
+}
@@ -0,0 +1,172 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/layer5io/meshery-adapter-library/meshes"
+	internalconfig "github.com/layer5io/meshery-traefik-mesh/internal/config"
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// upgradeParams are the parameters of the upgrade operation
+type upgradeParams struct {
+	// Version is the version to upgrade to, the latest one offered is used when it is empty
+	Version string `yaml:"version"`
+	// ReuseValues keeps the values of the installed release, the values of the request are merged
+	// over them. The values of the profile and of the request replace them otherwise, the install
+	// mode of the release is kept either way.
+	ReuseValues bool `yaml:"reuseValues"`
+	// Profile selects the set of helm values applied when the values are not reused
+	Profile string `yaml:"profile"`
+	// Values are the helm values merged over the ones of the profile, or of the release
+	Values map[string]interface{} `yaml:"values"`
+	// ValuesYAML holds helm values as a YAML document, Values are merged over them
+	ValuesYAML string `yaml:"valuesYaml"`
+
+	namespaceLockParams `yaml:",inline"`
+	timeoutParams       `yaml:",inline"`
+}
+
+// upgradeResult is the outcome of the upgrade in a cluster
+type upgradeResult struct {
+	Release     string `json:"release"`
+	Namespace   string `json:"namespace"`
+	FromVersion string `json:"from_version"`
+	ToVersion   string `json:"to_version"`
+	Revision    int    `json:"revision"`
+}
+
+// upgradeValues returns the helm values of the upgrade, they are validated against the chart
+func (mesh *Mesh) upgradeValues(params upgradeParams, chartPath, namespace string) (map[string]interface{}, error) {
+	values, err := requestValues(installParams{Values: params.Values, ValuesYAML: params.ValuesYAML})
+	if err != nil {
+		return nil, err
+	}
+	if !params.ReuseValues {
+		profile, err := internalconfig.ResolveProfile(mesh.Config, params.Profile)
+		if err != nil {
+			return nil, err
+		}
+		values = internalconfig.MergeValues(internalconfig.Profiles[profile], values)
+	}
+	if len(values) == 0 {
+		return values, nil
+	}
+	if err := validateHelmValues(chartPath, namespace, values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// upgradeTraefikMesh upgrades the traefik mesh release of the namespace to the version in every cluster and
+// waits for the rollout, the versions upgraded from and to are streamed along e. A failed upgrade is rolled
+// back to the revision which was deployed.
+func (mesh *Mesh) upgradeTraefikMesh(ctx context.Context, version, namespace string, params upgradeParams, e *meshes.EventsResponse, kubeconfigs []string) (map[string]interface{}, error) {
+	chartPath, err := fetchChart(ctx, traefikMeshRepository(), traefikMeshChart, version)
+	if err != nil {
+		return nil, err
+	}
+	ch, err := loader.Load(chartPath)
+	if err != nil {
+		return nil, ErrApplyHelmChart(err)
+	}
+	values, err := mesh.upgradeValues(params, chartPath, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectFromClusters(kubeconfigs, func(kClient *mesherykube.Client) (interface{}, error) {
+		release, releaseNamespace, err := findTraefikMeshRelease(ctx, kClient, namespace)
+		if err != nil {
+			return nil, ErrUpgrade(err)
+		}
+		if release == "" {
+			return nil, ErrUpgrade(fmt.Errorf("traefik mesh is not installed with helm in namespace %s", namespace))
+		}
+		cfg, err := mesh.helmActionConfig(kClient, releaseNamespace)
+		if err != nil {
+			return nil, ErrUpgrade(err)
+		}
+		deployed, err := action.NewGet(cfg).Run(release)
+		if err != nil {
+			return nil, ErrUpgrade(err)
+		}
+		res := upgradeResult{
+			Release:     release,
+			Namespace:   releaseNamespace,
+			FromVersion: normalizeVersion(deployed.Chart.Metadata.AppVersion),
+			ToVersion:   version,
+		}
+		installed := valuesMode(deployed.Config)
+		if requested := valuesMode(values); requested != "" && installed != "" && requested != installed {
+			return nil, ErrUpgrade(fmt.Errorf("release %s is installed in %s mode, the upgrade doesn't switch it to %s mode, install it again to change the mode", release, installed, requested))
+		}
+		releaseValues := values
+		if !params.ReuseValues && installed != "" {
+			// the values of the release are replaced, not the install mode it was installed in
+			releaseValues = internalconfig.MergeValues(values, modeValues(installed))
+		}
+		mesh.streamUpdate(e, fmt.Sprintf("Upgrading Traefik service mesh from %s to %s in %s", res.FromVersion, res.ToVersion, kClient.RestConfig.Host), "")
+
+		upgrade := action.NewUpgrade(cfg)
+		upgrade.Namespace = releaseNamespace
+		upgrade.ReuseValues = params.ReuseValues
+		upgraded, err := upgrade.RunWithContext(ctx, release, ch, releaseValues)
+		if err == nil {
+			res.Revision = upgraded.Version
+			err = waitForMeshRollout(ctx, kClient, releaseNamespace)
+		}
+		if err != nil {
+			return nil, ErrUpgrade(mesh.rollbackUpgrade(cfg, release, deployed.Version, res.FromVersion, err))
+		}
+		if err := recordInstallBackend(ctx, kClient, releaseNamespace, installBackendHelm, version); err != nil {
+			return nil, ErrUpgrade(err)
+		}
+		return res, nil
+	})
+}
+
+// rollbackUpgrade rolls the release back to the revision, it returns the upgrade error along with the
+// outcome of the rollback
+func (mesh *Mesh) rollbackUpgrade(cfg *action.Configuration, release string, revision int, version string, upgradeErr error) error {
+	rollback := action.NewRollback(cfg)
+	rollback.Version = revision
+	if err := rollback.Run(release); err != nil {
+		return fmt.Errorf("%v, the rollback to revision %d (%s) failed too: %v", upgradeErr, revision, version, err)
+	}
+	return fmt.Errorf("%v, rolled back to revision %d (%s)", upgradeErr, revision, version)
+}
+
+// waitForMeshRollout waits for the controllers and the proxies of the namespace to run their updated pods
+func waitForMeshRollout(ctx context.Context, kClient *mesherykube.Client, namespace string) error {
+	var deps *appsv1.DeploymentList
+	err := retryOnTransient(ctx, func() (err error) {
+		deps, err = kClient.KubeClient.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{LabelSelector: controllerSelector})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	var dss *appsv1.DaemonSetList
+	err = retryOnTransient(ctx, func() (err error) {
+		dss, err = kClient.KubeClient.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{LabelSelector: proxyPodSelector})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	workloads := make([]scaledWorkload, 0, len(deps.Items)+len(dss.Items))
+	for _, dep := range deps.Items {
+		workloads = append(workloads, scaledWorkload{Kind: "Deployment", Name: dep.Name, Namespace: namespace})
+	}
+	for _, ds := range dss.Items {
+		workloads = append(workloads, scaledWorkload{Kind: "DaemonSet", Name: ds.Name, Namespace: namespace})
+	}
+	return waitForRollout(ctx, kClient, namespace, workloads)
+}
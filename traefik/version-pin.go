@@ -0,0 +1,83 @@
+package traefik
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/layer5io/meshery-traefik-mesh/internal/config"
+)
+
+// nearestVersionsCount is the number of available versions suggested for a version which isn't available
+const nearestVersionsCount = 3
+
+// resolvePinnedVersion checks that the requested version is a release of traefik mesh
+// with a chart in the helm repository, it returns the version to install
+func resolvePinnedVersion(ctx context.Context, version string) (string, error) {
+	index, err := fetchHelmIndex(ctx, traefikMeshRepository())
+	if err != nil {
+		return "", err
+	}
+	available := make([]string, 0, len(index.Entries[traefikMeshChart]))
+	for _, entry := range index.Entries[traefikMeshChart] {
+		available = append(available, normalizeVersion(entry.AppVersion))
+	}
+
+	if _, ok := index.entryForAppVersion(traefikMeshChart, version); !ok {
+		return "", ErrVersionNotAvailable(version, nearestVersions(version, available, nearestVersionsCount))
+	}
+
+	// the releases are checked when GitHub is reachable, e.g. to reject the drafts, the
+	// lookup is bounded so that it doesn't hold up the install when it isn't
+	ctx, cancel := context.WithTimeout(ctx, config.VersionsTimeout())
+	defer cancel()
+	releases, err := config.GetLatestReleases(ctx, 100)
+	if err != nil || len(releases) == 0 {
+		return version, nil
+	}
+	for _, release := range releases {
+		if !release.Draft && normalizeVersion(string(release.Name)) == normalizeVersion(version) {
+			return version, nil
+		}
+	}
+	return "", ErrVersionNotAvailable(version, nearestVersions(version, available, nearestVersionsCount))
+}
+
+// nearestVersions returns at most count versions of available, the closest to version first
+func nearestVersions(version string, available []string, count int) []string {
+	target := versionNumbers(version)
+	nearest := append([]string{}, available...)
+	sort.SliceStable(nearest, func(i, j int) bool {
+		return versionDistance(target, versionNumbers(nearest[i])) < versionDistance(target, versionNumbers(nearest[j]))
+	})
+	if count > len(nearest) {
+		count = len(nearest)
+	}
+	return nearest[:count]
+}
+
+// versionNumbers returns the major, minor and patch numbers of the version, the missing or invalid ones are 0
+func versionNumbers(version string) [3]int {
+	var nums [3]int
+	version = strings.TrimPrefix(version, "v")
+	// the pre-release and build metadata don't matter to find the nearest versions
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	for i, part := range strings.SplitN(version, ".", 3) {
+		nums[i], _ = strconv.Atoi(part)
+	}
+	return nums
+}
+
+// versionDistance weighs the difference of the major versions over the minor versions over the patches
+func versionDistance(a, b [3]int) int {
+	abs := func(i int) int {
+		if i < 0 {
+			return -i
+		}
+		return i
+	}
+	return abs(a[0]-b[0])*1e6 + abs(a[1]-b[1])*1e3 + abs(a[2]-b[2])
+}
@@ -0,0 +1,263 @@
+package traefik
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	mesherykube "github.com/layer5io/meshkit/utils/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// podDiagnosis describes why a pod of a workload isn't ready, or why a node doesn't run a pod of a DaemonSet
+type podDiagnosis struct {
+	Workload string `json:"workload"`
+	Pod      string `json:"pod,omitempty"`
+	Node     string `json:"node,omitempty"`
+	// Reason is e.g. Unschedulable, CrashLoopBackOff, ImagePullBackOff or UntoleratedTaint
+	Reason  string `json:"reason"`
+	Message string `json:"message,omitempty"`
+}
+
+func (d podDiagnosis) String() string {
+	subject := d.Workload
+	switch {
+	case d.Pod != "":
+		subject += " pod " + d.Pod
+	case d.Node != "":
+		subject += " on node " + d.Node
+	}
+	if d.Message == "" {
+		return fmt.Sprintf("%s: %s", subject, d.Reason)
+	}
+	return fmt.Sprintf("%s: %s: %s", subject, d.Reason, d.Message)
+}
+
+// waitForRollout waits for the workloads to run their updated pods. On each poll the pods of the workloads
+// still rolling out are diagnosed. It gives up after the readiness timeout of the operation, listing the
+// workloads which rolled out, the ones which didn't and the last diagnosis of their pods.
+func waitForRollout(ctx context.Context, kClient *mesherykube.Client, namespace string, workloads []scaledWorkload) error {
+	rolled := make(map[int]bool, len(workloads))
+	var diagnoses []podDiagnosis
+	b := readinessBackoff(ctx)
+	err := backoff.Retry(func() error {
+		diagnoses = nil
+		var inProgress error
+		for i, w := range workloads {
+			if rolled[i] {
+				continue
+			}
+			done, err := rolledOut(ctx, kClient, namespace, w)
+			if err != nil {
+				return err
+			}
+			if done {
+				rolled[i] = true
+				continue
+			}
+			if inProgress == nil {
+				inProgress = fmt.Errorf("the rollout of %s %s/%s is in progress", w.Kind, namespace, w.Name)
+			}
+			found, err := diagnoseWorkload(ctx, kClient, namespace, w)
+			if err != nil {
+				return err
+			}
+			diagnoses = append(diagnoses, found...)
+		}
+		return inProgress
+	}, backoff.WithContext(b, ctx))
+	if err != nil {
+		var ready, pending []string
+		for i, w := range workloads {
+			name := fmt.Sprintf("%s %s/%s", w.Kind, namespace, w.Name)
+			if rolled[i] {
+				ready = append(ready, name)
+			} else {
+				pending = append(pending, name)
+			}
+		}
+		return ErrReadinessTimeout(b.GetElapsedTime().Round(time.Second), ready, pending, describeDiagnoses(diagnoses), err)
+	}
+	return nil
+}
+
+// rolledOut reports whether every pod of the workload is updated and available
+func rolledOut(ctx context.Context, kClient *mesherykube.Client, namespace string, w scaledWorkload) (bool, error) {
+	if w.Kind == "Deployment" {
+		var dep *appsv1.Deployment
+		err := retryOnTransient(ctx, func() (err error) {
+			dep, err = kClient.KubeClient.AppsV1().Deployments(namespace).Get(ctx, w.Name, metav1.GetOptions{})
+			return err
+		})
+		if err != nil {
+			return false, err
+		}
+		st := dep.Status
+		return st.ObservedGeneration >= dep.Generation && st.UpdatedReplicas == *dep.Spec.Replicas &&
+			st.Replicas == *dep.Spec.Replicas && st.AvailableReplicas == *dep.Spec.Replicas, nil
+	}
+	var ds *appsv1.DaemonSet
+	err := retryOnTransient(ctx, func() (err error) {
+		ds, err = kClient.KubeClient.AppsV1().DaemonSets(namespace).Get(ctx, w.Name, metav1.GetOptions{})
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	st := ds.Status
+	return st.ObservedGeneration >= ds.Generation && st.UpdatedNumberScheduled == st.DesiredNumberScheduled &&
+		st.NumberAvailable == st.DesiredNumberScheduled, nil
+}
+
+// diagnoseWorkload returns the reasons why the pods of the Deployment or DaemonSet aren't ready. The nodes
+// a DaemonSet can't run on because of a taint it doesn't tolerate are reported too.
+func diagnoseWorkload(ctx context.Context, kClient *mesherykube.Client, namespace string, w scaledWorkload) ([]podDiagnosis, error) {
+	var selector *metav1.LabelSelector
+	var ds *appsv1.DaemonSet
+	err := retryOnTransient(ctx, func() error {
+		if w.Kind == "Deployment" {
+			dep, err := kClient.KubeClient.AppsV1().Deployments(namespace).Get(ctx, w.Name, metav1.GetOptions{})
+			if err == nil {
+				selector = dep.Spec.Selector
+			}
+			return err
+		}
+		var err error
+		ds, err = kClient.KubeClient.AppsV1().DaemonSets(namespace).Get(ctx, w.Name, metav1.GetOptions{})
+		if err == nil {
+			selector = ds.Spec.Selector
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	sel, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	var pods *corev1.PodList
+	err = retryOnTransient(ctx, func() (err error) {
+		pods, err = kClient.KubeClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: sel.String()})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	workload := fmt.Sprintf("%s %s/%s", w.Kind, namespace, w.Name)
+	var diagnoses []podDiagnosis
+	for _, pod := range pods.Items {
+		if podReady(pod) {
+			continue
+		}
+		d := diagnosePod(pod)
+		d.Workload = workload
+		diagnoses = append(diagnoses, d)
+	}
+	if ds != nil {
+		found, err := diagnoseTaintedNodes(ctx, kClient, workload, ds, pods.Items)
+		if err != nil {
+			return nil, err
+		}
+		diagnoses = append(diagnoses, found...)
+	}
+	return diagnoses, nil
+}
+
+// diagnosePod returns the reason why the pod isn't ready: it can't be scheduled, a container is waiting,
+// e.g. for its image to be pulled or to be restarted after a crash, or it fails its readiness probe
+func diagnosePod(pod corev1.Pod) podDiagnosis {
+	d := podDiagnosis{Pod: pod.Name, Node: pod.Spec.NodeName}
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodScheduled && c.Status == corev1.ConditionFalse {
+			d.Reason, d.Message = c.Reason, c.Message
+			return d
+		}
+	}
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, cs := range statuses {
+		if waiting := cs.State.Waiting; waiting != nil && waiting.Reason != "" && waiting.Reason != "PodInitializing" && waiting.Reason != "ContainerCreating" {
+			d.Reason = waiting.Reason
+			d.Message = fmt.Sprintf("container %s: %s", cs.Name, waiting.Message)
+			if last := cs.LastTerminationState.Terminated; last != nil {
+				d.Message += fmt.Sprintf(", last exited with code %d (%s)", last.ExitCode, last.Reason)
+			}
+			return d
+		}
+	}
+	for _, cs := range statuses {
+		if !cs.Ready && cs.State.Running != nil {
+			d.Reason = "NotReady"
+			d.Message = fmt.Sprintf("container %s is running but not ready, restarted %d times", cs.Name, cs.RestartCount)
+			return d
+		}
+	}
+	d.Reason = string(pod.Status.Phase)
+	d.Message = pod.Status.Message
+	return d
+}
+
+// diagnoseTaintedNodes returns the nodes selected by the DaemonSet which run none of its pods because
+// they carry a taint the pods don't tolerate
+func diagnoseTaintedNodes(ctx context.Context, kClient *mesherykube.Client, workload string, ds *appsv1.DaemonSet, pods []corev1.Pod) ([]podDiagnosis, error) {
+	var nodes *corev1.NodeList
+	err := retryOnTransient(ctx, func() (err error) {
+		nodes, err = kClient.KubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	running := make(map[string]bool, len(pods))
+	for _, pod := range pods {
+		running[pod.Spec.NodeName] = true
+	}
+	nodeSelector := labels.SelectorFromSet(ds.Spec.Template.Spec.NodeSelector)
+
+	var diagnoses []podDiagnosis
+	for _, node := range nodes.Items {
+		if running[node.Name] || !nodeSelector.Matches(labels.Set(node.Labels)) {
+			continue
+		}
+		for _, taint := range node.Spec.Taints {
+			taint := taint
+			if taint.Effect == corev1.TaintEffectPreferNoSchedule || tolerated(ds.Spec.Template.Spec.Tolerations, &taint) {
+				continue
+			}
+			diagnoses = append(diagnoses, podDiagnosis{
+				Workload: workload,
+				Node:     node.Name,
+				Reason:   "UntoleratedTaint",
+				Message:  fmt.Sprintf("the node has the taint %s which the pods don't tolerate", taint.ToString()),
+			})
+			break
+		}
+	}
+	return diagnoses, nil
+}
+
+// tolerated reports whether one of the tolerations tolerates the taint
+func tolerated(tolerations []corev1.Toleration, taint *corev1.Taint) bool {
+	for _, t := range tolerations {
+		if t.ToleratesTaint(taint) {
+			return true
+		}
+	}
+	return false
+}
+
+// describeDiagnoses returns the diagnoses in order, one per line
+func describeDiagnoses(diagnoses []podDiagnosis) []string {
+	lines := make([]string, 0, len(diagnoses))
+	for _, d := range diagnoses {
+		lines = append(lines, d.String())
+	}
+	sort.Strings(lines)
+	return lines
+}